@@ -7,14 +7,19 @@ package main
 
 import (
 	"crypto/ed25519"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/BurntSushi/toml"
@@ -23,10 +28,13 @@ import (
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla"
 	"github.com/dtn7/dtn7-go/pkg/cla/bbc"
+	"github.com/dtn7/dtn7-go/pkg/cla/httpcl"
 	"github.com/dtn7/dtn7-go/pkg/cla/mtcp"
 	"github.com/dtn7/dtn7-go/pkg/cla/tcpclv4"
+	"github.com/dtn7/dtn7-go/pkg/cla/udpcl"
 	"github.com/dtn7/dtn7-go/pkg/discovery"
 	"github.com/dtn7/dtn7-go/pkg/routing"
+	"github.com/dtn7/dtn7-go/pkg/storage"
 )
 
 // tomlConfig describes the TOML-configuration.
@@ -35,6 +43,7 @@ type tomlConfig struct {
 	Logging   logConf
 	Discovery discoveryConf
 	Agents    agentsConfig
+	Metrics   metricsConf
 	Listen    []convergenceConf
 	Peer      []convergenceConf
 	Routing   routing.RoutingConf
@@ -42,10 +51,33 @@ type tomlConfig struct {
 
 // coreConf describes the Core-configuration block.
 type coreConf struct {
-	Store             string
-	InspectAllBundles bool   `toml:"inspect-all-bundles"`
-	NodeId            string `toml:"node-id"`
-	SignPriv          string `toml:"signature-private"`
+	Store              string
+	InspectAllBundles  bool   `toml:"inspect-all-bundles"`
+	NodeId             string `toml:"node-id"`
+	SignPriv           string `toml:"signature-private"`
+	StoreCapacity      int    `toml:"store-capacity"`
+	Eviction           string
+	OutboundQueue      bool   `toml:"outbound-queue"`
+	LenientDecoding    bool   `toml:"lenient-decoding"`
+	CRCPolicy          string `toml:"crc-policy"`
+	AdminRecordPolicy  string `toml:"admin-record-policy"`
+	TransmitPolicy     string `toml:"transmit-policy"`
+	FuturePolicy       string `toml:"future-policy"`
+	FutureTolerance    string `toml:"future-tolerance"`
+	DeliveryOrder      string `toml:"delivery-order"`
+	MaxBandwidth       uint64 `toml:"max-bandwidth"`
+	RetainDelivered    bool   `toml:"retain-delivered"`
+	ForwardingTimeout  string `toml:"forwarding-timeout"`
+	ForwardConcurrency int    `toml:"forward-concurrency"`
+	ReassemblyTimeout  string `toml:"reassembly-timeout"`
+	FragmentMinPayload int    `toml:"fragment-min-payload"`
+	Shutdown           string
+	ShutdownTimeout    string `toml:"shutdown-timeout"`
+	JanitorInterval    string `toml:"janitor-interval"`
+	RetryBackoff       string `toml:"retry-backoff"`
+	RetryBackoffMax    string `toml:"retry-backoff-max"`
+	Throttle           routing.ThrottleConfig
+	TrustedKeys        map[string]string `toml:"trusted-keys"`
 }
 
 // logConf describes the Logging-configuration block.
@@ -60,6 +92,28 @@ type discoveryConf struct {
 	IPv4     bool
 	IPv6     bool
 	Interval uint
+	Format   string
+	Address4 string
+	Address6 string
+	Port     uint16
+}
+
+// parseDiscoveryConf creates a discovery.ManagerConfig for the discoveryConf.
+func parseDiscoveryConf(conf discoveryConf) (config discovery.ManagerConfig, err error) {
+	switch conf.Format {
+	case "", "cbor":
+		config.Format = discovery.FormatCBOR
+	case "ipnd":
+		config.Format = discovery.FormatIPND
+	default:
+		err = fmt.Errorf("unknown discovery.format \"%s\"", conf.Format)
+		return
+	}
+
+	config.Address4 = conf.Address4
+	config.Address6 = conf.Address6
+	config.Port = conf.Port
+	return
 }
 
 // agentsConfig describes the ApplicationAgents/Agent-configuration block.
@@ -73,14 +127,101 @@ type agentsWebserverConfig struct {
 	Address   string
 	Websocket bool
 	Rest      bool
+	RestAcks  bool `toml:"rest-acks"`
+	Stream    agentsStreamConfig
+}
+
+// agentsStreamConfig describes the nested "Stream" configuration for the webserver's WebSocketStreamAgent.
+type agentsStreamConfig struct {
+	Endpoint    string
+	Destination string
+	Lifetime    string
+	ReadLimit   int64 `toml:"read-limit"`
+}
+
+// metricsConf describes the Metrics-configuration block.
+type metricsConf struct {
+	Address string
+	Path    string
 }
 
 // convergenceConf describes the Convergence-configuration block, used for
 // "listen" and "peer".
 type convergenceConf struct {
-	Node     string
-	Protocol string
-	Endpoint string
+	Node         string
+	Protocol     string
+	Endpoint     string
+	MaxTransfers int `toml:"max-transfers"`
+
+	// The following fields are used by HTTPCL only.
+	Timeout     string
+	MaxBodySize int64  `toml:"max-body-size"`
+	TLSCert     string `toml:"tls-cert"`
+	TLSKey      string `toml:"tls-key"`
+	TLSCA       string `toml:"tls-ca"`
+}
+
+// parseHTTPTimeout parses an HTTPCL's timeout. An empty string results in the default timeout.
+func parseHTTPTimeout(conv convergenceConf, setTimeout func(time.Duration)) error {
+	if conv.Timeout == "" {
+		return nil
+	}
+
+	timeout, err := time.ParseDuration(conv.Timeout)
+	if err != nil {
+		return err
+	}
+	setTimeout(timeout)
+	return nil
+}
+
+// parseHTTPServer creates an HTTPServer, listening for HTTPCL requests.
+func parseHTTPServer(conv convergenceConf, nodeId bpv7.EndpointID) (*httpcl.HTTPServer, error) {
+	serv := httpcl.NewHTTPServer(conv.Endpoint, nodeId, true)
+
+	if err := parseHTTPTimeout(conv, serv.SetTimeout); err != nil {
+		return nil, err
+	}
+	if conv.MaxBodySize > 0 {
+		serv.SetMaxBodySize(conv.MaxBodySize)
+	}
+	if conv.TLSCert != "" || conv.TLSKey != "" {
+		cert, err := tls.LoadX509KeyPair(conv.TLSCert, conv.TLSKey)
+		if err != nil {
+			return nil, err
+		}
+		serv.SetTLSConfig(&tls.Config{Certificates: []tls.Certificate{cert}})
+	}
+
+	return serv, nil
+}
+
+// parseHTTPClient creates an HTTPClient, sending HTTPCL requests to a peer.
+func parseHTTPClient(conv convergenceConf) (*httpcl.HTTPClient, error) {
+	endpointID, err := bpv7.NewEndpointID(conv.Node)
+	if err != nil {
+		return nil, err
+	}
+
+	client := httpcl.NewHTTPClient(conv.Endpoint, endpointID, true)
+
+	if err := parseHTTPTimeout(conv, client.SetTimeout); err != nil {
+		return nil, err
+	}
+	if conv.TLSCA != "" {
+		pem, err := ioutil.ReadFile(conv.TLSCA)
+		if err != nil {
+			return nil, err
+		}
+
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", conv.TLSCA)
+		}
+		client.SetTLSConfig(&tls.Config{RootCAs: rootCAs})
+	}
+
+	return client, nil
 }
 
 func parseListenPort(endpoint string) (port int, err error) {
@@ -119,6 +260,13 @@ func parseListen(conv convergenceConf, nodeId bpv7.EndpointID) (cla.Convergable,
 		conn, err := bbc.NewBundleBroadcastingConnector(conv.Endpoint, true)
 		return conn, nodeId, cla.BBC, discovery.Announcement{}, err
 
+	case "httpcl":
+		serv, err := parseHTTPServer(conv, nodeId)
+		if err != nil {
+			return nil, nodeId, cla.HTTPCL, discovery.Announcement{}, err
+		}
+		return serv, nodeId, cla.HTTPCL, discovery.Announcement{}, nil
+
 	case "mtcp":
 		portInt, err := parseListenPort(conv.Endpoint)
 		if err != nil {
@@ -140,6 +288,7 @@ func parseListen(conv convergenceConf, nodeId bpv7.EndpointID) (cla.Convergable,
 		}
 
 		listener := tcpclv4.ListenTCP(conv.Endpoint, nodeId)
+		listener.SetMaxTransfers(conv.MaxTransfers)
 
 		msg := discovery.Announcement{
 			Type:     cla.TCPCLv4,
@@ -149,8 +298,23 @@ func parseListen(conv convergenceConf, nodeId bpv7.EndpointID) (cla.Convergable,
 
 		return listener, nodeId, cla.TCPCLv4, msg, nil
 
+	case "udpcl":
+		portInt, err := parseListenPort(conv.Endpoint)
+		if err != nil {
+			return nil, nodeId, cla.UDPCL, discovery.Announcement{}, err
+		}
+
+		msg := discovery.Announcement{
+			Type:     cla.UDPCL,
+			Endpoint: nodeId,
+			Port:     uint(portInt),
+		}
+
+		return udpcl.NewUDPServer(conv.Endpoint, nodeId, true), nodeId, cla.UDPCL, msg, nil
+
 	case "tcpclv4-ws":
 		listener := tcpclv4.ListenWebSocket(nodeId)
+		listener.SetMaxTransfers(conv.MaxTransfers)
 
 		httpMux := http.NewServeMux()
 		httpMux.Handle("/tcpclv4", listener)
@@ -178,6 +342,13 @@ func parseListen(conv convergenceConf, nodeId bpv7.EndpointID) (cla.Convergable,
 func parsePeer(conv convergenceConf, nodeId bpv7.EndpointID) (cla.ConvergenceSender, error) {
 
 	switch conv.Protocol {
+	case "httpcl":
+		if client, err := parseHTTPClient(conv); err != nil {
+			return nil, err
+		} else {
+			return client, nil
+		}
+
 	case "mtcp":
 		if endpointID, err := bpv7.NewEndpointID(conv.Node); err != nil {
 			return nil, err
@@ -185,17 +356,52 @@ func parsePeer(conv convergenceConf, nodeId bpv7.EndpointID) (cla.ConvergenceSen
 			return mtcp.NewMTCPClient(conv.Endpoint, endpointID, true), nil
 		}
 
+	case "udpcl":
+		if endpointID, err := bpv7.NewEndpointID(conv.Node); err != nil {
+			return nil, err
+		} else {
+			return udpcl.NewUDPClient(conv.Endpoint, endpointID, true), nil
+		}
+
 	case "tcpclv4":
-		return tcpclv4.DialTCP(conv.Endpoint, nodeId, true), nil
+		client := tcpclv4.DialTCP(conv.Endpoint, nodeId, true)
+		client.SetMaxTransfers(conv.MaxTransfers)
+		return client, nil
 
 	case "tcpclv4-ws":
-		return tcpclv4.DialWebSocket(conv.Endpoint, nodeId, true), nil
+		client := tcpclv4.DialWebSocket(conv.Endpoint, nodeId, true)
+		client.SetMaxTransfers(conv.MaxTransfers)
+		return client, nil
 
 	default:
 		return nil, fmt.Errorf("unknown peer.protocol \"%s\"", conv.Protocol)
 	}
 }
 
+// parseTrustedKeys maps node IDs to their hex encoded ed25519 public keys.
+func parseTrustedKeys(conf map[string]string) (keys map[bpv7.EndpointID]ed25519.PublicKey, err error) {
+	keys = make(map[bpv7.EndpointID]ed25519.PublicKey)
+	for node, keyHex := range conf {
+		eid, eidErr := bpv7.NewEndpointID(node)
+		if eidErr != nil {
+			err = eidErr
+			return
+		}
+
+		key, keyErr := hex.DecodeString(keyHex)
+		if keyErr != nil {
+			err = keyErr
+			return
+		} else if len(key) != ed25519.PublicKeySize {
+			err = fmt.Errorf("trusted key of %v has a length of %d bytes, not %d", eid, len(key), ed25519.PublicKeySize)
+			return
+		}
+
+		keys[eid] = key
+	}
+	return
+}
+
 // parseAgents for the ApplicationAgents.
 func parseAgents(conf agentsConfig) (agents []agent.ApplicationAgent, err error) {
 	if conf.Ping != "" {
@@ -208,8 +414,9 @@ func parseAgents(conf agentsConfig) (agents []agent.ApplicationAgent, err error)
 	}
 
 	if (conf.Webserver != agentsWebserverConfig{}) {
-		if !conf.Webserver.Websocket && !conf.Webserver.Rest {
-			err = fmt.Errorf("webserver agent needs at least one of Websocket or REST")
+		stream := conf.Webserver.Stream != agentsStreamConfig{}
+		if !conf.Webserver.Websocket && !conf.Webserver.Rest && !stream {
+			err = fmt.Errorf("webserver agent needs at least one of Websocket, REST or Stream")
 			return
 		}
 
@@ -225,10 +432,22 @@ func parseAgents(conf agentsConfig) (agents []agent.ApplicationAgent, err error)
 		if conf.Webserver.Rest {
 			restRouter := r.PathPrefix("/rest").Subrouter()
 			ra := agent.NewRestAgent(restRouter)
+			ra.SetApplicationAcks(conf.Webserver.RestAcks)
 
 			agents = append(agents, ra)
 		}
 
+		if stream {
+			wsa, wsaErr := parseStreamAgent(conf.Webserver.Stream)
+			if wsaErr != nil {
+				err = wsaErr
+				return
+			}
+			r.HandleFunc("/stream", wsa.ServeHTTP)
+
+			agents = append(agents, wsa)
+		}
+
 		httpServer := &http.Server{
 			Addr:    conf.Webserver.Address,
 			Handler: r,
@@ -249,6 +468,80 @@ func parseAgents(conf agentsConfig) (agents []agent.ApplicationAgent, err error)
 	return
 }
 
+// parseStreamAgent creates a WebSocketStreamAgent. Bundles are sent with a lifetime of one day, if not configured.
+func parseStreamAgent(conf agentsStreamConfig) (wsa *agent.WebSocketStreamAgent, err error) {
+	endpoint, err := bpv7.NewEndpointID(conf.Endpoint)
+	if err != nil {
+		return
+	}
+
+	destination, err := bpv7.NewEndpointID(conf.Destination)
+	if err != nil {
+		return
+	}
+
+	lifetime := 24 * time.Hour
+	if conf.Lifetime != "" {
+		if lifetime, err = time.ParseDuration(conf.Lifetime); err != nil {
+			return
+		}
+	}
+
+	wsa = agent.NewWebSocketStreamAgent(endpoint, destination, lifetime)
+	if conf.ReadLimit > 0 {
+		wsa.SetReadLimit(conf.ReadLimit)
+	}
+	return
+}
+
+// parseMetrics registers the Core's metrics and serves them over HTTP for Prometheus.
+func parseMetrics(conf metricsConf, c *routing.Core) (err error) {
+	if conf.Path == "" {
+		conf.Path = "/metrics"
+	}
+
+	reg := prometheus.NewRegistry()
+	if err = c.SetMetrics(reg); err != nil {
+		return
+	}
+
+	httpMux := http.NewServeMux()
+	httpMux.Handle(conf.Path, promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+
+	httpServer := &http.Server{
+		Addr:    conf.Address,
+		Handler: httpMux,
+	}
+
+	errChan := make(chan error)
+	go func() { errChan <- httpServer.ListenAndServe() }()
+
+	select {
+	case err = <-errChan:
+		return
+
+	case <-time.After(100 * time.Millisecond):
+		break
+	}
+
+	return
+}
+
+// reloadRoutes replaces the routes of a StaticRouting by those currently configured in the file.
+func reloadRoutes(filename string, c *routing.Core) error {
+	sr, ok := c.RoutingAlgorithm().(*routing.StaticRouting)
+	if !ok {
+		return fmt.Errorf("routing algorithm %v has no static routes", c.RoutingAlgorithm())
+	}
+
+	var conf tomlConfig
+	if _, err := toml.DecodeFile(filename, &conf); err != nil {
+		return err
+	}
+
+	return sr.UpdateRoutes(conf.Routing.StaticConf)
+}
+
 // parseCore creates the Core based on the given TOML configuration.
 func parseCore(filename string) (c *routing.Core, ds *discovery.Manager, err error) {
 	var conf tomlConfig
@@ -315,6 +608,145 @@ func parseCore(filename string) (c *routing.Core, ds *discovery.Manager, err err
 	if c, err = routing.NewCore(conf.Core.Store, nodeId, conf.Core.InspectAllBundles, conf.Routing, signPriv); err != nil {
 		return
 	}
+	if conf.Core.LenientDecoding {
+		c.SetDecodeMode(bpv7.LenientDecoding)
+	}
+	c.SetThrottle(conf.Core.Throttle)
+
+	if conf.Core.TrustedKeys != nil {
+		if trustedKeys, trustedKeysErr := parseTrustedKeys(conf.Core.TrustedKeys); trustedKeysErr != nil {
+			err = trustedKeysErr
+			return
+		} else {
+			c.SetTrustedKeys(trustedKeys)
+		}
+	}
+	c.SetBandwidthLimit(conf.Core.MaxBandwidth)
+	c.SetRetainDelivered(conf.Core.RetainDelivered)
+
+	if eviction, evictionErr := storage.ParseEvictionStrategy(conf.Core.Eviction); evictionErr != nil {
+		err = evictionErr
+		return
+	} else {
+		c.SetStoreCapacity(conf.Core.StoreCapacity, eviction)
+	}
+
+	if crcPolicy, crcPolicyErr := routing.ParseCRCPolicy(conf.Core.CRCPolicy); crcPolicyErr != nil {
+		err = crcPolicyErr
+		return
+	} else {
+		c.SetCRCPolicy(crcPolicy)
+	}
+
+	if adminRecordPolicy, adminRecordPolicyErr := routing.ParseAdminRecordPolicy(conf.Core.AdminRecordPolicy); adminRecordPolicyErr != nil {
+		err = adminRecordPolicyErr
+		return
+	} else {
+		c.SetAdminRecordPolicy(adminRecordPolicy)
+	}
+
+	if transmitPolicy, transmitPolicyErr := routing.ParseTransmitPolicy(conf.Core.TransmitPolicy); transmitPolicyErr != nil {
+		err = transmitPolicyErr
+		return
+	} else {
+		c.SetTransmitPolicy(transmitPolicy)
+	}
+
+	if futurePolicy, futurePolicyErr := routing.ParseFuturePolicy(conf.Core.FuturePolicy); futurePolicyErr != nil {
+		err = futurePolicyErr
+		return
+	} else {
+		var tolerance time.Duration
+		if conf.Core.FutureTolerance != "" {
+			if tolerance, err = time.ParseDuration(conf.Core.FutureTolerance); err != nil {
+				return
+			}
+		}
+		c.SetFuturePolicy(futurePolicy, tolerance)
+	}
+
+	if conf.Core.DeliveryOrder != "" {
+		if window, windowErr := time.ParseDuration(conf.Core.DeliveryOrder); windowErr != nil {
+			err = windowErr
+			return
+		} else {
+			c.SetDeliveryOrder(window)
+		}
+	}
+
+	if conf.Core.ForwardingTimeout != "" {
+		if timeout, timeoutErr := time.ParseDuration(conf.Core.ForwardingTimeout); timeoutErr != nil {
+			err = timeoutErr
+			return
+		} else {
+			c.SetForwardingTimeout(timeout)
+		}
+	}
+
+	c.SetForwardConcurrency(conf.Core.ForwardConcurrency)
+	c.SetFragmentMinPayload(conf.Core.FragmentMinPayload)
+
+	if conf.Core.ReassemblyTimeout != "" {
+		if timeout, timeoutErr := time.ParseDuration(conf.Core.ReassemblyTimeout); timeoutErr != nil {
+			err = timeoutErr
+			return
+		} else {
+			c.SetReassemblyTimeout(timeout)
+		}
+	}
+
+	if conf.Core.JanitorInterval != "" {
+		if interval, intervalErr := time.ParseDuration(conf.Core.JanitorInterval); intervalErr != nil {
+			err = intervalErr
+			return
+		} else {
+			c.SetJanitorInterval(interval)
+		}
+	}
+
+	if conf.Core.RetryBackoff != "" || conf.Core.RetryBackoffMax != "" {
+		base, max := 10*time.Second, 5*time.Minute
+		if conf.Core.RetryBackoff != "" {
+			if base, err = time.ParseDuration(conf.Core.RetryBackoff); err != nil {
+				return
+			}
+		}
+		if conf.Core.RetryBackoffMax != "" {
+			if max, err = time.ParseDuration(conf.Core.RetryBackoffMax); err != nil {
+				return
+			}
+		}
+		c.SetCLARetryBackoff(base, max)
+	}
+
+	if shutdownMode, shutdownModeErr := routing.ParseShutdownMode(conf.Core.Shutdown); shutdownModeErr != nil {
+		err = shutdownModeErr
+		return
+	} else {
+		var timeout time.Duration
+		if conf.Core.ShutdownTimeout != "" {
+			if timeout, err = time.ParseDuration(conf.Core.ShutdownTimeout); err != nil {
+				return
+			}
+		}
+		c.SetShutdown(shutdownMode, timeout)
+	}
+
+	if conf.Core.OutboundQueue {
+		if queue, queueErr := storage.NewOutboundQueue(conf.Core.Store); queueErr != nil {
+			err = queueErr
+			return
+		} else {
+			c.SetOutboundQueue(queue)
+		}
+	}
+
+	// Metrics
+	if conf.Metrics.Address != "" {
+		if err = parseMetrics(conf.Metrics, c); err != nil {
+			return
+		}
+	}
 
 	// Agents
 	if conf.Agents != (agentsConfig{}) {
@@ -323,7 +755,9 @@ func parseCore(filename string) (c *routing.Core, ds *discovery.Manager, err err
 			return
 		} else {
 			for _, appAgent := range appAgents {
-				c.RegisterApplicationAgent(appAgent)
+				if err = c.RegisterApplicationAgent(appAgent); err != nil {
+					return
+				}
 			}
 		}
 	}
@@ -334,7 +768,9 @@ func parseCore(filename string) (c *routing.Core, ds *discovery.Manager, err err
 			err = lErr
 			return
 		} else {
-			c.RegisterCLA(convRec, claType, eid)
+			if err = c.RegisterCLA(convRec, claType, eid); err != nil {
+				return
+			}
 			if discoMsg != (discovery.Announcement{}) {
 				discoveryMsgs = append(discoveryMsgs, discoMsg)
 			}
@@ -361,9 +797,14 @@ func parseCore(filename string) (c *routing.Core, ds *discovery.Manager, err err
 			conf.Discovery.Interval = 10
 		}
 
+		var dsConf discovery.ManagerConfig
+		if dsConf, err = parseDiscoveryConf(conf.Discovery); err != nil {
+			return
+		}
+
 		ds, err = discovery.NewManager(
-			c.NodeId, c.RegisterConvergable, discoveryMsgs,
-			time.Duration(conf.Discovery.Interval)*time.Second, conf.Discovery.IPv4, conf.Discovery.IPv6)
+			c.NodeId, c.RegisterConvergable, c.UnregisterConvergable, discoveryMsgs,
+			time.Duration(conf.Discovery.Interval)*time.Second, conf.Discovery.IPv4, conf.Discovery.IPv6, dsConf)
 		if err != nil {
 			return
 		}
@@ -7,16 +7,22 @@ package main
 import (
 	"os"
 	"os/signal"
+	"syscall"
 
 	log "github.com/sirupsen/logrus"
 )
 
-// waitSigint blocks the current thread until a SIGINT appears.
-func waitSigint() {
+// waitSigint blocks the current thread until a SIGINT or SIGTERM appears. Each SIGHUP calls the reload function.
+func waitSigint(reload func()) {
 	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, os.Interrupt)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
-	<-sig
+	for s := range sig {
+		if s != syscall.SIGHUP {
+			return
+		}
+		reload()
+	}
 }
 
 func main() {
@@ -31,7 +37,11 @@ func main() {
 		}).Fatal("Failed to parse config")
 	}
 
-	waitSigint()
+	waitSigint(func() {
+		if err := reloadRoutes(os.Args[1], core); err != nil {
+			log.WithError(err).Warn("Failed to reload static routes")
+		}
+	})
 	log.Info("Shutting down..")
 
 	core.Close()
@@ -20,11 +20,12 @@ require (
 	github.com/kr/text v0.2.0 // indirect
 	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
 	github.com/pkg/errors v0.9.1 // indirect
+	github.com/prometheus/client_golang v1.9.0
 	github.com/schollz/peerdiscovery v1.6.1
 	github.com/sirupsen/logrus v1.7.0
 	github.com/timshannon/badgerhold v1.0.0
 	github.com/ulikunitz/xz v0.5.8
-	golang.org/x/sys v0.0.0-20201117222635-ba5294a509c7
+	golang.org/x/sys v0.0.0-20201214210602-f9fddec55a1e
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 	google.golang.org/protobuf v1.25.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20200902074654-038fdea0a05b // indirect
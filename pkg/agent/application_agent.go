@@ -23,17 +23,13 @@ type ApplicationAgent interface {
 	MessageSender() chan Message
 }
 
-// bagContainsEndpoint checks if some bag/array/slice of endpoints contains another collection of endpoints.
+// bagContainsEndpoint checks if some bag/array/slice of endpoints matches another collection of endpoints.
 func bagContainsEndpoint(bag []bpv7.EndpointID, eids []bpv7.EndpointID) bool {
-	matches := map[bpv7.EndpointID]struct{}{}
-
 	for _, eid := range eids {
-		matches[eid] = struct{}{}
-	}
-
-	for _, eid := range bag {
-		if _, ok := matches[eid]; ok {
-			return true
+		for _, bagEid := range bag {
+			if bagEid.Matches(eid) {
+				return true
+			}
 		}
 	}
 	return false
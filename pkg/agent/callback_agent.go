@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package agent
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// CallbackAgent is a lightweight ApplicationAgent, passing each incoming Bundle to a callback function.
+type CallbackAgent struct {
+	endpoint     bpv7.EndpointID
+	callback     func(bpv7.Bundle)
+	maxStaleness time.Duration
+
+	receiver chan Message
+	sender   chan Message
+
+	closeSyn  chan struct{}
+	closeOnce sync.Once
+}
+
+// NewCallback creates a new CallbackAgent ApplicationAgent, calling the callback for each Bundle to this endpoint.
+func NewCallback(endpoint bpv7.EndpointID, callback func(bpv7.Bundle)) *CallbackAgent {
+	ca := &CallbackAgent{
+		endpoint: endpoint,
+		callback: callback,
+		receiver: make(chan Message),
+		sender:   make(chan Message),
+		closeSyn: make(chan struct{}),
+	}
+
+	go ca.handler()
+
+	return ca
+}
+
+func (ca *CallbackAgent) log() *log.Entry {
+	return log.WithField("CallbackAgent", ca.endpoint)
+}
+
+func (ca *CallbackAgent) handler() {
+	for {
+		select {
+		case m, ok := <-ca.receiver:
+			if !ok {
+				close(ca.sender)
+				return
+			}
+
+			switch m := m.(type) {
+			case BundleMessage:
+				ca.callback(m.Bundle)
+
+			case ShutdownMessage:
+				close(ca.sender)
+				return
+
+			default:
+				ca.log().WithField("message", m).Info("Received unsupported Message")
+			}
+
+		case <-ca.closeSyn:
+			// Closing the sender results in an unregistration, which closes the receiver afterwards. Until then,
+			// incoming Messages are dropped to not block the supervising code.
+			close(ca.sender)
+			for range ca.receiver {
+			}
+			return
+		}
+	}
+}
+
+// SetMaxStaleness to only pass Bundles up to this age to the callback, compare StalenessLimiter. Zero disables this
+// limit, which is the default.
+//
+// This should be set before registering the CallbackAgent.
+func (ca *CallbackAgent) SetMaxStaleness(maxStaleness time.Duration) {
+	ca.maxStaleness = maxStaleness
+}
+
+// MaxStaleness implements the StalenessLimiter.
+func (ca *CallbackAgent) MaxStaleness() time.Duration {
+	return ca.maxStaleness
+}
+
+// Close this CallbackAgent. No more Bundles will be passed to the callback afterwards.
+func (ca *CallbackAgent) Close() {
+	ca.closeOnce.Do(func() { close(ca.closeSyn) })
+}
+
+func (ca *CallbackAgent) Endpoints() []bpv7.EndpointID {
+	return []bpv7.EndpointID{ca.endpoint}
+}
+
+func (ca *CallbackAgent) MessageReceiver() chan Message {
+	return ca.receiver
+}
+
+func (ca *CallbackAgent) MessageSender() chan Message {
+	return ca.sender
+}
@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestCallbackAgent(t *testing.T) {
+	bndlChan := make(chan bpv7.Bundle, 1)
+	ca := NewCallback(bpv7.MustNewEndpointID("dtn://foo/cb"), func(b bpv7.Bundle) { bndlChan <- b })
+
+	bndlOut, bndlOutErr := bpv7.Builder().
+		Source("dtn://bar/").
+		Destination("dtn://foo/cb").
+		CreationTimestampNow().
+		Lifetime("5m").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if bndlOutErr != nil {
+		t.Fatal(bndlOutErr)
+	}
+
+	ca.receiver <- BundleMessage{bndlOut}
+
+	select {
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("CallbackAgent did not call back after 500ms")
+
+	case bndlIn := <-bndlChan:
+		if bndlIn.ID() != bndlOut.ID() {
+			t.Fatalf("Callback Bundle %v is not the delivered Bundle %v", bndlIn.ID(), bndlOut.ID())
+		}
+	}
+
+	ca.Close()
+
+	select {
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("CallbackAgent did not close its sender after 500ms")
+
+	case _, ok := <-ca.sender:
+		if ok {
+			t.Fatal("CallbackAgent sent an unexpected Message")
+		}
+	}
+
+	close(ca.receiver)
+}
@@ -38,9 +38,13 @@ func (mux *MuxAgent) handle() {
 	for msg := range mux.receiver {
 		mux.Lock()
 		for _, child := range mux.children {
-			if rec := msg.Recipients(); rec == nil || AppAgentContainsEndpoint(child, rec) {
-				child.MessageReceiver() <- msg
+			if rec := msg.Recipients(); rec != nil && !AppAgentContainsEndpoint(child, rec) {
+				continue
+			} else if bMsg, isBundle := msg.(BundleMessage); isBundle && AppAgentIsStale(child, bMsg.Bundle) {
+				continue
 			}
+
+			child.MessageReceiver() <- msg
 		}
 		mux.Unlock()
 
@@ -88,6 +92,24 @@ func (mux *MuxAgent) unregister(agent ApplicationAgent) {
 	}
 }
 
+// IsStale checks if a Bundle is too old for all ApplicationAgents addressed by it, compare StalenessLimiter. If no
+// ApplicationAgent is addressed, false is returned.
+func (mux *MuxAgent) IsStale(b bpv7.Bundle) bool {
+	mux.Lock()
+	defer mux.Unlock()
+
+	addressed := false
+	for _, child := range mux.children {
+		if !AppAgentHasEndpoint(child, b.PrimaryBlock.Destination) {
+			continue
+		} else if !AppAgentIsStale(child, b) {
+			return false
+		}
+		addressed = true
+	}
+	return addressed
+}
+
 func (mux *MuxAgent) Endpoints() (endpoints []bpv7.EndpointID) {
 	mux.Lock()
 	defer mux.Unlock()
@@ -94,3 +94,94 @@ func TestMuxAgent(t *testing.T) {
 		t.Fatalf("expected %v, got %v", ShutdownMessage{}, msgs[0])
 	}
 }
+
+func TestMuxAgentGroup(t *testing.T) {
+	b, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://group/~news").
+		CreationTimestampEpoch().
+		Lifetime("24h").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := NewMuxAgent()
+
+	member1 := newMockAgent([]bpv7.EndpointID{bpv7.MustNewEndpointID("dtn://agent/mock-1/"), bpv7.MustNewEndpointID("dtn://group/~news")})
+	member2 := newMockAgent([]bpv7.EndpointID{bpv7.MustNewEndpointID("dtn://group/~news")})
+	other := newMockAgent([]bpv7.EndpointID{bpv7.MustNewEndpointID("dtn://group/~sports")})
+
+	mux.Register(member1)
+	mux.Register(member2)
+	mux.Register(other)
+
+	mux.MessageReceiver() <- BundleMessage{b}
+	time.Sleep(250 * time.Millisecond)
+
+	for i, member := range []*mockAgent{member1, member2} {
+		if msgs := member.inbox(); len(msgs) != 1 {
+			t.Fatalf("group member %d received %d messages, expected one", i+1, len(msgs))
+		}
+	}
+	if msgs := other.inbox(); len(msgs) != 0 {
+		t.Fatalf("non-member received %d messages", len(msgs))
+	}
+
+	mux.MessageReceiver() <- ShutdownMessage{}
+}
+
+// staleMockAgent is a mockAgent with a StalenessLimiter.
+type staleMockAgent struct {
+	*mockAgent
+	maxStaleness time.Duration
+}
+
+func (m staleMockAgent) MaxStaleness() time.Duration {
+	return m.maxStaleness
+}
+
+func TestMuxAgentStaleness(t *testing.T) {
+	eid := bpv7.MustNewEndpointID("dtn://agent/mock/")
+	b, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination(eid).
+		CreationTimestampTime(time.Now().Add(-time.Minute)).
+		Lifetime("24h").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := NewMuxAgent()
+
+	tight := staleMockAgent{newMockAgent([]bpv7.EndpointID{eid}), time.Second}
+	tolerant := staleMockAgent{newMockAgent([]bpv7.EndpointID{eid}), time.Hour}
+
+	mux.Register(tight)
+	if !mux.IsStale(b) {
+		t.Fatal("bundle is not stale for the tight agent")
+	}
+
+	mux.Register(tolerant)
+	if mux.IsStale(b) {
+		t.Fatal("bundle is stale, even though the tolerant agent accepts it")
+	}
+
+	mux.MessageReceiver() <- BundleMessage{b}
+	time.Sleep(250 * time.Millisecond)
+
+	if msgs := tight.inbox(); len(msgs) != 0 {
+		t.Fatalf("tight agent received stale messages %v", msgs)
+	}
+	if msgs := tolerant.inbox(); len(msgs) != 1 {
+		t.Fatalf("tolerant agent received %d messages", len(msgs))
+	}
+
+	b.PrimaryBlock.Destination = bpv7.MustNewEndpointID("dtn://agent/unknown/")
+	if mux.IsStale(b) {
+		t.Fatal("bundle for no agent is stale")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 
 	log "github.com/sirupsen/logrus"
 
@@ -66,6 +67,20 @@ import (
 //   //    }
 //   // <- {"error":""}
 //
+//   // Alternatively, send a bundle from our endpoint with a base64 encoded payload, POST to /send
+//   // -> {
+//   //      "uuid": "75be76e2-23fc-da0e-eeb8-4773f84a9d2f",
+//   //      "destination": "dtn://dst/",
+//   //      "lifetime": "24h",
+//   //      "payload": "aGVsbG8gd29ybGQ="
+//   //    }
+//   // <- {"error":"","bundle_id":"dtn://foo/bar-670683126000-0"}
+//
+//   // Alternatively, fetch only the base64 encoded payloads of new bundles, GET /fetch?uuid=75be76e2-...
+//   // <- {"error":"","payloads":[
+//   //      {"bundle_id":"dtn://sender/-639925926000-0","source":"dtn://sender/","payload":"aGVsbG8gd29ybGQ="}
+//   //    ]}
+//
 //   // 4. Unregister the client, POST to /unregister
 //   // -> {"uuid":"75be76e2-23fc-da0e-eeb8-4773f84a9d2f"}
 //   // <- {"error":""}
@@ -79,6 +94,9 @@ type RestAgent struct {
 	// map UUIDs to EIDs and received bundles
 	clients sync.Map // uuid[string] -> bpv7.EndpointID
 	mailbox sync.Map // uuid[string] -> []bpv7.Bundle
+
+	// applicationAcks is non-zero if enabled, compare SetApplicationAcks.
+	applicationAcks int32
 }
 
 // NewRestAgent creates a new RESTful Application Agent.
@@ -93,13 +111,26 @@ func NewRestAgent(router *mux.Router) (ra *RestAgent) {
 	ra.router.HandleFunc("/register", ra.handleRegister).Methods(http.MethodPost)
 	ra.router.HandleFunc("/unregister", ra.handleUnregister).Methods(http.MethodPost)
 	ra.router.HandleFunc("/fetch", ra.handleFetch).Methods(http.MethodPost)
+	ra.router.HandleFunc("/fetch", ra.handleFetchPayloads).Methods(http.MethodGet)
 	ra.router.HandleFunc("/build", ra.handleBuild).Methods(http.MethodPost)
+	ra.router.HandleFunc("/send", ra.handleSend).Methods(http.MethodPost)
 
 	go ra.handler()
 
 	return ra
 }
 
+// SetApplicationAcks enables the generation of application-level acknowledgements. If a delivered bundle has the
+// RequestUserApplicationAck flag set, an acknowledgement bundle is sent back to its source. The acknowledgement's
+// payload is the delivered bundle's ID.
+func (ra *RestAgent) SetApplicationAcks(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&ra.applicationAcks, value)
+}
+
 // handler checks the receiver channel and deals with inbounding messages.
 func (ra *RestAgent) handler() {
 	defer close(ra.sender)
@@ -144,6 +175,25 @@ func (ra *RestAgent) receiveBundleMessage(msg BundleMessage) {
 			"uuid":   uuid,
 		}).Info("REST Application Agent delivering message to a client's inbox")
 	}
+
+	if len(uuids) > 0 && atomic.LoadInt32(&ra.applicationAcks) != 0 && msg.Bundle.PrimaryBlock.BundleControlFlags.Has(bpv7.RequestUserApplicationAck) {
+		ra.ackBundle(msg.Bundle)
+	}
+}
+
+// ackBundle sends an application-level acknowledgement for a delivered bundle back to its source.
+func (ra *RestAgent) ackBundle(b bpv7.Bundle) {
+	ack, err := b.Reply(b.PrimaryBlock.Destination, []byte(b.ID().String()))
+	if err != nil {
+		log.WithField("bundle", b.ID().String()).WithError(err).Warn("REST Agent failed to build an acknowledgement")
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"bundle": b.ID().String(),
+		"ack":    ack.ID().String(),
+	}).Info("REST Agent sending an application-level acknowledgement")
+	ra.sender <- BundleMessage{Bundle: ack}
 }
 
 // randomUuid to be used for authentication. UUID does not complain RFC 4122.
@@ -270,6 +320,74 @@ func (ra *RestAgent) handleBuild(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleFetchPayloads returns the payloads of the bundles from some client's inbox, called by a GET on /fetch.
+func (ra *RestAgent) handleFetchPayloads(w http.ResponseWriter, r *http.Request) {
+	var fetchResponse RestFetchPayloadsResponse
+
+	uuid := r.URL.Query().Get("uuid")
+	if _, ok := ra.clients.Load(uuid); !ok {
+		log.WithField("uuid", uuid).Debug("REST client cannot fetch for unknown UUID")
+		fetchResponse.Error = "Invalid UUID"
+	} else {
+		fetchResponse.Payloads = make([]RestPayload, 0)
+
+		if val, ok := ra.mailbox.Load(uuid); ok {
+			log.WithField("uuid", uuid).Info("REST client fetches payloads")
+			ra.mailbox.Delete(uuid)
+
+			for _, b := range val.([]bpv7.Bundle) {
+				payload := RestPayload{BundleId: b.ID().String(), Source: b.PrimaryBlock.SourceNode.String()}
+				if pb, pbErr := b.PayloadBlock(); pbErr == nil {
+					payload.Payload = pb.Value.(*bpv7.PayloadBlock).Data()
+				}
+				fetchResponse.Payloads = append(fetchResponse.Payloads, payload)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(fetchResponse); err != nil {
+		log.WithError(err).Warn("Failed to write REST fetch response")
+	}
+}
+
+// handleSend creates and dispatches a new bundle from a client's endpoint, called by /send.
+func (ra *RestAgent) handleSend(w http.ResponseWriter, r *http.Request) {
+	var (
+		sendRequest  RestSendRequest
+		sendResponse RestSendResponse
+	)
+
+	if jsonErr := json.NewDecoder(r.Body).Decode(&sendRequest); jsonErr != nil {
+		log.WithError(jsonErr).Warn("Failed to parse REST send request")
+		sendResponse.Error = jsonErr.Error()
+	} else if eid, ok := ra.clients.Load(sendRequest.UUID); !ok {
+		log.WithField("uuid", sendRequest.UUID).Debug("REST client cannot send for unknown UUID")
+		sendResponse.Error = "Invalid UUID"
+	} else if b, bErr := bpv7.Builder().
+		Source(eid).
+		Destination(sendRequest.Destination).
+		CreationTimestampNow().
+		Lifetime(sendRequest.Lifetime).
+		PayloadBlock(sendRequest.Payload).
+		Build(); bErr != nil {
+		log.WithError(bErr).WithField("uuid", sendRequest.UUID).Warn("REST client failed to send a bundle")
+		sendResponse.Error = bErr.Error()
+	} else {
+		log.WithFields(log.Fields{
+			"uuid":   sendRequest.UUID,
+			"bundle": b.ID().String(),
+		}).Info("REST client sent bundle")
+		sendResponse.BundleId = b.ID().String()
+		ra.sender <- BundleMessage{Bundle: b}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sendResponse); err != nil {
+		log.WithError(err).Warn("Failed to write REST send response")
+	}
+}
+
 func (ra *RestAgent) Endpoints() (eids []bpv7.EndpointID) {
 	ra.clients.Range(func(_, v interface{}) bool {
 		eids = append(eids, v.(bpv7.EndpointID))
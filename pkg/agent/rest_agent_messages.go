@@ -48,3 +48,31 @@ type RestBuildRequest struct {
 type RestBuildResponse struct {
 	Error string `json:"error"`
 }
+
+// RestSendRequest describes a JSON to be POSTed to /send. The bundle's source is the client's endpoint and its
+// payload is base64 encoded.
+type RestSendRequest struct {
+	UUID        string `json:"uuid"`
+	Destination string `json:"destination"`
+	Lifetime    string `json:"lifetime"`
+	Payload     []byte `json:"payload"`
+}
+
+// RestSendResponse describes a JSON response for /send.
+type RestSendResponse struct {
+	Error    string `json:"error"`
+	BundleId string `json:"bundle_id"`
+}
+
+// RestPayload is a delivered bundle's payload, as listed in a RestFetchPayloadsResponse.
+type RestPayload struct {
+	BundleId string `json:"bundle_id"`
+	Source   string `json:"source"`
+	Payload  []byte `json:"payload"`
+}
+
+// RestFetchPayloadsResponse describes a JSON response for a GET request on /fetch.
+type RestFetchPayloadsResponse struct {
+	Error    string        `json:"error"`
+	Payloads []RestPayload `json:"payloads"`
+}
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"sync"
@@ -208,3 +209,162 @@ func TestRestAgentCycle(t *testing.T) {
 		t.Fatal("endpoint is still registered")
 	}
 }
+
+func TestRestAgentApplicationAck(t *testing.T) {
+	restAgent := NewRestAgent(mux.NewRouter())
+	restAgent.SetApplicationAcks(true)
+	defer func() { restAgent.MessageReceiver() <- ShutdownMessage{} }()
+
+	registerEid := bpv7.MustNewEndpointID("dtn://foo/bar")
+	restAgent.clients.Store("uuid", registerEid)
+
+	ackRequested, err := bpv7.Builder().
+		Source("dtn://sender/").
+		Destination(registerEid).
+		BundleCtrlFlags(bpv7.RequestUserApplicationAck).
+		CreationTimestampNow().
+		Lifetime("24h").
+		HopCountBlock(23).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		bndl bpv7.Bundle
+		ack  bool
+	}{
+		{createBundle("dtn://sender/", registerEid.String(), t), false},
+		{ackRequested, true},
+	}
+
+	for _, test := range tests {
+		restAgent.MessageReceiver() <- BundleMessage{Bundle: test.bndl}
+
+		select {
+		case <-time.After(250 * time.Millisecond):
+			if test.ack {
+				t.Fatalf("no acknowledgement was sent for %v", test.bndl.ID())
+			}
+
+		case msg := <-restAgent.MessageSender():
+			if !test.ack {
+				t.Fatalf("unexpected message %v for %v", msg, test.bndl.ID())
+			}
+
+			ack := msg.(BundleMessage).Bundle
+			if ack.PrimaryBlock.Destination != test.bndl.PrimaryBlock.SourceNode {
+				t.Fatalf("acknowledgement is addressed to %v", ack.PrimaryBlock.Destination)
+			} else if ack.PrimaryBlock.SourceNode != registerEid {
+				t.Fatalf("acknowledgement is sent from %v", ack.PrimaryBlock.SourceNode)
+			}
+
+			if payload, err := ack.PayloadBlock(); err != nil {
+				t.Fatal(err)
+			} else if data := string(payload.Value.(*bpv7.PayloadBlock).Data()); data != test.bndl.ID().String() {
+				t.Fatalf("acknowledgement's payload is %q", data)
+			}
+
+			if hc, err := ack.ExtensionBlock(bpv7.ExtBlockTypeHopCountBlock); err != nil {
+				t.Fatal(err)
+			} else if limit := hc.Value.(*bpv7.HopCountBlock).Limit; limit != 23 {
+				t.Fatalf("acknowledgement's hop limit is %d", limit)
+			}
+		}
+	}
+}
+
+func TestRestAgentSendFetchPayloads(t *testing.T) {
+	router := mux.NewRouter()
+	restAgent := NewRestAgent(router)
+	defer func() { restAgent.MessageReceiver() <- ShutdownMessage{} }()
+
+	registerEid := bpv7.MustNewEndpointID("dtn://foo/bar")
+	restAgent.clients.Store("uuid", registerEid)
+
+	// Send a bundle with a base64 encoded payload
+	sendRequest := RestSendRequest{
+		UUID:        "uuid",
+		Destination: "dtn://dst/",
+		Lifetime:    "24h",
+		Payload:     []byte("hello world"),
+	}
+	sendBuf := new(bytes.Buffer)
+	if err := json.NewEncoder(sendBuf).Encode(sendRequest); err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(sendBuf.String(), `"payload":"aGVsbG8gd29ybGQ="`) {
+		t.Fatalf("payload is not base64 encoded: %s", sendBuf.String())
+	}
+
+	sendChan := make(chan bpv7.Bundle, 1)
+	go func() { sendChan <- (<-restAgent.MessageSender()).(BundleMessage).Bundle }()
+
+	sendRec := httptest.NewRecorder()
+	router.ServeHTTP(sendRec, httptest.NewRequest(http.MethodPost, "/send", sendBuf))
+
+	var sendResponse RestSendResponse
+	if err := json.NewDecoder(sendRec.Body).Decode(&sendResponse); err != nil {
+		t.Fatal(err)
+	} else if sendResponse.Error != "" {
+		t.Fatal(sendResponse.Error)
+	}
+
+	select {
+	case b := <-sendChan:
+		if b.ID().String() != sendResponse.BundleId {
+			t.Fatalf("sent bundle %v, response names %s", b.ID(), sendResponse.BundleId)
+		} else if b.PrimaryBlock.SourceNode != registerEid {
+			t.Fatalf("bundle's source is %v", b.PrimaryBlock.SourceNode)
+		} else if pb, err := b.PayloadBlock(); err != nil {
+			t.Fatal(err)
+		} else if payload := pb.Value.(*bpv7.PayloadBlock).Data(); string(payload) != "hello world" {
+			t.Fatalf("payload is %q", payload)
+		}
+
+	case <-time.After(250 * time.Millisecond):
+		t.Fatal("no bundle was sent")
+	}
+
+	// Sending for an unknown client must fail
+	sendRec = httptest.NewRecorder()
+	router.ServeHTTP(sendRec, httptest.NewRequest(http.MethodPost, "/send",
+		strings.NewReader(`{"uuid":"nope","destination":"dtn://dst/","lifetime":"24h","payload":""}`)))
+	if err := json.NewDecoder(sendRec.Body).Decode(&sendResponse); err != nil {
+		t.Fatal(err)
+	} else if sendResponse.Error == "" {
+		t.Fatal("sending for an unknown UUID did not error")
+	}
+
+	// Fetch the payload of a delivered bundle
+	b := createBundle("dtn://sender/", registerEid.String(), t)
+	restAgent.MessageReceiver() <- BundleMessage{Bundle: b}
+	time.Sleep(100 * time.Millisecond)
+
+	for _, expected := range []int{1, 0} {
+		fetchRec := httptest.NewRecorder()
+		router.ServeHTTP(fetchRec, httptest.NewRequest(http.MethodGet, "/fetch?uuid=uuid", nil))
+
+		var fetchResponse RestFetchPayloadsResponse
+		if err := json.NewDecoder(fetchRec.Body).Decode(&fetchResponse); err != nil {
+			t.Fatal(err)
+		} else if fetchResponse.Error != "" {
+			t.Fatal(fetchResponse.Error)
+		} else if l := len(fetchResponse.Payloads); l != expected {
+			t.Fatalf("fetched %d payloads, expected %d", l, expected)
+		} else if expected == 1 {
+			if p := fetchResponse.Payloads[0]; p.BundleId != b.ID().String() || string(p.Payload) != "hello world" {
+				t.Fatalf("fetched payload %v", p)
+			}
+		}
+	}
+
+	fetchRec := httptest.NewRecorder()
+	router.ServeHTTP(fetchRec, httptest.NewRequest(http.MethodGet, "/fetch?uuid=nope", nil))
+	var fetchResponse RestFetchPayloadsResponse
+	if err := json.NewDecoder(fetchRec.Body).Decode(&fetchResponse); err != nil {
+		t.Fatal(err)
+	} else if fetchResponse.Error == "" {
+		t.Fatal("fetching for an unknown UUID did not error")
+	}
+}
@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package agent
+
+import (
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// StalenessLimiter is an optional interface for an ApplicationAgent to declare the maximum age of Bundles delivered
+// to it. Older Bundles are not passed to this ApplicationAgent, as their data is considered of no use anymore.
+type StalenessLimiter interface {
+	// MaxStaleness returns the maximum age of a delivered Bundle. Zero disables this limit.
+	MaxStaleness() time.Duration
+}
+
+// bundleAge based on its creation timestamp or, for a Bundle created without a clock, its Bundle Age Block.
+func bundleAge(b bpv7.Bundle) (age time.Duration, ok bool) {
+	if ts := b.PrimaryBlock.CreationTimestamp; !ts.IsZeroTime() {
+		return time.Since(ts.DtnTime().Time()), true
+	}
+
+	if ageBlock, err := b.ExtensionBlock(bpv7.ExtBlockTypeBundleAgeBlock); err == nil {
+		return time.Duration(ageBlock.Value.(*bpv7.BundleAgeBlock).Age()) * time.Millisecond, true
+	}
+
+	return 0, false
+}
+
+// AppAgentIsStale checks if a Bundle is too old for an ApplicationAgent, based on its optional StalenessLimiter.
+func AppAgentIsStale(app ApplicationAgent, b bpv7.Bundle) bool {
+	limiter, ok := app.(StalenessLimiter)
+	if !ok || limiter.MaxStaleness() <= 0 {
+		return false
+	}
+
+	age, ok := bundleAge(b)
+	return ok && age > limiter.MaxStaleness()
+}
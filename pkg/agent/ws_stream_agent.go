@@ -0,0 +1,234 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package agent
+
+import (
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// defaultStreamReadLimit is the maximum size of a frame received by a WebSocketStreamAgent's client, if not changed by
+// SetReadLimit.
+const defaultStreamReadLimit = 1 << 20
+
+// WebSocketStreamAgent is a WebSocket based ApplicationAgent streaming raw payloads, e.g., for interactive
+// applications as chats or telemetry.
+//
+// In contrast to the WebSocketAgent, there is no message protocol. Each bundle delivered to the agent's endpoint is
+// pushed to all connected clients as a binary frame of its payload. Each binary frame received from a client is sent
+// as a bundle's payload from the agent's endpoint to the preconfigured destination. A disconnected client is
+// unregistered automatically. A client sending a frame larger than the read limit gets disconnected.
+type WebSocketStreamAgent struct {
+	readLimit int64
+
+	endpoint    bpv7.EndpointID
+	destination bpv7.EndpointID
+	lifetime    time.Duration
+
+	receiver  chan Message
+	clientMux *MuxAgent
+
+	upgrader websocket.Upgrader
+}
+
+// NewWebSocketStreamAgent for an endpoint, sending received frames to the destination with the given lifetime. It
+// will be started with its handler and the ServeHTTP function must be bound to the HTTP server.
+func NewWebSocketStreamAgent(endpoint, destination bpv7.EndpointID, lifetime time.Duration) (wsa *WebSocketStreamAgent) {
+	wsa = &WebSocketStreamAgent{
+		readLimit: defaultStreamReadLimit,
+
+		endpoint:    endpoint,
+		destination: destination,
+		lifetime:    lifetime,
+
+		receiver:  make(chan Message),
+		clientMux: NewMuxAgent(),
+
+		upgrader: websocket.Upgrader{},
+	}
+
+	go wsa.handler()
+
+	return
+}
+
+// SetReadLimit sets the maximum size of a frame received from a client, defaulting to 1 MiB. This limit applies to
+// clients connecting afterwards.
+func (w *WebSocketStreamAgent) SetReadLimit(limit int64) {
+	atomic.StoreInt64(&w.readLimit, limit)
+}
+
+// handler is the "generic" handler for a WebSocketStreamAgent.
+func (w *WebSocketStreamAgent) handler() {
+	for msg := range w.receiver {
+		w.clientMux.MessageReceiver() <- msg
+
+		if _, isShutdown := msg.(ShutdownMessage); isShutdown {
+			log.Info("WebSocketStreamAgent received a shutdown")
+			return
+		}
+	}
+}
+
+// ServeHTTP must be bound to a HTTP endpoint, e.g., to /stream by a http.ServeMux.
+func (w *WebSocketStreamAgent) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	conn, connErr := w.upgrader.Upgrade(rw, r, nil)
+	if connErr != nil {
+		log.WithError(connErr).Warn("Upgrading HTTP request to WebSocket errored")
+		return
+	}
+	conn.SetReadLimit(atomic.LoadInt64(&w.readLimit))
+
+	client := &webStreamClient{
+		agent:    w,
+		conn:     conn,
+		receiver: make(chan Message),
+		sender:   make(chan Message),
+	}
+	w.clientMux.Register(client)
+
+	client.start()
+}
+
+// Endpoints of this agent, if at least one client is connected.
+func (w *WebSocketStreamAgent) Endpoints() []bpv7.EndpointID {
+	return w.clientMux.Endpoints()
+}
+
+// MessageReceiver is a channel on which the ApplicationAgent must listen for incoming Messages.
+func (w *WebSocketStreamAgent) MessageReceiver() chan Message {
+	return w.receiver
+}
+
+// MessageSender is a channel to which the ApplicationAgent can send outgoing Messages.
+func (w *WebSocketStreamAgent) MessageSender() chan Message {
+	return w.clientMux.MessageSender()
+}
+
+// webStreamClient is a connected client of a WebSocketStreamAgent.
+type webStreamClient struct {
+	sync.Mutex
+
+	agent    *WebSocketStreamAgent
+	conn     *websocket.Conn
+	receiver chan Message
+	sender   chan Message
+
+	closeOnce sync.Once
+}
+
+func (client *webStreamClient) start() {
+	go client.handleReceiver()
+	client.handleConn()
+}
+
+// close the WebSocket connection, which lets handleConn return and close the sender channel afterwards.
+func (client *webStreamClient) close() {
+	client.closeOnce.Do(func() {
+		log.WithField("web stream client", client.conn.RemoteAddr().String()).Debug("Closing connection")
+
+		_ = client.conn.Close()
+	})
+}
+
+// handleReceiver pushes the payload of each delivered bundle to the client. It runs until the MuxAgent has unregistered
+// this client and closed its receiver.
+func (client *webStreamClient) handleReceiver() {
+	var logger = log.WithField("web stream client", client.conn.RemoteAddr().String())
+
+	for msg := range client.receiver {
+		switch msg := msg.(type) {
+		case ShutdownMessage:
+			logger.Debug("Received Shutdown")
+			client.close()
+
+		case BundleMessage:
+			pb, pbErr := msg.Bundle.PayloadBlock()
+			if pbErr != nil {
+				logger.WithField("bundle", msg.Bundle).WithError(pbErr).Warn("Bundle has no payload")
+				continue
+			}
+
+			if err := client.writeFrame(pb.Value.(*bpv7.PayloadBlock).Data()); err != nil {
+				logger.WithError(err).Warn("Sending payload errored")
+				client.close()
+			} else {
+				logger.WithField("bundle", msg.Bundle).Info("Sent payload to client")
+			}
+
+		default:
+			logger.WithField("message", msg).Debug("Received unknown / unsupported message")
+		}
+	}
+}
+
+// handleConn sends each binary frame from the client as a bundle. Afterwards, the closed sender channel results in
+// the client's unregistration by the MuxAgent.
+func (client *webStreamClient) handleConn() {
+	defer close(client.sender)
+	defer client.close()
+
+	var logger = log.WithField("web stream client", client.conn.RemoteAddr().String())
+
+	for {
+		messageType, reader, err := client.conn.NextReader()
+		if err != nil {
+			logger.WithError(err).Debug("Client disconnected")
+			return
+		} else if messageType != websocket.BinaryMessage {
+			logger.WithField("message type", messageType).Warn("Websocket Reader's type is not binary")
+			return
+		}
+
+		payload, err := ioutil.ReadAll(reader)
+		if err != nil {
+			logger.WithError(err).Warn("Reading frame errored")
+			return
+		}
+
+		b, err := bpv7.Builder().
+			Source(client.agent.endpoint).
+			Destination(client.agent.destination).
+			CreationTimestampNow().
+			Lifetime(client.agent.lifetime).
+			PayloadBlock(payload).
+			Build()
+		if err != nil {
+			logger.WithError(err).Warn("Building bundle errored")
+			return
+		}
+
+		logger.WithField("bundle", b.ID()).Info("Received payload, sending bundle")
+		client.sender <- BundleMessage{Bundle: b}
+	}
+}
+
+func (client *webStreamClient) writeFrame(data []byte) error {
+	client.Lock()
+	defer client.Unlock()
+
+	return client.conn.WriteMessage(websocket.BinaryMessage, data)
+}
+
+func (client *webStreamClient) Endpoints() []bpv7.EndpointID {
+	return []bpv7.EndpointID{client.agent.endpoint}
+}
+
+func (client *webStreamClient) MessageReceiver() chan Message {
+	return client.receiver
+}
+
+func (client *webStreamClient) MessageSender() chan Message {
+	return client.sender
+}
@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package agent
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestWebSocketStreamAgent(t *testing.T) {
+	endpoint := bpv7.MustNewEndpointID("dtn://foo/chat")
+	destination := bpv7.MustNewEndpointID("dtn://bar/chat")
+
+	// Start WebSocketStreamAgent server
+	addr := fmt.Sprintf("localhost:%d", randomPort(t))
+	wsa := NewWebSocketStreamAgent(endpoint, destination, time.Hour)
+	defer func() { wsa.MessageReceiver() <- ShutdownMessage{} }()
+
+	httpMux := http.NewServeMux()
+	httpMux.HandleFunc("/stream", wsa.ServeHTTP)
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: httpMux,
+	}
+	go func() { _ = httpServer.ListenAndServe() }()
+	defer func() { _ = httpServer.Close() }()
+
+	for i := 1; !isAddrReachable(addr); i++ {
+		if i == 3 {
+			t.Fatal("WebSocketStreamAgent seems to be unreachable")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Connect client
+	u := url.URL{Scheme: "ws", Host: addr, Path: "/stream"}
+	wsClient, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; !AppAgentHasEndpoint(wsa, endpoint); i++ {
+		if i >= 100 {
+			t.Fatal("endpoint was not registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Deliver a bundle, whose payload must be pushed to the client
+	wsa.MessageReceiver() <- BundleMessage{Bundle: createBundle("dtn://bar/chat", endpoint.String(), t)}
+
+	_ = wsClient.SetReadDeadline(time.Now().Add(time.Second))
+	if messageType, data, err := wsClient.ReadMessage(); err != nil {
+		t.Fatal(err)
+	} else if messageType != websocket.BinaryMessage {
+		t.Fatalf("message type is %d, not binary", messageType)
+	} else if string(data) != "hello world" {
+		t.Fatalf("received payload %q", data)
+	}
+
+	// Write a frame, which must be sent as a bundle
+	if err := wsClient.WriteMessage(websocket.BinaryMessage, []byte("hello back")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-wsa.MessageSender():
+		b := msg.(BundleMessage).Bundle
+		if b.PrimaryBlock.SourceNode != endpoint || b.PrimaryBlock.Destination != destination {
+			t.Fatalf("bundle is sent from %v to %v", b.PrimaryBlock.SourceNode, b.PrimaryBlock.Destination)
+		} else if pb, err := b.PayloadBlock(); err != nil {
+			t.Fatal(err)
+		} else if payload := pb.Value.(*bpv7.PayloadBlock).Data(); string(payload) != "hello back" {
+			t.Fatalf("bundle's payload is %q", payload)
+		}
+
+	case <-time.After(time.Second):
+		t.Fatal("no bundle was sent")
+	}
+
+	// A disconnected client must be unregistered
+	if err := wsClient.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; AppAgentHasEndpoint(wsa, endpoint); i++ {
+		if i >= 100 {
+			t.Fatal("endpoint is still registered after disconnecting")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebSocketStreamAgentReadLimit(t *testing.T) {
+	endpoint := bpv7.MustNewEndpointID("dtn://foo/chat")
+	destination := bpv7.MustNewEndpointID("dtn://bar/chat")
+
+	addr := fmt.Sprintf("localhost:%d", randomPort(t))
+	wsa := NewWebSocketStreamAgent(endpoint, destination, time.Hour)
+	wsa.SetReadLimit(16)
+	defer func() { wsa.MessageReceiver() <- ShutdownMessage{} }()
+
+	httpMux := http.NewServeMux()
+	httpMux.HandleFunc("/stream", wsa.ServeHTTP)
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: httpMux,
+	}
+	go func() { _ = httpServer.ListenAndServe() }()
+	defer func() { _ = httpServer.Close() }()
+
+	for i := 1; !isAddrReachable(addr); i++ {
+		if i == 3 {
+			t.Fatal("WebSocketStreamAgent seems to be unreachable")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	u := url.URL{Scheme: "ws", Host: addr, Path: "/stream"}
+	wsClient, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wsClient.Close()
+
+	for i := 0; !AppAgentHasEndpoint(wsa, endpoint); i++ {
+		if i >= 100 {
+			t.Fatal("endpoint was not registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A frame exceeding the read limit must disconnect the client without sending a bundle
+	if err := wsClient.WriteMessage(websocket.BinaryMessage, make([]byte, 17)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-wsa.MessageSender():
+		t.Fatalf("oversized frame was sent as %v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	for i := 0; AppAgentHasEndpoint(wsa, endpoint); i++ {
+		if i >= 100 {
+			t.Fatal("endpoint is still registered after exceeding the read limit")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
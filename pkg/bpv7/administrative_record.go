@@ -28,6 +28,15 @@ type AdministrativeRecord interface {
 	RecordTypeCode() uint64
 }
 
+// UnknownAdministrativeRecordError is returned for an administrative record whose type code is not registered.
+type UnknownAdministrativeRecordError struct {
+	TypeCode uint64
+}
+
+func (uare *UnknownAdministrativeRecordError) Error() string {
+	return fmt.Sprintf("no AdministrativeRecord registered for record type code %d", uare.TypeCode)
+}
+
 // AdministrativeRecordManager keeps a book on various types of AdministrativeRecords that can be changed at runtime.
 // Thus, new AdministrativeRecords can be created based on their block type code.
 //
@@ -96,7 +105,7 @@ func (arm *AdministrativeRecordManager) ReadAdministrativeRecord(r io.Reader) (a
 		err = cborErr
 		return
 	} else if arType, ok := arm.data.Load(typeCode); !ok {
-		err = fmt.Errorf("no AdministrativeRecord registered for record type code %d", typeCode)
+		err = &UnknownAdministrativeRecordError{TypeCode: typeCode}
 		return
 	} else {
 		ar = reflect.New(arType.(reflect.Type)).Interface().(AdministrativeRecord)
@@ -254,27 +254,41 @@ type StatusReport struct {
 // bundle status report reason code will be used and the bundle status item
 // gets the given timestamp.
 func NewStatusReport(bndl Bundle, statusItem StatusInformationPos, reason StatusReportReason, time DtnTime) (report *StatusReport) {
+	if !bndl.PrimaryBlock.BundleControlFlags.Has(RequestStatusTime) {
+		time = 0
+	}
+	return NewStatusReportForID(bndl.ID(), statusItem, reason, time)
+}
+
+// NewStatusReportForID creates a bundle status report for a referenced BundleID, without requiring the Bundle itself.
+// The status time will only be reported for a non-zero time.
+func NewStatusReportForID(bid BundleID, statusItem StatusInformationPos, reason StatusReportReason, time DtnTime) (report *StatusReport) {
 	report = &StatusReport{
 		StatusInformation: make([]BundleStatusItem, maxStatusInformationPos),
 		ReportReason:      reason,
-		RefBundle:         bndl.ID(),
+		RefBundle:         bid,
 	}
 
 	for i := 0; i < maxStatusInformationPos; i++ {
-		sip := StatusInformationPos(i)
-
-		switch {
-		case sip == statusItem && bndl.PrimaryBlock.BundleControlFlags.Has(RequestStatusTime):
-			report.StatusInformation[i] = NewTimeReportingBundleStatusItem(time)
+		report.StatusInformation[i] = NewBundleStatusItem(false)
+	}
+	report.Assert(statusItem, time)
+	return
+}
 
-		case sip == statusItem:
-			report.StatusInformation[i] = NewBundleStatusItem(true)
+// Assert a StatusInformationPos within this status report. Thus, multiple statuses of a bundle, e.g., its reception and
+// delivery, can be reported by a single status report. As for NewStatusReportForID, the status time will only be
+// reported for a non-zero time.
+func (sr *StatusReport) Assert(statusItem StatusInformationPos, time DtnTime) {
+	if statusItem < 0 || int(statusItem) >= len(sr.StatusInformation) {
+		return
+	}
 
-		default:
-			report.StatusInformation[i] = NewBundleStatusItem(false)
-		}
+	if time != 0 {
+		sr.StatusInformation[statusItem] = NewTimeReportingBundleStatusItem(time)
+	} else {
+		sr.StatusInformation[statusItem] = NewBundleStatusItem(true)
 	}
-	return
 }
 
 // StatusInformations returns an array of available StatusInformationPos.
@@ -113,6 +113,43 @@ func TestStatusReportCreationNoTime(t *testing.T) {
 	}
 }
 
+func TestStatusReportAssert(t *testing.T) {
+	bid := BundleID{
+		SourceNode: MustNewEndpointID("dtn://src/"),
+		Timestamp:  NewCreationTimestamp(DtnTimeNow(), 0),
+	}
+
+	sr := NewStatusReportForID(bid, ReceivedBundle, NoInformation, 23)
+	sr.Assert(ForwardedBundle, 42)
+	sr.Assert(DeliveredBundle, 0)
+	sr.Assert(StatusInformationPos(maxStatusInformationPos), 0)
+
+	if sips := sr.StatusInformations(); !reflect.DeepEqual(sips, []StatusInformationPos{ReceivedBundle, ForwardedBundle, DeliveredBundle}) {
+		t.Fatalf("asserted status information are %v", sips)
+	}
+
+	for _, test := range []struct {
+		sip  StatusInformationPos
+		time DtnTime
+	}{{ReceivedBundle, 23}, {ForwardedBundle, 42}, {DeliveredBundle, DtnTimeEpoch}} {
+		if bsi := sr.StatusInformation[test.sip]; bsi.Time != test.time || bsi.StatusRequested != (test.time != 0) {
+			t.Fatalf("%v's status item is incorrect: %v", test.sip, bsi)
+		}
+	}
+
+	// The asserted status information must survive serialization.
+	var buff bytes.Buffer
+	if err := cboring.Marshal(sr, &buff); err != nil {
+		t.Fatal(err)
+	}
+	sr2 := &StatusReport{}
+	if err := cboring.Unmarshal(sr2, &buff); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(sr, sr2) {
+		t.Fatalf("status reports differ: %v, %v", sr, sr2)
+	}
+}
+
 func TestStatusReportApplicationRecord(t *testing.T) {
 	bndl, err := Builder().
 		Source("dtn://src/").
@@ -5,6 +5,8 @@
 package bpv7
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -25,3 +27,17 @@ func TestAdministrativeRecordManager_Register(t *testing.T) {
 		}
 	}
 }
+
+func TestAdministrativeRecordManager_ReadUnknown(t *testing.T) {
+	arm := NewAdministrativeRecordManager()
+
+	// CBOR array of the unregistered record type code 99 and some content.
+	_, err := arm.ReadAdministrativeRecord(bytes.NewReader([]byte{0x82, 0x18, 0x63, 0x00}))
+
+	var unknownErr *UnknownAdministrativeRecordError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("expected UnknownAdministrativeRecordError, got %v", err)
+	} else if unknownErr.TypeCode != 99 {
+		t.Fatalf("unknown record type code is %d, expected 99", unknownErr.TypeCode)
+	}
+}
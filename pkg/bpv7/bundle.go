@@ -50,6 +50,12 @@ func ParseBundle(r io.Reader) (b Bundle, err error) {
 	return
 }
 
+// ParseBundleMode reads a CBOR encoded Bundle from a Reader, as ParseBundle does, in the given DecodeMode.
+func ParseBundleMode(r io.Reader, mode DecodeMode) (b Bundle, err error) {
+	err = b.UnmarshalCborMode(r, mode)
+	return
+}
+
 // WriteBundle writes this Bundle CBOR encoded into a Writer.
 func (b *Bundle) WriteBundle(w io.Writer) error {
 	return cboring.Marshal(b, w)
@@ -145,7 +151,7 @@ func (b *Bundle) RemoveExtensionBlockByBlockNumber(blockNumber uint64) {
 }
 
 // SetCRCType sets the given CRCType for each block. To also calculate and set
-// the CRC value, one should also call the CalculateCRC method.
+// the CRC value, one should also call the Finalize method.
 func (b *Bundle) SetCRCType(crcType CRCType) {
 	b.forEachBlock(func(blck block) {
 		blck.SetCRCType(crcType)
@@ -164,10 +170,32 @@ func (b Bundle) ID() BundleID {
 	}
 }
 
+// String representation of this Bundle, its ID and, if present, the Hop Count Block's state.
 func (b Bundle) String() string {
+	if hc, err := b.ExtensionBlock(ExtBlockTypeHopCountBlock); err == nil {
+		return fmt.Sprintf("%v (hop count %v)", b.ID(), hc.Value)
+	}
 	return b.ID().String()
 }
 
+// Reply creates a new Bundle from the given source back to this Bundle's source, e.g., for an acknowledgement. The
+// reply's lifetime and an optional hop limit are taken from this Bundle.
+func (b Bundle) Reply(source EndpointID, payload []byte) (Bundle, error) {
+	bldr := Builder().
+		CRC(CRC32).
+		Source(source).
+		Destination(b.PrimaryBlock.SourceNode).
+		BundleCtrlFlags(MustNotFragmented).
+		CreationTimestampNow().
+		Lifetime(b.PrimaryBlock.Lifetime)
+
+	if hc, err := b.ExtensionBlock(ExtBlockTypeHopCountBlock); err == nil {
+		bldr = bldr.HopCountBlock(int(hc.Value.(*HopCountBlock).Limit))
+	}
+
+	return bldr.PayloadBlock(payload).Build()
+}
+
 // IsLifetimeExceeded of this Bundle by checking an optional Bundle Age Block and the PrimaryBlock's Lifetime.
 func (b Bundle) IsLifetimeExceeded() bool {
 	if b.PrimaryBlock.CreationTimestamp.IsZeroTime() {
@@ -183,6 +211,42 @@ func (b Bundle) IsLifetimeExceeded() bool {
 	return time.Now().After(maxTimestamp)
 }
 
+// VerifyCRC checks if the CRC values of all canonical blocks match their content, e.g., after being modified. Blocks
+// without a CRC value are skipped. Compare CanonicalBlock.VerifyCRC.
+func (b *Bundle) VerifyCRC() (errs error) {
+	for i := range b.CanonicalBlocks {
+		if err := b.CanonicalBlocks[i].VerifyCRC(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return
+}
+
+// UpdateCRC recalculates the CRC values of all canonical blocks.
+func (b *Bundle) UpdateCRC() (errs error) {
+	for i := range b.CanonicalBlocks {
+		if err := b.CanonicalBlocks[i].UpdateCRC(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return
+}
+
+// Finalize sorts the blocks and calculates the CRC values of all blocks at once. A Bundle built with
+// BundleBuilder.DeferCRC has no valid CRC values until being finalized. Thus, this must be called after the last
+// modification of such a Bundle.
+func (b *Bundle) Finalize() (errs error) {
+	b.sortBlocks()
+
+	if err := b.PrimaryBlock.calculateCRC(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	if err := b.UpdateCRC(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	return
+}
+
 // CheckValid returns an array of errors for incorrect data.
 func (b Bundle) CheckValid() (errs error) {
 	// Check blocks for errors
@@ -233,8 +297,10 @@ func (b Bundle) CheckValid() (errs error) {
 		cbBlockTypes[blockType] = true
 	}
 
-	// Check if the PayloadBlock is the last block.
-	if last := b.CanonicalBlocks[len(b.CanonicalBlocks)-1].Value.BlockTypeCode(); last != ExtBlockTypePayloadBlock {
+	// Check if the PayloadBlock exists and is the last block.
+	if !b.HasExtensionBlock(ExtBlockTypePayloadBlock) {
+		errs = multierror.Append(errs, fmt.Errorf("Bundle: no Payload Block is present"))
+	} else if last := b.CanonicalBlocks[len(b.CanonicalBlocks)-1].Value.BlockTypeCode(); last != ExtBlockTypePayloadBlock {
 		errs = multierror.Append(errs,
 			fmt.Errorf("Bundle: last CannonicalBlock is not a Payload Block, but %d", last))
 	}
@@ -301,14 +367,33 @@ func (b *Bundle) MarshalCbor(w io.Writer) error {
 	return nil
 }
 
-// UnmarshalCbor creates this Bundle based on a CBOR representation.
+// UnmarshalCbor creates this Bundle based on a CBOR representation, using the StrictDecoding.
 func (b *Bundle) UnmarshalCbor(r io.Reader) error {
+	return b.UnmarshalCborMode(r, StrictDecoding)
+}
+
+// UnmarshalCborMode creates this Bundle based on a CBOR representation. Its strictness depends on the DecodeMode.
+func (b *Bundle) UnmarshalCborMode(r io.Reader, mode DecodeMode) error {
+	if lr, err := checkLegacyFormat(r); err != nil {
+		return err
+	} else {
+		r = lr
+	}
+
+	if mode == LenientDecoding {
+		if nr, err := normalizeBundleCbor(r); err != nil {
+			return err
+		} else {
+			r = nr
+		}
+	}
+
 	if err := cboring.ReadExpect(cboring.IndefiniteArray, r); err != nil {
 		return err
 	}
 
 	if err := cboring.Unmarshal(&b.PrimaryBlock, r); err != nil {
-		return fmt.Errorf("PrimaryBlock failed: %v", err)
+		return fmt.Errorf("PrimaryBlock failed: %w", err)
 	}
 
 	for {
@@ -322,9 +407,28 @@ func (b *Bundle) UnmarshalCbor(r io.Reader) error {
 		}
 	}
 
+	// Some implementations do not place the Payload Block last. Sorting restores a consistent order.
+	b.sortBlocks()
+
 	return b.CheckValid()
 }
 
+// checkLegacyFormat inspects the first byte of an encoded Bundle. Bundles prior to version 7, e.g., those of RFC 5050,
+// are not encoded in CBOR, but start with their version number as a single byte. Otherwise, an equivalent Reader is
+// returned.
+func checkLegacyFormat(r io.Reader) (io.Reader, error) {
+	var first [1]byte
+	if _, err := io.ReadFull(r, first[:]); err != nil {
+		return nil, err
+	}
+
+	if version := uint64(first[0]); 4 <= version && version < dtnVersion {
+		return nil, &LegacyFormatError{Version: version}
+	}
+
+	return io.MultiReader(bytes.NewReader(first[:]), r), nil
+}
+
 // MarshalJSON creates a JSON object for this Bundle.
 func (b Bundle) MarshalJSON() ([]byte, error) {
 	canonicals := make([]json.Marshaler, len(b.CanonicalBlocks))
@@ -7,7 +7,10 @@ package bpv7
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"time"
 )
 
@@ -30,6 +33,12 @@ type BundleBuilder struct {
 	canonicals       []CanonicalBlock
 	canonicalCounter uint64
 	crcType          CRCType
+	deferCRC         bool
+	epochTimestamp   bool
+	adminRecord      bool
+
+	payloadIntegrity      bool
+	payloadIntegrityFlags BlockControlFlags
 }
 
 // Builder creates a new BundleBuilder.
@@ -58,6 +67,16 @@ func (bldr *BundleBuilder) CRC(crcType CRCType) *BundleBuilder {
 	return bldr
 }
 
+// DeferCRC skips the CRC calculation when building the bundle. This might be used when building many bundles which
+// will be modified afterwards. Such a bundle has no valid CRC values until Bundle.Finalize was called.
+func (bldr *BundleBuilder) DeferCRC() *BundleBuilder {
+	if bldr.err == nil {
+		bldr.deferCRC = true
+	}
+
+	return bldr
+}
+
 // Build creates a new Bundle and returns an optional error.
 func (bldr *BundleBuilder) Build() (bndl Bundle, err error) {
 	if bldr.err != nil {
@@ -76,14 +95,110 @@ func (bldr *BundleBuilder) Build() (bndl Bundle, err error) {
 		return
 	}
 
+	// A bundle created at the epoch, due to a missing real-time clock, needs a Bundle Age Block to track its age.
+	if !bldr.hasCanonical(ExtBlockTypeBundleAgeBlock) {
+		if bldr.epochTimestamp {
+			if bldr.BundleAgeBlock(0); bldr.err != nil {
+				err = bldr.err
+				return
+			}
+		} else if bldr.primary.CreationTimestamp.IsZeroTime() {
+			err = fmt.Errorf("creation timestamp is zero, which requires a Bundle Age Block; use CreationTimestampEpoch or BundleAgeBlock")
+			return
+		}
+	}
+
+	// An administrative record's flags might have been overwritten afterwards, e.g., by BundleCtrlFlags.
+	if bldr.adminRecord {
+		bldr.primary.BundleControlFlags = adminRecordCtrlFlags(bldr.primary.BundleControlFlags)
+	}
+
 	bndl, err = NewBundle(bldr.primary, bldr.canonicals)
-	if err == nil {
+	if err != nil {
+		return
+	}
+
+	if bldr.payloadIntegrity {
+		if pb, pbErr := bndl.PayloadBlock(); pbErr != nil {
+			err = pbErr
+			return
+		} else {
+			bndl.AddExtensionBlock(NewCanonicalBlock(
+				0, bldr.payloadIntegrityFlags, NewPayloadIntegrityBlock(pb.Value.(*PayloadBlock).Data())))
+		}
+	}
+
+	// An administrative record's payload must be a CBOR encoded record, compare AdministrativeRecord.
+	if bndl.IsAdministrativeRecord() {
+		var unknownErr *UnknownAdministrativeRecordError
+		if _, arErr := bndl.AdministrativeRecord(); arErr != nil && !errors.As(arErr, &unknownErr) {
+			err = fmt.Errorf("administrative record payload is malformed: %w", arErr)
+			return
+		}
+	}
+
+	if bldr.deferCRC {
+		bndl.PrimaryBlock.setCRCType(bldr.crcType)
+		for i := range bndl.CanonicalBlocks {
+			bndl.CanonicalBlocks[i].SetCRCType(bldr.crcType)
+		}
+	} else {
 		bndl.SetCRCType(bldr.crcType)
 	}
 
 	return
 }
 
+// Warning is a non-fatal advisory about a built Bundle, as returned by BuildWithWarnings.
+type Warning int
+
+const (
+	// WarningShortLifetime indicates a lifetime of less than a minute, which might expire before any contact.
+	WarningShortLifetime Warning = iota
+
+	// WarningLargeWithoutCRC indicates a payload of at least one MiB without any CRC to detect corruptions.
+	WarningLargeWithoutCRC
+)
+
+const (
+	// shortLifetimeWarning is the lifetime below which WarningShortLifetime is issued.
+	shortLifetimeWarning = time.Minute
+
+	// largeWithoutCRCWarning is the payload size from which on WarningLargeWithoutCRC is issued.
+	largeWithoutCRCWarning = 1 << 20
+)
+
+func (w Warning) String() string {
+	switch w {
+	case WarningShortLifetime:
+		return "lifetime is shorter than a minute"
+	case WarningLargeWithoutCRC:
+		return "large payload without a CRC"
+	default:
+		return "unknown warning"
+	}
+}
+
+// BuildWithWarnings is like Build, but additionally returns non-fatal Warnings about the built Bundle. Those might
+// be logged or reacted upon by the caller.
+func (bldr *BundleBuilder) BuildWithWarnings() (bndl Bundle, warnings []Warning, err error) {
+	if bndl, err = bldr.Build(); err != nil {
+		return
+	}
+
+	if time.Duration(bndl.PrimaryBlock.Lifetime)*time.Millisecond < shortLifetimeWarning {
+		warnings = append(warnings, WarningShortLifetime)
+	}
+
+	if bldr.crcType == CRCNo && !bldr.payloadIntegrity {
+		if pb, pbErr := bndl.PayloadBlock(); pbErr == nil && len(pb.Value.(*PayloadBlock).Data()) >= largeWithoutCRCWarning {
+			warnings = append(warnings, WarningLargeWithoutCRC)
+		}
+	}
+
+	return
+}
+
 // mustBuild is like Build, but panics on an error. This method is only intended for internal testing.
 func (bldr *BundleBuilder) mustBuild() Bundle {
 	if b, err := bldr.Build(); err != nil {
@@ -194,15 +309,33 @@ func (bldr *BundleBuilder) ReportTo(eid interface{}) *BundleBuilder {
 func (bldr *BundleBuilder) creationTimestamp(t DtnTime) *BundleBuilder {
 	if bldr.err == nil {
 		bldr.primary.CreationTimestamp = NewCreationTimestamp(t, 0)
+		bldr.epochTimestamp = false
 	}
 
 	return bldr
 }
 
 // CreationTimestampEpoch sets the bundle's creation timestamp to the epoch
-// time, stored in its primary block.
+// time, stored in its primary block. This is intended for nodes without a
+// real-time clock. Thus, a Bundle Age Block of zero is added while building,
+// if no such block was added before.
 func (bldr *BundleBuilder) CreationTimestampEpoch() *BundleBuilder {
-	return bldr.creationTimestamp(DtnTimeEpoch)
+	bldr.creationTimestamp(DtnTimeEpoch)
+	if bldr.err == nil {
+		bldr.epochTimestamp = true
+	}
+
+	return bldr
+}
+
+// hasCanonical checks if a canonical block of this block type code was added.
+func (bldr *BundleBuilder) hasCanonical(blockType uint64) bool {
+	for _, cb := range bldr.canonicals {
+		if cb.TypeCode() == blockType {
+			return true
+		}
+	}
+	return false
 }
 
 // CreationTimestampNow sets the bundle's creation timestamp to the current
@@ -231,10 +364,10 @@ func (bldr *BundleBuilder) Lifetime(duration interface{}) *BundleBuilder {
 		return bldr
 	}
 
-	if us, usErr := bldrParseLifetime(duration); usErr != nil {
-		bldr.err = usErr
+	if ms, msErr := bldrParseLifetime(duration); msErr != nil {
+		bldr.err = msErr
 	} else {
-		bldr.primary.Lifetime = us
+		bldr.primary.Lifetime = ms
 	}
 
 	return bldr
@@ -260,6 +393,8 @@ func (bldr *BundleBuilder) BundleCtrlFlags(bcf BundleControlFlags) *BundleBuilde
 //   BlockControlFlags are _optional_ block processing control flags or
 //   CanonicalBlock is a CanonicalBlock
 //
+// A GenericExtensionBlock of a registered block type code, compare RegisterExtensionBlockType, is converted into its
+// specific ExtensionBlock.
 func (bldr *BundleBuilder) Canonical(args ...interface{}) *BundleBuilder {
 	if bldr.err != nil {
 		return bldr
@@ -298,6 +433,13 @@ func (bldr *BundleBuilder) Canonical(args ...interface{}) *BundleBuilder {
 			return bldr
 		}
 
+		if resolved, err := GetExtensionBlockManager().resolveBlock(data); err != nil {
+			bldr.err = fmt.Errorf("Canonical failed to read block type %d: %w", data.BlockTypeCode(), err)
+			return bldr
+		} else {
+			data = resolved
+		}
+
 		if data.BlockTypeCode() == ExtBlockTypePayloadBlock {
 			blockNumber = 1
 		} else {
@@ -310,6 +452,13 @@ func (bldr *BundleBuilder) Canonical(args ...interface{}) *BundleBuilder {
 
 	case CanonicalBlock:
 		cb := args[0].(CanonicalBlock)
+		if resolved, err := GetExtensionBlockManager().resolveBlock(cb.Value); err != nil {
+			bldr.err = fmt.Errorf("Canonical failed to read block type %d: %w", cb.TypeCode(), err)
+			return bldr
+		} else {
+			cb.Value = resolved
+		}
+
 		if cb.TypeCode() == ExtBlockTypePayloadBlock {
 			blockNumber = 1
 		} else {
@@ -357,7 +506,7 @@ func (bldr *BundleBuilder) BundleAgeBlock(args ...interface{}) *BundleBuilder {
 		bldr.err = msErr
 	}
 
-	flags := bldr.canonicalParseFlags(args) | ReplicateBlock
+	flags := bldr.canonicalParseFlags(args...) | ReplicateBlock
 
 	return bldr.Canonical(NewBundleAgeBlock(ms), flags)
 }
@@ -366,7 +515,7 @@ func (bldr *BundleBuilder) BundleAgeBlock(args ...interface{}) *BundleBuilder {
 //
 //   Limit[, BlockControlFlags]
 //
-//   where Limit is the limit of this Hop Count Block and
+//   where Limit is the limit of this Hop Count Block, between 1 and 255, and
 //   BlockControlFlags are _optional_ block processing control flags
 //
 func (bldr *BundleBuilder) HopCountBlock(args ...interface{}) *BundleBuilder {
@@ -374,32 +523,163 @@ func (bldr *BundleBuilder) HopCountBlock(args ...interface{}) *BundleBuilder {
 		return bldr
 	}
 
+	if len(args) == 0 {
+		bldr.err = fmt.Errorf("HopCountBlock received no parameters")
+		return bldr
+	}
+
 	limit, chk := args[0].(int)
 	if !chk {
 		bldr.err = fmt.Errorf("HopCountBlock received wrong parameter type")
+		return bldr
+	} else if limit < 1 || limit > 255 {
+		bldr.err = fmt.Errorf("HopCountBlock's limit %d is not within the range from 1 to 255", limit)
+		return bldr
 	}
 
-	flags := bldr.canonicalParseFlags(args) | ReplicateBlock
+	flags := bldr.canonicalParseFlags(args...) | ReplicateBlock
 
 	return bldr.Canonical(NewHopCountBlock(uint8(limit)), flags)
 }
 
+// RoutingMetricBlock adds a routing metric block to this bundle. The parameters are:
+//
+//   Metric[, BlockControlFlags]
+//
+//   where Metric is the initial metric as an int or uint64 and
+//   BlockControlFlags are _optional_ block processing control flags
+//
+func (bldr *BundleBuilder) RoutingMetricBlock(args ...interface{}) *BundleBuilder {
+	if bldr.err != nil {
+		return bldr
+	}
+
+	var metric uint64
+	switch m := args[0].(type) {
+	case uint64:
+		metric = m
+	case int:
+		if m < 0 {
+			bldr.err = fmt.Errorf("RoutingMetricBlock received a negative metric")
+		}
+		metric = uint64(m)
+	default:
+		bldr.err = fmt.Errorf("RoutingMetricBlock received wrong parameter type")
+	}
+
+	flags := bldr.canonicalParseFlags(args...) | ReplicateBlock
+
+	return bldr.Canonical(NewRoutingMetricBlock(metric), flags)
+}
+
+// DeliveryGuaranteeBlock adds a delivery guarantee block to this bundle. The parameters are:
+//
+//   Guarantee[, BlockControlFlags]
+//
+//   where Guarantee is a DeliveryGuarantee or its name, e.g., "at-least-once", and
+//   BlockControlFlags are _optional_ block processing control flags
+//
+func (bldr *BundleBuilder) DeliveryGuaranteeBlock(args ...interface{}) *BundleBuilder {
+	if bldr.err != nil {
+		return bldr
+	}
+
+	var guarantee DeliveryGuarantee
+	switch g := args[0].(type) {
+	case DeliveryGuarantee:
+		guarantee = g
+	case string:
+		if parsed, err := ParseDeliveryGuarantee(g); err != nil {
+			bldr.err = err
+		} else {
+			guarantee = parsed
+		}
+	default:
+		bldr.err = fmt.Errorf("DeliveryGuaranteeBlock received wrong parameter type")
+	}
+
+	flags := bldr.canonicalParseFlags(args...) | ReplicateBlock
+
+	return bldr.Canonical(NewDeliveryGuaranteeBlock(guarantee), flags)
+}
+
 // PayloadBlock adds a payload block to this bundle. The parameters are:
 //
 //   Data[, BlockControlFlags]
 //
 //   where Data is the payload's data and
 //   BlockControlFlags are _optional_ block processing control flags
+//
+//   Data might be a []byte or a string, stored verbatim, or an io.Reader, which is read completely.
+//   Other fixed-size values are encoded by binary.Write in little-endian byte order.
+//   Data might also be an AdministrativeRecord, compare AdministrativeRecord.
 func (bldr *BundleBuilder) PayloadBlock(args ...interface{}) *BundleBuilder {
-	var buf bytes.Buffer
-	if err := binary.Write(&buf, binary.LittleEndian, args[0]); err != nil {
-		bldr.err = err
+	if bldr.err != nil {
 		return bldr
 	}
 
+	if len(args) == 0 {
+		bldr.err = fmt.Errorf("PayloadBlock was called with no parameters")
+		return bldr
+	}
+
+	var data []byte
+	switch arg := args[0].(type) {
+	case AdministrativeRecord:
+		return bldr.administrativeRecord(arg, args[1:]...)
+
+	case []byte:
+		data = arg
+
+	case string:
+		data = []byte(arg)
+
+	case io.Reader:
+		if readData, err := ioutil.ReadAll(arg); err != nil {
+			bldr.err = fmt.Errorf("reading payload errored: %w", err)
+			return bldr
+		} else {
+			data = readData
+		}
+
+	default:
+		var buf bytes.Buffer
+		if err := binary.Write(&buf, binary.LittleEndian, arg); err != nil {
+			bldr.err = err
+			return bldr
+		}
+		data = buf.Bytes()
+	}
+
 	// Call Canonical, but add PayloadBlock as the first variadic parameter
 	return bldr.Canonical(append(
-		[]interface{}{NewPayloadBlock(buf.Bytes())}, args[1:]...)...)
+		[]interface{}{NewPayloadBlock(data)}, args[1:]...)...)
+}
+
+// PayloadIntegrityBlock adds a payload integrity block to this bundle, which is verified end-to-end by the
+// destination, but ignored by relays. Its hash is calculated for the final payload while building. The parameters are:
+//
+//   [BlockControlFlags]
+//
+//   where BlockControlFlags are _optional_ block processing control flags
+//
+// This provides an end-to-end detection of corrupted payloads without a CRC for each block, checked at each hop.
+func (bldr *BundleBuilder) PayloadIntegrityBlock(args ...interface{}) *BundleBuilder {
+	if bldr.err != nil {
+		return bldr
+	}
+
+	if len(args) > 0 {
+		if flags, ok := args[0].(BlockControlFlags); !ok {
+			bldr.err = fmt.Errorf("PayloadIntegrityBlock received wrong parameter type")
+			return bldr
+		} else {
+			bldr.payloadIntegrityFlags = flags
+		}
+	}
+
+	bldr.payloadIntegrity = true
+	return bldr
 }
 
 // PreviousNodeBlock adds a previous node block to this bundle. The parameters
@@ -420,14 +700,22 @@ func (bldr *BundleBuilder) PreviousNodeBlock(args ...interface{}) *BundleBuilder
 		bldr.err = eidErr
 	}
 
-	flags := bldr.canonicalParseFlags(args) | ReplicateBlock
+	flags := bldr.canonicalParseFlags(args...) | ReplicateBlock
 
 	return bldr.Canonical(NewPreviousNodeBlock(eid), flags)
 }
 
 // AdministrativeRecord configures an AdministrativeRecord as the Payload. Furthermore, the AdministrativeRecordPayload
 // BundleControlFlags is set.
+//
+// Passing an AdministrativeRecord to PayloadBlock has the same effect. Building a Bundle with a set
+// AdministrativeRecordPayload flag but a payload other than an AdministrativeRecord results in an error.
 func (bldr *BundleBuilder) AdministrativeRecord(ar AdministrativeRecord) *BundleBuilder {
+	return bldr.administrativeRecord(ar)
+}
+
+// administrativeRecord configures an AdministrativeRecord as the Payload, with optional BlockControlFlags.
+func (bldr *BundleBuilder) administrativeRecord(ar AdministrativeRecord, args ...interface{}) *BundleBuilder {
 	if bldr.err != nil {
 		return bldr
 	}
@@ -437,12 +725,16 @@ func (bldr *BundleBuilder) AdministrativeRecord(ar AdministrativeRecord) *Bundle
 		return bldr
 	}
 
-	// Enforce the AdministrativeRecordPayload flag and unset all requests.
-	noRequests := ^(StatusRequestReception | StatusRequestForward | StatusRequestDelivery | StatusRequestDeletion)
-	bldr.primary.BundleControlFlags |= AdministrativeRecordPayload
-	bldr.primary.BundleControlFlags &= noRequests
+	bldr.adminRecord = true
+	bldr.primary.BundleControlFlags = adminRecordCtrlFlags(bldr.primary.BundleControlFlags)
 
-	return bldr.PayloadBlock(buff.Bytes())
+	return bldr.PayloadBlock(append([]interface{}{buff.Bytes()}, args...)...)
+}
+
+// adminRecordCtrlFlags enforces the AdministrativeRecordPayload flag and unsets all status report requests.
+func adminRecordCtrlFlags(bcf BundleControlFlags) BundleControlFlags {
+	noRequests := ^(StatusRequestReception | StatusRequestForward | StatusRequestDelivery | StatusRequestDeletion)
+	return (bcf | AdministrativeRecordPayload) & noRequests
 }
 
 // StatusReport configures this BundleBuilder's Bundle to be an AdministrativeRecord, delivering a StatusReport.
@@ -510,6 +802,21 @@ func BuildFromMap(m map[string]interface{}) (bndl Bundle, err error) {
 
 	for method, args := range m {
 		switch method {
+		// func (bldr *BundleBuilder) CRC(crcType CRCType) *BundleBuilder
+		case "crc":
+			switch crcArgs := args.(type) {
+			case CRCType:
+				bldr.CRC(crcArgs)
+			case string:
+				if crcType, crcErr := ParseCRCType(crcArgs); crcErr != nil {
+					err = crcErr
+				} else {
+					bldr.CRC(crcType)
+				}
+			default:
+				err = fmt.Errorf("crc has an unsupported type %T", args)
+			}
+
 		// func (bldr *BundleBuilder) Destination(eid interface{}) *BundleBuilder
 		case "destination":
 			bldr.Destination(args)
@@ -559,6 +866,14 @@ func BuildFromMap(m map[string]interface{}) (bndl Bundle, err error) {
 		case "hop_count_block":
 			bldr.HopCountBlock(args)
 
+		// func (bldr *BundleBuilder) RoutingMetricBlock(args ...interface{}) *BundleBuilder
+		case "routing_metric_block":
+			bldr.RoutingMetricBlock(args)
+
+		// func (bldr *BundleBuilder) DeliveryGuaranteeBlock(args ...interface{}) *BundleBuilder
+		case "delivery_guarantee_block":
+			bldr.DeliveryGuaranteeBlock(args)
+
 		// func (bldr *BundleBuilder) PayloadBlock(args ...interface{}) *BundleBuilder
 		case "payload_block":
 			if sArgs, ok := args.(string); ok {
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -26,6 +27,8 @@ func TestBundleBuilderSimple(t *testing.T) {
 
 	if err != nil {
 		t.Fatalf("Builder errored: %v", err)
+	} else if bndl.PrimaryBlock.Lifetime != 600000 {
+		t.Fatalf("Lifetime of 10m is %d, expected 600000 ms", bndl.PrimaryBlock.Lifetime)
 	}
 
 	buff := new(bytes.Buffer)
@@ -173,6 +176,63 @@ func TestBundleBuilderAdministrativeRecord(t *testing.T) {
 	}
 }
 
+func TestBundleBuilderAdministrativeRecordPayload(t *testing.T) {
+	sr := NewStatusReport(MustNewBundle(PrimaryBlock{
+		SourceNode:        MustNewEndpointID("dtn://host-a/"),
+		CreationTimestamp: NewCreationTimestamp(DtnTimeNow(), 0),
+	}, nil), DeliveredBundle, NoInformation, DtnTimeNow())
+
+	b, err := Builder().
+		Source("dtn://host-b/").
+		Destination("dtn://host-a/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock(sr).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	} else if !b.IsAdministrativeRecord() {
+		t.Fatal("bundle with an AdministrativeRecord payload misses the flag")
+	} else if b.PrimaryBlock.BundleControlFlags.Has(StatusRequestDelivery) {
+		t.Fatal("administrative record requests a status report")
+	} else if ar, err := b.AdministrativeRecord(); err != nil {
+		t.Fatal(err)
+	} else if ar.(*StatusReport).RefBundle != sr.RefBundle {
+		t.Fatalf("status report refers to %v, not %v", ar.(*StatusReport).RefBundle, sr.RefBundle)
+	}
+
+	// Bundle control flags set afterwards must neither drop the flag nor request status reports.
+	b, err = Builder().
+		Source("dtn://host-b/").
+		Destination("dtn://host-a/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		AdministrativeRecord(sr).
+		BundleCtrlFlags(MustNotFragmented | StatusRequestDelivery).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	} else if !b.IsAdministrativeRecord() {
+		t.Fatal("overwritten bundle control flags dropped the administrative record flag")
+	} else if !b.PrimaryBlock.BundleControlFlags.Has(MustNotFragmented) {
+		t.Fatal("bundle control flags were not applied")
+	} else if b.PrimaryBlock.BundleControlFlags.Has(StatusRequestDelivery) {
+		t.Fatal("administrative record requests a status report")
+	}
+
+	_, err = Builder().
+		BundleCtrlFlags(AdministrativeRecordPayload).
+		Source("dtn://host-b/").
+		Destination("dtn://host-a/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err == nil {
+		t.Fatal("administrative record with a malformed payload was built")
+	}
+}
+
 func TestBuildFromMap(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -220,6 +280,36 @@ func TestBuildFromMap(t *testing.T) {
 				mustBuild(),
 			wantErr: false,
 		},
+		{
+			name: "crc by name",
+			args: map[string]interface{}{
+				"crc":                      "crc16",
+				"destination":              "dtn://dst/",
+				"source":                   "dtn://src/",
+				"creation_timestamp_epoch": true,
+				"lifetime":                 "24h",
+				"bundle_age_block":         23,
+				"payload_block":            "hello world",
+			},
+			wantBndl: Builder().
+				CRC(CRC16).
+				Destination("dtn://dst/").
+				Source("dtn://src/").
+				CreationTimestampEpoch().
+				Lifetime("24h").
+				BundleAgeBlock(23).
+				PayloadBlock([]byte("hello world")).
+				mustBuild(),
+			wantErr: false,
+		},
+		{
+			name: "unknown crc",
+			args: map[string]interface{}{
+				"crc": "crc64",
+			},
+			wantBndl: Bundle{},
+			wantErr:  true,
+		},
 		{
 			name: "illegal method",
 			args: map[string]interface{}{
@@ -285,3 +375,385 @@ func TestBuildFromMapJSON(t *testing.T) {
 		t.Fatalf("%v != %v", expectedBndl, bndl)
 	}
 }
+
+func TestBundleBuilderDeferCRC(t *testing.T) {
+	bndl, err := Builder().
+		CRC(CRC32).
+		DeferCRC().
+		Source("dtn://myself/").
+		Destination("dtn://dest/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock([]byte("hello world!")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bndl.PrimaryBlock.CRC != nil {
+		t.Fatalf("primary block of an unfinalized bundle has a CRC value: %x", bndl.PrimaryBlock.CRC)
+	}
+	for _, cb := range bndl.CanonicalBlocks {
+		if cb.CRC != nil {
+			t.Fatalf("canonical block %d of an unfinalized bundle has a CRC value: %x", cb.BlockNumber, cb.CRC)
+		}
+	}
+
+	// Tweak the bundle before finalizing it.
+	hcb := NewCanonicalBlock(0, 0, NewHopCountBlock(64))
+	hcb.SetCRCType(CRC32)
+	bndl.AddExtensionBlock(hcb)
+
+	if err := bndl.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	if bndl.PrimaryBlock.CRC == nil {
+		t.Fatal("primary block of a finalized bundle has no CRC value")
+	}
+	for _, cb := range bndl.CanonicalBlocks {
+		if cb.CRC == nil {
+			t.Fatalf("canonical block %d of a finalized bundle has no CRC value", cb.BlockNumber)
+		}
+	}
+	if err := bndl.VerifyCRC(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Parsing the serialized bundle checks all CRC values.
+	buff := new(bytes.Buffer)
+	if err := bndl.MarshalCbor(buff); err != nil {
+		t.Fatal(err)
+	}
+
+	var bndl2 Bundle
+	if err := bndl2.UnmarshalCbor(buff); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(bndl, bndl2) {
+		t.Fatalf("finalized bundle differs from its parsed representation: %v, %v", bndl, bndl2)
+	}
+}
+
+func TestBundleBuilderWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		crcType  CRCType
+		lifetime string
+		payload  int
+		warnings []Warning
+	}{
+		{"none", CRC32, "10m", 1 << 20, nil},
+		{"small without crc", CRCNo, "10m", 1024, nil},
+		{"large without crc", CRCNo, "10m", 1 << 20, []Warning{WarningLargeWithoutCRC}},
+		{"short lifetime", CRC32, "30s", 1024, []Warning{WarningShortLifetime}},
+		{"both", CRCNo, "30s", 1 << 20, []Warning{WarningShortLifetime, WarningLargeWithoutCRC}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bndl, warnings, err := Builder().
+				CRC(test.crcType).
+				Source("dtn://myself/").
+				Destination("dtn://dest/").
+				CreationTimestampNow().
+				Lifetime(test.lifetime).
+				PayloadBlock(make([]byte, test.payload)).
+				BuildWithWarnings()
+
+			if err != nil {
+				t.Fatal(err)
+			} else if err := bndl.CheckValid(); err != nil {
+				t.Fatal(err)
+			} else if !reflect.DeepEqual(warnings, test.warnings) {
+				t.Fatalf("warnings are %v, expected %v", warnings, test.warnings)
+			}
+		})
+	}
+
+	if _, warnings, err := Builder().Source("dtn://myself/").BuildWithWarnings(); err == nil {
+		t.Fatal("building an invalid bundle did not error")
+	} else if len(warnings) > 0 {
+		t.Fatalf("erroneous build resulted in warnings %v", warnings)
+	}
+}
+
+func TestBundleBuilderExtensionBlockFlags(t *testing.T) {
+	bndl, err := Builder().
+		Source("dtn://myself/").
+		Destination("dtn://dest/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		BundleAgeBlock(0, StatusReportBlock).
+		HopCountBlock(64, DeleteBundle).
+		PreviousNodeBlock("dtn://prev/", StatusReportBlock|DeleteBundle).
+		PayloadBlock([]byte("hello world!")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		blockType uint64
+		flags     BlockControlFlags
+	}{
+		{ExtBlockTypeBundleAgeBlock, StatusReportBlock | ReplicateBlock},
+		{ExtBlockTypeHopCountBlock, DeleteBundle | ReplicateBlock},
+		{ExtBlockTypePreviousNodeBlock, StatusReportBlock | DeleteBundle | ReplicateBlock},
+	}
+
+	for _, test := range tests {
+		if cb, err := bndl.ExtensionBlock(test.blockType); err != nil {
+			t.Fatal(err)
+		} else if cb.BlockControlFlags != test.flags {
+			t.Fatalf("block %d has flags %v, expected %v", test.blockType, cb.BlockControlFlags, test.flags)
+		}
+	}
+
+	if _, err := Builder().
+		Source("dtn://myself/").
+		Destination("dtn://dest/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		HopCountBlock(64, "no flags").
+		PayloadBlock([]byte("hello world!")).
+		Build(); err == nil {
+		t.Fatal("invalid block control flags were accepted")
+	}
+}
+
+func TestBundleBuilderPayloadIntegrityBlock(t *testing.T) {
+	bndl, warnings, err := Builder().
+		Source("dtn://myself/").
+		Destination("dtn://dest/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadIntegrityBlock().
+		PayloadBlock(make([]byte, 1<<20)).
+		BuildWithWarnings()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(warnings) > 0 {
+		t.Fatalf("integrity protected bundle resulted in warnings %v", warnings)
+	}
+
+	if _, err := bndl.ExtensionBlock(ExtBlockTypePayloadIntegrityBlock); err != nil {
+		t.Fatal(err)
+	} else if err := bndl.VerifyPayloadIntegrity(); err != nil {
+		t.Fatal(err)
+	}
+
+	pb, err := bndl.PayloadBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	(*pb.Value.(*PayloadBlock))[0] ^= 0xff
+
+	if err := bndl.VerifyPayloadIntegrity(); err == nil {
+		t.Fatal("corrupted payload was verified")
+	}
+
+	if _, err := Builder().
+		Source("dtn://myself/").
+		Destination("dtn://dest/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadIntegrityBlock("nope").
+		PayloadBlock([]byte("hello world!")).
+		Build(); err == nil {
+		t.Fatal("PayloadIntegrityBlock with a wrong parameter did not error")
+	}
+}
+
+func TestBundleBuilderPayloadBlockFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags BlockControlFlags
+		valid bool
+	}{
+		{"none", 0, true},
+		{"status report", StatusReportBlock, true},
+		{"delete bundle", DeleteBundle | StatusReportBlock, true},
+		{"remove block", RemoveBlock, false},
+		{"remove block with status report", RemoveBlock | StatusReportBlock, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bndl, err := Builder().
+				Source("dtn://myself/").
+				Destination("dtn://dest/").
+				CreationTimestampNow().
+				Lifetime("10m").
+				PayloadBlock([]byte("hello world!"), test.flags).
+				Build()
+
+			if test.valid && err != nil {
+				t.Fatalf("valid flags %v errored: %v", test.flags, err)
+			} else if !test.valid && err == nil {
+				t.Fatalf("invalid flags %v were accepted", test.flags)
+			} else if test.valid {
+				if pb, pbErr := bndl.PayloadBlock(); pbErr != nil {
+					t.Fatal(pbErr)
+				} else if pb.BlockControlFlags != test.flags {
+					t.Fatalf("payload block has flags %v, expected %v", pb.BlockControlFlags, test.flags)
+				}
+			}
+		})
+	}
+}
+
+func TestBundleBuilderEpochBundleAge(t *testing.T) {
+	bndl, err := Builder().
+		Source("dtn://myself/").
+		Destination("dtn://dest/").
+		CreationTimestampEpoch().
+		Lifetime("10m").
+		PayloadBlock([]byte("hello world!")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cb, err := bndl.ExtensionBlock(ExtBlockTypeBundleAgeBlock); err != nil {
+		t.Fatalf("epoch bundle has no Bundle Age Block: %v", err)
+	} else if age := cb.Value.(*BundleAgeBlock).Age(); age != 0 {
+		t.Fatalf("Bundle Age Block is %d, expected 0", age)
+	}
+
+	// An explicit Bundle Age Block must not be duplicated.
+	bndl, err = Builder().
+		Source("dtn://myself/").
+		Destination("dtn://dest/").
+		CreationTimestampEpoch().
+		Lifetime("10m").
+		BundleAgeBlock(23).
+		PayloadBlock([]byte("hello world!")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ageBlocks := 0
+	for _, cb := range bndl.CanonicalBlocks {
+		if cb.TypeCode() == ExtBlockTypeBundleAgeBlock {
+			ageBlocks++
+		}
+	}
+	if ageBlocks != 1 {
+		t.Fatalf("bundle has %d Bundle Age Blocks", ageBlocks)
+	}
+
+	// A zero creation timestamp without a Bundle Age Block must be rejected.
+	if _, err := Builder().
+		Source("dtn://myself/").
+		Destination("dtn://dest/").
+		CreationTimestampTime(DtnTimeEpoch.Time()).
+		Lifetime("10m").
+		PayloadBlock([]byte("hello world!")).
+		Build(); err == nil {
+		t.Fatal("zero creation timestamp without a Bundle Age Block was accepted")
+	}
+
+	// A real creation timestamp does not need a Bundle Age Block.
+	if bndl, err := Builder().
+		Source("dtn://myself/").
+		Destination("dtn://dest/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock([]byte("hello world!")).
+		Build(); err != nil {
+		t.Fatal(err)
+	} else if _, err := bndl.ExtensionBlock(ExtBlockTypeBundleAgeBlock); err == nil {
+		t.Fatal("bundle with a real creation timestamp has a Bundle Age Block")
+	}
+}
+
+func TestBundleBuilderBlockNumbers(t *testing.T) {
+	builder := func() *BundleBuilder {
+		return Builder().
+			Source("dtn://myself/").
+			Destination("dtn://dest/").
+			CreationTimestampNow().
+			Lifetime("10m")
+	}
+
+	tests := []struct {
+		name  string
+		bldr  *BundleBuilder
+		valid bool
+	}{
+		{"payload", builder().HopCountBlock(64).PayloadBlock([]byte("hello")), true},
+		{"no payload", builder().HopCountBlock(64), false},
+		{"two payloads", builder().PayloadBlock([]byte("hello")).PayloadBlock([]byte("world")), false},
+		{"two hop counts", builder().HopCountBlock(64).HopCountBlock(32).PayloadBlock([]byte("hello")), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bndl, err := test.bldr.Build()
+			if (err == nil) != test.valid {
+				t.Fatalf("expected valid := %t, got %v", test.valid, err)
+			} else if !test.valid {
+				return
+			}
+
+			// The payload block must be emitted last, the other blocks ordered by their unique block numbers.
+			blocks := bndl.CanonicalBlocks
+			if last := blocks[len(blocks)-1]; last.TypeCode() != ExtBlockTypePayloadBlock || last.BlockNumber != 1 {
+				t.Fatalf("last block is %v", last)
+			}
+			for i := 1; i < len(blocks)-1; i++ {
+				if blocks[i-1].BlockNumber >= blocks[i].BlockNumber {
+					t.Fatalf("blocks are not ordered: %v", blocks)
+				}
+			}
+		})
+	}
+}
+
+func TestBundleBuilderPayloadBlockData(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		want []byte
+	}{
+		{"bytes", []byte("hello world!"), []byte("hello world!")},
+		{"string", "hello world!", []byte("hello world!")},
+		{"reader", strings.NewReader("hello world!"), []byte("hello world!")},
+		{"empty", []byte{}, []byte{}},
+		{"binary", []byte{0x00, 0xFF, 0x23, 0x42}, []byte{0x00, 0xFF, 0x23, 0x42}},
+		{"uint16", uint16(0x2342), []byte{0x42, 0x23}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bndl, err := Builder().
+				Source("dtn://myself/").
+				Destination("dtn://dest/").
+				CreationTimestampNow().
+				Lifetime("10m").
+				PayloadBlock(test.data).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pb, err := bndl.PayloadBlock()
+			if err != nil {
+				t.Fatal(err)
+			} else if data := pb.Value.(*PayloadBlock).Data(); !bytes.Equal(data, test.want) {
+				t.Fatalf("payload is %x, expected %x", data, test.want)
+			}
+		})
+	}
+
+	if _, err := Builder().
+		Source("dtn://myself/").
+		Destination("dtn://dest/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock().
+		Build(); err == nil {
+		t.Fatal("PayloadBlock without parameters did not error")
+	}
+}
@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"bytes"
+)
+
+// BundleSnapshot is a plain representation of a Bundle's decoded fields, free of interfaces and codec internals.
+// Thus, two BundleSnapshots can be compared by reflect.DeepEqual, e.g., within tests.
+type BundleSnapshot struct {
+	Version            uint64
+	BundleControlFlags BundleControlFlags
+	CRCType            CRCType
+
+	Destination string
+	SourceNode  string
+	ReportTo    string
+
+	CreationTime    DtnTime
+	SequenceNumber  uint64
+	Lifetime        uint64
+	FragmentOffset  uint64
+	TotalDataLength uint64
+	CanonicalBlocks []CanonicalSnapshot
+}
+
+// CanonicalSnapshot is a plain representation of a CanonicalBlock, part of a BundleSnapshot.
+type CanonicalSnapshot struct {
+	BlockNumber       uint64
+	BlockTypeCode     uint64
+	BlockTypeName     string
+	BlockControlFlags BlockControlFlags
+	CRCType           CRCType
+
+	// Data is the serialized block-type specific data. It is nil if the serialization failed.
+	Data []byte
+}
+
+// Snapshot creates a BundleSnapshot of a Bundle. Calculated CRC values are omitted.
+func Snapshot(b Bundle) BundleSnapshot {
+	pb := b.PrimaryBlock
+
+	snapshot := BundleSnapshot{
+		Version:            pb.Version,
+		BundleControlFlags: pb.BundleControlFlags,
+		CRCType:            pb.CRCType,
+
+		Destination: pb.Destination.String(),
+		SourceNode:  pb.SourceNode.String(),
+		ReportTo:    pb.ReportTo.String(),
+
+		CreationTime:    pb.CreationTimestamp.DtnTime(),
+		SequenceNumber:  pb.CreationTimestamp.SequenceNumber(),
+		Lifetime:        pb.Lifetime,
+		FragmentOffset:  pb.FragmentOffset,
+		TotalDataLength: pb.TotalDataLength,
+	}
+
+	for _, cb := range b.CanonicalBlocks {
+		cs := CanonicalSnapshot{
+			BlockNumber:       cb.BlockNumber,
+			BlockTypeCode:     cb.TypeCode(),
+			BlockTypeName:     cb.Value.BlockTypeName(),
+			BlockControlFlags: cb.BlockControlFlags,
+			CRCType:           cb.CRCType,
+		}
+
+		buff := new(bytes.Buffer)
+		if err := GetExtensionBlockManager().WriteBlock(cb.Value, buff); err == nil {
+			cs.Data = buff.Bytes()
+		}
+
+		snapshot.CanonicalBlocks = append(snapshot.CanonicalBlocks, cs)
+	}
+
+	return snapshot
+}
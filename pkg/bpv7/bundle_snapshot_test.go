@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	build := func(payload string) Bundle {
+		b, err := Builder().
+			CRC(CRC32).
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampTime(time.Unix(1600000000, 0)).
+			Lifetime("87600h").
+			HopCountBlock(64).
+			PayloadBlock([]byte(payload)).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	b1 := build("hello world")
+
+	// An equivalent Bundle, passed through its CBOR representation.
+	buff := new(bytes.Buffer)
+	if err := b1.WriteBundle(buff); err != nil {
+		t.Fatal(err)
+	}
+	b2, err := ParseBundle(buff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s1, s2 := Snapshot(b1), Snapshot(b2)
+	if !reflect.DeepEqual(s1, s2) {
+		t.Fatalf("snapshots of equivalent bundles differ:\n%v\n%v", s1, s2)
+	}
+
+	if s1.SourceNode != "dtn://src/" || s1.Lifetime != 315360000000 || len(s1.CanonicalBlocks) != 2 {
+		t.Fatalf("snapshot has unexpected fields: %v", s1)
+	}
+	for _, cs := range s1.CanonicalBlocks {
+		if cs.Data == nil {
+			t.Fatalf("canonical block %d misses its data", cs.BlockNumber)
+		}
+	}
+
+	if s3 := Snapshot(build("goodbye world")); reflect.DeepEqual(s1, s3) {
+		t.Fatalf("snapshots of different bundles are equal: %v", s3)
+	}
+}
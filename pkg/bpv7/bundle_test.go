@@ -6,7 +6,9 @@ package bpv7
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"reflect"
 	"testing"
@@ -108,6 +110,68 @@ func TestBundleCbor(t *testing.T) {
 	}
 }
 
+func TestBundleLegacyFormat(t *testing.T) {
+	b, err := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buff := new(bytes.Buffer)
+	if err := b.MarshalCbor(buff); err != nil {
+		t.Fatal(err)
+	}
+	data := buff.Bytes()
+
+	// An indefinite-length array, followed by the primary block's array header and its version.
+	if data[0] != cboring.IndefiniteArray || data[2] != byte(dtnVersion) {
+		t.Fatalf("unexpected bundle encoding %x", data[:3])
+	}
+	withVersion := func(version byte) []byte {
+		versionData := append([]byte(nil), data...)
+		versionData[2] = version
+		return versionData
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		valid   bool
+		legacy  bool
+		version uint64
+	}{
+		{"bpv7", data, true, false, 0},
+		{"rfc5050", []byte{0x06, 0x81, 0x10, 0x00, 0x00, 0x00, 0x00}, false, true, 6},
+		{"primary block v6", withVersion(0x06), false, true, 6},
+		{"primary block v8", withVersion(0x08), false, false, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b2 Bundle
+			err := b2.UnmarshalCbor(bytes.NewBuffer(test.data))
+
+			var legacyErr *LegacyFormatError
+			if isLegacy := errors.As(err, &legacyErr); isLegacy != test.legacy {
+				t.Fatalf("legacy format detection is %t, expected %t: %v", isLegacy, test.legacy, err)
+			} else if isLegacy && legacyErr.Version != test.version {
+				t.Fatalf("detected version %d, expected %d", legacyErr.Version, test.version)
+			}
+
+			if test.valid && err != nil {
+				t.Fatal(err)
+			} else if !test.valid && err == nil {
+				t.Fatal("unsupported bundle was parsed")
+			}
+		})
+	}
+}
+
 func TestBundleExtensionBlock(t *testing.T) {
 	var bndl, err = NewBundle(
 		NewPrimaryBlock(
@@ -201,6 +265,27 @@ func TestBundleCheckValid(t *testing.T) {
 			[]CanonicalBlock{
 				NewCanonicalBlock(1, 0, NewPayloadBlock(nil))}),
 			false},
+
+		// No Payload Block
+		{createNewBundle(
+			NewPrimaryBlock(MustNotFragmented, DtnNone(), DtnNone(), NewCreationTimestamp(42000000000000, 0), 3600),
+			[]CanonicalBlock{
+				NewCanonicalBlock(2, 0, NewHopCountBlock(23))}),
+			false},
+
+		// Reserved block numbers
+		{createNewBundle(
+			NewPrimaryBlock(MustNotFragmented, DtnNone(), DtnNone(), NewCreationTimestamp(42000000000000, 0), 3600),
+			[]CanonicalBlock{
+				NewCanonicalBlock(0, 0, NewHopCountBlock(23)),
+				NewCanonicalBlock(1, 0, NewPayloadBlock(nil))}),
+			false},
+		{createNewBundle(
+			NewPrimaryBlock(MustNotFragmented, DtnNone(), DtnNone(), NewCreationTimestamp(42000000000000, 0), 3600),
+			[]CanonicalBlock{
+				NewCanonicalBlock(1, 0, NewHopCountBlock(23)),
+				NewCanonicalBlock(2, 0, NewPayloadBlock(nil))}),
+			false},
 	}
 
 	for _, test := range tests {
@@ -283,6 +368,39 @@ func BenchmarkBundleSerializationCboring(b *testing.B) {
 	}
 }
 
+// BenchmarkBundleSerializationMemory reports the allocated memory for serializing a large Bundle into a stream, e.g.,
+// a TCPCLv4 transfer. As a block's CRC value is calculated while writing, the payload is not copied.
+func BenchmarkBundleSerializationMemory(b *testing.B) {
+	payload := make([]byte, 10485760)
+
+	rand.Seed(0)
+	rand.Read(payload)
+
+	for _, crc := range []CRCType{CRCNo, CRC16, CRC32} {
+		bndl := MustNewBundle(
+			NewPrimaryBlock(
+				0,
+				MustNewEndpointID("dtn://dest/"),
+				MustNewEndpointID("dtn://src/"),
+				NewCreationTimestamp(DtnTimeEpoch, 0),
+				60*60*1000000),
+			[]CanonicalBlock{
+				NewCanonicalBlock(2, 0, NewBundleAgeBlock(0)),
+				NewCanonicalBlock(1, 0, NewPayloadBlock(payload)),
+			})
+		bndl.SetCRCType(crc)
+
+		b.Run(crc.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := bndl.WriteBundle(ioutil.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 func BenchmarkBundleDeserializationCboring(b *testing.B) {
 	var sizes = []int{0, 1024, 1048576, 10485760, 104857600}
 	var crcs = []CRCType{CRCNo, CRC16, CRC32}
@@ -329,3 +447,104 @@ func BenchmarkBundleDeserializationCboring(b *testing.B) {
 		}
 	}
 }
+
+func TestBundleCborUnsortedBlocks(t *testing.T) {
+	b1, err := Builder().
+		CRC(CRC32).
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		HopCountBlock(64).
+		BundleAgeBlock(0).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Move the Payload Block in front of the extension blocks.
+	unsorted := b1
+	unsorted.CanonicalBlocks = nil
+	for _, cb := range b1.CanonicalBlocks {
+		if cb.TypeCode() == ExtBlockTypePayloadBlock {
+			unsorted.CanonicalBlocks = append([]CanonicalBlock{cb}, unsorted.CanonicalBlocks...)
+		} else {
+			unsorted.CanonicalBlocks = append(unsorted.CanonicalBlocks, cb)
+		}
+	}
+
+	buff := new(bytes.Buffer)
+	if err := cboring.Marshal(&unsorted, buff); err != nil {
+		t.Fatal(err)
+	}
+
+	b2 := Bundle{}
+	if err := cboring.Unmarshal(&b2, buff); err != nil {
+		t.Fatalf("decoding unsorted bundle failed: %v", err)
+	}
+
+	if payloadBlock, err := b2.PayloadBlock(); err != nil {
+		t.Fatal(err)
+	} else if data := payloadBlock.Value.(*PayloadBlock).Data(); string(data) != "hello world" {
+		t.Fatalf("payload is %q", data)
+	}
+
+	for _, blockType := range []uint64{ExtBlockTypeHopCountBlock, ExtBlockTypeBundleAgeBlock} {
+		if !b2.HasExtensionBlock(blockType) {
+			t.Fatalf("extension block %d is missing", blockType)
+		}
+	}
+
+	// After decoding, the blocks should be sorted again, resulting in the original representation.
+	buff1, buff2 := new(bytes.Buffer), new(bytes.Buffer)
+	if err := cboring.Marshal(&b1, buff1); err != nil {
+		t.Fatal(err)
+	} else if err := cboring.Marshal(&b2, buff2); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(buff1.Bytes(), buff2.Bytes()) {
+		t.Fatalf("Cbor-Representations do not match:\n- %x\n- %x", buff1.Bytes(), buff2.Bytes())
+	}
+}
+
+func TestBundleVerifyCRC(t *testing.T) {
+	b, err := Builder().
+		CRC(CRC32).
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		HopCountBlock(64).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Blocks without any calculated CRC value are valid.
+	if err := b.VerifyCRC(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.WriteBundle(new(bytes.Buffer)); err != nil {
+		t.Fatal(err)
+	} else if err := b.VerifyCRC(); err != nil {
+		t.Fatal(err)
+	}
+
+	hcBlock, err := b.ExtensionBlock(ExtBlockTypeHopCountBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hcBlock.Value.(*HopCountBlock).Increment()
+
+	if err := b.VerifyCRC(); err == nil {
+		t.Fatal("modified block without recalculated CRC passed the verification")
+	}
+
+	if err := b.UpdateCRC(); err != nil {
+		t.Fatal(err)
+	} else if err := b.VerifyCRC(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
+	"io/ioutil"
 	"strings"
 
 	"github.com/dtn7/cboring"
@@ -55,6 +57,32 @@ func (cb *CanonicalBlock) SetCRCType(crcType CRCType) {
 	cb.CRCType = crcType
 }
 
+// UpdateCRC recalculates this block's CRC value. This must be called after modifying a block with a CRC.
+func (cb *CanonicalBlock) UpdateCRC() error {
+	if !cb.HasCRC() {
+		cb.CRC = nil
+		return nil
+	}
+	return cb.MarshalCbor(ioutil.Discard)
+}
+
+// VerifyCRC checks if this block's CRC value matches its content. A block without a CRC value, e.g., one which
+// was never serialized, is considered valid.
+func (cb CanonicalBlock) VerifyCRC() error {
+	if !cb.HasCRC() || cb.CRC == nil {
+		return nil
+	}
+
+	calc := cb
+	if err := calc.UpdateCRC(); err != nil {
+		return err
+	} else if !bytes.Equal(cb.CRC, calc.CRC) {
+		return fmt.Errorf("canonical block %d has an invalid CRC value: %x instead of expected %x",
+			cb.BlockNumber, cb.CRC, calc.CRC)
+	}
+	return nil
+}
+
 // MarshalCbor writes this Canonical Block's CBOR representation.
 func (cb *CanonicalBlock) MarshalCbor(w io.Writer) error {
 	var blockLen uint64 = 5
@@ -62,9 +90,15 @@ func (cb *CanonicalBlock) MarshalCbor(w io.Writer) error {
 		blockLen = 6
 	}
 
-	crcBuff := new(bytes.Buffer)
+	// The CRC value is calculated while writing, without buffering the whole block, e.g., a huge payload.
+	var crcHash hash.Hash
 	if cb.HasCRC() {
-		w = io.MultiWriter(w, crcBuff)
+		if h, err := newCRCHash(cb.CRCType); err != nil {
+			return err
+		} else {
+			crcHash = h
+		}
+		w = io.MultiWriter(w, crcHash)
 	}
 
 	if err := cboring.WriteArrayLength(blockLen, w); err != nil {
@@ -84,7 +118,7 @@ func (cb *CanonicalBlock) MarshalCbor(w io.Writer) error {
 	}
 
 	if cb.HasCRC() {
-		if crcVal, crcErr := calculateCRCBuff(crcBuff, cb.CRCType); crcErr != nil {
+		if crcVal, crcErr := sumCRCHash(crcHash, cb.CRCType); crcErr != nil {
 			return crcErr
 		} else if err := cboring.WriteByteString(crcVal, w); err != nil {
 			return err
@@ -107,14 +141,15 @@ func (cb *CanonicalBlock) UnmarshalCbor(r io.Reader) error {
 		blockLen = bl
 	}
 
-	// Pipe incoming bytes into a separate CRC buffer
+	// Pipe incoming bytes into a separate CRC buffer until the CRC type is known
+	blockReader := r
 	crcBuff := new(bytes.Buffer)
 	if blockLen == 6 {
 		// Replay array's start
 		if err := cboring.WriteArrayLength(blockLen, crcBuff); err != nil {
 			return err
 		}
-		r = io.TeeReader(r, crcBuff)
+		r = io.TeeReader(blockReader, crcBuff)
 	}
 
 	var blockType uint64
@@ -142,6 +177,20 @@ func (cb *CanonicalBlock) UnmarshalCbor(r io.Reader) error {
 		cb.CRCType = CRCType(crcT)
 	}
 
+	// Afterwards, the CRC value is calculated while reading, without buffering the whole block
+	var crcHash hash.Hash
+	if blockLen == 6 {
+		if h, err := newCRCHash(cb.CRCType); err != nil {
+			return err
+		} else if h != nil {
+			crcHash = h
+			_, _ = crcHash.Write(crcBuff.Bytes())
+			r = io.TeeReader(blockReader, crcHash)
+		} else {
+			r = blockReader
+		}
+	}
+
 	if b, err := GetExtensionBlockManager().ReadBlock(blockType, r); err != nil {
 		return fmt.Errorf("unmarshalling block type %d failed: %v", blockType, err)
 	} else {
@@ -149,9 +198,9 @@ func (cb *CanonicalBlock) UnmarshalCbor(r io.Reader) error {
 	}
 
 	if blockLen == 6 {
-		if crcCalc, crcErr := calculateCRCBuff(crcBuff, cb.CRCType); crcErr != nil {
+		if crcCalc, crcErr := sumCRCHash(crcHash, cb.CRCType); crcErr != nil {
 			return crcErr
-		} else if crcVal, err := cboring.ReadByteString(r); err != nil {
+		} else if crcVal, err := cboring.ReadByteString(blockReader); err != nil {
 			return err
 		} else if !bytes.Equal(crcCalc, crcVal) {
 			return fmt.Errorf("invalid CRC value: %x instead of expected %x", crcVal, crcCalc)
@@ -207,6 +256,22 @@ func (cb CanonicalBlock) CheckValid() (errs error) {
 			"CanonicalBlock is a PayloadBlock with a block number %d != 1", cb.BlockNumber))
 	}
 
+	// Block number zero identifies the primary block and block number one the payload block.
+	if cb.BlockNumber == 0 {
+		errs = multierror.Append(errs, fmt.Errorf(
+			"CanonicalBlock has the block number 0, which is reserved for the primary block"))
+	} else if cb.Value.BlockTypeCode() != ExtBlockTypePayloadBlock && cb.BlockNumber == 1 {
+		errs = multierror.Append(errs, fmt.Errorf(
+			"CanonicalBlock of type %d has the block number 1, which is reserved for the payload block",
+			cb.Value.BlockTypeCode()))
+	}
+
+	// The payload block cannot be removed, as a bundle without a payload is invalid.
+	if cb.Value.BlockTypeCode() == ExtBlockTypePayloadBlock && cb.BlockControlFlags.Has(RemoveBlock) {
+		errs = multierror.Append(errs, fmt.Errorf(
+			"CanonicalBlock is a PayloadBlock with the remove block flag set"))
+	}
+
 	return
 }
 
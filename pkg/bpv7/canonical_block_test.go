@@ -31,6 +31,11 @@ func TestCanonicalBlockCheckValid(t *testing.T) {
 		{CanonicalBlock{9, 0, CRCNo, nil, NewPayloadBlock(nil)}, false},
 		{CanonicalBlock{1, 0, CRCNo, nil, NewPayloadBlock(nil)}, true},
 
+		// Reserved block numbers
+		{CanonicalBlock{0, 0, CRCNo, nil, NewHopCountBlock(23)}, false},
+		{CanonicalBlock{1, 0, CRCNo, nil, NewHopCountBlock(23)}, false},
+		{CanonicalBlock{2, 0, CRCNo, nil, NewHopCountBlock(23)}, true},
+
 		// Reserved bits in block control flags
 		{CanonicalBlock{1, 0x80, CRCNo, nil, NewPayloadBlock(nil)}, true},
 
@@ -8,10 +8,13 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"hash"
 	"hash/crc32"
 
 	"github.com/dtn7/cboring"
 	"github.com/howeyc/crc16"
+
+	"github.com/dtn7/dtn7-go/pkg/internal/enum"
 )
 
 // CRCType indicates which CRC type is used. Only the three defined consts
@@ -29,19 +32,24 @@ const (
 	CRC32 CRCType = 2
 )
 
+var crcTypeNames = enum.NewNames("CRC type", "no", "16", "32").
+	Alias(uint64(CRCNo), "none", "crcno", "crc-no").
+	Alias(uint64(CRC16), "crc16", "crc-16").
+	Alias(uint64(CRC32), "crc32", "crc-32", "crc32c")
+
 func (c CRCType) String() string {
-	switch c {
-	case CRCNo:
-		return "no"
-	case CRC16:
-		return "16"
-	case CRC32:
-		return "32"
-	default:
-		return "unknown"
-	}
+	return crcTypeNames.String(uint64(c))
+}
+
+// ParseCRCType from a human readable name, e.g., from a configuration. Next to the values returned by String, names
+// like "crc32" or "none" are accepted, case-insensitive.
+func ParseCRCType(name string) (CRCType, error) {
+	c, err := crcTypeNames.Parse(name)
+	return CRCType(c), err
 }
 
+// The reversed CCITT polynomial 0x1021 with an initial and final XOR of 0xFFFF, as applied for tables created by
+// crc16.MakeTable, results in the X-25 CRC-16.
 var (
 	crc16table = crc16.MakeTable(crc16.CCITT)
 	crc32table = crc32.MakeTable(crc32.Castagnoli)
@@ -76,6 +84,47 @@ func calculateCRCBuff(buff *bytes.Buffer, crcType CRCType) ([]byte, error) {
 	return data, nil
 }
 
+// newCRCHash creates a hash.Hash to calculate a block's CRC value incrementally, while the block is being written or
+// read. Thus, a block does not need to be buffered completely for its CRC value. For CRCNo, nil is returned.
+func newCRCHash(crcType CRCType) (hash.Hash, error) {
+	switch crcType {
+	case CRCNo:
+		return nil, nil
+	case CRC16:
+		return crc16.New(crc16table), nil
+	case CRC32:
+		return crc32.New(crc32table), nil
+	default:
+		return nil, fmt.Errorf("unknown CRCType %d", crcType)
+	}
+}
+
+// sumCRCHash calculates a block's CRC value from a hash.Hash, created by newCRCHash, which was fed with the block's
+// data up to its CRC field. Like calculateCRCBuff, the CRC type's empty bytes are appended first.
+func sumCRCHash(h hash.Hash, crcType CRCType) ([]byte, error) {
+	data, typeErr := emptyCRC(crcType)
+	if typeErr != nil {
+		return nil, typeErr
+	} else if h == nil {
+		return data, nil
+	}
+
+	if err := cboring.WriteByteString(data, h); err != nil {
+		return nil, err
+	}
+
+	switch h := h.(type) {
+	case crc16.Hash16:
+		binary.BigEndian.PutUint16(data, h.Sum16())
+	case hash.Hash32:
+		binary.BigEndian.PutUint32(data, h.Sum32())
+	default:
+		return nil, fmt.Errorf("unsupported CRC hash %T", h)
+	}
+
+	return data, nil
+}
+
 // emptyCRC returns the "default" CRC value for the given CRC Type.
 func emptyCRC(crcType CRCType) (arr []byte, err error) {
 	switch crcType {
@@ -0,0 +1,214 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/dtn7/cboring"
+	"github.com/howeyc/crc16"
+)
+
+func TestParseCRCType(t *testing.T) {
+	tests := []struct {
+		name    string
+		crcType CRCType
+		err     bool
+	}{
+		{"no", CRCNo, false},
+		{"none", CRCNo, false},
+		{"16", CRC16, false},
+		{"crc16", CRC16, false},
+		{"CRC-16", CRC16, false},
+		{"32", CRC32, false},
+		{"crc32", CRC32, false},
+		{" CRC32C ", CRC32, false},
+		{"", CRCNo, true},
+		{"crc64", CRCNo, true},
+		{"unknown", CRCNo, true},
+	}
+
+	for _, test := range tests {
+		crcType, err := ParseCRCType(test.name)
+
+		if test.err == (err == nil) {
+			t.Fatalf("Error value for %q was unexpected: %v != %v", test.name, test.err, err)
+		}
+
+		if test.crcType != crcType {
+			t.Fatalf("Value for %q was unexpected: %v != %v", test.name, test.crcType, crcType)
+		}
+	}
+}
+
+func TestCRCTypeString(t *testing.T) {
+	tests := []struct {
+		crcType CRCType
+		name    string
+	}{
+		{CRCNo, "no"},
+		{CRC16, "16"},
+		{CRC32, "32"},
+		{CRCType(23), "unknown"},
+	}
+
+	for _, test := range tests {
+		if name := test.crcType.String(); name != test.name {
+			t.Fatalf("String for %d was unexpected: %q != %q", test.crcType, name, test.name)
+		}
+
+		if test.crcType > CRC32 {
+			continue
+		}
+		if crcType, err := ParseCRCType(test.name); err != nil {
+			t.Fatal(err)
+		} else if crcType != test.crcType {
+			t.Fatalf("String %q does not parse back to %v", test.name, test.crcType)
+		}
+	}
+}
+
+func TestCRCCheckValues(t *testing.T) {
+	// Check values for the ASCII string "123456789" of CRC-16/X-25 and CRC-32C, respectively.
+	data := []byte("123456789")
+
+	if crc := crc16.Checksum(data, crc16table); crc != 0x906E {
+		t.Fatalf("CRC16 check value is %04x, expected 906e", crc)
+	}
+	if crc := crc32.Checksum(data, crc32table); crc != 0xE3069283 {
+		t.Fatalf("CRC32 check value is %08x, expected e3069283", crc)
+	}
+}
+
+func TestCalculateCRCBuff(t *testing.T) {
+	tests := []struct {
+		crcType CRCType
+		length  int
+	}{
+		{CRCNo, 0},
+		{CRC16, 2},
+		{CRC32, 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.crcType.String(), func(t *testing.T) {
+			buff := bytes.NewBufferString("hello world")
+
+			crc, err := calculateCRCBuff(buff, test.crcType)
+			if err != nil {
+				t.Fatal(err)
+			} else if len(crc) != test.length {
+				t.Fatalf("CRC has %d bytes, expected %d", len(crc), test.length)
+			}
+
+			// The CRC is calculated over the data, followed by the CRC field's byte string of zeros.
+			field := new(bytes.Buffer)
+			if err := cboring.WriteByteString(make([]byte, test.length), field); err != nil {
+				t.Fatal(err)
+			}
+			data := append([]byte("hello world"), field.Bytes()...)
+
+			switch test.crcType {
+			case CRC16:
+				if expected := crc16.Checksum(data, crc16table); binary.BigEndian.Uint16(crc) != expected {
+					t.Fatalf("CRC16 is %x, expected %04x", crc, expected)
+				}
+			case CRC32:
+				if expected := crc32.Checksum(data, crc32table); binary.BigEndian.Uint32(crc) != expected {
+					t.Fatalf("CRC32 is %x, expected %08x", crc, expected)
+				}
+			}
+		})
+	}
+
+	if _, err := calculateCRCBuff(new(bytes.Buffer), CRCType(23)); err == nil {
+		t.Fatal("unknown CRC type did not error")
+	}
+}
+
+func TestSumCRCHash(t *testing.T) {
+	for _, crcType := range []CRCType{CRCNo, CRC16, CRC32} {
+		t.Run(crcType.String(), func(t *testing.T) {
+			expected, err := calculateCRCBuff(bytes.NewBufferString("hello world"), crcType)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h, err := newCRCHash(crcType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h != nil {
+				// Feed the data in chunks, as done while streaming a block.
+				_, _ = h.Write([]byte("hello "))
+				_, _ = h.Write([]byte("world"))
+			}
+
+			if crc, err := sumCRCHash(h, crcType); err != nil {
+				t.Fatal(err)
+			} else if !bytes.Equal(crc, expected) {
+				t.Fatalf("CRC is %x, expected %x", crc, expected)
+			}
+		})
+	}
+
+	if _, err := newCRCHash(CRCType(23)); err == nil {
+		t.Fatal("unknown CRC type did not error")
+	}
+}
+
+func TestBundleCRC16(t *testing.T) {
+	bndl, err := Builder().
+		CRC(CRC16).
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("30m").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buff := new(bytes.Buffer)
+	if err := bndl.MarshalCbor(buff); err != nil {
+		t.Fatal(err)
+	}
+	data := buff.Bytes()
+
+	var bndl2 Bundle
+	if err := bndl2.UnmarshalCbor(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	if crcType := bndl2.PrimaryBlock.GetCRCType(); crcType != CRC16 {
+		t.Fatalf("primary block has CRC type %v", crcType)
+	} else if l := len(bndl2.PrimaryBlock.CRC); l != 2 {
+		t.Fatalf("primary block's CRC has %d bytes", l)
+	}
+
+	if pb, err := bndl2.PayloadBlock(); err != nil {
+		t.Fatal(err)
+	} else if pb.CRCType != CRC16 {
+		t.Fatalf("payload block has CRC type %v", pb.CRCType)
+	} else if l := len(pb.CRC); l != 2 {
+		t.Fatalf("payload block's CRC has %d bytes", l)
+	}
+
+	// The payload block's CRC is the last field, followed by the break code.
+	if !bytes.Equal(data[len(data)-4:len(data)-1], append([]byte{0x42}, bndl2.CanonicalBlocks[len(bndl2.CanonicalBlocks)-1].CRC...)) {
+		t.Fatalf("payload block's CRC field is not a two byte string: %x", data[len(data)-4:len(data)-1])
+	}
+
+	// Flipping a payload bit must be detected.
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)-6] ^= 0x01
+	if err := new(Bundle).UnmarshalCbor(bytes.NewReader(corrupted)); err == nil {
+		t.Fatal("corrupted Bundle was unmarshalled without an error")
+	}
+}
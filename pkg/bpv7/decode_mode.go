@@ -0,0 +1,214 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/dtn7/cboring"
+)
+
+// DecodeMode describes how strict CBOR encoded Bundles are decoded, compare ParseBundleMode.
+type DecodeMode uint32
+
+const (
+	// StrictDecoding only accepts Bundles encoded as specified, which is the default.
+	StrictDecoding DecodeMode = iota
+
+	// LenientDecoding also accepts unambiguous variations of the CBOR encoding, as created by some less-conformant
+	// implementations. Both the Bundle's array and the blocks' arrays might be of a definite or an indefinite length.
+	// Indefinite-length strings and maps are accepted as well.
+	//
+	// A block's CRC value is checked against the definite-length representation. Thus, blocks with CRC values
+	// calculated over an indefinite-length representation will be rejected.
+	LenientDecoding
+)
+
+func (mode DecodeMode) String() string {
+	switch mode {
+	case StrictDecoding:
+		return "strict"
+	case LenientDecoding:
+		return "lenient"
+	default:
+		return "unknown"
+	}
+}
+
+// cborHeader is the initial byte of a CBOR data item together with its raw argument.
+type cborHeader struct {
+	raw   []byte
+	major byte
+	adds  byte
+	n     uint64
+}
+
+// indefinite checks if this header starts an indefinite-length item.
+func (h cborHeader) indefinite() bool {
+	return h.adds == 31
+}
+
+// readCborHeader reads a CBOR data item's header while keeping its raw representation.
+func readCborHeader(r io.Reader) (h cborHeader, err error) {
+	var buff [9]byte
+	if _, err = io.ReadFull(r, buff[:1]); err != nil {
+		return
+	}
+
+	h.major = buff[0] & 0xE0
+	h.adds = buff[0] & 0x1F
+
+	switch {
+	case h.adds <= 23:
+		h.n = uint64(h.adds)
+		h.raw = buff[:1]
+
+	case h.adds <= 27:
+		l := 1 << (h.adds - 24)
+		if _, err = io.ReadFull(r, buff[1:1+l]); err != nil {
+			return
+		}
+		for i := 1; i <= l; i++ {
+			h.n = h.n<<8 | uint64(buff[i])
+		}
+		h.raw = buff[:1+l]
+
+	case h.adds == 31:
+		h.raw = buff[:1]
+
+	default:
+		err = fmt.Errorf("reserved additional information 0x%x", h.adds)
+	}
+	return
+}
+
+// normalizeCbor copies the next CBOR data item from the Reader into the Writer. Indefinite-length items will be
+// written with a definite length.
+func normalizeCbor(r io.Reader, w io.Writer) error {
+	h, err := readCborHeader(r)
+	if err != nil {
+		return err
+	}
+
+	if h.indefinite() {
+		if h.major == cboring.SimpleData {
+			return fmt.Errorf("unexpected break code")
+		}
+		return normalizeCborIndefinite(h, r, w)
+	}
+
+	if _, err := w.Write(h.raw); err != nil {
+		return err
+	}
+
+	switch h.major {
+	case cboring.ByteString, cboring.TextString:
+		_, err := io.CopyN(w, r, int64(h.n))
+		return err
+
+	case cboring.Array, cboring.Map, 0xC0:
+		items := h.n
+		if h.major == cboring.Map {
+			items *= 2
+		} else if h.major == 0xC0 {
+			// A tag is followed by exactly one data item.
+			items = 1
+		}
+
+		for i := uint64(0); i < items; i++ {
+			if err := normalizeCbor(r, w); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// normalizeCborIndefinite copies an indefinite-length item, started by the header, as a definite-length one.
+func normalizeCborIndefinite(h cborHeader, r io.Reader, w io.Writer) error {
+	var (
+		buff  = new(bytes.Buffer)
+		items uint64
+	)
+
+	for {
+		next, err := readCborHeader(r)
+		if err != nil {
+			return err
+		} else if next.major == cboring.SimpleData && next.indefinite() {
+			break
+		}
+
+		switch h.major {
+		case cboring.ByteString, cboring.TextString:
+			// Chunks of an indefinite-length string are definite-length strings of the same type.
+			if next.major != h.major || next.indefinite() {
+				return fmt.Errorf("invalid chunk of an indefinite-length string")
+			}
+			if _, err := io.CopyN(buff, r, int64(next.n)); err != nil {
+				return err
+			}
+			items += next.n
+
+		case cboring.Array, cboring.Map:
+			if err := normalizeCbor(io.MultiReader(bytes.NewReader(next.raw), r), buff); err != nil {
+				return err
+			}
+			items++
+
+		default:
+			return fmt.Errorf("major type 0x%x cannot have an indefinite length", h.major)
+		}
+	}
+
+	if h.major == cboring.Map {
+		if items%2 != 0 {
+			return fmt.Errorf("indefinite-length map has an odd number of items")
+		}
+		items /= 2
+	}
+
+	if err := cboring.WriteMajors(h.major, items, w); err != nil {
+		return err
+	}
+	_, err := buff.WriteTo(w)
+	return err
+}
+
+// normalizeBundleCbor reads a possibly less-conformant CBOR encoded Bundle and returns a Reader for its conformant
+// representation: an indefinite-length array of definite-length block arrays.
+func normalizeBundleCbor(r io.Reader) (io.Reader, error) {
+	h, err := readCborHeader(r)
+	if err != nil {
+		return nil, err
+	} else if h.major != cboring.Array {
+		return nil, fmt.Errorf("expected array for a Bundle, got major type 0x%x", h.major)
+	}
+
+	buff := new(bytes.Buffer)
+	buff.WriteByte(cboring.IndefiniteArray)
+
+	for i := uint64(0); h.indefinite() || i < h.n; i++ {
+		next, err := readCborHeader(r)
+		if err != nil {
+			return nil, err
+		} else if next.major == cboring.SimpleData && next.indefinite() {
+			if !h.indefinite() {
+				return nil, fmt.Errorf("unexpected break code")
+			}
+			break
+		}
+
+		if err := normalizeCbor(io.MultiReader(bytes.NewReader(next.raw), r), buff); err != nil {
+			return nil, err
+		}
+	}
+
+	buff.WriteByte(cboring.BreakCode)
+	return buff, nil
+}
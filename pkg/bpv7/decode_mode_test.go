@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dtn7/cboring"
+)
+
+// testLooseBundleCbor re-encodes a conformant Bundle with indefinite-length block arrays. If definite is set, the
+// Bundle's outer array will have a definite length.
+func testLooseBundleCbor(t *testing.T, b Bundle, definite bool) []byte {
+	conform := new(bytes.Buffer)
+	if err := b.WriteBundle(conform); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bytes.NewReader(conform.Bytes())
+	if err := cboring.ReadExpect(cboring.IndefiniteArray, r); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := new(bytes.Buffer)
+	var n uint64
+	for ; ; n++ {
+		h, err := readCborHeader(r)
+		if err != nil {
+			t.Fatal(err)
+		} else if h.major == cboring.SimpleData && h.indefinite() {
+			break
+		} else if h.major != cboring.Array {
+			t.Fatalf("expected block array, got major type 0x%x", h.major)
+		}
+
+		blocks.WriteByte(cboring.IndefiniteArray)
+		for i := uint64(0); i < h.n; i++ {
+			if err := normalizeCbor(r, blocks); err != nil {
+				t.Fatal(err)
+			}
+		}
+		blocks.WriteByte(cboring.BreakCode)
+	}
+
+	loose := new(bytes.Buffer)
+	if definite {
+		if err := cboring.WriteArrayLength(n, loose); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(loose, blocks)
+	} else {
+		loose.WriteByte(cboring.IndefiniteArray)
+		_, _ = io.Copy(loose, blocks)
+		loose.WriteByte(cboring.BreakCode)
+	}
+	return loose.Bytes()
+}
+
+func TestDecodeModeLenient(t *testing.T) {
+	b, err := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampTime(time.Unix(1600000000, 0)).
+		Lifetime("87600h").
+		HopCountBlock(64).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, definite := range []bool{false, true} {
+		data := testLooseBundleCbor(t, b, definite)
+
+		if _, err := ParseBundle(bytes.NewReader(data)); err == nil {
+			t.Fatalf("strict decoding accepted a less-conformant bundle, definite: %t", definite)
+		}
+
+		if bLenient, err := ParseBundleMode(bytes.NewReader(data), LenientDecoding); err != nil {
+			t.Fatalf("lenient decoding failed, definite: %t: %v", definite, err)
+		} else if !reflect.DeepEqual(Snapshot(b), Snapshot(bLenient)) {
+			t.Fatalf("lenient decoded bundle differs, definite: %t:\n%v\n%v", definite, b, bLenient)
+		}
+	}
+
+	// Conformant bundles must still be decodable in lenient mode.
+	buff := new(bytes.Buffer)
+	if err := b.WriteBundle(buff); err != nil {
+		t.Fatal(err)
+	} else if bLenient, err := ParseBundleMode(buff, LenientDecoding); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(Snapshot(b), Snapshot(bLenient)) {
+		t.Fatalf("lenient decoded bundle differs:\n%v\n%v", b, bLenient)
+	}
+}
+
+func TestNormalizeCborStrings(t *testing.T) {
+	// Indefinite-length byte string of two chunks within an indefinite-length array.
+	in := []byte{0x9F, 0x5F, 0x42, 0x01, 0x02, 0x41, 0x03, 0xFF, 0x01, 0xFF}
+	expected := []byte{0x82, 0x43, 0x01, 0x02, 0x03, 0x01}
+
+	out := new(bytes.Buffer)
+	if err := normalizeCbor(bytes.NewReader(in), out); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(out.Bytes(), expected) {
+		t.Fatalf("expected %x, got %x", expected, out.Bytes())
+	}
+}
@@ -0,0 +1,235 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dtn7/cboring"
+)
+
+// EDN returns the CBOR Extended Diagnostic Notation of the Bundle's wire encoding, as specified in RFC 8949 and
+// RFC 8610. This human-readable representation eases comparing Bundles with those of other implementations.
+//
+// If the Bundle cannot be encoded, the diagnostic output up to this point is followed by an EDN comment with the
+// error, e.g., "[_ / error: ... /".
+func EDN(b Bundle) string {
+	var (
+		buff = new(bytes.Buffer)
+		sb   strings.Builder
+	)
+
+	err := b.MarshalCbor(buff)
+	if err == nil {
+		err = writeEdn(buff, &sb)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(&sb, " / error: %v /", err)
+	}
+
+	return sb.String()
+}
+
+// writeEdn writes the next CBOR data item from the Reader as its diagnostic notation.
+func writeEdn(r io.Reader, sb *strings.Builder) error {
+	h, err := readCborHeader(r)
+	if err != nil {
+		return err
+	}
+
+	switch h.major {
+	case cboring.UInt:
+		sb.WriteString(strconv.FormatUint(h.n, 10))
+
+	case 0x20:
+		// Negative integer, -1 - n; might exceed an int64.
+		if h.n < math.MaxUint64 {
+			_, _ = fmt.Fprintf(sb, "-%d", h.n+1)
+		} else {
+			sb.WriteString("-18446744073709551616")
+		}
+
+	case cboring.ByteString, cboring.TextString:
+		if h.indefinite() {
+			return writeEdnIndefiniteString(h, r, sb)
+		}
+		return writeEdnString(h, r, sb)
+
+	case cboring.Array, cboring.Map:
+		return writeEdnContainer(h, r, sb)
+
+	case 0xC0:
+		_, _ = fmt.Fprintf(sb, "%d(", h.n)
+		if err := writeEdn(r, sb); err != nil {
+			return err
+		}
+		sb.WriteString(")")
+
+	case cboring.SimpleData:
+		return writeEdnSimple(h, sb)
+	}
+
+	return nil
+}
+
+// writeEdnString writes a definite-length byte or text string.
+func writeEdnString(h cborHeader, r io.Reader, sb *strings.Builder) error {
+	data := make([]byte, h.n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+
+	if h.major == cboring.ByteString {
+		_, _ = fmt.Fprintf(sb, "h'%s'", hex.EncodeToString(data))
+	} else {
+		sb.WriteString(strconv.Quote(string(data)))
+	}
+	return nil
+}
+
+// writeEdnIndefiniteString writes an indefinite-length string as its chunks, e.g., (_ h'01', h'02').
+func writeEdnIndefiniteString(h cborHeader, r io.Reader, sb *strings.Builder) error {
+	sb.WriteString("(_ ")
+	for i := 0; ; i++ {
+		chunk, err := readCborHeader(r)
+		if err != nil {
+			return err
+		} else if chunk.major == cboring.SimpleData && chunk.indefinite() {
+			break
+		} else if chunk.major != h.major || chunk.indefinite() {
+			return fmt.Errorf("invalid chunk of an indefinite-length string")
+		}
+
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if err := writeEdnString(chunk, r, sb); err != nil {
+			return err
+		}
+	}
+	sb.WriteString(")")
+	return nil
+}
+
+// writeEdnContainer writes an array or a map, e.g., [1, 2] or {1: 2}, prefixed by an underscore if indefinite.
+func writeEdnContainer(h cborHeader, r io.Reader, sb *strings.Builder) error {
+	open, close := "[", "]"
+	if h.major == cboring.Map {
+		open, close = "{", "}"
+	}
+
+	sb.WriteString(open)
+	if h.indefinite() {
+		sb.WriteString("_ ")
+	}
+
+	items := h.n
+	if h.major == cboring.Map {
+		items *= 2
+	}
+
+	for i := uint64(0); h.indefinite() || i < items; i++ {
+		next, err := readCborHeader(r)
+		if err != nil {
+			return err
+		} else if next.major == cboring.SimpleData && next.indefinite() {
+			if !h.indefinite() {
+				return fmt.Errorf("unexpected break code")
+			}
+			break
+		}
+
+		if i > 0 {
+			if h.major == cboring.Map && i%2 == 1 {
+				sb.WriteString(": ")
+			} else {
+				sb.WriteString(", ")
+			}
+		}
+
+		if err := writeEdn(io.MultiReader(bytes.NewReader(next.raw), r), sb); err != nil {
+			return err
+		}
+	}
+
+	sb.WriteString(close)
+	return nil
+}
+
+// writeEdnSimple writes simple values and floating-point numbers.
+func writeEdnSimple(h cborHeader, sb *strings.Builder) error {
+	switch h.adds {
+	case 20:
+		sb.WriteString("false")
+	case 21:
+		sb.WriteString("true")
+	case 22:
+		sb.WriteString("null")
+	case 23:
+		sb.WriteString("undefined")
+	case 24:
+		_, _ = fmt.Fprintf(sb, "simple(%d)", h.n)
+	case 25:
+		sb.WriteString(formatEdnFloat(halfToFloat64(uint16(h.n))))
+	case 26:
+		sb.WriteString(formatEdnFloat(float64(math.Float32frombits(uint32(h.n)))))
+	case 27:
+		sb.WriteString(formatEdnFloat(math.Float64frombits(h.n)))
+	case 31:
+		return fmt.Errorf("unexpected break code")
+	default:
+		_, _ = fmt.Fprintf(sb, "simple(%d)", h.adds)
+	}
+	return nil
+}
+
+// halfToFloat64 converts an IEEE 754 half-precision float.
+func halfToFloat64(half uint16) float64 {
+	exp := int(half>>10) & 0x1F
+	mant := float64(half & 0x3FF)
+
+	var val float64
+	switch exp {
+	case 0:
+		val = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			val = math.Inf(1)
+		} else {
+			val = math.NaN()
+		}
+	default:
+		val = math.Ldexp(mant+1024, exp-25)
+	}
+
+	if half&0x8000 != 0 {
+		return -val
+	}
+	return val
+}
+
+// formatEdnFloat formats a float, always containing a decimal point or an exponent.
+func formatEdnFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
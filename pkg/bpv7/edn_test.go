@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestEDN(t *testing.T) {
+	b := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		creationTimestamp(DtnTime(1000)).
+		Lifetime("876000h").
+		HopCountBlock(16).
+		PayloadBlock([]byte("hello")).
+		mustBuild()
+
+	expected := `[_ [7, 131072, 2, [1, "//dst/"], [1, "//src/"], [1, "//src/"], [1000, 0], 3153600000000, h'14322f57'], ` +
+		`[10, 2, 1, 0, h'821000'], [1, 1, 0, 0, h'68656c6c6f']]`
+
+	if edn := EDN(b); edn != expected {
+		t.Fatalf("EDN mismatches:\n%s\n%s", edn, expected)
+	}
+}
+
+func TestEDNDataItems(t *testing.T) {
+	tests := []struct {
+		cbor string
+		edn  string
+	}{
+		{"00", "0"},
+		{"1903e8", "1000"},
+		{"20", "-1"},
+		{"3bffffffffffffffff", "-18446744073709551616"},
+		{"4401020304", "h'01020304'"},
+		{"6449455446", `"IETF"`},
+		{"5f42010243030405ff", "(_ h'0102', h'030405')"},
+		{"80", "[]"},
+		{"8301820203820405", "[1, [2, 3], [4, 5]]"},
+		{"9f018202039f0405ffff", "[_ 1, [2, 3], [_ 4, 5]]"},
+		{"a26161016162820203", `{"a": 1, "b": [2, 3]}`},
+		{"c074323031332d30332d32315432303a30343a30305a", `0("2013-03-21T20:04:00Z")`},
+		{"f4", "false"},
+		{"f5", "true"},
+		{"f6", "null"},
+		{"f93e00", "1.5"},
+		{"fa47c35000", "100000.0"},
+		{"fb7e37e43c8800759c", "1e+300"},
+		{"f97c00", "Infinity"},
+	}
+
+	for _, test := range tests {
+		data, err := hex.DecodeString(test.cbor)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var sb strings.Builder
+		if err := writeEdn(bytes.NewReader(data), &sb); err != nil {
+			t.Fatalf("%s errored: %v", test.cbor, err)
+		} else if edn := sb.String(); edn != test.edn {
+			t.Fatalf("%s resulted in %s, expected %s", test.cbor, edn, test.edn)
+		}
+	}
+}
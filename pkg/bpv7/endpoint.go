@@ -210,6 +210,21 @@ func (eid EndpointID) SameNode(other EndpointID) bool {
 	}
 }
 
+// Matches checks if a bundle addressed to the other Endpoint should be delivered to this Endpoint, e.g., to an
+// application agent registered for it. Both singleton and non-singleton Endpoints, compare IsSingleton, must be equal.
+// A non-singleton Endpoint, like "dtn://group/~news", is therefore matched by each of its members' registrations.
+// An unset Endpoint is treated as "dtn:none".
+func (eid EndpointID) Matches(other EndpointID) bool {
+	if eid.EndpointType == nil {
+		eid = DtnNone()
+	}
+	if other.EndpointType == nil {
+		other = DtnNone()
+	}
+
+	return eid == other
+}
+
 // CheckValid returns an array of errors for incorrect data.
 func (eid EndpointID) CheckValid() error {
 	if eid.EndpointType == nil {
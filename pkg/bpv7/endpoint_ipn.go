@@ -19,6 +19,8 @@ const (
 )
 
 // IpnEndpoint describes the ipn URI for EndpointIDs, as defined in RFC 6260.
+//
+// As specified in RFC 9171, section 4.2.5.1.2, "ipn:0.0" is the null endpoint, equivalent to "dtn:none".
 type IpnEndpoint struct {
 	Node    uint64
 	Service uint64
@@ -74,14 +76,21 @@ func (e IpnEndpoint) Path() string {
 
 // IsSingleton checks if this Endpoint represents a singleton.
 //
-// All IPN Endpoints are singletons by definition.
-func (_ IpnEndpoint) IsSingleton() bool {
-	return true
+// All IPN Endpoints, except the null endpoint "ipn:0.0", are singletons by definition.
+func (e IpnEndpoint) IsSingleton() bool {
+	return !e.isNull()
+}
+
+// isNull checks if this is the null endpoint "ipn:0.0".
+func (e IpnEndpoint) isNull() bool {
+	return e.Node == 0 && e.Service == 0
 }
 
 // CheckValid returns an array of errors for incorrect data.
 func (e IpnEndpoint) CheckValid() error {
-	if e.Node < 1 || e.Service < 1 {
+	if e.isNull() {
+		return nil
+	} else if e.Node < 1 || e.Service < 1 {
 		return fmt.Errorf("ipn's node and Service number must be >= 1")
 	}
 
@@ -6,6 +6,7 @@ package bpv7
 
 import (
 	"bytes"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -19,6 +20,8 @@ func TestNewIpnEndpoint(t *testing.T) {
 	}{
 		{"ipn:1.1", 1, 1, true},
 		{"ipn:23.42", 23, 42, true},
+		{"ipn:0.0", 0, 0, true},
+		{"ipn:18446744073709551615.42", math.MaxUint64, 42, true},
 		{"ipn:0.1", 0, 0, false},
 		{"ipn:1.0", 0, 0, false},
 		{"ipn:99999999999999999999.1", 0, 0, false},
@@ -49,6 +52,8 @@ func TestIpnEndpointCbor(t *testing.T) {
 	}{
 		{IpnEndpoint{1, 1}, []byte{0x82, 0x01, 0x01}},
 		{IpnEndpoint{23, 42}, []byte{0x82, 0x17, 0x18, 0x2A}},
+		{IpnEndpoint{0, 0}, []byte{0x82, 0x00, 0x00}},
+		{IpnEndpoint{math.MaxUint64, 42}, []byte{0x82, 0x1B, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x18, 0x2A}},
 	}
 
 	for _, test := range tests {
@@ -36,7 +36,7 @@ func TestEndpointCheckValid(t *testing.T) {
 	}{
 		{EndpointID{nil}, false},
 		{EndpointID{&DtnEndpoint{IsDtnNone: true}}, true},
-		{EndpointID{&IpnEndpoint{0, 0}}, false},
+		{EndpointID{&IpnEndpoint{0, 0}}, true},
 		{EndpointID{&IpnEndpoint{0, 1}}, false},
 		{EndpointID{&IpnEndpoint{1, 0}}, false},
 		{EndpointID{&IpnEndpoint{1, 1}}, true},
@@ -59,6 +59,8 @@ func TestEndpointCbor(t *testing.T) {
 		{"dtn://foo/bar", []byte{0x82, 0x01, 0x69, 0x2F, 0x2F, 0x66, 0x6F, 0x6F, 0x2F, 0x62, 0x61, 0x72}},
 		{"ipn:1.1", []byte{0x82, 0x02, 0x82, 0x01, 0x01}},
 		{"ipn:23.42", []byte{0x82, 0x02, 0x82, 0x17, 0x18, 0x2A}},
+		{"ipn:0.0", []byte{0x82, 0x02, 0x82, 0x00, 0x00}},
+		{"ipn:18446744073709551615.1", []byte{0x82, 0x02, 0x82, 0x1B, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}},
 	}
 
 	for _, test := range tests {
@@ -136,6 +138,7 @@ func TestEndpointSingleton(t *testing.T) {
 		{"dtn://foo/~bar/", false},
 		{"ipn:1.1", true},
 		{"ipn:23.42", true},
+		{"ipn:0.0", false},
 	}
 
 	for _, test := range tests {
@@ -267,3 +270,30 @@ func TestEndpointIDSameNode(t *testing.T) {
 		}
 	}
 }
+
+func TestEndpointIDMatches(t *testing.T) {
+	tests := []struct {
+		eid1    EndpointID
+		eid2    EndpointID
+		matches bool
+	}{
+		{MustNewEndpointID("dtn://foo/bar"), MustNewEndpointID("dtn://foo/bar"), true},
+		{MustNewEndpointID("dtn://foo/bar"), MustNewEndpointID("dtn://foo/buz"), false},
+		{MustNewEndpointID("dtn://group/~news"), MustNewEndpointID("dtn://group/~news"), true},
+		{MustNewEndpointID("dtn://group/~news"), MustNewEndpointID("dtn://group/news"), false},
+		{MustNewEndpointID("ipn:23.42"), MustNewEndpointID("ipn:23.42"), true},
+		{MustNewEndpointID("ipn:23.42"), MustNewEndpointID("ipn:23.23"), false},
+		{EndpointID{EndpointType: nil}, DtnNone(), true},
+		{EndpointID{EndpointType: nil}, EndpointID{EndpointType: nil}, true},
+		{EndpointID{EndpointType: nil}, MustNewEndpointID("dtn://foo/"), false},
+	}
+
+	for _, test := range tests {
+		if res := test.eid1.Matches(test.eid2); res != test.matches {
+			t.Fatalf("%v.Matches(%v) := %t", test.eid1, test.eid2, res)
+		}
+		if res := test.eid2.Matches(test.eid1); res != test.matches {
+			t.Fatalf("%v.Matches(%v) := %t", test.eid2, test.eid1, res)
+		}
+	}
+}
@@ -41,6 +41,18 @@ const (
 
 	// ExtBlockTypeSignatureBlock is the custom block type code for a SignatureBlock, bpv7/extension_block_signature.go
 	ExtBlockTypeSignatureBlock uint64 = 195
+
+	// ExtBlockTypePayloadIntegrityBlock is the custom block type code for a PayloadIntegrityBlock, bpv7/extension_block_payload_integrity.go
+	ExtBlockTypePayloadIntegrityBlock uint64 = 196
+
+	// ExtBlockTypeRoutingMetricBlock is the custom block type code for a RoutingMetricBlock, bpv7/extension_block_routing_metric.go
+	ExtBlockTypeRoutingMetricBlock uint64 = 197
+
+	// ExtBlockTypeDeliveryGuaranteeBlock is the custom block type code for a DeliveryGuaranteeBlock, bpv7/extension_block_delivery_guarantee.go
+	ExtBlockTypeDeliveryGuaranteeBlock uint64 = 198
+
+	// ExtBlockTypeSummaryVectorBlock is the custom block type code for a SummaryVectorBlock, bpv7/extension_block_summary_vector.go
+	ExtBlockTypeSummaryVectorBlock uint64 = 199
 )
 
 // ExtensionBlock describes the block-type specific data of any Canonical Block.
@@ -118,6 +130,9 @@ func (ebm *ExtensionBlockManager) IsKnown(typeCode uint64) bool {
 
 // createBlock returns either a specific ExtensionBlock or, if type code is not registered, an GenericExtensionBlock.
 func (ebm *ExtensionBlockManager) createBlock(typeCode uint64) ExtensionBlock {
+	ebm.mutex.Lock()
+	defer ebm.mutex.Unlock()
+
 	if extType, exists := ebm.data[typeCode]; exists {
 		return reflect.New(extType).Interface().(ExtensionBlock)
 	} else {
@@ -176,6 +191,21 @@ func (ebm *ExtensionBlockManager) ReadBlock(typeCode uint64, r io.Reader) (b Ext
 	return
 }
 
+// resolveBlock converts a GenericExtensionBlock of a registered block type code into its specific ExtensionBlock by
+// reading its data. All other ExtensionBlocks are returned unchanged.
+func (ebm *ExtensionBlockManager) resolveBlock(b ExtensionBlock) (ExtensionBlock, error) {
+	geb, isGeneric := b.(*GenericExtensionBlock)
+	if !isGeneric || !ebm.IsKnown(geb.BlockTypeCode()) {
+		return b, nil
+	}
+
+	var buff bytes.Buffer
+	if err := ebm.WriteBlock(geb, &buff); err != nil {
+		return nil, err
+	}
+	return ebm.ReadBlock(geb.BlockTypeCode(), &buff)
+}
+
 var (
 	extensionBlockManager      *ExtensionBlockManager
 	extensionBlockManagerMutex sync.Mutex
@@ -195,7 +225,18 @@ func GetExtensionBlockManager() *ExtensionBlockManager {
 		_ = extensionBlockManager.Register(NewPreviousNodeBlock(DtnNone()))
 		_ = extensionBlockManager.Register(NewBundleAgeBlock(0))
 		_ = extensionBlockManager.Register(NewHopCountBlock(0))
+		_ = extensionBlockManager.Register(NewPayloadIntegrityBlock(nil))
+		_ = extensionBlockManager.Register(NewRoutingMetricBlock(0))
+		_ = extensionBlockManager.Register(NewDeliveryGuaranteeBlock(BestEffort))
+		_ = extensionBlockManager.Register(NewSummaryVectorBlock(nil))
 	}
 
 	return extensionBlockManager
 }
+
+// RegisterExtensionBlockType registers a new ExtensionBlock type through an exemplary instance at the singleton
+// ExtensionBlockManager, compare GetExtensionBlockManager. Afterwards, blocks of this block type code are unmarshalled
+// into this type instead of a GenericExtensionBlock.
+func RegisterExtensionBlockType(eb ExtensionBlock) error {
+	return GetExtensionBlockManager().Register(eb)
+}
@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dtn7/cboring"
+
+	"github.com/dtn7/dtn7-go/pkg/internal/enum"
+)
+
+// DeliveryGuarantee is the delivery guarantee an application requested for its bundle.
+type DeliveryGuarantee uint64
+
+const (
+	// BestEffort bundles are forwarded once, without being stored for later attempts or being acknowledged.
+	BestEffort DeliveryGuarantee = iota
+
+	// AtLeastOnce bundles request a delivery status report as their acknowledgement and are retransmitted by their
+	// source until being acknowledged. The destination might deliver retransmitted bundles multiple times.
+	AtLeastOnce
+
+	// ExactlyOnce bundles are retransmitted like AtLeastOnce bundles, but their destination remembers delivered
+	// bundles until their lifetime expires. Thus, retransmissions are only acknowledged again, not delivered.
+	ExactlyOnce
+)
+
+var deliveryGuaranteeNames = enum.NewNames("delivery guarantee", "best-effort", "at-least-once", "exactly-once").
+	Alias(uint64(BestEffort), "best_effort").
+	Alias(uint64(AtLeastOnce), "at_least_once").
+	Alias(uint64(ExactlyOnce), "exactly_once")
+
+func (dg DeliveryGuarantee) String() string {
+	return deliveryGuaranteeNames.String(uint64(dg))
+}
+
+// ParseDeliveryGuarantee from a human readable name, e.g., "at-least-once". Underscores are accepted as well.
+func ParseDeliveryGuarantee(name string) (DeliveryGuarantee, error) {
+	dg, err := deliveryGuaranteeNames.Parse(name)
+	return DeliveryGuarantee(dg), err
+}
+
+// DeliveryGuaranteeBlock carries the DeliveryGuarantee of a bundle, selected by the application at its injection.
+// Bundles without such a block are handled without any specific guarantee, i.e., stored and forwarded until their
+// lifetime expires.
+//
+// NOTE:
+// This is a custom extension block, and not part of the original bpv7 specification.
+// It is currently assigned the block type code 198,
+// which the specification sets aside for "private and/or experimental use"
+type DeliveryGuaranteeBlock DeliveryGuarantee
+
+// BlockTypeCode must return a constant integer, indicating the block type code.
+func (dgb *DeliveryGuaranteeBlock) BlockTypeCode() uint64 {
+	return ExtBlockTypeDeliveryGuaranteeBlock
+}
+
+// BlockTypeName must return a constant string, this block's name.
+func (dgb *DeliveryGuaranteeBlock) BlockTypeName() string {
+	return "Delivery Guarantee Block"
+}
+
+// NewDeliveryGuaranteeBlock creates a new DeliveryGuaranteeBlock for the given DeliveryGuarantee.
+func NewDeliveryGuaranteeBlock(guarantee DeliveryGuarantee) *DeliveryGuaranteeBlock {
+	dgb := DeliveryGuaranteeBlock(guarantee)
+	return &dgb
+}
+
+// Guarantee returns the DeliveryGuarantee.
+func (dgb *DeliveryGuaranteeBlock) Guarantee() DeliveryGuarantee {
+	return DeliveryGuarantee(*dgb)
+}
+
+// MarshalCbor writes a CBOR representation for a Delivery Guarantee Block.
+func (dgb *DeliveryGuaranteeBlock) MarshalCbor(w io.Writer) error {
+	return cboring.WriteUInt(uint64(*dgb), w)
+}
+
+// UnmarshalCbor reads the CBOR representation for a Delivery Guarantee Block.
+func (dgb *DeliveryGuaranteeBlock) UnmarshalCbor(r io.Reader) error {
+	if guarantee, err := cboring.ReadUInt(r); err != nil {
+		return err
+	} else {
+		*dgb = DeliveryGuaranteeBlock(guarantee)
+		return nil
+	}
+}
+
+// MarshalJSON writes a JSON representation for a Delivery Guarantee Block.
+func (dgb *DeliveryGuaranteeBlock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dgb.Guarantee().String())
+}
+
+// CheckValid returns an array of errors for incorrect data.
+func (dgb *DeliveryGuaranteeBlock) CheckValid() error {
+	if guarantee := dgb.Guarantee(); guarantee > ExactlyOnce {
+		return fmt.Errorf("unknown delivery guarantee %d", guarantee)
+	}
+	return nil
+}
+
+// DeliveryGuarantee returns the guarantee of this Bundle's DeliveryGuaranteeBlock. If no such block exists, false is
+// returned.
+func (b *Bundle) DeliveryGuarantee() (guarantee DeliveryGuarantee, ok bool) {
+	if dgBlock, err := b.ExtensionBlock(ExtBlockTypeDeliveryGuaranteeBlock); err != nil {
+		return BestEffort, false
+	} else {
+		return dgBlock.Value.(*DeliveryGuaranteeBlock).Guarantee(), true
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseDeliveryGuarantee(t *testing.T) {
+	for _, guarantee := range []DeliveryGuarantee{BestEffort, AtLeastOnce, ExactlyOnce} {
+		if parsed, err := ParseDeliveryGuarantee(guarantee.String()); err != nil {
+			t.Fatal(err)
+		} else if parsed != guarantee {
+			t.Fatalf("%q was parsed as %v", guarantee.String(), parsed)
+		}
+	}
+
+	if parsed, err := ParseDeliveryGuarantee(" Exactly_Once "); err != nil || parsed != ExactlyOnce {
+		t.Fatalf("alternative spelling was parsed as %v, %v", parsed, err)
+	}
+	if _, err := ParseDeliveryGuarantee("at-most-once"); err == nil {
+		t.Fatal("unknown delivery guarantee did not error")
+	}
+}
+
+func TestDeliveryGuaranteeBlockBundle(t *testing.T) {
+	b, err := BuildFromMap(map[string]interface{}{
+		"destination":              "dtn://dst/",
+		"source":                   "dtn://src/",
+		"creation_timestamp_now":   true,
+		"lifetime":                 "10m",
+		"delivery_guarantee_block": "at-least-once",
+		"payload_block":            "hello world",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dgBlock, err := b.ExtensionBlock(ExtBlockTypeDeliveryGuaranteeBlock)
+	if err != nil {
+		t.Fatal(err)
+	} else if dgBlock.BlockControlFlags&ReplicateBlock == 0 {
+		t.Fatalf("DeliveryGuaranteeBlock misses the ReplicateBlock flag: %v", dgBlock.BlockControlFlags)
+	}
+
+	buff := new(bytes.Buffer)
+	if err := b.MarshalCbor(buff); err != nil {
+		t.Fatal(err)
+	}
+
+	var b2 Bundle
+	if err := b2.UnmarshalCbor(buff); err != nil {
+		t.Fatal(err)
+	} else if guarantee, ok := b2.DeliveryGuarantee(); !ok {
+		t.Fatal("unmarshalled bundle has no DeliveryGuaranteeBlock")
+	} else if guarantee != AtLeastOnce {
+		t.Fatalf("unmarshalled guarantee is %v, expected %v", guarantee, AtLeastOnce)
+	}
+
+	if err := NewDeliveryGuaranteeBlock(DeliveryGuarantee(23)).CheckValid(); err == nil {
+		t.Fatal("unknown delivery guarantee is valid")
+	}
+
+	b.RemoveExtensionBlockByBlockNumber(dgBlock.BlockNumber)
+	if _, ok := b.DeliveryGuarantee(); ok {
+		t.Fatal("bundle without a DeliveryGuaranteeBlock returned a guarantee")
+	}
+}
@@ -36,6 +36,21 @@ func NewHopCountBlock(limit uint8) *HopCountBlock {
 	}
 }
 
+// GetLimit returns the hop limit.
+func (hcb HopCountBlock) GetLimit() uint8 {
+	return hcb.Limit
+}
+
+// GetCount returns the current hop count.
+func (hcb HopCountBlock) GetCount() uint8 {
+	return hcb.Count
+}
+
+// Reset the hop counter to zero, keeping the hop limit.
+func (hcb *HopCountBlock) Reset() {
+	hcb.Count = 0
+}
+
 // IsExceeded returns true if the hop limit exceeded.
 func (hcb HopCountBlock) IsExceeded() bool {
 	return hcb.Count > hcb.Limit
@@ -53,6 +68,11 @@ func (hcb *HopCountBlock) Decrement() {
 	hcb.Count--
 }
 
+// String representation of the hop count, "count/limit".
+func (hcb HopCountBlock) String() string {
+	return fmt.Sprintf("%d/%d", hcb.Count, hcb.Limit)
+}
+
 // MarshalCbor writes a CBOR representation of this Hop Count Block.
 func (hcb *HopCountBlock) MarshalCbor(w io.Writer) error {
 	if err := cboring.WriteArrayLength(2, w); err != nil {
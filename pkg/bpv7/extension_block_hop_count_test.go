@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHopCountBlockLimit(t *testing.T) {
+	hcb := NewHopCountBlock(2)
+	if hcb.GetLimit() != 2 || hcb.GetCount() != 0 {
+		t.Fatalf("new block is %v, expected 0/2", hcb)
+	}
+
+	for i := uint8(1); i <= 2; i++ {
+		if exceeded := hcb.Increment(); exceeded {
+			t.Fatalf("hop count %v is exceeded", hcb)
+		} else if hcb.GetCount() != i {
+			t.Fatalf("hop count is %d, expected %d", hcb.GetCount(), i)
+		} else if err := hcb.CheckValid(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The count equals the limit, one more hop exceeds the block.
+	if exceeded := hcb.Increment(); !exceeded {
+		t.Fatalf("hop count %v is not exceeded", hcb)
+	} else if err := hcb.CheckValid(); err == nil {
+		t.Fatalf("exceeded hop count %v is valid", hcb)
+	}
+
+	hcb.Reset()
+	if hcb.IsExceeded() || hcb.GetCount() != 0 || hcb.GetLimit() != 2 {
+		t.Fatalf("reset hop count is %v, expected 0/2", hcb)
+	}
+}
+
+func TestHopCountBlockBuilder(t *testing.T) {
+	tests := []struct {
+		limit interface{}
+		valid bool
+	}{
+		{0, false},
+		{1, true},
+		{64, true},
+		{255, true},
+		{256, false},
+		{-1, false},
+		{"64", false},
+	}
+
+	for _, test := range tests {
+		_, err := Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("10m").
+			HopCountBlock(test.limit).
+			PayloadBlock([]byte("hello world")).
+			Build()
+
+		if (err == nil) != test.valid {
+			t.Fatalf("limit %v: expected valid = %t, got %v", test.limit, test.valid, err)
+		}
+	}
+}
+
+func TestHopCountBlockBundleString(t *testing.T) {
+	b := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		HopCountBlock(64).
+		PayloadBlock([]byte("hello world")).
+		mustBuild()
+
+	hcBlock, err := b.ExtensionBlock(ExtBlockTypeHopCountBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hcBlock.Value.(*HopCountBlock).Increment()
+
+	if s := b.String(); !strings.HasPrefix(s, b.ID().String()) || !strings.HasSuffix(s, "(hop count 1/64)") {
+		t.Fatalf("bundle's string %q lacks its ID or hop count", s)
+	}
+}
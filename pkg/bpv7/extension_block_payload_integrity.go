@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dtn7/cboring"
+)
+
+// PayloadIntegrityBlock contains a SHA-256 hash of a bundle's complete payload. Unlike the CRC of a fragment's
+// Payload Block, this hash covers the unfragmented payload and allows an end-to-end verification after reassembly.
+//
+// NOTE:
+// This is a custom extension block, and not part of the original bpv7 specification.
+// It is currently assigned the block type code 196,
+// which the specification sets aside for "private and/or experimental use"
+type PayloadIntegrityBlock []byte
+
+// NewPayloadIntegrityBlock creates a new PayloadIntegrityBlock for the given payload.
+func NewPayloadIntegrityBlock(payload []byte) *PayloadIntegrityBlock {
+	hash := sha256.Sum256(payload)
+	pib := PayloadIntegrityBlock(hash[:])
+	return &pib
+}
+
+// BlockTypeCode must return a constant integer, indicating the block type code.
+func (pib *PayloadIntegrityBlock) BlockTypeCode() uint64 {
+	return ExtBlockTypePayloadIntegrityBlock
+}
+
+// BlockTypeName must return a constant string, this block's name.
+func (pib *PayloadIntegrityBlock) BlockTypeName() string {
+	return "Payload Integrity Block"
+}
+
+// Verify the given payload against this block's hash.
+func (pib *PayloadIntegrityBlock) Verify(payload []byte) error {
+	if hash := sha256.Sum256(payload); !bytes.Equal(hash[:], *pib) {
+		return fmt.Errorf("payload's SHA-256 %x does not match %x", hash, []byte(*pib))
+	}
+	return nil
+}
+
+// MarshalCbor writes the CBOR representation of a PayloadIntegrityBlock.
+func (pib *PayloadIntegrityBlock) MarshalCbor(w io.Writer) error {
+	return cboring.WriteByteString(*pib, w)
+}
+
+// UnmarshalCbor reads a CBOR representation of a PayloadIntegrityBlock.
+func (pib *PayloadIntegrityBlock) UnmarshalCbor(r io.Reader) error {
+	if data, err := cboring.ReadByteString(r); err != nil {
+		return err
+	} else {
+		*pib = data
+		return nil
+	}
+}
+
+// MarshalJSON writes the JSON representation of a PayloadIntegrityBlock.
+func (pib *PayloadIntegrityBlock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(*pib))
+}
+
+// CheckValid returns an array of errors for incorrect data.
+func (pib *PayloadIntegrityBlock) CheckValid() error {
+	if l := len(*pib); l != sha256.Size {
+		return fmt.Errorf("PayloadIntegrityBlock's length is %d, not %d", l, sha256.Size)
+	}
+	return nil
+}
+
+// AddPayloadIntegrityBlock attaches a PayloadIntegrityBlock for the current payload to this Bundle. This block will
+// only be part of the first fragment and is checked on reassembly.
+func (b *Bundle) AddPayloadIntegrityBlock() error {
+	payloadBlock, err := b.PayloadBlock()
+	if err != nil {
+		return err
+	}
+
+	b.AddExtensionBlock(NewCanonicalBlock(0, 0, NewPayloadIntegrityBlock(payloadBlock.Value.(*PayloadBlock).Data())))
+	return nil
+}
+
+// VerifyPayloadIntegrity checks the payload against an existing PayloadIntegrityBlock. If no such block exists,
+// there is nothing to verify and nil is returned.
+func (b Bundle) VerifyPayloadIntegrity() error {
+	integrityBlock, err := b.ExtensionBlock(ExtBlockTypePayloadIntegrityBlock)
+	if err != nil {
+		return nil
+	}
+
+	payloadBlock, err := b.PayloadBlock()
+	if err != nil {
+		return err
+	}
+
+	return integrityBlock.Value.(*PayloadIntegrityBlock).Verify(payloadBlock.Value.(*PayloadBlock).Data())
+}
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/dtn7/cboring"
+)
+
+// RoutingMetricBlock carries the accumulated cost of a bundle's path, e.g., its distance or ETX. Each node adds its
+// link cost when forwarding a bundle, allowing routing algorithms to prefer paths of a lower cost.
+//
+// NOTE:
+// This is a custom extension block, and not part of the original bpv7 specification.
+// It is currently assigned the block type code 197,
+// which the specification sets aside for "private and/or experimental use"
+type RoutingMetricBlock uint64
+
+// BlockTypeCode must return a constant integer, indicating the block type code.
+func (rmb *RoutingMetricBlock) BlockTypeCode() uint64 {
+	return ExtBlockTypeRoutingMetricBlock
+}
+
+// BlockTypeName must return a constant string, this block's name.
+func (rmb *RoutingMetricBlock) BlockTypeName() string {
+	return "Routing Metric Block"
+}
+
+// NewRoutingMetricBlock creates a new RoutingMetricBlock for the given metric.
+func NewRoutingMetricBlock(metric uint64) *RoutingMetricBlock {
+	rmb := RoutingMetricBlock(metric)
+	return &rmb
+}
+
+// Metric returns the accumulated metric.
+func (rmb *RoutingMetricBlock) Metric() uint64 {
+	return uint64(*rmb)
+}
+
+// Add a cost to the metric and return the new metric. The metric saturates instead of overflowing.
+func (rmb *RoutingMetricBlock) Add(cost uint64) uint64 {
+	if metric := uint64(*rmb); metric > math.MaxUint64-cost {
+		*rmb = RoutingMetricBlock(math.MaxUint64)
+	} else {
+		*rmb = RoutingMetricBlock(metric + cost)
+	}
+	return uint64(*rmb)
+}
+
+// MarshalCbor writes a CBOR representation for a Routing Metric Block.
+func (rmb *RoutingMetricBlock) MarshalCbor(w io.Writer) error {
+	return cboring.WriteUInt(uint64(*rmb), w)
+}
+
+// UnmarshalCbor reads the CBOR representation for a Routing Metric Block.
+func (rmb *RoutingMetricBlock) UnmarshalCbor(r io.Reader) error {
+	if metric, err := cboring.ReadUInt(r); err != nil {
+		return err
+	} else {
+		*rmb = RoutingMetricBlock(metric)
+		return nil
+	}
+}
+
+// MarshalJSON writes a JSON representation for a Routing Metric Block.
+func (rmb *RoutingMetricBlock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rmb.Metric())
+}
+
+// CheckValid returns an array of errors for incorrect data.
+func (rmb *RoutingMetricBlock) CheckValid() error {
+	return nil
+}
+
+// RoutingMetric returns the metric of this Bundle's RoutingMetricBlock. If no such block exists, false is returned.
+func (b *Bundle) RoutingMetric() (metric uint64, ok bool) {
+	if rmBlock, err := b.ExtensionBlock(ExtBlockTypeRoutingMetricBlock); err != nil {
+		return 0, false
+	} else {
+		return rmBlock.Value.(*RoutingMetricBlock).Metric(), true
+	}
+}
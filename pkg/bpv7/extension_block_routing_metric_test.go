@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestRoutingMetricBlockAdd(t *testing.T) {
+	rmb := NewRoutingMetricBlock(23)
+	if metric := rmb.Add(19); metric != 42 || rmb.Metric() != 42 {
+		t.Fatalf("metric is %d, expected 42", rmb.Metric())
+	}
+
+	rmb = NewRoutingMetricBlock(math.MaxUint64 - 1)
+	if metric := rmb.Add(23); metric != math.MaxUint64 {
+		t.Fatalf("metric overflowed to %d", metric)
+	}
+}
+
+func TestRoutingMetricBlockBundle(t *testing.T) {
+	b, err := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		RoutingMetricBlock(23).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rmBlock, err := b.ExtensionBlock(ExtBlockTypeRoutingMetricBlock)
+	if err != nil {
+		t.Fatal(err)
+	} else if rmBlock.BlockControlFlags&ReplicateBlock == 0 {
+		t.Fatalf("RoutingMetricBlock misses the ReplicateBlock flag: %v", rmBlock.BlockControlFlags)
+	}
+	rmBlock.Value.(*RoutingMetricBlock).Add(19)
+
+	buff := new(bytes.Buffer)
+	if err := b.MarshalCbor(buff); err != nil {
+		t.Fatal(err)
+	}
+
+	var b2 Bundle
+	if err := b2.UnmarshalCbor(buff); err != nil {
+		t.Fatal(err)
+	} else if metric, ok := b2.RoutingMetric(); !ok {
+		t.Fatal("unmarshalled bundle has no RoutingMetricBlock")
+	} else if metric != 42 {
+		t.Fatalf("unmarshalled metric is %d, expected 42", metric)
+	}
+
+	b.RemoveExtensionBlockByBlockNumber(rmBlock.BlockNumber)
+	if _, ok := b.RoutingMetric(); ok {
+		t.Fatal("bundle without a RoutingMetricBlock returned a metric")
+	}
+}
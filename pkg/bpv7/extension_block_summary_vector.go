@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dtn7/cboring"
+)
+
+// SummaryVectorBlock lists the BundleIDs of all bundles stored at its sending node. It is exchanged between
+// neighbors by the "epidemic" routing to only offer bundles unknown to a peer.
+//
+// Each BundleID is serialized as an array of two or, for fragments, four fields.
+//
+// NOTE:
+// This is a custom extension block, and not part of the original bpv7 specification.
+// It is currently assigned the block type code 199,
+// which the specification sets aside for "private and/or experimental use"
+type SummaryVectorBlock []BundleID
+
+// BlockTypeCode must return a constant integer, indicating the block type code.
+func (svb *SummaryVectorBlock) BlockTypeCode() uint64 {
+	return ExtBlockTypeSummaryVectorBlock
+}
+
+// BlockTypeName must return a constant string, this block's name.
+func (svb *SummaryVectorBlock) BlockTypeName() string {
+	return "Summary Vector Block"
+}
+
+// NewSummaryVectorBlock creates a new SummaryVectorBlock for the given BundleIDs.
+func NewSummaryVectorBlock(bids []BundleID) *SummaryVectorBlock {
+	svb := SummaryVectorBlock(bids)
+	return &svb
+}
+
+// BundleIDs returns the listed BundleIDs.
+func (svb *SummaryVectorBlock) BundleIDs() []BundleID {
+	return *svb
+}
+
+// MarshalCbor writes a CBOR representation for a Summary Vector Block.
+func (svb *SummaryVectorBlock) MarshalCbor(w io.Writer) error {
+	if err := cboring.WriteArrayLength(uint64(len(*svb)), w); err != nil {
+		return err
+	}
+
+	for i := range *svb {
+		bid := &(*svb)[i]
+		if err := cboring.WriteArrayLength(bid.Len(), w); err != nil {
+			return err
+		}
+		if err := cboring.Marshal(bid, w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalCbor reads the CBOR representation for a Summary Vector Block.
+func (svb *SummaryVectorBlock) UnmarshalCbor(r io.Reader) error {
+	n, err := cboring.ReadArrayLength(r)
+	if err != nil {
+		return err
+	}
+
+	bids := make([]BundleID, n)
+	for i := range bids {
+		if fields, err := cboring.ReadArrayLength(r); err != nil {
+			return err
+		} else if fields != 2 && fields != 4 {
+			return fmt.Errorf("BundleID has %d fields, expected 2 or 4", fields)
+		} else {
+			bids[i].IsFragment = fields == 4
+		}
+
+		if err := cboring.Unmarshal(&bids[i], r); err != nil {
+			return err
+		}
+	}
+
+	*svb = bids
+	return nil
+}
+
+// MarshalJSON writes a JSON representation for a Summary Vector Block.
+func (svb *SummaryVectorBlock) MarshalJSON() ([]byte, error) {
+	bids := make([]string, len(*svb))
+	for i, bid := range *svb {
+		bids[i] = bid.String()
+	}
+	return json.Marshal(bids)
+}
+
+// CheckValid returns an array of errors for incorrect data.
+func (svb *SummaryVectorBlock) CheckValid() error {
+	return nil
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSummaryVectorBlockCbor(t *testing.T) {
+	tests := []SummaryVectorBlock{
+		{},
+		{
+			{SourceNode: MustNewEndpointID("dtn://foo/"), Timestamp: NewCreationTimestamp(DtnTimeEpoch, 23)},
+		},
+		{
+			{SourceNode: MustNewEndpointID("dtn://foo/"), Timestamp: NewCreationTimestamp(DtnTimeEpoch, 23)},
+			{
+				SourceNode:      MustNewEndpointID("ipn:23.42"),
+				Timestamp:       NewCreationTimestamp(DtnTimeNow(), 0),
+				IsFragment:      true,
+				FragmentOffset:  100,
+				TotalDataLength: 1000,
+			},
+		},
+	}
+
+	for _, svb := range tests {
+		svb := svb
+
+		buff := new(bytes.Buffer)
+		if err := GetExtensionBlockManager().WriteBlock(&svb, buff); err != nil {
+			t.Fatal(err)
+		}
+
+		svb2, err := GetExtensionBlockManager().ReadBlock(ExtBlockTypeSummaryVectorBlock, buff)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if bids := svb2.(*SummaryVectorBlock).BundleIDs(); !reflect.DeepEqual(bids, svb.BundleIDs()) {
+			t.Fatalf("unmarshalled BundleIDs differ: %v, %v", bids, svb.BundleIDs())
+		}
+	}
+}
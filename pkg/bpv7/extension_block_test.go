@@ -6,8 +6,11 @@ package bpv7
 
 import (
 	"bytes"
+	"io"
 	"reflect"
 	"testing"
+
+	"github.com/dtn7/cboring"
 )
 
 func TestExtensionBlockManager(t *testing.T) {
@@ -52,6 +55,7 @@ func TestExtensionBlockManagerRWBlock(t *testing.T) {
 		// CBOR; wrapped within a CBOR byte string
 		{NewBundleAgeBlock(23), []byte{0x41, 0x17}, ExtBlockTypeBundleAgeBlock},
 		{NewHopCountBlock(16), []byte{0x43, 0x82, 0x10, 0x00}, ExtBlockTypeHopCountBlock},
+		{NewRoutingMetricBlock(42), []byte{0x42, 0x18, 0x2A}, ExtBlockTypeRoutingMetricBlock},
 		{NewPreviousNodeBlock(MustNewEndpointID("dtn://23/")), []byte{0x48, 0x82, 0x01, 0x65, 0x2F, 0x2F, 0x32, 0x33, 0x2F}, ExtBlockTypePreviousNodeBlock},
 
 		// Binary; also wrapped, of course
@@ -86,3 +90,83 @@ func TestExtensionBlockManagerGenericRegister(t *testing.T) {
 		t.Fatalf("Registering a GenericExtensionBlock did not errored")
 	}
 }
+
+// customBlock is a third-party ExtensionBlock, only used for testing.
+type customBlock uint64
+
+func (cb *customBlock) BlockTypeCode() uint64 {
+	return 250
+}
+
+func (cb *customBlock) BlockTypeName() string {
+	return "Custom Block"
+}
+
+func (cb *customBlock) CheckValid() error {
+	return nil
+}
+
+func (cb *customBlock) MarshalCbor(w io.Writer) error {
+	return cboring.WriteUInt(uint64(*cb), w)
+}
+
+func (cb *customBlock) UnmarshalCbor(r io.Reader) error {
+	n, err := cboring.ReadUInt(r)
+	*cb = customBlock(n)
+	return err
+}
+
+func TestRegisterExtensionBlockType(t *testing.T) {
+	// 0x18 0x2A is the CBOR encoded 42.
+	build := func() Bundle {
+		return Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampEpoch().
+			Lifetime("10m").
+			Canonical(NewGenericExtensionBlock([]byte{0x18, 0x2A}, 250)).
+			PayloadBlock([]byte("hello world")).
+			mustBuild()
+	}
+	roundTrip := func(b Bundle) Bundle {
+		var buff bytes.Buffer
+		if err := b.MarshalCbor(&buff); err != nil {
+			t.Fatal(err)
+		}
+		b2, err := ParseBundle(&buff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b2
+	}
+
+	b := roundTrip(build())
+	if cb, err := b.ExtensionBlock(250); err != nil {
+		t.Fatal(err)
+	} else if _, ok := cb.Value.(*GenericExtensionBlock); !ok {
+		t.Fatalf("unregistered block is a %T", cb.Value)
+	}
+
+	if err := RegisterExtensionBlockType(new(customBlock)); err != nil {
+		t.Fatal(err)
+	}
+	defer GetExtensionBlockManager().Unregister(new(customBlock))
+
+	if err := RegisterExtensionBlockType(new(customBlock)); err == nil {
+		t.Fatal("registering the custom block twice did not error")
+	}
+
+	b = build()
+	if cb, err := b.ExtensionBlock(250); err != nil {
+		t.Fatal(err)
+	} else if v, ok := cb.Value.(*customBlock); !ok || *v != 42 {
+		t.Fatalf("built block is %T %v", cb.Value, cb.Value)
+	}
+
+	b = roundTrip(b)
+	if cb, err := b.ExtensionBlock(250); err != nil {
+		t.Fatal(err)
+	} else if v, ok := cb.Value.(*customBlock); !ok || *v != 42 {
+		t.Fatalf("parsed block is %T %v", cb.Value, cb.Value)
+	}
+}
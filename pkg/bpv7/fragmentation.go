@@ -15,6 +15,17 @@ import (
 
 // Fragment a Bundle into multiple Bundles, with each serialized Bundle limited to mtu bytes.
 func (b Bundle) Fragment(mtu int) (bs []Bundle, err error) {
+	return b.FragmentMinPayload(mtu, 1)
+}
+
+// FragmentMinPayload fragments a Bundle like Fragment, but requires each fragment to carry at least minPayload bytes
+// of the payload, except for the last one. If the MTU cannot accommodate this much payload next to the blocks'
+// overhead, an error is returned instead of splitting the Bundle into lots of useless tiny fragments.
+func (b Bundle) FragmentMinPayload(mtu, minPayload int) (bs []Bundle, err error) {
+	if minPayload < 1 {
+		minPayload = 1
+	}
+
 	if b.PrimaryBlock.BundleControlFlags.Has(MustNotFragmented) {
 		err = fmt.Errorf("bundle control flags forbids bundle fragmentation")
 		return
@@ -58,6 +69,9 @@ func (b Bundle) Fragment(mtu int) (bs []Bundle, err error) {
 		if overhead >= mtu {
 			err = fmt.Errorf("bundle overhead of fragment %d exceeds MTU", i)
 			return
+		} else if room, remaining := mtu-overhead, payloadBlockLen-i; room < minPayload && room < remaining {
+			err = fmt.Errorf("fragment %d has only room for %d payload bytes, less than the minimum of %d", i, room, minPayload)
+			return
 		}
 
 		fragBundle := MustNewBundle(fragPrimaryBlock, nil)
@@ -181,8 +195,9 @@ func prepareReassembly(bs []Bundle) error {
 			return fmt.Errorf("next fragment starts at offset %d, gap from %d to %d", fragOff, lastIndex, fragOff)
 		} else if payloadBlock, err := b.PayloadBlock(); err != nil {
 			return err
-		} else {
-			lastIndex = fragOff + uint64(len(payloadBlock.Value.(*PayloadBlock).Data()))
+		} else if end := fragOff + uint64(len(payloadBlock.Value.(*PayloadBlock).Data())); end > lastIndex {
+			// Overlapping fragments, e.g., from different fragmentations, might be fully covered by previous ones.
+			lastIndex = end
 		}
 	}
 
@@ -216,8 +231,11 @@ func mergeFragmentPayload(bs []Bundle) (data []byte, err error) {
 		}
 		fragPayloadData = fragPayloadBlock.Value.(*PayloadBlock).Data()
 
-		data = append(data, fragPayloadData[lastIndex-fragStartIndex:]...)
-		lastIndex = fragStartIndex + len(fragPayloadData)
+		// Skip fragments whose payload was already covered by the previous ones.
+		if fragEndIndex := fragStartIndex + len(fragPayloadData); fragEndIndex > lastIndex {
+			data = append(data, fragPayloadData[lastIndex-fragStartIndex:]...)
+			lastIndex = fragEndIndex
+		}
 	}
 
 	return
@@ -259,6 +277,11 @@ func ReassembleFragments(bs []Bundle) (b Bundle, err error) {
 		b.AddExtensionBlock(cb)
 	}
 
+	if err = b.VerifyPayloadIntegrity(); err != nil {
+		err = fmt.Errorf("reassembled bundle failed verification: %v", err)
+		return
+	}
+
 	err = b.CheckValid()
 	return
 }
@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bpv7
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dtn7/cboring"
+)
+
+// ReactiveFragment creates a fragment from the prefix of a serialized Bundle, e.g., after its transmission was
+// interrupted. This is the receiving side of reactive fragmentation, as described in RFC 9171, section 5.8.
+//
+// The prefix must contain all blocks in front of the Payload Block and at least one byte of its payload. As the
+// Payload Block is serialized last, its data is the only thing which might be incomplete. A CRC of the Payload Block
+// cannot be checked for a partial payload. If the prefix contains the whole Bundle, this Bundle is returned.
+func ReactiveFragment(data []byte) (frag Bundle, err error) {
+	r := bytes.NewReader(data)
+
+	if err = cboring.ReadExpect(cboring.IndefiniteArray, r); err != nil {
+		return
+	}
+
+	var primary PrimaryBlock
+	if err = cboring.Unmarshal(&primary, r); err != nil {
+		err = fmt.Errorf("PrimaryBlock failed: %w", err)
+		return
+	}
+
+	var canonicals []CanonicalBlock
+	for {
+		blockStart := len(data) - r.Len()
+
+		cb := CanonicalBlock{}
+		if cbErr := cboring.Unmarshal(&cb, r); cbErr == cboring.FlagBreakCode {
+			return NewBundle(primary, canonicals)
+		} else if cbErr == nil {
+			canonicals = append(canonicals, cb)
+			continue
+		} else if frag, err = reactivePayloadFragment(primary, canonicals, data[blockStart:]); err != nil {
+			err = fmt.Errorf("CanonicalBlock failed: %v; no partial Payload Block: %w", cbErr, err)
+		}
+		return
+	}
+}
+
+// reactivePayloadFragment creates a fragment from a truncated Payload Block, the last serialized block.
+func reactivePayloadFragment(primary PrimaryBlock, canonicals []CanonicalBlock, data []byte) (frag Bundle, err error) {
+	if primary.BundleControlFlags.Has(MustNotFragmented) {
+		err = fmt.Errorf("bundle control flags forbids bundle fragmentation")
+		return
+	}
+
+	r := bytes.NewReader(data)
+
+	var blockLen, blockType, blockNumber, blockFlags, crcType uint64
+	if blockLen, err = cboring.ReadArrayLength(r); err != nil {
+		return
+	} else if blockType, err = cboring.ReadUInt(r); err != nil {
+		return
+	} else if blockType != ExtBlockTypePayloadBlock {
+		err = fmt.Errorf("truncated block has type %d", blockType)
+		return
+	} else if blockNumber, err = cboring.ReadUInt(r); err != nil {
+		return
+	} else if blockFlags, err = cboring.ReadUInt(r); err != nil {
+		return
+	} else if crcType, err = cboring.ReadUInt(r); err != nil {
+		return
+	}
+
+	payloadLen, err := cboring.ReadByteStringLen(r)
+	if err != nil {
+		return
+	}
+
+	// A block which was not truncated failed for another reason, e.g., an invalid CRC.
+	blockRemainder := payloadLen
+	if blockLen == 6 {
+		crc, crcErr := emptyCRC(CRCType(crcType))
+		if crcErr != nil {
+			err = crcErr
+			return
+		}
+		blockRemainder += 1 + uint64(len(crc))
+	}
+	if uint64(r.Len()) >= blockRemainder {
+		err = fmt.Errorf("payload block is not truncated")
+		return
+	}
+
+	payload := data[len(data)-r.Len():]
+	if uint64(len(payload)) > payloadLen {
+		payload = payload[:payloadLen]
+	}
+	if len(payload) == 0 {
+		err = fmt.Errorf("no payload was received")
+		return
+	}
+
+	fragOffset, totalLen := uint64(0), payloadLen
+	if primary.BundleControlFlags.Has(IsFragment) {
+		fragOffset, totalLen = primary.FragmentOffset, primary.TotalDataLength
+	}
+
+	fragPrimary, _, err := fragmentPrimaryBlock(primary, int(fragOffset), int(totalLen))
+	if err != nil {
+		return
+	}
+
+	frag, err = NewBundle(fragPrimary, append(canonicals, CanonicalBlock{
+		BlockNumber:       blockNumber,
+		BlockControlFlags: BlockControlFlags(blockFlags),
+		CRCType:           CRCType(crcType),
+		Value:             NewPayloadBlock(append([]byte(nil), payload...)),
+	}))
+	return
+}
+
+// ReactiveRemainder creates the fragment carrying the remaining payload of a Bundle whose serialization was only
+// received up to the given length, e.g., before a connection broke down. This is the sending side's counterpart to
+// ReactiveFragment; both fragments together contain the whole payload.
+//
+// An error is returned if the receiver was not able to create a fragment from this prefix or if there is no payload
+// left. In both cases, the whole Bundle needs to be sent again.
+func (b Bundle) ReactiveRemainder(received int) (frag Bundle, err error) {
+	buff := new(bytes.Buffer)
+	if err = b.MarshalCbor(buff); err != nil {
+		return
+	} else if received >= buff.Len() {
+		err = fmt.Errorf("bundle was received completely")
+		return
+	}
+
+	head, err := ReactiveFragment(buff.Bytes()[:received])
+	if err != nil {
+		return
+	}
+
+	headPayloadBlock, err := head.PayloadBlock()
+	if err != nil {
+		return
+	}
+	payloadBlock, err := b.PayloadBlock()
+	if err != nil {
+		return
+	}
+
+	headLen := len(headPayloadBlock.Value.(*PayloadBlock).Data())
+	payload := payloadBlock.Value.(*PayloadBlock).Data()
+	if headLen >= len(payload) {
+		err = fmt.Errorf("payload was received completely")
+		return
+	}
+
+	fragOffset, totalLen := 0, len(payload)
+	if b.PrimaryBlock.BundleControlFlags.Has(IsFragment) {
+		fragOffset, totalLen = int(b.PrimaryBlock.FragmentOffset), int(b.PrimaryBlock.TotalDataLength)
+	}
+
+	fragPrimary, _, err := fragmentPrimaryBlock(b.PrimaryBlock, fragOffset+headLen, totalLen)
+	if err != nil {
+		return
+	}
+
+	frag = MustNewBundle(fragPrimary, nil)
+	for _, cb := range b.CanonicalBlocks {
+		if cb.TypeCode() != ExtBlockTypePayloadBlock && cb.BlockControlFlags.Has(ReplicateBlock) {
+			frag.AddExtensionBlock(cb)
+		}
+	}
+	frag.AddExtensionBlock(CanonicalBlock{
+		BlockControlFlags: payloadBlock.BlockControlFlags,
+		CRCType:           payloadBlock.CRCType,
+		Value:             NewPayloadBlock(payload[headLen:]),
+	})
+
+	err = frag.CheckValid()
+	return
+}
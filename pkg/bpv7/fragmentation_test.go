@@ -119,6 +119,43 @@ func TestBundleFragmentHugeMtu(t *testing.T) {
 	}
 }
 
+func TestBundleFragmentMinPayload(t *testing.T) {
+	const minPayload = 64
+
+	bndl, err := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("5m").
+		PayloadBlock(make([]byte, 1024)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The blocks' overhead leaves less than minPayload bytes within this MTU.
+	if frags, err := bndl.FragmentMinPayload(96, minPayload); err == nil {
+		t.Fatalf("MTU too small for the minimum payload resulted in %d fragments", len(frags))
+	}
+
+	frags, err := bndl.FragmentMinPayload(256, minPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, frag := range frags[:len(frags)-1] {
+		if pb, err := frag.PayloadBlock(); err != nil {
+			t.Fatal(err)
+		} else if l := len(pb.Value.(*PayloadBlock).Data()); l < minPayload {
+			t.Fatalf("fragment %d carries %d payload bytes, less than %d", i, l, minPayload)
+		}
+	}
+
+	// Only the last fragment might carry less than minPayload bytes, not the first one.
+	if _, err := bndl.FragmentMinPayload(256, 1024); err == nil {
+		t.Fatal("MTU too small for the minimum payload did not error")
+	}
+}
+
 func TestIsBundleReassemblable(t *testing.T) {
 	bndl, err := Builder().
 		Source("dtn://src/").
@@ -198,6 +235,47 @@ func TestReassembleFragments(t *testing.T) {
 	}
 }
 
+func TestReassembleFragmentsOverlapping(t *testing.T) {
+	payloadData := make([]byte, 1024)
+	rand.Seed(23)
+	_, _ = rand.Read(payloadData)
+
+	bndl, err := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("5m").
+		PayloadBlock(payloadData).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	smallFrags, err := bndl.Fragment(128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	largeFrags, err := bndl.Fragment(384)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Mix fragments of both fragmentations, including duplicates and fragments covered by others.
+	frags := append([]Bundle{}, largeFrags...)
+	frags = append(frags, smallFrags[1], smallFrags[len(smallFrags)/2], smallFrags[1])
+
+	bndl2, err := ReassembleFragments(frags)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pb, err := bndl2.PayloadBlock(); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(pb.Value.(*PayloadBlock).Data(), payloadData) {
+		t.Fatal("Reassembled payload differs")
+	}
+}
+
 func TestReassembleFragmentsMissing(t *testing.T) {
 	bndl, err := Builder().
 		Source("dtn://src/").
@@ -224,3 +302,158 @@ func TestReassembleFragmentsMissing(t *testing.T) {
 		t.Fatalf("Expected error for missing fragment")
 	}
 }
+
+func TestReassembleFragmentsIntegrity(t *testing.T) {
+	for _, corrupt := range []bool{false, true} {
+		t.Run(fmt.Sprintf("corrupt=%t", corrupt), func(t *testing.T) {
+			payloadData := make([]byte, 1024)
+			rand.Seed(23)
+			_, _ = rand.Read(payloadData)
+
+			bndl, err := Builder().
+				Source("dtn://src/").
+				Destination("dtn://dst/").
+				CreationTimestampNow().
+				Lifetime("5m").
+				PayloadBlock(payloadData).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			} else if err = bndl.AddPayloadIntegrityBlock(); err != nil {
+				t.Fatal(err)
+			}
+
+			frags, err := bndl.Fragment(128)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if corrupt {
+				// Alter the last fragment's payload while keeping a valid fragment
+				payloadBlock, err := frags[len(frags)-1].PayloadBlock()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				data := append([]byte(nil), payloadBlock.Value.(*PayloadBlock).Data()...)
+				data[0] ^= 0xff
+				payloadBlock.Value = NewPayloadBlock(data)
+			}
+
+			bndl2, err := ReassembleFragments(frags)
+			if corrupt {
+				if err == nil {
+					t.Fatal("Reassembly of a corrupted fragment did not fail")
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if err = bndl2.VerifyPayloadIntegrity(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestReactiveFragment(t *testing.T) {
+	payloadData := make([]byte, 1024)
+	rand.Seed(42)
+	_, _ = rand.Read(payloadData)
+
+	bndl, err := Builder().
+		CRC(CRC32).
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("5m").
+		HopCountBlock(64).
+		PayloadBlock(payloadData).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buff := new(bytes.Buffer)
+	if err := bndl.MarshalCbor(buff); err != nil {
+		t.Fatal(err)
+	}
+	data := buff.Bytes()
+
+	// The payload's data are the last bytes, only followed by its CRC and the break code.
+	payloadStart := len(data) - len(payloadData) - 1 - 1 - 4
+
+	for _, received := range []int{payloadStart + 1, payloadStart + 512, payloadStart + len(payloadData) - 1} {
+		t.Run(fmt.Sprintf("%d", received), func(t *testing.T) {
+			head, err := ReactiveFragment(data[:received])
+			if err != nil {
+				t.Fatal(err)
+			}
+			tail, err := bndl.ReactiveRemainder(received)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if pb, err := head.PayloadBlock(); err != nil {
+				t.Fatal(err)
+			} else if l := len(pb.Value.(*PayloadBlock).Data()); l != received-payloadStart {
+				t.Fatalf("head fragment has %d payload bytes, expected %d", l, received-payloadStart)
+			}
+			if off := tail.PrimaryBlock.FragmentOffset; off != uint64(received-payloadStart) {
+				t.Fatalf("tail fragment has offset %d, expected %d", off, received-payloadStart)
+			}
+
+			bndl2, err := ReassembleFragments([]Bundle{head, tail})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pb, err := bndl2.PayloadBlock(); err != nil {
+				t.Fatal(err)
+			} else if !bytes.Equal(pb.Value.(*PayloadBlock).Data(), payloadData) {
+				t.Fatal("Reassembled payload differs")
+			}
+		})
+	}
+
+	for _, received := range []int{1, payloadStart - 3, payloadStart} {
+		if _, err := ReactiveFragment(data[:received]); err == nil {
+			t.Fatalf("prefix of %d bytes without payload resulted in a fragment", received)
+		}
+		if _, err := bndl.ReactiveRemainder(received); err == nil {
+			t.Fatalf("prefix of %d bytes without payload resulted in a remainder", received)
+		}
+	}
+
+	if b, err := ReactiveFragment(data); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(b, bndl) {
+		t.Fatal("complete Bundle differs")
+	}
+}
+
+func TestReactiveFragmentMustNotFragment(t *testing.T) {
+	bndl, err := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("5m").
+		BundleCtrlFlags(MustNotFragmented).
+		PayloadBlock(make([]byte, 128)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buff := new(bytes.Buffer)
+	if err := bndl.MarshalCbor(buff); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReactiveFragment(buff.Bytes()[:buff.Len()-32]); err == nil {
+		t.Fatal("Bundle with MustNotFragmented flag resulted in a fragment")
+	}
+	if _, err := bndl.ReactiveRemainder(buff.Len() - 32); err == nil {
+		t.Fatal("Bundle with MustNotFragmented flag resulted in a remainder")
+	}
+}
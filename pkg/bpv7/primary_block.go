@@ -17,6 +17,17 @@ import (
 
 const dtnVersion uint64 = 7
 
+// LegacyFormatError is returned when decoding a bundle of a previous Bundle Protocol version, e.g., of version 6 as
+// specified in RFC 5050. Such bundles cannot be processed and should be migrated by their sender.
+type LegacyFormatError struct {
+	Version uint64
+}
+
+func (lfe *LegacyFormatError) Error() string {
+	return fmt.Sprintf("bundle uses the legacy format of Bundle Protocol version %d, only version %d is supported",
+		lfe.Version, dtnVersion)
+}
+
 // PrimaryBlock is a representation of the primary bundle block as defined in section 4.3.1.
 type PrimaryBlock struct {
 	Version            uint64
@@ -78,13 +89,19 @@ func (pb PrimaryBlock) GetCRCType() CRCType {
 // primary block is present. Thus, until BPsec is available in dtn7-go, all
 // created primary blocks will have a mandatory CRC value.
 func (pb *PrimaryBlock) SetCRCType(crcType CRCType) {
+	pb.setCRCType(crcType)
+	_ = pb.calculateCRC()
+}
+
+// setCRCType sets the CRC type without calculating the CRC value, compare BundleBuilder.DeferCRC.
+func (pb *PrimaryBlock) setCRCType(crcType CRCType) {
 	// NOTE: Until BPsec has landed, set a CRC for primary blocks.
 	if crcType == CRCNo {
 		crcType = CRC32
 	}
 
 	pb.CRCType = crcType
-	_ = pb.calculateCRC()
+	pb.CRC = nil
 }
 
 // calculateCRC serializes the PrimaryBlock once to calculate its CRC value.
@@ -95,6 +112,12 @@ func (pb *PrimaryBlock) calculateCRC() error {
 	return pb.MarshalCbor(new(bytes.Buffer))
 }
 
+// UpdateCRC recalculates this block's CRC value. This must be called after modifying a PrimaryBlock, e.g., after
+// changing its Destination.
+func (pb *PrimaryBlock) UpdateCRC() error {
+	return pb.calculateCRC()
+}
+
 // MarshalCbor writes the CBOR representation of a PrimaryBlock.
 func (pb *PrimaryBlock) MarshalCbor(w io.Writer) error {
 	blockLen := func() uint64 {
@@ -180,6 +203,8 @@ func (pb *PrimaryBlock) UnmarshalCbor(r io.Reader) error {
 
 	if version, err := cboring.ReadUInt(r); err != nil {
 		return err
+	} else if version < dtnVersion {
+		return &LegacyFormatError{Version: version}
 	} else if version != dtnVersion {
 		return fmt.Errorf("expected version %d, got %d", dtnVersion, version)
 	} else {
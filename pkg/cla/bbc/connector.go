@@ -30,18 +30,25 @@ type Connector struct {
 	closedRAck chan struct{}
 	closedWSyn chan struct{}
 	closedWAck chan struct{}
+
+	*cla.ByteCounts
+	cla.BundleDecoder
 }
 
 // NewConnector creates a new Connector, wrapping around the given Modem.
 func NewConnector(modem Modem, permanent bool) *Connector {
+	counts := new(cla.ByteCounts)
+
 	return &Connector{
-		modem:            modem,
+		modem:            &countingModem{Modem: modem, counts: counts},
 		permanent:        permanent,
 		tid:              randomTransmissionId(),
 		transmissions:    make(map[byte]*IncomingTransmission),
 		fragmentOut:      make(chan Fragment, 64),
 		failTransmission: make(chan byte, 64),
 		reportChan:       make(chan cla.ConvergenceStatus, 64),
+
+		ByteCounts: counts,
 	}
 }
 
@@ -121,7 +128,7 @@ func (c *Connector) handleIncomingFragment(frag Fragment) (err error) {
 	if transmission.IsFinished() {
 		var bndl bpv7.Bundle
 
-		if bndl, err = transmission.Bundle(); err == nil {
+		if bndl, err = transmission.Bundle(c.DecodeMode()); err == nil {
 			logger.WithFields(log.Fields{
 				"transaction": transmission,
 				"bundle":      bndl.ID(),
@@ -249,3 +256,29 @@ func (c *Connector) GetEndpointID() bpv7.EndpointID {
 func (c *Connector) String() string {
 	return c.Address()
 }
+
+// countingModem wraps a Modem, counting the bytes of all sent and received Fragments for the Connector's ByteCounts.
+type countingModem struct {
+	Modem
+	counts *cla.ByteCounts
+}
+
+func (cm *countingModem) Send(f Fragment) error {
+	if err := cm.Modem.Send(f); err != nil {
+		return err
+	}
+
+	cm.counts.AddSent(len(f.Bytes()))
+	return nil
+}
+
+func (cm *countingModem) Receive() (f Fragment, err error) {
+	if f, err = cm.Modem.Receive(); err == nil {
+		cm.counts.AddReceived(len(f.Bytes()))
+	}
+	return
+}
+
+func (cm *countingModem) String() string {
+	return fmt.Sprintf("%v", cm.Modem)
+}
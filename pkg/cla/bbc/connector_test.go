@@ -33,6 +33,16 @@ func TestConnector(t *testing.T) {
 
 	uff := <-c.Channel()
 	t.Log(uff)
+
+	// The dummy modem also receives its own broadcasted Fragments. The last one might still be counted as sent.
+	for i := 0; ; i++ {
+		if sent, received := c.BytesSent(), c.BytesReceived(); sent > 0 && sent == received {
+			break
+		} else if i >= 50 {
+			t.Fatalf("counted %d bytes sent and %d bytes received", sent, received)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
 }
 
 func TestConnectorUnregisterTransmission(t *testing.T) {
@@ -93,7 +93,8 @@ func (t *IncomingTransmission) ReadFragment(f Fragment) (finished bool, err erro
 	return
 }
 
-func (t *IncomingTransmission) Bundle() (bndl bpv7.Bundle, err error) {
+// Bundle decodes the Bundle of a finished IncomingTransmission in the given DecodeMode.
+func (t *IncomingTransmission) Bundle(mode bpv7.DecodeMode) (bndl bpv7.Bundle, err error) {
 	if !t.IsFinished() {
 		err = fmt.Errorf("Transmission is not finished yet")
 		return
@@ -105,7 +106,7 @@ func (t *IncomingTransmission) Bundle() (bndl bpv7.Bundle, err error) {
 		return
 	}
 
-	err = bndl.UnmarshalCbor(xzR)
+	err = bndl.UnmarshalCborMode(xzR, mode)
 	return
 }
 
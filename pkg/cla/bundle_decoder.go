@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package cla
+
+import (
+	"sync/atomic"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// DecodeModeSetter is an optional interface for a Convergence to decode received Bundles in a configurable
+// bpv7.DecodeMode. The Manager sets its DecodeMode for each registered DecodeModeSetter, compare
+// Manager.SetDecodeMode.
+type DecodeModeSetter interface {
+	// SetDecodeMode changes the DecodeMode of all following Bundle decodings.
+	SetDecodeMode(mode bpv7.DecodeMode)
+}
+
+// BundleDecoder implements the DecodeModeSetter and might be embedded into a Convergence, which decodes Bundles,
+// e.g., by bpv7.Bundle.UnmarshalCborMode, in its DecodeMode. Its zero value uses the bpv7.StrictDecoding.
+type BundleDecoder struct {
+	mode uint32
+}
+
+// SetDecodeMode changes the DecodeMode of all following Bundle decodings.
+func (bd *BundleDecoder) SetDecodeMode(mode bpv7.DecodeMode) {
+	atomic.StoreUint32(&bd.mode, uint32(mode))
+}
+
+// DecodeMode returns the currently used DecodeMode.
+func (bd *BundleDecoder) DecodeMode() bpv7.DecodeMode {
+	return bpv7.DecodeMode(atomic.LoadUint32(&bd.mode))
+}
@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package cla
+
+import (
+	"io"
+	"sync"
+)
+
+// ByteCounter is an optional interface for a Convergence to report its cumulative amount of transferred bytes,
+// including the convergence layer's own headers. This allows accounting the traffic per peer.
+type ByteCounter interface {
+	// BytesSent returns the total amount of bytes sent.
+	BytesSent() uint64
+
+	// BytesReceived returns the total amount of bytes received.
+	BytesReceived() uint64
+}
+
+// ByteCounts implements the ByteCounter and might be embedded into a Convergence. The counters are fed by wrapping
+// the underlying connection's Writer and Reader with CountingWriter and CountingReader. Message based convergence
+// layers might also call AddSent and AddReceived directly.
+type ByteCounts struct {
+	mutex    sync.Mutex
+	sent     uint64
+	received uint64
+}
+
+// BytesSent returns the total amount of bytes written through a CountingWriter.
+func (bc *ByteCounts) BytesSent() uint64 {
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+
+	return bc.sent
+}
+
+// BytesReceived returns the total amount of bytes read through a CountingReader.
+func (bc *ByteCounts) BytesReceived() uint64 {
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+
+	return bc.received
+}
+
+// AddSent adds n bytes to the sent counter.
+func (bc *ByteCounts) AddSent(n int) {
+	bc.mutex.Lock()
+	bc.sent += uint64(n)
+	bc.mutex.Unlock()
+}
+
+// AddReceived adds n bytes to the received counter.
+func (bc *ByteCounts) AddReceived(n int) {
+	bc.mutex.Lock()
+	bc.received += uint64(n)
+	bc.mutex.Unlock()
+}
+
+// CountingWriter wraps a Writer, adding all written bytes to the sent counter. A nil ByteCounts returns the Writer.
+func (bc *ByteCounts) CountingWriter(w io.Writer) io.Writer {
+	if bc == nil {
+		return w
+	}
+	return &countingWriter{w: w, counts: bc}
+}
+
+// CountingReader wraps a Reader, adding all read bytes to the received counter. A nil ByteCounts returns the Reader.
+func (bc *ByteCounts) CountingReader(r io.Reader) io.Reader {
+	if bc == nil {
+		return r
+	}
+	return &countingReader{r: r, counts: bc}
+}
+
+type countingWriter struct {
+	w      io.Writer
+	counts *ByteCounts
+}
+
+func (cw *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = cw.w.Write(p)
+	cw.counts.AddSent(n)
+	return
+}
+
+type countingReader struct {
+	r      io.Reader
+	counts *ByteCounts
+}
+
+func (cr *countingReader) Read(p []byte) (n int, err error) {
+	n, err = cr.r.Read(p)
+	cr.counts.AddReceived(n)
+	return
+}
@@ -26,6 +26,12 @@ const (
 	// Only here for completeness
 	BBC CLAType = 20
 
+	// HTTPCL identifies the convergence layer based on HTTP POST requests, implemented in cla/httpcl.
+	HTTPCL CLAType = 30
+
+	// UDPCL identifies the convergence layer based on UDP datagrams, implemented in cla/udpcl.
+	UDPCL CLAType = 40
+
 	unknownClaTypeString string = "unknown CLA type"
 )
 
@@ -51,6 +57,12 @@ func (claType CLAType) String() string {
 	case BBC:
 		return "BBC"
 
+	case HTTPCL:
+		return "HTTPCL"
+
+	case UDPCL:
+		return "UDPCL"
+
 	default:
 		return unknownClaTypeString
 	}
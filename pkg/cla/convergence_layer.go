@@ -24,6 +24,7 @@
 package cla
 
 import (
+	"context"
 	"io"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
@@ -85,6 +86,57 @@ type ConvergenceSender interface {
 	GetPeerEndpointID() bpv7.EndpointID
 }
 
+// MTUReporter is an optional interface for a ConvergenceSender to report the
+// maximum size of a serialized bundle, e.g., based on the underlying link.
+// Larger bundles will be fragmented before being sent.
+type MTUReporter interface {
+	// MTU returns the maximum size of a serialized bundle in bytes. Zero
+	// indicates no limit.
+	MTU() int
+}
+
+// ContextSender is an optional interface for a ConvergenceSender to abort
+// sending a bundle when a context is done, e.g., after the bundle's lifetime.
+// The ConvergenceSender's Send method should behave like SendContext with a
+// background context. Compare the SendContext function.
+type ContextSender interface {
+	// SendContext sends a bundle like Send, but returns the context's error
+	// after it is done.
+	SendContext(ctx context.Context, b bpv7.Bundle) error
+}
+
+// SendContext sends a bundle by a ConvergenceSender until the context is done.
+// If the ConvergenceSender is a ContextSender, its SendContext method is used.
+// Otherwise, its Send method is not waited for after the context is done, but
+// might still finish in the background.
+func SendContext(ctx context.Context, cs ConvergenceSender, b bpv7.Bundle) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if ctxSender, ok := cs.(ContextSender); ok {
+		return ctxSender.SendContext(ctx, b)
+	}
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- cs.Send(b) }()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GracefulCloser is an optional interface for a Convergence to close its
+// session gracefully, e.g., by a termination handshake with its peer. The
+// context bounds this closing; afterwards, the Convergence must be closed.
+type GracefulCloser interface {
+	// CloseContext closes this Convergence gracefully until the context is done.
+	CloseContext(ctx context.Context) error
+}
+
 // ConvergenceProvider is a more general kind of CLA service which does not
 // transfer any Bundles by itself, but supplies/creates new Convergence types.
 // Those Convergence objects will be passed to a Manager. Thus, one might think
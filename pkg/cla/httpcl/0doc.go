@@ -0,0 +1,14 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package httpcl provides a convergence layer based on plain HTTP(S) requests.
+//
+// Each bundle is sent as the CBOR encoded body of a POST request, possibly using a chunked transfer encoding. Thus,
+// bundles might pass proxies or firewalls where a TCPCL connection cannot be established. As MTCP, this convergence
+// layer is unidirectional: the HTTPServer implements the ConvergenceReceiver and the HTTPClient the ConvergenceSender
+// interfaces defined in the parent cla package.
+//
+// The HTTPServer answers with "202 Accepted" after passing a received bundle on. The HTTPClient maps the response's
+// status code to its Send method's result, compare cla.RefusalError.
+package httpcl
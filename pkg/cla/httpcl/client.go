@@ -0,0 +1,209 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package httpcl
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/cboring"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// defaultTimeout limits an HTTPClient's requests and an HTTPServer's reading of a request, unless altered by SetTimeout.
+const defaultTimeout = time.Minute
+
+// HTTPClient sends bundles as the body of POST requests to an HTTPServer's URL. This struct implements a
+// ConvergenceSender.
+type HTTPClient struct {
+	url        string
+	peer       bpv7.EndpointID
+	permanent  bool
+	httpClient *http.Client
+	reportChan chan cla.ConvergenceStatus
+	failChan   chan struct{}
+
+	stopSyn chan struct{}
+	stopAck chan struct{}
+
+	*cla.ByteCounts
+}
+
+// NewHTTPClient creates a new HTTPClient, sending to the given URL, e.g., "https://example.com/dtn", for the
+// registered endpoint ID. The permanent flag indicates if this HTTPClient should never be removed from the core.
+func NewHTTPClient(url string, peer bpv7.EndpointID, permanent bool) *HTTPClient {
+	return &HTTPClient{
+		url:        url,
+		peer:       peer,
+		permanent:  permanent,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+
+		ByteCounts: new(cla.ByteCounts),
+	}
+}
+
+// SetTimeout limits the duration of each request, including reading the response. A zero timeout means no limit.
+// This must be called before starting the HTTPClient.
+func (client *HTTPClient) SetTimeout(timeout time.Duration) {
+	client.httpClient.Timeout = timeout
+}
+
+// SetTLSConfig configures the TLS connections to https URLs, e.g., to trust a custom root CA. This must be called
+// before starting the HTTPClient.
+func (client *HTTPClient) SetTLSConfig(config *tls.Config) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	client.httpClient.Transport = transport
+}
+
+func (client *HTTPClient) Start() (err error, retry bool) {
+	retry = true
+
+	if !strings.HasPrefix(client.url, "http://") && !strings.HasPrefix(client.url, "https://") {
+		err = fmt.Errorf("URL %s is neither an http nor an https URL", client.url)
+		retry = false
+		return
+	}
+
+	client.reportChan = make(chan cla.ConvergenceStatus)
+	client.failChan = make(chan struct{}, 1)
+	client.stopSyn = make(chan struct{})
+	client.stopAck = make(chan struct{})
+
+	go client.handler()
+	return
+}
+
+func (client *HTTPClient) handler() {
+	defer func() {
+		close(client.reportChan)
+		close(client.stopAck)
+	}()
+
+	// As HTTP is stateless, the peer is assumed to be present until a request fails.
+	if !client.report(cla.NewConvergencePeerAppeared(client, client.GetPeerEndpointID())) {
+		return
+	}
+
+	for {
+		select {
+		case <-client.stopSyn:
+			return
+
+		case <-client.failChan:
+			if !client.report(cla.NewConvergencePeerDisappeared(client, client.GetPeerEndpointID())) {
+				return
+			}
+		}
+	}
+}
+
+// report a ConvergenceStatus, unless this HTTPClient is being closed.
+func (client *HTTPClient) report(cs cla.ConvergenceStatus) bool {
+	select {
+	case <-client.stopSyn:
+		return false
+	case client.reportChan <- cs:
+		return true
+	}
+}
+
+// Send a bundle as the body of a POST request. The bundle is encoded while being sent, resulting in a chunked
+// transfer encoding. An unsuccessful status code results in a cla.RefusalError. Only the body is taken into account
+// for the ByteCounter, HTTP's headers are not.
+func (client *HTTPClient) Send(bndl bpv7.Bundle) error {
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(cboring.Marshal(&bndl, client.CountingWriter(pw)))
+	}()
+	defer func() { _ = pr.Close() }()
+
+	req, err := http.NewRequest(http.MethodPost, client.url, pr)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/cbor")
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"client": client,
+			"bundle": bndl.ID(),
+			"error":  err,
+		}).Warn("HTTPClient failed to send bundle")
+
+		select {
+		case client.failChan <- struct{}{}:
+		default:
+		}
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	return statusError(resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// statusError maps an HTTP response's status code to the result of the Send method.
+func statusError(statusCode int, body string) error {
+	reason := fmt.Sprintf("HTTP %d %s", statusCode, http.StatusText(statusCode))
+	if body != "" {
+		reason = fmt.Sprintf("%s: %s", reason, body)
+	}
+
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		return nil
+
+	case statusCode == http.StatusConflict:
+		return cla.NewRefusalError(cla.RefusalDuplicate, reason)
+
+	case statusCode == http.StatusTooManyRequests, statusCode == http.StatusServiceUnavailable:
+		return cla.NewRefusalError(cla.RefusalTemporary, reason)
+
+	case statusCode >= 400 && statusCode < 500:
+		return cla.NewRefusalError(cla.RefusalPermanent, reason)
+
+	default:
+		return fmt.Errorf("request failed with %s", reason)
+	}
+}
+
+func (client *HTTPClient) Channel() chan cla.ConvergenceStatus {
+	return client.reportChan
+}
+
+func (client *HTTPClient) Close() error {
+	close(client.stopSyn)
+	<-client.stopAck
+
+	return nil
+}
+
+func (client *HTTPClient) GetPeerEndpointID() bpv7.EndpointID {
+	return client.peer
+}
+
+func (client *HTTPClient) Address() string {
+	return client.url
+}
+
+func (client *HTTPClient) IsPermanent() bool {
+	return client.permanent
+}
+
+func (client *HTTPClient) String() string {
+	return client.url
+}
@@ -0,0 +1,274 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package httpcl
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/routing"
+)
+
+func getRandomPort(t *testing.T) int {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = l.Close() }()
+
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestHTTPServerClientCore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	nodeId := bpv7.MustNewEndpointID("dtn://receiver/")
+	c, err := routing.NewCore(dir, nodeId, false, routing.RoutingConf{Algorithm: "epidemic"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	port := getRandomPort(t)
+	serv := NewHTTPServer(fmt.Sprintf("localhost:%d", port), nodeId, false)
+	if err := c.RegisterCLA(serv, cla.HTTPCL, nodeId); err != nil {
+		t.Fatal(err)
+	}
+
+	delivered := make(chan bpv7.Bundle, 1)
+	unsubscribe, err := c.Subscribe(bpv7.MustNewEndpointID("dtn://receiver/app"), func(b bpv7.Bundle) { delivered <- b })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsubscribe()
+
+	// A payload of one MiB is sent in multiple chunks.
+	payload := bytes.Repeat([]byte("hello world!"), 1<<20/12)
+	bndl, err := bpv7.Builder().
+		Source("dtn://sender/").
+		Destination("dtn://receiver/app").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock(payload).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewHTTPClient(fmt.Sprintf("http://localhost:%d/", port), nodeId, false)
+	if err, _ := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for range client.Channel() {
+		}
+	}()
+	defer func() { _ = client.Close() }()
+
+	// The server might need some time to be started by the Core's CLA manager.
+	for i := 0; ; i++ {
+		if err := client.Send(bndl); err == nil {
+			break
+		} else if i >= 50 {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("bundle was not delivered")
+
+	case b := <-delivered:
+		if b.ID() != bndl.ID() {
+			t.Fatalf("delivered bundle %v, expected %v", b.ID(), bndl.ID())
+		} else if pb, err := b.PayloadBlock(); err != nil {
+			t.Fatal(err)
+		} else if data := pb.Value.(*bpv7.PayloadBlock).Data(); !bytes.Equal(data, payload) {
+			t.Fatalf("delivered payload of %d bytes differs", len(data))
+		}
+	}
+}
+
+func TestHTTPClientStatusCodes(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		valid      bool
+		refusal    bool
+		kind       cla.RefusalKind
+	}{
+		{http.StatusOK, true, false, 0},
+		{http.StatusAccepted, true, false, 0},
+		{http.StatusConflict, false, true, cla.RefusalDuplicate},
+		{http.StatusTooManyRequests, false, true, cla.RefusalTemporary},
+		{http.StatusServiceUnavailable, false, true, cla.RefusalTemporary},
+		{http.StatusBadRequest, false, true, cla.RefusalPermanent},
+		{http.StatusRequestEntityTooLarge, false, true, cla.RefusalPermanent},
+		{http.StatusInternalServerError, false, false, 0},
+	}
+
+	bndl, err := bpv7.Builder().
+		Source("dtn://sender/").
+		Destination("dtn://receiver/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock([]byte("hello world!")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		t.Run(http.StatusText(test.statusCode), func(t *testing.T) {
+			httpServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = ioutil.ReadAll(r.Body)
+				w.WriteHeader(test.statusCode)
+			}))
+			defer httpServ.Close()
+
+			client := NewHTTPClient(httpServ.URL, bpv7.MustNewEndpointID("dtn://receiver/"), false)
+			if err, _ := client.Start(); err != nil {
+				t.Fatal(err)
+			}
+			go func() {
+				for range client.Channel() {
+				}
+			}()
+			defer func() { _ = client.Close() }()
+
+			err := client.Send(bndl)
+			if test.valid {
+				if err != nil {
+					t.Fatalf("status code %d resulted in an error: %v", test.statusCode, err)
+				}
+				return
+			} else if err == nil {
+				t.Fatalf("status code %d did not result in an error", test.statusCode)
+			}
+
+			var refusal *cla.RefusalError
+			if isRefusal := errors.As(err, &refusal); isRefusal != test.refusal {
+				t.Fatalf("status code %d resulted in %v, expected refusal %t", test.statusCode, err, test.refusal)
+			} else if isRefusal && refusal.Kind != test.kind {
+				t.Fatalf("status code %d resulted in refusal %v, expected %v", test.statusCode, refusal.Kind, test.kind)
+			}
+		})
+	}
+}
+
+// startHTTPClient starts the HTTPClient and discards its status messages.
+func startHTTPClient(t *testing.T, client *HTTPClient) {
+	if err, _ := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for range client.Channel() {
+		}
+	}()
+}
+
+func TestHTTPServerMaxBodySize(t *testing.T) {
+	serv := NewHTTPServer("localhost:0", bpv7.MustNewEndpointID("dtn://receiver/"), false)
+	serv.SetMaxBodySize(1024)
+
+	httpServ := httptest.NewServer(serv)
+	defer httpServ.Close()
+
+	bndl, err := bpv7.Builder().
+		Source("dtn://sender/").
+		Destination("dtn://receiver/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock(bytes.Repeat([]byte("hello world!"), 1024)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The HTTPClient uses a chunked transfer encoding without any Content-Length.
+	client := NewHTTPClient(httpServ.URL, bpv7.MustNewEndpointID("dtn://receiver/"), false)
+	startHTTPClient(t, client)
+	defer func() { _ = client.Close() }()
+
+	var refusal *cla.RefusalError
+	if err := client.Send(bndl); !errors.As(err, &refusal) || refusal.Kind != cla.RefusalPermanent {
+		t.Fatalf("sending an oversized bundle resulted in %v, expected a permanent refusal", err)
+	}
+
+	var buff bytes.Buffer
+	if err := bndl.MarshalCbor(&buff); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := http.Post(httpServ.URL, "application/cbor", &buff); err != nil {
+		t.Fatal(err)
+	} else if _ = resp.Body.Close(); resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized request with a Content-Length resulted in %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPClientTimeoutTLS(t *testing.T) {
+	bndl, err := bpv7.Builder().
+		Source("dtn://sender/").
+		Destination("dtn://receiver/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock([]byte("hello world!")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var delay time.Duration
+	httpServ := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		time.Sleep(delay)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer httpServ.Close()
+
+	// The test server's certificate is not trusted by default.
+	untrusted := NewHTTPClient(httpServ.URL, bpv7.MustNewEndpointID("dtn://receiver/"), false)
+	startHTTPClient(t, untrusted)
+	defer func() { _ = untrusted.Close() }()
+
+	if err := untrusted.Send(bndl); err == nil {
+		t.Fatal("sending to an untrusted server did not error")
+	}
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(httpServ.Certificate())
+
+	client := NewHTTPClient(httpServ.URL, bpv7.MustNewEndpointID("dtn://receiver/"), false)
+	client.SetTLSConfig(&tls.Config{RootCAs: rootCAs})
+	client.SetTimeout(100 * time.Millisecond)
+	startHTTPClient(t, client)
+	defer func() { _ = client.Close() }()
+
+	if err := client.Send(bndl); err != nil {
+		t.Fatal(err)
+	}
+
+	delay = 500 * time.Millisecond
+	if err := client.Send(bndl); err == nil {
+		t.Fatal("sending to a slow server did not time out")
+	}
+}
@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package httpcl
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// defaultMaxBodySize limits a request's body, i.e., a received bundle, unless altered by SetMaxBodySize.
+const defaultMaxBodySize = 64 << 20
+
+// HTTPServer receives bundles as the body of POST requests and forwards them to its channel. This struct implements
+// a ConvergenceReceiver and an http.Handler.
+type HTTPServer struct {
+	listenAddress string
+	reportChan    chan cla.ConvergenceStatus
+	endpointID    bpv7.EndpointID
+	permanent     bool
+
+	timeout     time.Duration
+	maxBodySize int64
+	tlsConfig   *tls.Config
+
+	httpServer *http.Server
+
+	// closeMutex is read-locked while passing received bundles to the reportChan, which is closed exclusively.
+	closeMutex sync.RWMutex
+	closed     bool
+	stopSyn    chan struct{}
+
+	*cla.ByteCounts
+	cla.BundleDecoder
+}
+
+// NewHTTPServer creates a new HTTPServer for the given listen address. The permanent flag indicates if this
+// HTTPServer should never be removed from the core.
+func NewHTTPServer(listenAddress string, endpointID bpv7.EndpointID, permanent bool) *HTTPServer {
+	return &HTTPServer{
+		listenAddress: listenAddress,
+		reportChan:    make(chan cla.ConvergenceStatus),
+		endpointID:    endpointID,
+		permanent:     permanent,
+		timeout:       defaultTimeout,
+		maxBodySize:   defaultMaxBodySize,
+		stopSyn:       make(chan struct{}),
+
+		ByteCounts: new(cla.ByteCounts),
+	}
+}
+
+// SetTimeout limits the duration of reading a request, including its body. A zero timeout means no limit. This must
+// be called before starting the HTTPServer.
+func (serv *HTTPServer) SetTimeout(timeout time.Duration) {
+	serv.timeout = timeout
+}
+
+// SetMaxBodySize limits the size of a request's body, i.e., of a received bundle. Larger requests are answered with
+// "413 Request Entity Too Large". This must be called before starting the HTTPServer.
+func (serv *HTTPServer) SetMaxBodySize(size int64) {
+	serv.maxBodySize = size
+}
+
+// SetTLSConfig enables HTTPS for this HTTPServer. The config must contain at least one certificate. This must be
+// called before starting the HTTPServer.
+func (serv *HTTPServer) SetTLSConfig(config *tls.Config) {
+	serv.tlsConfig = config
+}
+
+func (serv *HTTPServer) Start() (error, bool) {
+	tcpAddr, err := net.ResolveTCPAddr("tcp", serv.listenAddress)
+	if err != nil {
+		return err, false
+	}
+
+	ln, err := net.ListenTCP("tcp", tcpAddr)
+	if err != nil {
+		return err, true
+	}
+
+	serv.httpServer = &http.Server{
+		Handler:     serv,
+		ReadTimeout: serv.timeout,
+		TLSConfig:   serv.tlsConfig,
+	}
+	go func() {
+		var err error
+		if serv.tlsConfig != nil {
+			err = serv.httpServer.ServeTLS(ln, "", "")
+		} else {
+			err = serv.httpServer.Serve(ln)
+		}
+
+		if err != nil && err != http.ErrServerClosed {
+			log.WithFields(log.Fields{
+				"cla":   serv,
+				"error": err,
+			}).Error("HTTPServer failed to serve")
+		}
+	}()
+
+	return nil, true
+}
+
+// ServeHTTP handles a POST request, containing a CBOR encoded bundle as its body.
+func (serv *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.ContentLength > serv.maxBodySize {
+		http.Error(w, "bundle exceeds the maximum size", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	body := &bodyReader{r: http.MaxBytesReader(w, r.Body, serv.maxBodySize)}
+
+	bndl := new(bpv7.Bundle)
+	if err := bndl.UnmarshalCborMode(bufio.NewReader(serv.CountingReader(body)), serv.DecodeMode()); err != nil {
+		log.WithFields(log.Fields{
+			"cla":    serv,
+			"remote": r.RemoteAddr,
+			"error":  err,
+		}).Warn("HTTPServer failed to read bundle")
+
+		if body.n >= serv.maxBodySize {
+			http.Error(w, "bundle exceeds the maximum size", http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, fmt.Sprintf("failed to read bundle: %v", err), http.StatusBadRequest)
+		}
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"cla":    serv,
+		"remote": r.RemoteAddr,
+		"bundle": bndl.ID(),
+	}).Debug("HTTPServer received a bundle")
+
+	serv.closeMutex.RLock()
+	defer serv.closeMutex.RUnlock()
+
+	if serv.closed {
+		http.Error(w, "server is closed", http.StatusServiceUnavailable)
+		return
+	}
+
+	select {
+	case <-serv.stopSyn:
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+
+	case serv.reportChan <- cla.NewConvergenceReceivedBundle(serv, serv.endpointID, bndl):
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// bodyReader counts the bytes read from a request's body to detect an exceeded http.MaxBytesReader.
+type bodyReader struct {
+	r io.Reader
+	n int64
+}
+
+func (br *bodyReader) Read(p []byte) (n int, err error) {
+	n, err = br.r.Read(p)
+	br.n += int64(n)
+	return
+}
+
+func (serv *HTTPServer) Channel() chan cla.ConvergenceStatus {
+	return serv.reportChan
+}
+
+func (serv *HTTPServer) Close() (err error) {
+	close(serv.stopSyn)
+
+	if serv.httpServer != nil {
+		err = serv.httpServer.Shutdown(context.Background())
+	}
+
+	serv.closeMutex.Lock()
+	serv.closed = true
+	close(serv.reportChan)
+	serv.closeMutex.Unlock()
+
+	return
+}
+
+func (serv *HTTPServer) GetEndpointID() bpv7.EndpointID {
+	return serv.endpointID
+}
+
+func (serv *HTTPServer) Address() string {
+	if serv.tlsConfig != nil {
+		return fmt.Sprintf("https://%s", serv.listenAddress)
+	}
+	return fmt.Sprintf("http://%s", serv.listenAddress)
+}
+
+func (serv *HTTPServer) IsPermanent() bool {
+	return serv.permanent
+}
+
+func (serv *HTTPServer) String() string {
+	return serv.Address()
+}
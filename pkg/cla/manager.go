@@ -8,6 +8,8 @@
 package cla
 
 import (
+	"context"
+	"sort"
 	"sync"
 	"time"
 
@@ -24,8 +26,15 @@ type Manager struct {
 	// queueTtl is the amount of retries for a CLA.
 	queueTtl int32
 
-	// retryTime is the duration between two activation attempts.
-	retryTime time.Duration
+	// retryTick is the interval to check for deactivated CLAs, which are due for another activation attempt.
+	retryTick time.Duration
+
+	// backoff between two activation attempts of a failing CLA, compare SetRetryBackoff.
+	backoff      retryBackoff
+	backoffMutex sync.Mutex
+
+	// decodeMode is set for each registered DecodeModeSetter, compare SetDecodeMode.
+	decodeMode BundleDecoder
 
 	// convs maps each CLA's address to a wrapped convergenceElem struct.
 	// convs: Map[string]*convergenceElem
@@ -52,13 +61,19 @@ type Manager struct {
 	// after the Close method was called once.
 	stopFlag      bool
 	stopFlagMutex sync.Mutex
+
+	// retiredSent and retiredReceived keep the byte counts of unregistered ByteCounter CLAs, see TransferredBytes.
+	retiredSent     uint64
+	retiredReceived uint64
+	retiredMutex    sync.Mutex
 }
 
 // NewManager creates a new Manager to supervise different CLAs.
 func NewManager() *Manager {
 	manager := &Manager{
 		queueTtl:  10,
-		retryTime: 10 * time.Second,
+		retryTick: time.Second,
+		backoff:   retryBackoff{base: 10 * time.Second, max: 5 * time.Minute},
 
 		convs: new(sync.Map),
 
@@ -80,7 +95,7 @@ func NewManager() *Manager {
 
 // handler is the internal goroutine for management.
 func (manager *Manager) handler() {
-	activateTicker := time.NewTicker(manager.retryTime)
+	activateTicker := time.NewTicker(manager.retryTick)
 	defer activateTicker.Stop()
 
 	for {
@@ -132,7 +147,7 @@ func (manager *Manager) handler() {
 					return true
 				}
 
-				if successful, retry := ce.activate(); !successful && !retry {
+				if successful, retry := ce.activate(manager.retryBackoff()); !successful && !retry {
 					log.WithFields(log.Fields{
 						"cla": ce.conv,
 					}).Warn("Startup of CLA failed, a retry should not be made")
@@ -145,6 +160,50 @@ func (manager *Manager) handler() {
 	}
 }
 
+// SetRetryBackoff configures the delay between activation attempts of a failing CLA. After the first failure, the
+// next attempt is made after base. This delay is doubled for each further failure, up to max. A CLA which disappears
+// shortly after being started, i.e., within base, is handled like a failed one.
+func (manager *Manager) SetRetryBackoff(base, max time.Duration) {
+	if max < base {
+		max = base
+	}
+
+	manager.backoffMutex.Lock()
+	manager.backoff = retryBackoff{base: base, max: max}
+	manager.backoffMutex.Unlock()
+}
+
+// SetDecodeMode for received Bundles of all registered and future CLAs implementing the DecodeModeSetter.
+func (manager *Manager) SetDecodeMode(mode bpv7.DecodeMode) {
+	manager.decodeMode.SetDecodeMode(mode)
+
+	manager.convs.Range(func(_, convElem interface{}) bool {
+		manager.applyDecodeMode(convElem.(*convergenceElem).conv)
+		return true
+	})
+
+	manager.providersMutex.Lock()
+	for _, provider := range manager.providers {
+		manager.applyDecodeMode(provider)
+	}
+	manager.providersMutex.Unlock()
+}
+
+// applyDecodeMode sets the Manager's DecodeMode for a DecodeModeSetter.
+func (manager *Manager) applyDecodeMode(conv Convergable) {
+	if setter, ok := conv.(DecodeModeSetter); ok {
+		setter.SetDecodeMode(manager.decodeMode.DecodeMode())
+	}
+}
+
+// retryBackoff returns the currently configured retryBackoff.
+func (manager *Manager) retryBackoff() retryBackoff {
+	manager.backoffMutex.Lock()
+	defer manager.backoffMutex.Unlock()
+
+	return manager.backoff
+}
+
 // Channel references the outgoing channel for ConvergenceStatus messages.
 func (manager *Manager) Channel() chan ConvergenceStatus {
 	return manager.outChnl
@@ -160,9 +219,7 @@ func (manager *Manager) isStopped() bool {
 
 // Close the Manager and all supervised CLAs.
 func (manager *Manager) Close() error {
-	manager.stopFlagMutex.Lock()
-	manager.stopFlag = true
-	manager.stopFlagMutex.Unlock()
+	manager.setStopped()
 
 	close(manager.stopSyn)
 	<-manager.stopAck
@@ -170,12 +227,44 @@ func (manager *Manager) Close() error {
 	return nil
 }
 
+// setStopped prohibits acting on new CLAs, compare isStopped.
+func (manager *Manager) setStopped() {
+	manager.stopFlagMutex.Lock()
+	manager.stopFlag = true
+	manager.stopFlagMutex.Unlock()
+}
+
+// CloseGracefully closes all supervised CLAs implementing the GracefulCloser, until the context is done. Afterwards,
+// no new CLAs are accepted and the Manager itself must still be closed by Close.
+func (manager *Manager) CloseGracefully(ctx context.Context) {
+	manager.setStopped()
+
+	var wg sync.WaitGroup
+	manager.convs.Range(func(_, convElem interface{}) bool {
+		ce := convElem.(*convergenceElem)
+		if gc, ok := ce.conv.(GracefulCloser); ok && ce.isActive() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				if err := gc.CloseContext(ctx); err != nil {
+					log.WithField("cla", ce.conv).WithError(err).Warn("Closing CLA gracefully errored")
+				}
+			}()
+		}
+		return true
+	})
+	wg.Wait()
+}
+
 // Register any kind of Convergable.
 func (manager *Manager) Register(conv Convergable) {
 	if manager.isStopped() {
 		return
 	}
 
+	manager.applyDecodeMode(conv)
+
 	if c, ok := conv.(Convergence); ok {
 		manager.registerConvergence(c)
 	} else if c, ok := conv.(ConvergenceProvider); ok {
@@ -188,6 +277,7 @@ func (manager *Manager) Register(conv Convergable) {
 func (manager *Manager) registerConvergence(conv Convergence) {
 	// Check if this CLA is already known. Re-activate a deactivated CLA or abort.
 	var ce *convergenceElem
+	var created bool
 	if convElem, exists := manager.convs.Load(conv.Address()); exists {
 		ce = convElem.(*convergenceElem)
 		if ce.isActive() {
@@ -200,6 +290,7 @@ func (manager *Manager) registerConvergence(conv Convergence) {
 		}
 	} else {
 		ce = newConvergenceElement(conv, manager.inChnl, manager.queueTtl)
+		created = true
 	}
 
 	// Check if this CLA is a sender to a registered receiver.
@@ -216,13 +307,18 @@ func (manager *Manager) registerConvergence(conv Convergence) {
 		}
 	}
 
-	if successful, retry := ce.activate(); !successful && !retry {
+	if successful, retry := ce.activate(manager.retryBackoff()); !successful && !retry {
 		log.WithFields(log.Fields{
 			"cla":     conv,
 			"address": conv.Address(),
 		}).Warn("Startup of CLA  failed, a retry should not be made")
 	} else {
 		manager.convs.Store(conv.Address(), ce)
+
+		// A restarted CLA's counters continue, thus its previously retired counts are live again.
+		if created {
+			manager.resumeByteCounts(conv)
+		}
 	}
 }
 
@@ -281,6 +377,7 @@ func (manager *Manager) unregisterConvergence(conv Convergence) {
 
 	element.deactivate(manager.queueTtl)
 	manager.convs.Delete(conv.Address())
+	manager.retireByteCounts(conv)
 }
 
 func (manager *Manager) unregisterProvider(conv ConvergenceProvider) {
@@ -295,10 +392,52 @@ func (manager *Manager) unregisterProvider(conv ConvergenceProvider) {
 	}
 }
 
-// Restart a known Convergable.
+// Restart a known Convergable. A supervised Convergence stays registered and is started again directly, unless it
+// failed recently or was only active for a short time. Then it will be started after the backoff delay by the
+// Manager, compare SetRetryBackoff.
 func (manager *Manager) Restart(conv Convergable) {
-	manager.Unregister(conv)
-	manager.Register(conv)
+	c, ok := conv.(Convergence)
+	if !ok || manager.isStopped() {
+		manager.Unregister(conv)
+		manager.Register(conv)
+		return
+	}
+
+	convElem, exists := manager.convs.Load(c.Address())
+	if !exists || convElem.(*convergenceElem).conv != c {
+		manager.Unregister(conv)
+		manager.Register(conv)
+		return
+	}
+
+	ce := convElem.(*convergenceElem)
+	rb := manager.retryBackoff()
+
+	if ce.isActive() {
+		ce.deactivate(manager.queueTtl)
+
+		if ce.checkShortLived(rb) {
+			log.WithField("cla", c).Info("CLA was only active for a short time, delaying its restart")
+		}
+	}
+
+	if successful, retry := ce.activate(rb); !successful && !retry {
+		log.WithField("cla", c).Warn("Restart of CLA failed, a retry should not be made")
+
+		manager.convs.Delete(c.Address())
+		manager.retireByteCounts(c)
+	}
+}
+
+// States of all supervised CLAs, sorted by their address.
+func (manager *Manager) States() (states []ConvergenceState) {
+	manager.convs.Range(func(_, convElem interface{}) bool {
+		states = append(states, convElem.(*convergenceElem).state())
+		return true
+	})
+
+	sort.Slice(states, func(i, j int) bool { return states[i].Address < states[j].Address })
+	return
 }
 
 // Sender returns an array of all active ConvergenceSenders.
@@ -333,6 +472,52 @@ func (manager *Manager) Receiver() (crs []ConvergenceReceiver) {
 	return
 }
 
+// retireByteCounts of an unregistered CLA to keep them in TransferredBytes.
+func (manager *Manager) retireByteCounts(conv Convergence) {
+	if bc, ok := conv.(ByteCounter); ok {
+		manager.retiredMutex.Lock()
+		manager.retiredSent += bc.BytesSent()
+		manager.retiredReceived += bc.BytesReceived()
+		manager.retiredMutex.Unlock()
+	}
+}
+
+// resumeByteCounts of a (re-)registered CLA by removing its counts from the retired ones.
+func (manager *Manager) resumeByteCounts(conv Convergence) {
+	if bc, ok := conv.(ByteCounter); ok {
+		manager.retiredMutex.Lock()
+		manager.retiredSent = saturatingSub(manager.retiredSent, bc.BytesSent())
+		manager.retiredReceived = saturatingSub(manager.retiredReceived, bc.BytesReceived())
+		manager.retiredMutex.Unlock()
+	}
+}
+
+func saturatingSub(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
+
+// TransferredBytes sums up the bytes sent and received by all CLAs implementing the ByteCounter, including those
+// which were already unregistered.
+func (manager *Manager) TransferredBytes() (sent, received uint64) {
+	manager.convs.Range(func(_, convElem interface{}) bool {
+		if bc, ok := convElem.(*convergenceElem).conv.(ByteCounter); ok {
+			sent += bc.BytesSent()
+			received += bc.BytesReceived()
+		}
+		return true
+	})
+
+	manager.retiredMutex.Lock()
+	sent += manager.retiredSent
+	received += manager.retiredReceived
+	manager.retiredMutex.Unlock()
+
+	return
+}
+
 func (manager *Manager) RegisterEndpointID(claType CLAType, eid bpv7.EndpointID) {
 	clas, ok := manager.listenerIDs[claType]
 
@@ -355,6 +540,20 @@ func (manager *Manager) EndpointIDs(claType CLAType) []bpv7.EndpointID {
 	}
 }
 
+// HasEndpointID checks if exactly this EndpointID was registered for some CLA, compare RegisterEndpointID. In
+// contrast, HasEndpoint matches the EndpointID's authority.
+func (manager *Manager) HasEndpointID(eid bpv7.EndpointID) bool {
+	for _, clas := range manager.listenerIDs {
+		for _, adapter := range clas {
+			if adapter == eid {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func (manager *Manager) HasEndpoint(endpoint bpv7.EndpointID) bool {
 	for _, clas := range manager.listenerIDs {
 		for _, adapter := range clas {
@@ -7,6 +7,7 @@ package cla
 import (
 	"sync"
 	"sync/atomic"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -30,6 +31,12 @@ type convergenceElem struct {
 	// stop{Syn,Ack} are used to supervise closing this convergenceElem, see deactivate()
 	stopSyn chan struct{}
 	stopAck chan struct{}
+
+	// failures counts consecutive failed or short-lived activations, which delay the next attempt until retryAt.
+	// activeSince is the time of the last successful activation.
+	failures    int
+	retryAt     time.Time
+	activeSince time.Time
 }
 
 // newConvergenceElement creates a new convergenceElem for a Convergence with
@@ -89,8 +96,9 @@ func (ce *convergenceElem) handler() {
 }
 
 // activate tries to start this convergenceElem. Both a success message and an
-// indicator for a new attempt are returned.
-func (ce *convergenceElem) activate() (successful, retry bool) {
+// indicator for a new attempt are returned. After failures, a new attempt is
+// only made after the retryBackoff's delay.
+func (ce *convergenceElem) activate(rb retryBackoff) (successful, retry bool) {
 	if ce.isActive() {
 		return
 	}
@@ -98,6 +106,11 @@ func (ce *convergenceElem) activate() (successful, retry bool) {
 	ce.mutex.Lock()
 	defer ce.mutex.Unlock()
 
+	// Neither log this nor count it as a failure, as it happens for each tick of the Manager.
+	if time.Now().Before(ce.retryAt) {
+		return false, true
+	}
+
 	if atomic.LoadInt32(&ce.ttl) == 0 && !ce.conv.IsPermanent() {
 		log.WithFields(log.Fields{
 			"cla":   ce.conv,
@@ -114,6 +127,7 @@ func (ce *convergenceElem) activate() (successful, retry bool) {
 		}).Info("Started CLA")
 
 		atomic.StoreInt32(&ce.ttl, -1)
+		ce.activeSince = time.Now()
 
 		ce.stopSyn = make(chan struct{})
 		ce.stopAck = make(chan struct{})
@@ -131,6 +145,7 @@ func (ce *convergenceElem) activate() (successful, retry bool) {
 
 		if claRetry {
 			atomic.AddInt32(&ce.ttl, -1)
+			ce.scheduleRetry(rb)
 		} else {
 			atomic.StoreInt32(&ce.ttl, 0)
 		}
@@ -158,3 +173,39 @@ func (ce *convergenceElem) deactivate(ttl int32) {
 
 	atomic.StoreInt32(&ce.ttl, ttl)
 }
+
+// scheduleRetry delays the next activation after another failure. The caller must hold the mutex.
+func (ce *convergenceElem) scheduleRetry(rb retryBackoff) {
+	ce.failures++
+	ce.retryAt = time.Now().Add(rb.delay(ce.failures))
+}
+
+// checkShortLived must be called after deactivating this convergenceElem, e.g., for a disappeared peer. If it was
+// active for less than the retryBackoff's base delay, this is treated like a failure and true is returned. Otherwise,
+// the previous failures are forgotten.
+func (ce *convergenceElem) checkShortLived(rb retryBackoff) bool {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+
+	if time.Since(ce.activeSince) < rb.base {
+		ce.scheduleRetry(rb)
+		return true
+	}
+
+	ce.failures = 0
+	ce.retryAt = time.Time{}
+	return false
+}
+
+// state of this convergenceElem, compare Manager.States.
+func (ce *convergenceElem) state() ConvergenceState {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+
+	return ConvergenceState{
+		Address:  ce.conv.Address(),
+		Active:   ce.isActive(),
+		Failures: ce.failures,
+		RetryAt:  ce.retryAt,
+	}
+}
@@ -5,7 +5,9 @@
 package cla
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"reflect"
 	"sync"
 	"testing"
@@ -117,3 +119,149 @@ func TestManager(t *testing.T) {
 		}
 	}
 }
+
+// countingConvRec is a mockConvRec with a ByteCounter.
+type countingConvRec struct {
+	*mockConvRec
+	*ByteCounts
+}
+
+func TestManagerTransferredBytes(t *testing.T) {
+	var manager = NewManager()
+	defer func() { _ = manager.Close() }()
+
+	go func(ch chan ConvergenceStatus) {
+		for range ch {
+		}
+	}(manager.Channel())
+
+	conv := countingConvRec{
+		mockConvRec: newMockConvRec(true, "counting", bpv7.MustNewEndpointID("dtn://counting/")),
+		ByteCounts:  new(ByteCounts),
+	}
+	manager.Register(conv)
+
+	_, _ = conv.CountingWriter(ioutil.Discard).Write(make([]byte, 23))
+	_, _ = ioutil.ReadAll(conv.CountingReader(bytes.NewReader(make([]byte, 42))))
+
+	check := func(state string) {
+		if sent, received := manager.TransferredBytes(); sent != 23 || received != 42 {
+			t.Fatalf("%s: transferred bytes are %d sent and %d received", state, sent, received)
+		}
+	}
+
+	check("registered")
+
+	manager.Restart(conv)
+	check("restarted")
+
+	manager.Unregister(conv)
+	check("unregistered")
+}
+
+// decodingConvRec is a mockConvRec, supporting the DecodeModeSetter.
+type decodingConvRec struct {
+	*mockConvRec
+	*BundleDecoder
+}
+
+func TestManagerDecodeMode(t *testing.T) {
+	var manager = NewManager()
+	defer func() { _ = manager.Close() }()
+
+	go func(ch chan ConvergenceStatus) {
+		for range ch {
+		}
+	}(manager.Channel())
+
+	newConv := func(name string) decodingConvRec {
+		return decodingConvRec{
+			mockConvRec:   newMockConvRec(true, name, bpv7.MustNewEndpointID("dtn://"+name+"/")),
+			BundleDecoder: new(BundleDecoder),
+		}
+	}
+
+	early := newConv("early")
+	manager.Register(early)
+	if mode := early.DecodeMode(); mode != bpv7.StrictDecoding {
+		t.Fatalf("default decode mode is %v", mode)
+	}
+
+	manager.SetDecodeMode(bpv7.LenientDecoding)
+
+	late := newConv("late")
+	manager.Register(late)
+
+	for _, conv := range []decodingConvRec{early, late} {
+		if mode := conv.DecodeMode(); mode != bpv7.LenientDecoding {
+			t.Fatalf("CLA %s has decode mode %v, expected %v", conv.address, mode, bpv7.LenientDecoding)
+		}
+	}
+}
+
+func TestRetryBackoffDelay(t *testing.T) {
+	rb := retryBackoff{base: time.Second, max: 5 * time.Second}
+
+	tests := []struct {
+		failures int
+		delay    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 5 * time.Second},
+		{100, 5 * time.Second},
+	}
+
+	for _, test := range tests {
+		if delay := rb.delay(test.failures); delay != test.delay {
+			t.Fatalf("delay after %d failures is %v, expected %v", test.failures, delay, test.delay)
+		}
+	}
+}
+
+func TestManagerRetryBackoff(t *testing.T) {
+	var manager = NewManager()
+	defer func() { _ = manager.Close() }()
+
+	go func(ch chan ConvergenceStatus) {
+		for range ch {
+		}
+	}(manager.Channel())
+
+	manager.SetRetryBackoff(time.Minute, time.Hour)
+
+	checkState := func(address string, active bool, failures int) {
+		for _, state := range manager.States() {
+			if state.Address != address {
+				continue
+			}
+
+			if state.Active != active || state.Failures != failures {
+				t.Fatalf("state of %s is %v, expected active = %t and %d failures", address, state, active, failures)
+			} else if !active && time.Until(state.RetryAt) < 59*time.Second {
+				t.Fatalf("state of %s has no delayed retry: %v", address, state)
+			}
+			return
+		}
+		t.Fatalf("no state for %s", address)
+	}
+
+	// A failing CLA must not be restarted before its backoff delay has passed.
+	failing := newMockConvSender(false, "mock://failing/", bpv7.MustNewEndpointID("dtn://failing/"))
+	manager.Register(failing)
+	checkState("mock://failing/", false, 1)
+
+	for i := 0; i < 10; i++ {
+		manager.Restart(failing)
+	}
+	checkState("mock://failing/", false, 1)
+
+	// A CLA disappearing right after its start is handled like a failing one.
+	flapping := newMockConvSender(true, "mock://flapping/", bpv7.MustNewEndpointID("dtn://flapping/"))
+	manager.Register(flapping)
+	checkState("mock://flapping/", true, 0)
+
+	manager.Restart(flapping)
+	checkState("mock://flapping/", false, 1)
+}
@@ -11,6 +11,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"net"
 	"sync"
 	"time"
@@ -28,6 +29,7 @@ import (
 // a ConvergenceSender.
 type MTCPClient struct {
 	conn       net.Conn
+	writer     io.Writer
 	peer       bpv7.EndpointID
 	mutex      sync.Mutex
 	reportChan chan cla.ConvergenceStatus
@@ -37,6 +39,8 @@ type MTCPClient struct {
 
 	stopSyn chan struct{}
 	stopAck chan struct{}
+
+	*cla.ByteCounts
 }
 
 // NewMTCPClient creates a new MTCPClient, connected to the given address for
@@ -47,6 +51,8 @@ func NewMTCPClient(address string, peer bpv7.EndpointID, permanent bool) *MTCPCl
 		peer:      peer,
 		permanent: permanent,
 		address:   address,
+
+		ByteCounts: new(cla.ByteCounts),
 	}
 }
 
@@ -71,6 +77,7 @@ func (client *MTCPClient) Start() (err error, retry bool) {
 	client.stopAck = make(chan struct{})
 
 	client.conn = conn
+	client.writer = client.CountingWriter(conn)
 
 	go client.handler()
 	return
@@ -95,7 +102,7 @@ func (client *MTCPClient) handler() {
 
 		case <-ticker.C:
 			client.mutex.Lock()
-			err := cboring.WriteByteStringLen(0, client.conn)
+			err := cboring.WriteByteStringLen(0, client.writer)
 			client.mutex.Unlock()
 
 			if err != nil {
@@ -126,7 +133,7 @@ func (client *MTCPClient) Send(bndl bpv7.Bundle) (err error) {
 	client.mutex.Lock()
 	defer client.mutex.Unlock()
 
-	connWriter := bufio.NewWriter(client.conn)
+	connWriter := bufio.NewWriter(client.writer)
 
 	buff := new(bytes.Buffer)
 	if cborErr := cboring.Marshal(&bndl, buff); cborErr != nil {
@@ -150,7 +157,7 @@ func (client *MTCPClient) Send(bndl bpv7.Bundle) (err error) {
 	}
 
 	// Check if the connection is still alive with an empty, unbuffered packet
-	if probeErr := cboring.WriteByteStringLen(0, client.conn); probeErr != nil {
+	if probeErr := cboring.WriteByteStringLen(0, client.writer); probeErr != nil {
 		err = probeErr
 		return
 	}
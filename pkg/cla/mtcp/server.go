@@ -30,6 +30,9 @@ type MTCPServer struct {
 
 	stopSyn chan struct{}
 	stopAck chan struct{}
+
+	*cla.ByteCounts
+	cla.BundleDecoder
 }
 
 // NewMTCPServer creates a new MTCPServer for the given listen address. The
@@ -43,6 +46,8 @@ func NewMTCPServer(listenAddress string, endpointID bpv7.EndpointID, permanent b
 		permanent:     permanent,
 		stopSyn:       make(chan struct{}),
 		stopAck:       make(chan struct{}),
+
+		ByteCounts: new(cla.ByteCounts),
 	}
 }
 
@@ -103,7 +108,7 @@ func (serv *MTCPServer) handleSender(conn net.Conn) {
 		"conn": conn,
 	}).Debug("MTCP handleServer connection was established")
 
-	connReader := bufio.NewReader(conn)
+	connReader := bufio.NewReader(serv.CountingReader(conn))
 	for {
 		if n, err := cboring.ReadByteStringLen(connReader); err != nil {
 			if err != io.EOF {
@@ -124,7 +129,7 @@ func (serv *MTCPServer) handleSender(conn net.Conn) {
 		}
 
 		bndl := new(bpv7.Bundle)
-		if err := cboring.Unmarshal(bndl, connReader); err != nil {
+		if err := bndl.UnmarshalCborMode(connReader, serv.DecodeMode()); err != nil {
 			log.WithFields(log.Fields{
 				"cla":   serv,
 				"conn":  conn,
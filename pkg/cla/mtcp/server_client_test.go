@@ -5,12 +5,15 @@
 package mtcp
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"reflect"
 	"sync"
 	"testing"
 
+	"github.com/dtn7/cboring"
+
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla"
 )
@@ -129,3 +132,76 @@ func TestMTCPServerClient(t *testing.T) {
 		t.Fatalf("Counter is not zero: %d", c.(int))
 	}
 }
+
+func TestMTCPByteCounter(t *testing.T) {
+	port := getRandomPort(t)
+
+	serv := NewMTCPServer(fmt.Sprintf(":%d", port), bpv7.MustNewEndpointID("dtn://mtcpcla/"), false)
+	if err, _ := serv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = serv.Close() }()
+
+	received := make(chan struct{}, 10)
+	go func() {
+		for cs := range serv.Channel() {
+			if cs.MessageType == cla.ReceivedBundle {
+				received <- struct{}{}
+			}
+		}
+	}()
+
+	client := NewAnonymousMTCPClient(fmt.Sprintf("localhost:%d", port), false)
+	if err, _ := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for range client.Channel() {
+		}
+	}()
+	defer func() { _ = client.Close() }()
+
+	var expected uint64
+	for i := 0; i < 5; i++ {
+		bndl, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dest/").
+			CreationTimestampNow().
+			Lifetime("60s").
+			PayloadBlock(make([]byte, 100*(i+1))).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		buff := new(bytes.Buffer)
+		if err := bndl.MarshalCbor(buff); err != nil {
+			t.Fatal(err)
+		}
+		hdr := new(bytes.Buffer)
+		if err := cboring.WriteByteStringLen(uint64(buff.Len()), hdr); err != nil {
+			t.Fatal(err)
+		}
+
+		// Byte string header, bundle, and the empty probe's one byte.
+		expected += uint64(hdr.Len()+buff.Len()) + 1
+
+		if err := client.Send(bndl); err != nil {
+			t.Fatal(err)
+		}
+		<-received
+	}
+
+	// Allow one additional keepalive probe.
+	if sent := client.BytesSent(); sent < expected || sent > expected+1 {
+		t.Fatalf("client sent %d bytes, expected %d", sent, expected)
+	}
+	if client.BytesReceived() != 0 {
+		t.Fatalf("client received %d bytes", client.BytesReceived())
+	}
+
+	// The server might not have read the last probe yet.
+	if recv := serv.BytesReceived(); recv+1 < expected || recv > client.BytesSent() {
+		t.Fatalf("server received %d bytes, client sent %d bytes", recv, client.BytesSent())
+	}
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package cla
+
+import "fmt"
+
+// RefusalKind classifies a peer's refusal of a bundle transmission.
+type RefusalKind uint
+
+const (
+	// RefusalDuplicate indicates that the peer already has this bundle.
+	RefusalDuplicate RefusalKind = iota
+
+	// RefusalTemporary indicates that the peer cannot accept the bundle right now, e.g., due to exhausted resources.
+	// The transmission might be retried later.
+	RefusalTemporary
+
+	// RefusalPermanent indicates that the peer will not accept this bundle.
+	RefusalPermanent
+)
+
+func (rk RefusalKind) String() string {
+	switch rk {
+	case RefusalDuplicate:
+		return "duplicate"
+	case RefusalTemporary:
+		return "temporary"
+	case RefusalPermanent:
+		return "permanent"
+	default:
+		return "unknown"
+	}
+}
+
+// RefusalError is returned by a ConvergenceSender's Send method if the peer refused the bundle. This allows the
+// caller to react based on the RefusalKind.
+type RefusalError struct {
+	Kind   RefusalKind
+	Reason string
+}
+
+// NewRefusalError for a RefusalKind and a CLA-specific reason.
+func NewRefusalError(kind RefusalKind, reason string) *RefusalError {
+	return &RefusalError{Kind: kind, Reason: reason}
+}
+
+func (re *RefusalError) Error() string {
+	return fmt.Sprintf("peer refused bundle (%v): %s", re.Kind, re.Reason)
+}
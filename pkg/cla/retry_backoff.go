@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package cla
+
+import "time"
+
+// retryBackoff defines the exponentially increasing delay between activation attempts of a failing CLA.
+type retryBackoff struct {
+	base time.Duration
+	max  time.Duration
+}
+
+// delay before the next attempt after the given number of consecutive failures. Starting with base, the delay is
+// doubled for each further failure, but never exceeds max.
+func (rb retryBackoff) delay(failures int) time.Duration {
+	d := rb.base
+	for i := 1; i < failures && d < rb.max; i++ {
+		d *= 2
+	}
+
+	if d > rb.max {
+		d = rb.max
+	}
+	return d
+}
+
+// ConvergenceState describes the connection state of a CLA, supervised by a Manager.
+type ConvergenceState struct {
+	// Address of the CLA, compare Convergence.Address.
+	Address string
+
+	// Active is true for a successfully started CLA.
+	Active bool
+
+	// Failures counts consecutive failed or short-lived activations. The next attempt will not be made before RetryAt.
+	Failures int
+	RetryAt  time.Time
+}
@@ -5,10 +5,13 @@
 package tcpclv4
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -31,6 +34,11 @@ type Client struct {
 
 	customStartFunc func(*Client) error
 
+	maxTransfers int
+
+	*cla.ByteCounts
+	cla.BundleDecoder
+
 	started    bool
 	connCloser io.Closer
 
@@ -38,13 +46,23 @@ type Client struct {
 	stageHandler    *stages.StageHandler
 	transferManager *utils.TransferManager
 
+	// resumption tracks interrupted outgoing transfers across an active peer's sessions.
+	resumption *utils.TransferResumption
+
 	nodeId     bpv7.EndpointID
 	peerNodeId bpv7.EndpointID
 
+	// transferMtu is the negotiated transfer MRU, compare MTU.
+	transferMtu uint64
+
 	reportChan chan cla.ConvergenceStatus
 
 	closeChanSyn chan struct{}
 	closeChanAck chan struct{}
+	closeOnce    *sync.Once
+
+	terminateSyn  chan struct{}
+	terminateOnce *sync.Once
 }
 
 func (client *Client) String() string {
@@ -68,6 +86,12 @@ func (client *Client) log() *log.Entry {
 	return log.WithField("cla", client.String())
 }
 
+// SetMaxTransfers limits the amount of concurrent outgoing transfers within this session. Further transfers wait
+// until a slot frees. A zero value disables this limit, which is the default. This must be set before starting.
+func (client *Client) SetMaxTransfers(n int) {
+	client.maxTransfers = n
+}
+
 // Start this Client and return both an error and a boolean indicating if another Start should be tried later.
 func (client *Client) Start() (err error, retry bool) {
 	if client.started {
@@ -86,6 +110,10 @@ func (client *Client) Start() (err error, retry bool) {
 
 	client.closeChanSyn = make(chan struct{})
 	client.closeChanAck = make(chan struct{})
+	client.closeOnce = new(sync.Once)
+
+	client.terminateSyn = make(chan struct{})
+	client.terminateOnce = new(sync.Once)
 
 	if client.messageSwitch == nil {
 		if err = client.customStartFunc(client); err != nil {
@@ -121,6 +149,7 @@ func (client *Client) Start() (err error, retry bool) {
 			},
 			PostHook: func(_ *stages.StageHandler, state *stages.State) error {
 				client.peerNodeId = state.PeerNodeId
+				client.transferMtu = state.TransferMtu
 				return nil
 			},
 		},
@@ -144,6 +173,14 @@ func (client *Client) Start() (err error, retry bool) {
 	case sMtu := <-sMtuChan:
 		stageHandlerIn, stageHandlerOut := client.stageHandler.Exchanges()
 		client.transferManager = utils.NewTransferManager(stageHandlerIn, stageHandlerOut, sMtu)
+		client.transferManager.SetMaxTransfers(client.maxTransfers)
+		client.transferManager.SetTransferMru(conf.TransferMru)
+		client.transferManager.SetDecodeMode(client.DecodeMode())
+
+		if client.resumption == nil {
+			client.resumption = utils.NewTransferResumption()
+		}
+		client.transferManager.SetResumption(client.resumption)
 	}
 
 	client.log().Info("Started TCPCLv4")
@@ -158,10 +195,17 @@ func (client *Client) handle() {
 	_, _, messageSwitchErr := client.messageSwitch.Exchange()
 	stageHandlerErr := client.stageHandler.Error()
 	incomingBundles, transferManagerErr := client.transferManager.Exchange()
+	terminateSyn := client.terminateSyn
 
 	defer func() {
 		client.log().Info("Closing down TCPCLv4")
 
+		for _, b := range client.transferManager.InterruptedBundles() {
+			b := b
+			client.log().WithField("bundle", b).Info("Received fragment of an interrupted transfer")
+			client.reportChan <- cla.NewConvergenceReceivedBundle(client, client.nodeId, &b)
+		}
+
 		client.reportChan <- cla.NewConvergencePeerDisappeared(client, client.peerNodeId)
 
 		closeErrFuncs := []func() error{
@@ -200,6 +244,11 @@ func (client *Client) handle() {
 			client.log().Debug("Received close signal")
 			return
 
+		case <-terminateSyn:
+			client.log().Debug("Received termination signal, awaiting SESS_TERM reply")
+			client.stageHandler.Terminate()
+			terminateSyn = nil
+
 		case err = <-messageSwitchErr:
 		case err = <-stageHandlerErr:
 		case err = <-transferManagerErr:
@@ -208,6 +257,8 @@ func (client *Client) handle() {
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				client.log().Info("Received EOF")
+			} else if errors.Is(err, stages.StageClose) {
+				client.log().Info("Session was terminated")
 			} else {
 				client.log().WithError(err).Error("Error occurred")
 			}
@@ -218,20 +269,60 @@ func (client *Client) handle() {
 
 // Send a bundle to this Client's endpoint.
 func (client *Client) Send(b bpv7.Bundle) error {
+	return client.SendContext(context.Background(), b)
+}
+
+// SendContext sends a bundle to this Client's endpoint until the context is done, compare cla.ContextSender.
+func (client *Client) SendContext(ctx context.Context, b bpv7.Bundle) error {
 	client.log().WithField("bundle", b).Debug("Sending Bundle...")
-	defer client.log().WithField("bundle", b).Info("Sent Bundle")
 
-	return client.transferManager.Send(b)
+	if err := client.transferManager.SendContext(ctx, b); err != nil {
+		var abandoned *utils.AbandonedTransferError
+		if errors.As(err, &abandoned) {
+			// The peer is still waiting for the abandoned transfer's remaining segments, which will never arrive.
+			client.log().WithField("bundle", b).WithError(err).Warn("Closing session after abandoning a transfer")
+			client.closeOnce.Do(func() { close(client.closeChanSyn) })
+		}
+		return err
+	}
+
+	client.log().WithField("bundle", b).Info("Sent Bundle")
+	return nil
+}
+
+// MTU returns the negotiated transfer MRU, i.e., the maximum size of a bundle to be sent within this session. Larger
+// bundles will be fragmented. A transfer MRU exceeding an int32 is treated as no limit.
+func (client *Client) MTU() int {
+	if client.transferMtu > math.MaxInt32 {
+		return 0
+	}
+	return int(client.transferMtu)
 }
 
 // Close signals this Client to shut down.
 func (client *Client) Close() error {
-	close(client.closeChanSyn)
+	client.closeOnce.Do(func() { close(client.closeChanSyn) })
 	<-client.closeChanAck
 
 	return nil
 }
 
+// CloseContext terminates this Client's session gracefully by a SESS_TERM handshake, compare cla.GracefulCloser.
+// The session is closed after the peer's reply or, at the latest, when the context is done.
+func (client *Client) CloseContext(ctx context.Context) error {
+	client.terminateOnce.Do(func() { close(client.terminateSyn) })
+
+	var err error
+	select {
+	case <-client.closeChanAck:
+	case <-ctx.Done():
+		err = fmt.Errorf("session was not terminated gracefully: %w", ctx.Err())
+	}
+
+	_ = client.Close()
+	return err
+}
+
 // Channel represents a return channel for transmitted bundles, status messages, etc.
 func (client *Client) Channel() chan cla.ConvergenceStatus {
 	return client.reportChan
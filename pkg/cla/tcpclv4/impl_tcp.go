@@ -23,6 +23,7 @@ type TCPListener struct {
 	listenAddress string
 	endpointID    bpv7.EndpointID
 	manager       *cla.Manager
+	maxTransfers  int
 
 	stopSyn chan struct{}
 	stopAck chan struct{}
@@ -40,6 +41,12 @@ func ListenTCP(listenAddress string, endpointID bpv7.EndpointID) *TCPListener {
 	}
 }
 
+// SetMaxTransfers limits the amount of concurrent outgoing transfers for each accepted session, compare
+// Client.SetMaxTransfers. This must be set before starting.
+func (listener *TCPListener) SetMaxTransfers(n int) {
+	listener.maxTransfers = n
+}
+
 // RegisterManager tells the TCPListener where to report new instances of cla.Convergence to.
 func (listener *TCPListener) RegisterManager(manager *cla.Manager) {
 	listener.manager = manager
@@ -75,6 +82,7 @@ func (listener *TCPListener) Start() error {
 					_ = listener.Close()
 				} else if conn, err := ln.Accept(); err == nil {
 					client := newClientTCP(conn, listener.endpointID)
+					client.SetMaxTransfers(listener.maxTransfers)
 					listener.manager.Register(client)
 				}
 			}
@@ -102,7 +110,7 @@ func tcpClientStart(client *Client) error {
 		return connErr
 	} else {
 		client.connCloser = conn
-		client.messageSwitch = utils.NewMessageSwitchReaderWriter(conn, conn)
+		client.messageSwitch = utils.NewMessageSwitchReaderWriter(conn, conn, client.ByteCounts)
 
 		client.log().Debug("Dialed successfully")
 		return nil
@@ -111,13 +119,16 @@ func tcpClientStart(client *Client) error {
 
 // newClientTCP creates a new Client on an existing connection. This function is used from the TCPListener.
 func newClientTCP(conn net.Conn, endpointID bpv7.EndpointID) *Client {
+	counts := new(cla.ByteCounts)
+
 	return &Client{
 		address:         conn.RemoteAddr().String(),
 		activePeer:      false,
 		customStartFunc: tcpClientStart,
 		connCloser:      conn,
-		messageSwitch:   utils.NewMessageSwitchReaderWriter(conn, conn),
+		messageSwitch:   utils.NewMessageSwitchReaderWriter(conn, conn, counts),
 		nodeId:          endpointID,
+		ByteCounts:      counts,
 	}
 }
 
@@ -129,5 +140,6 @@ func DialTCP(address string, endpointID bpv7.EndpointID, permanent bool) *Client
 		activePeer:      true,
 		customStartFunc: tcpClientStart,
 		nodeId:          endpointID,
+		ByteCounts:      new(cla.ByteCounts),
 	}
 }
@@ -252,3 +252,98 @@ func TestImplNetwork(t *testing.T) {
 		})
 	}
 }
+
+func TestClientByteCounter(t *testing.T) {
+	tests := []struct {
+		protocol   string
+		mkListener func(string) cla.ConvergenceProvider
+		mkClient   func(string) *Client
+	}{
+		{
+			"TCP",
+			func(addr string) cla.ConvergenceProvider {
+				return ListenTCP(addr, bpv7.MustNewEndpointID("dtn://server/"))
+			},
+			func(addr string) *Client {
+				return DialTCP(addr, bpv7.MustNewEndpointID("dtn://client/"), false)
+			},
+		},
+		{
+			"WebSocket",
+			func(addr string) cla.ConvergenceProvider {
+				listener := ListenWebSocket(bpv7.MustNewEndpointID("dtn://server/"))
+
+				httpMux := http.NewServeMux()
+				httpMux.Handle("/tcpclv4", listener)
+				go func() { _ = http.ListenAndServe(addr, httpMux) }()
+
+				return listener
+			},
+			func(addr string) *Client {
+				return DialWebSocket("ws://"+addr+"/tcpclv4", bpv7.MustNewEndpointID("dtn://client/"), false)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.protocol, func(t *testing.T) {
+			serverAddr := fmt.Sprintf("localhost:%d", randomTcpPort(t))
+
+			manager := cla.NewManager()
+			defer func() { _ = manager.Close() }()
+
+			received := make(chan struct{}, 1)
+			go func() {
+				for cs := range manager.Channel() {
+					if cs.MessageType == cla.ReceivedBundle {
+						received <- struct{}{}
+					}
+				}
+			}()
+
+			manager.Register(test.mkListener(serverAddr))
+			time.Sleep(250 * time.Millisecond)
+
+			client := test.mkClient(serverAddr)
+			if err, _ := client.Start(); err != nil {
+				t.Fatal(err)
+			}
+			go func() {
+				for range client.Channel() {
+				}
+			}()
+			defer func() { _ = client.Close() }()
+
+			const payload = 65536
+			bndl, err := bpv7.Builder().
+				Source("dtn://client/").
+				Destination("dtn://server/").
+				CreationTimestampNow().
+				Lifetime("10m").
+				PayloadBlock(randomData(payload)).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			} else if err := client.Send(bndl); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case <-received:
+			case <-time.After(5 * time.Second):
+				t.Fatal("bundle was not received")
+			}
+
+			// Besides the bundle, the session's contact header and messages are counted.
+			if sent := client.BytesSent(); sent <= payload {
+				t.Fatalf("client counted %d bytes sent, expected more than %d", sent, payload)
+			} else if received := client.BytesReceived(); received == 0 {
+				t.Fatal("client counted no bytes received")
+			}
+
+			if _, received := manager.TransferredBytes(); received <= payload {
+				t.Fatalf("listener counted %d bytes received, expected more than %d", received, payload)
+			}
+		})
+	}
+}
@@ -21,7 +21,8 @@ import (
 //
 // This type implements the cla.ConvergenceProvider and should be supervised by a cla.Manager.
 type WebSocketListener struct {
-	endpointID bpv7.EndpointID
+	endpointID   bpv7.EndpointID
+	maxTransfers int
 
 	manager      *cla.Manager
 	managerReady uint32
@@ -37,6 +38,12 @@ func ListenWebSocket(endpointID bpv7.EndpointID) *WebSocketListener {
 	}
 }
 
+// SetMaxTransfers limits the amount of concurrent outgoing transfers for each accepted session, compare
+// Client.SetMaxTransfers. This must be set before serving.
+func (listener *WebSocketListener) SetMaxTransfers(n int) {
+	listener.maxTransfers = n
+}
+
 // RegisterManager tells the WebSocketListener where to report new instances of cla.Convergence to.
 func (listener *WebSocketListener) RegisterManager(manager *cla.Manager) {
 	listener.manager = manager
@@ -65,6 +72,7 @@ func (listener *WebSocketListener) ServeHTTP(writer http.ResponseWriter, request
 		log.WithField("cla", listener).WithError(err).Warn("Upgrading connection errored")
 	} else {
 		client := newClientWebSocket(conn, listener.endpointID)
+		client.SetMaxTransfers(listener.maxTransfers)
 		listener.manager.Register(client)
 	}
 }
@@ -75,7 +83,7 @@ func webSocketClientStart(client *Client) error {
 		return err
 	} else {
 		client.connCloser = conn
-		client.messageSwitch = utils.NewMessageSwitchWebSocket(conn)
+		client.messageSwitch = utils.NewMessageSwitchWebSocket(conn, client.ByteCounts)
 
 		client.log().Debug("Dialed successfully")
 		return nil
@@ -84,13 +92,16 @@ func webSocketClientStart(client *Client) error {
 
 // newClientWebSocket creates a new Client on a new *websocket.Conn. This function is called from the WebSocketListener.
 func newClientWebSocket(conn *websocket.Conn, endpointID bpv7.EndpointID) *Client {
+	counts := new(cla.ByteCounts)
+
 	return &Client{
 		address:         conn.RemoteAddr().String(),
 		activePeer:      false,
 		customStartFunc: webSocketClientStart,
 		connCloser:      conn,
-		messageSwitch:   utils.NewMessageSwitchWebSocket(conn),
+		messageSwitch:   utils.NewMessageSwitchWebSocket(conn, counts),
 		nodeId:          endpointID,
+		ByteCounts:      counts,
 	}
 }
 
@@ -102,5 +113,6 @@ func DialWebSocket(address string, endpointID bpv7.EndpointID, permanent bool) *
 		activePeer:      true,
 		customStartFunc: webSocketClientStart,
 		nodeId:          endpointID,
+		ByteCounts:      new(cla.ByteCounts),
 	}
 }
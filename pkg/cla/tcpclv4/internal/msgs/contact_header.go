@@ -29,29 +29,34 @@ func (cf ContactFlags) String() string {
 	return strings.Join(flags, ",")
 }
 
+// ContactHeaderVersion is the TCPCL version, sent within each ContactHeader.
+const ContactHeaderVersion uint8 = 4
+
 // ContactHeader will be exchanged at first after a TCP connection was
 // established. Both entities are sending a ContactHeader and are validating
 // the peer's one.
 type ContactHeader struct {
-	Flags ContactFlags
+	Version uint8
+	Flags   ContactFlags
 }
 
-// contactHeaderHead represents both the magic "dtn!" and the version field (4).
-var contactHeaderHead = []byte{0x64, 0x74, 0x6E, 0x21, 0x04}
+// contactHeaderMagic represents the magic "dtn!", starting each ContactHeader.
+var contactHeaderMagic = []byte{0x64, 0x74, 0x6E, 0x21}
 
 // NewContactHeader creates a new ContactHeader with given ContactFlags.
 func NewContactHeader(flags ContactFlags) *ContactHeader {
 	return &ContactHeader{
-		Flags: flags,
+		Version: ContactHeaderVersion,
+		Flags:   flags,
 	}
 }
 
 func (ch ContactHeader) String() string {
-	return fmt.Sprintf("ContactHeader(Version=4, Flags=%v)", ch.Flags)
+	return fmt.Sprintf("ContactHeader(Version=%d, Flags=%v)", ch.Version, ch.Flags)
 }
 
 func (ch ContactHeader) Marshal(w io.Writer) error {
-	var data = append(contactHeaderHead, byte(ch.Flags))
+	var data = append(append([]byte{}, contactHeaderMagic...), ch.Version, byte(ch.Flags))
 
 	if n, err := w.Write(data); err != nil {
 		return err
@@ -62,6 +67,8 @@ func (ch ContactHeader) Marshal(w io.Writer) error {
 	return nil
 }
 
+// Unmarshal a ContactHeader. Only its magic is checked. Thus, a ContactHeader of another version will be read to be
+// validated by the contact exchange.
 func (ch *ContactHeader) Unmarshal(r io.Reader) error {
 	var data = make([]byte, 6)
 
@@ -69,10 +76,11 @@ func (ch *ContactHeader) Unmarshal(r io.Reader) error {
 		return err
 	}
 
-	if head := data[:5]; !bytes.Equal(head, contactHeaderHead) {
-		return fmt.Errorf("ContactHeader's magic and version does not match: %x != %x", head, contactHeaderHead)
+	if magic := data[:4]; !bytes.Equal(magic, contactHeaderMagic) {
+		return fmt.Errorf("ContactHeader's magic does not match: %x != %x", magic, contactHeaderMagic)
 	}
 
+	ch.Version = data[4]
 	ch.Flags = ContactFlags(data[5])
 
 	return nil
@@ -36,12 +36,12 @@ func TestContactHeaderUnmarshal(t *testing.T) {
 		valid         bool
 		contactHeader ContactHeader
 	}{
-		{[]byte{0x64, 0x74, 0x6E, 0x21, 0x04, 0x00}, true, ContactHeader{Flags: 0}},
-		{[]byte{0x64, 0x74, 0x6E, 0x21, 0x04, 0x01}, true, ContactHeader{Flags: ContactCanTls}},
+		{[]byte{0x64, 0x74, 0x6E, 0x21, 0x04, 0x00}, true, ContactHeader{Version: 4, Flags: 0}},
+		{[]byte{0x64, 0x74, 0x6E, 0x21, 0x04, 0x01}, true, ContactHeader{Version: 4, Flags: ContactCanTls}},
 		{[]byte{0x64, 0x74, 0x6E, 0x21, 0x04}, false, ContactHeader{}},
 		{[]byte{0x64, 0x74, 0x6E, 0x3F, 0x04, 0x00}, false, ContactHeader{}},
-		{[]byte{0x64, 0x74, 0x6E, 0x21, 0x23, 0x00}, false, ContactHeader{}},
-		{[]byte{0x64, 0x74, 0x6E, 0x21, 0x04, 0x23}, true, ContactHeader{Flags: 0x23}},
+		{[]byte{0x64, 0x74, 0x6E, 0x21, 0x23, 0x00}, true, ContactHeader{Version: 0x23, Flags: 0}},
+		{[]byte{0x64, 0x74, 0x6E, 0x21, 0x04, 0x23}, true, ContactHeader{Version: 4, Flags: 0x23}},
 	}
 
 	for _, test := range tests {
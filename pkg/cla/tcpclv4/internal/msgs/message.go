@@ -32,11 +32,20 @@ var messages = map[uint8]Message{
 	0x64: &ContactHeader{},
 }
 
-// NewMessage creates a new Message type for a given type code.
+// UnknownMessageError is returned for an unknown Message Header's type code, which should be answered by a MSG_REJECT.
+type UnknownMessageError struct {
+	TypeCode uint8
+}
+
+func (ume *UnknownMessageError) Error() string {
+	return fmt.Sprintf("no TCPCLv4 Message registered for type code %x", ume.TypeCode)
+}
+
+// NewMessage creates a new Message type for a given type code. An unknown type code results in an UnknownMessageError.
 func NewMessage(typeCode uint8) (msg Message, err error) {
 	msgType, exists := messages[typeCode]
 	if !exists {
-		err = fmt.Errorf("no TCPCLv4 Message registered for type code %x", typeCode)
+		err = &UnknownMessageError{TypeCode: typeCode}
 		return
 	}
 
@@ -57,6 +57,11 @@ type MessageRejectionMessage struct {
 	MessageHeader uint8
 }
 
+// NewUnknownMessageRejection creates a MessageRejectionMessage for the type code of an UnknownMessageError.
+func NewUnknownMessageRejection(ume *UnknownMessageError) *MessageRejectionMessage {
+	return NewMessageRejectionMessage(RejectionTypeUnknown, ume.TypeCode)
+}
+
 // NewMessageRejectionMessage creates a new MessageRejectionMessage with given fields.
 func NewMessageRejectionMessage(reasonCode MessageRejectionReason, messageHeader uint8) *MessageRejectionMessage {
 	return &MessageRejectionMessage{
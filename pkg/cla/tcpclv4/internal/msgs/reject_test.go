@@ -41,3 +41,43 @@ func TestMessageRejectionMessage(t *testing.T) {
 		}
 	}
 }
+
+func TestMessageRejectionReason(t *testing.T) {
+	tests := []struct {
+		reason MessageRejectionReason
+		name   string
+		valid  bool
+	}{
+		{RejectionTypeUnknown, "Message Type Unknown", true},
+		{RejectionUnsupported, "Message Unsupported", true},
+		{RejectionUnexpected, "Message Unexpected", true},
+		{0x00, "INVALID", false},
+		{0xF0, "INVALID", false},
+	}
+
+	for _, test := range tests {
+		if name := test.reason.String(); name != test.name {
+			t.Fatalf("reason %x's name is %q, expected %q", uint8(test.reason), name, test.name)
+		} else if valid := test.reason.IsValid(); valid != test.valid {
+			t.Fatalf("reason %x's validity is %t, expected %t", uint8(test.reason), valid, test.valid)
+		} else if !test.valid {
+			continue
+		}
+
+		mrmOut := NewMessageRejectionMessage(test.reason, 0xEE)
+
+		var buf bytes.Buffer
+		if err := mrmOut.Marshal(&buf); err != nil {
+			t.Fatal(err)
+		} else if data := buf.Bytes(); !bytes.Equal(data, []byte{MSG_REJECT, uint8(test.reason), 0xEE}) {
+			t.Fatalf("%v was marshalled to %x", mrmOut, data)
+		}
+
+		mrmIn := new(MessageRejectionMessage)
+		if err := mrmIn.Unmarshal(&buf); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(mrmOut, mrmIn) {
+			t.Fatalf("MessageRejectionMessage changed from %v to %v", mrmOut, mrmIn)
+		}
+	}
+}
@@ -5,24 +5,78 @@
 package msgs
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 )
 
 // SESS_INIT is the Message Header code for a Session Initialization Message.
 const SESS_INIT uint8 = 0x07
 
+// SessionExtensionFlags are the one-octet flags of a Session Extension Item.
+type SessionExtensionFlags uint8
+
+const (
+	// SessionExtensionCritical indicates that the receiving peer must handle this Session Extension Item.
+	SessionExtensionCritical SessionExtensionFlags = 0x01
+)
+
+// SessionExtensionItem is a Session Extension Item, optionally sent within a SESS_INIT message.
+type SessionExtensionItem struct {
+	Flags SessionExtensionFlags
+	Type  uint16
+	Value []byte
+}
+
+// IsCritical checks if the receiving peer must handle this Session Extension Item.
+func (sei SessionExtensionItem) IsCritical() bool {
+	return sei.Flags&SessionExtensionCritical != 0
+}
+
+func (sei SessionExtensionItem) String() string {
+	return fmt.Sprintf("Session Extension Item(Flags=%x, Type=%d, Length=%d)", sei.Flags, sei.Type, len(sei.Value))
+}
+
+func (sei SessionExtensionItem) Marshal(w io.Writer) error {
+	if len(sei.Value) > math.MaxUint16 {
+		return fmt.Errorf("Session Extension Item's value of %d bytes exceeds its length field", len(sei.Value))
+	}
+
+	var fields = []interface{}{sei.Flags, sei.Type, uint16(len(sei.Value))}
+	for _, field := range fields {
+		if err := binary.Write(w, binary.BigEndian, field); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write(sei.Value)
+	return err
+}
+
+func (sei *SessionExtensionItem) Unmarshal(r io.Reader) error {
+	var valueLen uint16
+	var fields = []interface{}{&sei.Flags, &sei.Type, &valueLen}
+	for _, field := range fields {
+		if err := binary.Read(r, binary.BigEndian, field); err != nil {
+			return err
+		}
+	}
+
+	sei.Value = make([]byte, valueLen)
+	_, err := io.ReadFull(r, sei.Value)
+	return err
+}
+
 // SessionInitMessage is the SESS_INIT message to negotiate session parameters.
-//
-// Session Extension Items are not yet implemented.
 type SessionInitMessage struct {
 	KeepaliveInterval uint16
 	SegmentMru        uint64
 	TransferMru       uint64
 	NodeId            string
 
-	// TODO: Session Extension Items
+	SessionExtensions []SessionExtensionItem
 }
 
 // NewSessionInitMessage creates a new SessionInitMessage with given fields.
@@ -36,8 +90,8 @@ func NewSessionInitMessage(keepaliveInterval uint16, segmentMru, transferMru uin
 }
 
 func (si SessionInitMessage) String() string {
-	return fmt.Sprintf("SESS_INIT(Keepalive Interval=%d, Segment MRU=%d, Transfer MRU=%d, Node ID=%s)",
-		si.KeepaliveInterval, si.SegmentMru, si.TransferMru, si.NodeId)
+	return fmt.Sprintf("SESS_INIT(Keepalive Interval=%d, Segment MRU=%d, Transfer MRU=%d, Node ID=%s, Session Extension Items=%v)",
+		si.KeepaliveInterval, si.SegmentMru, si.TransferMru, si.NodeId, si.SessionExtensions)
 }
 
 func (si SessionInitMessage) Marshal(w io.Writer) error {
@@ -60,13 +114,18 @@ func (si SessionInitMessage) Marshal(w io.Writer) error {
 		return fmt.Errorf("SESS_INIT Node ID's length is %d, but only wrote %d bytes", len(si.NodeId), n)
 	}
 
-	// TODO: Session Extension Items
-	// Currently, only an empty Session Extension Items Length is accepted.
-	if err := binary.Write(w, binary.BigEndian, uint32(0)); err != nil {
-		return err
+	var extsBuff bytes.Buffer
+	for _, ext := range si.SessionExtensions {
+		if err := ext.Marshal(&extsBuff); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	if err := binary.Write(w, binary.BigEndian, uint32(extsBuff.Len())); err != nil {
+		return err
+	}
+	_, err := w.Write(extsBuff.Bytes())
+	return err
 }
 
 func (si *SessionInitMessage) Unmarshal(r io.Reader) error {
@@ -98,16 +157,27 @@ func (si *SessionInitMessage) Unmarshal(r io.Reader) error {
 		si.NodeId = string(nodeIdBuff)
 	}
 
-	// TODO: Session Extension Items, see above
+	si.SessionExtensions = nil
+
 	var sessionExtsLen uint32
 	if err := binary.Read(r, binary.BigEndian, &sessionExtsLen); err != nil {
 		return err
-	} else if sessionExtsLen > 0 {
-		sessionExtsBuff := make([]byte, sessionExtsLen)
+	} else if sessionExtsLen == 0 {
+		return nil
+	}
 
-		if _, err := io.ReadFull(r, sessionExtsBuff); err != nil {
-			return err
+	sessionExtsBuff := make([]byte, sessionExtsLen)
+	if _, err := io.ReadFull(r, sessionExtsBuff); err != nil {
+		return err
+	}
+
+	extsReader := bytes.NewReader(sessionExtsBuff)
+	for extsReader.Len() > 0 {
+		var ext SessionExtensionItem
+		if err := ext.Unmarshal(extsReader); err != nil {
+			return fmt.Errorf("SESS_INIT's Session Extension Items are malformed: %v", err)
 		}
+		si.SessionExtensions = append(si.SessionExtensions, ext)
 	}
 
 	return nil
@@ -110,11 +110,18 @@ func TestSessionInitMessage(t *testing.T) {
 		0x00, 0x00,
 		// Node ID Data: none
 		// Session Extension Item Length (u32):
-		0x00, 0x00, 0x00, 0x08,
+		0x00, 0x00, 0x00, 0x0F,
 		// Session Extension Items:
-		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		// Flags (u8), Type (u16), Length (u16), Value:
+		0x00, 0x00, 0x01, 0x00, 0x03, 0x66, 0x6f, 0x6f,
+		// Flags (u8), Type (u16), Length (u16), Value:
+		0x01, 0x00, 0x02, 0x00, 0x02, 0x23, 0x42,
+	}
+	t6session := NewSessionInitMessage(0, 0, 0, "")
+	t6session.SessionExtensions = []SessionExtensionItem{
+		{Flags: 0, Type: 1, Value: []byte("foo")},
+		{Flags: SessionExtensionCritical, Type: 2, Value: []byte{0x23, 0x42}},
 	}
-	t6session := new(SessionInitMessage)
 
 	t7data := []byte{
 		// Message Header:
@@ -134,6 +141,24 @@ func TestSessionInitMessage(t *testing.T) {
 		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
 	}
 
+	t8data := []byte{
+		// Message Header:
+		0x07,
+		// Keepalive Interval (u16):
+		0x00, 0x00,
+		// Segment MRU (u64):
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		// Transfer MRU (u64):
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		// Node ID Length (u16):
+		0x00, 0x00,
+		// Node ID Data: none
+		// Session Extension Item Length (u32):
+		0x00, 0x00, 0x00, 0x08,
+		// Session Extension Items, whose value exceeds the items' length:
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+	}
+
 	tests := []struct {
 		valid     bool
 		bijective bool
@@ -145,8 +170,9 @@ func TestSessionInitMessage(t *testing.T) {
 		{true, true, t3session, t3data},
 		{false, false, nil, t4data},
 		{false, false, nil, t5data},
-		{true, false, t6session, t6data},
+		{true, true, t6session, t6data},
 		{false, false, nil, t7data},
+		{false, false, nil, t8data},
 	}
 
 	for _, test := range tests {
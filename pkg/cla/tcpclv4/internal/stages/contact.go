@@ -1,4 +1,4 @@
-// SPDX-FileCopyrightText: 2020 Alvar Penning
+// SPDX-FileCopyrightText: 2020, 2021 Alvar Penning
 //
 // SPDX-License-Identifier: GPL-3.0-or-later
 
@@ -11,6 +11,9 @@ import (
 )
 
 // ContactStage models the initial ContactHeader exchange.
+//
+// Both peers are sending their ContactHeader simultaneously while reading the other one's. Afterwards, the received
+// ContactHeader is validated. On a mismatch, the session will be terminated.
 type ContactStage struct {
 	state     *State
 	closeChan <-chan struct{}
@@ -21,47 +24,66 @@ func (cs *ContactStage) Handle(state *State, closeChan <-chan struct{}) {
 	cs.state = state
 	cs.closeChan = closeChan
 
-	if cs.state.Configuration.ActivePeer {
-		cs.handleActive()
-	} else {
-		cs.handlePassive()
-	}
-}
-
-func (cs *ContactStage) handleActive() {
-	cs.state.MsgOut <- msgs.NewContactHeader(cs.state.Configuration.ContactFlags)
-
-	if ch, err := cs.receiveMsgOrClose(); err != nil {
+	ch, err := cs.exchange()
+	if err != nil {
 		cs.state.StageError = err
-	} else {
-		cs.state.ContactFlags = ch.Flags
+		return
 	}
-}
 
-func (cs *ContactStage) handlePassive() {
-	if ch, err := cs.receiveMsgOrClose(); err != nil {
+	if err := cs.validate(ch); err != nil {
+		cs.terminate(msgs.TerminationContactFailure)
 		cs.state.StageError = err
 		return
-	} else {
-		cs.state.ContactFlags = ch.Flags
 	}
 
-	cs.state.MsgOut <- msgs.NewContactHeader(cs.state.Configuration.ContactFlags)
+	cs.state.ContactFlags = ch.Flags
+	cs.state.UseTls = cs.state.Configuration.ContactFlags&msgs.ContactCanTls != 0 && ch.Flags&msgs.ContactCanTls != 0
 }
 
-func (cs *ContactStage) receiveMsgOrClose() (ch *msgs.ContactHeader, err error) {
-	select {
-	case <-cs.closeChan:
-		err = StageClose
-		return
+// exchange sends the own ContactHeader and receives the peer's one at the same time.
+func (cs *ContactStage) exchange() (ch *msgs.ContactHeader, err error) {
+	var (
+		chOut  = msgs.NewContactHeader(cs.state.Configuration.ContactFlags)
+		msgOut = cs.state.MsgOut
+		msgIn  = cs.state.MsgIn
+	)
+
+	for msgOut != nil || msgIn != nil {
+		select {
+		case <-cs.closeChan:
+			err = StageClose
+			return
 
-	case msg := <-cs.state.MsgIn:
-		var ok bool
-		if ch, ok = msg.(*msgs.ContactHeader); !ok {
-			err = fmt.Errorf("received message has invalid type %T", msg)
-			ch = nil
+		case msgOut <- chOut:
+			msgOut = nil
+
+		case msg := <-msgIn:
+			msgIn = nil
+
+			var ok bool
+			if ch, ok = msg.(*msgs.ContactHeader); !ok {
+				err = fmt.Errorf("received message has invalid type %T", msg)
+				ch = nil
+				return
+			}
 		}
+	}
 
-		return
+	return
+}
+
+// validate the peer's ContactHeader.
+func (cs *ContactStage) validate(ch *msgs.ContactHeader) error {
+	if ch.Version != msgs.ContactHeaderVersion {
+		return fmt.Errorf("received ContactHeader has version %d, expected %d", ch.Version, msgs.ContactHeaderVersion)
+	}
+	return nil
+}
+
+// terminate the session by sending a SESS_TERM, unless the stage is being closed.
+func (cs *ContactStage) terminate(reason msgs.SessionTerminationCode) {
+	select {
+	case <-cs.closeChan:
+	case cs.state.MsgOut <- msgs.NewSessionTerminationMessage(0, reason):
 	}
 }
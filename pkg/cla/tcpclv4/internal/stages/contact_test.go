@@ -63,4 +63,79 @@ func TestContactStage(t *testing.T) {
 	if cf := passiveState.ContactFlags; cf != msgs.ContactCanTls {
 		t.Fatalf("passive state's contact flags are %v", cf)
 	}
+
+	if activeState.UseTls || passiveState.UseTls {
+		t.Fatal("TLS was negotiated, but only one peer is capable of TLS")
+	}
+}
+
+func TestContactStageVersion(t *testing.T) {
+	tests := []struct {
+		version uint8
+		valid   bool
+	}{
+		{msgs.ContactHeaderVersion, true},
+		{3, false},
+		{5, false},
+	}
+
+	for _, test := range tests {
+		msgIn := make(chan msgs.Message)
+		msgOut := make(chan msgs.Message)
+
+		contact := &ContactStage{}
+		state := &State{
+			Configuration: Configuration{
+				ActivePeer:   true,
+				ContactFlags: msgs.ContactCanTls,
+			},
+			MsgIn:  msgIn,
+			MsgOut: msgOut,
+		}
+		closeChan := make(chan struct{})
+
+		finChan := make(chan struct{})
+		go func() { contact.Handle(state, closeChan); close(finChan) }()
+
+		// Act as the peer, sending its ContactHeader before reading the other one.
+		peerCh := msgs.NewContactHeader(msgs.ContactCanTls)
+		peerCh.Version = test.version
+		msgIn <- peerCh
+
+		select {
+		case msg := <-msgOut:
+			if ch, ok := msg.(*msgs.ContactHeader); !ok {
+				t.Fatalf("expected ContactHeader, got %T", msg)
+			} else if ch.Version != msgs.ContactHeaderVersion {
+				t.Fatalf("sent ContactHeader has version %d", ch.Version)
+			}
+		case <-time.After(250 * time.Millisecond):
+			t.Fatal("timeout")
+		}
+
+		if !test.valid {
+			select {
+			case msg := <-msgOut:
+				if sessTerm, ok := msg.(*msgs.SessionTerminationMessage); !ok {
+					t.Fatalf("expected SessionTerminationMessage, got %T", msg)
+				} else if sessTerm.ReasonCode != msgs.TerminationContactFailure {
+					t.Fatalf("session was terminated with reason %v", sessTerm.ReasonCode)
+				}
+			case <-time.After(250 * time.Millisecond):
+				t.Fatal("timeout")
+			}
+		}
+
+		select {
+		case <-finChan:
+		case <-time.After(250 * time.Millisecond):
+			t.Fatal("timeout")
+		}
+
+		if err := state.StageError; (err == nil) != test.valid {
+			t.Fatalf("version %d: expected valid %t, got error %v", test.version, test.valid, err)
+		} else if test.valid && !state.UseTls {
+			t.Fatal("TLS was not negotiated, but both peers are capable of TLS")
+		}
+	}
 }
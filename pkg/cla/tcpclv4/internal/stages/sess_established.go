@@ -23,6 +23,9 @@ type SessEstablishedStage struct {
 	lastReceive time.Time
 	lastSend    time.Time
 
+	// terminating is set after sending a SESS_TERM, while awaiting the peer's reply.
+	terminating bool
+
 	keepalive *utils.KeepaliveTicker
 }
 
@@ -41,20 +44,33 @@ func (se *SessEstablishedStage) Handle(state *State, closeChan <-chan struct{})
 	}
 	defer se.keepalive.Stop()
 
+	terminateChan := se.state.TerminateChan
+
 	for {
 		var err error
 
 		select {
 		case <-se.closeChan:
 			err = StageClose
-			_ = se.messageOut(msgs.NewSessionTerminationMessage(0, msgs.TerminationUnknown))
+			if !se.terminating {
+				_ = se.messageOut(msgs.NewSessionTerminationMessage(0, msgs.TerminationUnknown))
+			}
+
+		case <-terminateChan:
+			// Only request the termination once; the session continues until the peer's reply.
+			terminateChan = nil
+			se.terminating = true
+			err = se.messageOut(msgs.NewSessionTerminationMessage(0, msgs.TerminationUnknown))
 
 		case <-se.keepalive.C:
 			err = se.handleKeepalive()
 
 		case msg := <-se.state.MsgIn:
 			if err = se.handleMsgIn(msg); errors.Is(err, sessTermRecv) {
-				_ = se.messageOut(msgs.NewSessionTerminationMessage(msgs.TerminationReply, msgs.TerminationUnknown))
+				// Reply to a SESS_TERM, unless it is the reply to our own one.
+				if term := msg.(*msgs.SessionTerminationMessage); !se.terminating || term.Flags&msgs.TerminationReply == 0 {
+					_ = se.messageOut(msgs.NewSessionTerminationMessage(msgs.TerminationReply, msgs.TerminationUnknown))
+				}
 				err = StageClose
 			}
 
@@ -80,17 +96,19 @@ func (se *SessEstablishedStage) messageOut(msg msgs.Message) error {
 
 // handleKeepalive is called from handle when the keepalive ticker ticks.
 //
-// This method does two things. First, it checks the last timestamp of a received message against the negotiated
-// keepalive value. This errors for a stalled session. Second, the last timestamp of a sent message is also compared
-// with the keepalive value. If the time delta is below 1/8 of the keepalive barrier, a KEEPALIVE message will be sent.
+// This method does two things. First, it checks the last timestamp of a received message against twice the negotiated
+// keepalive value. A stalled session is terminated with an idle timeout SESS_TERM and errors. Second, the last
+// timestamp of a sent message is also compared with the keepalive value. If the time delta is below 1/8 of the
+// keepalive barrier, a KEEPALIVE message will be sent.
 func (se *SessEstablishedStage) handleKeepalive() error {
 	keepalive := time.Duration(se.state.Keepalive) * time.Second
 
-	receiveDelta := time.Until(se.lastReceive.Add(keepalive))
+	receiveDelta := time.Until(se.lastReceive.Add(2 * keepalive))
 	sendDelta := time.Until(se.lastSend.Add(keepalive))
 
 	// Check last received message
 	if receiveDelta < 0 {
+		_ = se.messageOut(msgs.NewSessionTerminationMessage(0, msgs.TerminationIdleTimeout))
 		return fmt.Errorf("stalled session; last message at %v, keepalive of %v", se.lastReceive, keepalive)
 	}
 
@@ -121,6 +139,9 @@ func (se *SessEstablishedStage) handleMsgIn(msg msgs.Message) (err error) {
 	case *msgs.KeepaliveMessage:
 		// nothing to do
 
+	case *msgs.MessageRejectionMessage:
+		// The peer rejected one of our messages, which is not fatal for this session.
+
 	default:
 		se.state.ExchangeMsgIn <- msg
 	}
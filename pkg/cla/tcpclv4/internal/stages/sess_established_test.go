@@ -95,23 +95,35 @@ func TestSessEstablishedStageKeepaliveTimeout(t *testing.T) {
 	finChan := make(chan struct{})
 	go func() { sess.Handle(state, closer); close(finChan) }()
 
-	// Read outgoing KEEPALIVEs
+	// Read outgoing KEEPALIVEs and the final SESS_TERM
 	keepaliveCounter := int32(0)
+	idleTimeout := make(chan struct{}, 1)
 	go func() {
 		for msg := range msgOut {
-			if _, ok := msg.(*msgs.KeepaliveMessage); ok {
+			switch msg := msg.(type) {
+			case *msgs.KeepaliveMessage:
 				atomic.AddInt32(&keepaliveCounter, 1)
+			case *msgs.SessionTerminationMessage:
+				if msg.ReasonCode == msgs.TerminationIdleTimeout {
+					idleTimeout <- struct{}{}
+				}
 			}
 		}
 	}()
 
-	// Wait for an error because of missing KEEPALIVEs
+	// Wait for an error because of missing KEEPALIVEs, which must not occur before twice the keepalive interval
+	startTime := time.Now()
+
 	select {
 	case <-finChan:
 	case <-time.After(time.Duration(keepaliveSec*3) * time.Second):
 		t.Fatal("timeout")
 	}
 
+	if delta := time.Since(startTime); delta < time.Duration(keepaliveSec*2)*time.Second {
+		t.Fatalf("session was terminated after %v", delta)
+	}
+
 	// Close sessions
 	close(closer)
 
@@ -123,6 +135,12 @@ func TestSessEstablishedStageKeepaliveTimeout(t *testing.T) {
 	if atomic.LoadInt32(&keepaliveCounter) == 0 {
 		t.Fatal("no KEEPALIVEs were received")
 	}
+
+	select {
+	case <-idleTimeout:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("no SESS_TERM with an idle timeout was received")
+	}
 }
 
 func TestSessEstablishedStageMessageExchange(t *testing.T) {
@@ -280,3 +298,58 @@ func TestSessEstablishedStageSessTerm(t *testing.T) {
 		t.Fatalf("error is %v", err)
 	}
 }
+
+func TestSessEstablishedStageTerminate(t *testing.T) {
+	msgIn := make(chan msgs.Message, 32)
+	msgOut := make(chan msgs.Message, 32)
+
+	keepaliveSec := uint16(30)
+	terminate := make(chan struct{})
+
+	sess1 := &SessEstablishedStage{}
+	state1 := &State{
+		MsgIn:         msgIn,
+		MsgOut:        msgOut,
+		Keepalive:     keepaliveSec,
+		TerminateChan: terminate,
+	}
+
+	sess2 := &SessEstablishedStage{}
+	state2 := &State{
+		MsgIn:     msgOut,
+		MsgOut:    msgIn,
+		Keepalive: keepaliveSec,
+	}
+
+	finChan := make(chan struct{})
+	go func() { sess1.Handle(state1, make(chan struct{})); finChan <- struct{}{} }()
+	go func() { sess2.Handle(state2, make(chan struct{})); finChan <- struct{}{} }()
+
+	time.Sleep(100 * time.Millisecond)
+	close(terminate)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-finChan:
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("timeout")
+		}
+	}
+
+	for _, state := range []*State{state1, state2} {
+		if err := state.StageError; !errors.Is(err, StageClose) {
+			t.Fatalf("error is %v", err)
+		}
+	}
+
+	// The terminating session must not reply to the peer's reply.
+	select {
+	case msg := <-msgOut:
+		t.Fatalf("terminating session sent %v after the termination", msg)
+	default:
+	}
+
+	if !sess1.terminating || sess2.terminating {
+		t.Fatalf("terminating flags are %t and %t", sess1.terminating, sess2.terminating)
+	}
+}
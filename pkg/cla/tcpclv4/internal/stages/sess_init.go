@@ -44,16 +44,40 @@ func (ci *SessInitStage) Handle(state *State, closeChan <-chan struct{}) {
 		}
 	}
 
+	if err == nil {
+		err = ci.checkExtensions(ciIn)
+	}
+
 	if err == nil {
 		ci.state.Keepalive = uint16(math.Min(float64(ci.state.Configuration.Keepalive), float64(ciIn.KeepaliveInterval)))
-		ci.state.SegmentMtu = ciIn.SegmentMru
-		ci.state.TransferMtu = ciIn.TransferMru
+		ci.state.SegmentMtu = minMru(ci.state.Configuration.SegmentMru, ciIn.SegmentMru)
+		ci.state.TransferMtu = minMru(ci.state.Configuration.TransferMru, ciIn.TransferMru)
 		ci.state.PeerNodeId, err = bpv7.NewEndpointID(ciIn.NodeId)
 	}
 
 	ci.state.StageError = err
 }
 
+// checkExtensions of a received SESS_INIT. As no Session Extension Items are supported, a critical one results in a
+// SESS_TERM with a contact failure and an error. Other items are ignored.
+func (ci *SessInitStage) checkExtensions(ciIn *msgs.SessionInitMessage) error {
+	for _, ext := range ciIn.SessionExtensions {
+		if ext.IsCritical() {
+			ci.state.MsgOut <- msgs.NewSessionTerminationMessage(0, msgs.TerminationContactFailure)
+			return fmt.Errorf("received unsupported critical %v", ext)
+		}
+	}
+	return nil
+}
+
+// minMru negotiates the minimum of both MRUs.
+func minMru(own, peer uint64) uint64 {
+	if peer < own {
+		return peer
+	}
+	return own
+}
+
 func (ci *SessInitStage) receiveMsgOrClose() (ciIn *msgs.SessionInitMessage, err error) {
 	select {
 	case <-ci.closeChan:
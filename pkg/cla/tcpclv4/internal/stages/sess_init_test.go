@@ -70,18 +70,16 @@ func TestSessInitStage(t *testing.T) {
 		t.Fatalf("expected keepalive: %d, active: %d, passive: %d", keepalive, activeState.Keepalive, passiveState.Keepalive)
 	}
 
-	if mtu := passiveState.Configuration.SegmentMru; activeState.SegmentMtu != mtu {
-		t.Fatalf("active segmentu MTU %d != %d", activeState.SegmentMtu, mtu)
-	}
-	if mtu := activeState.Configuration.SegmentMru; passiveState.SegmentMtu != mtu {
-		t.Fatalf("passive segmentu MTU %d != %d", passiveState.SegmentMtu, mtu)
+	segmentMtu := passiveState.Configuration.SegmentMru
+	if activeState.SegmentMtu != segmentMtu || passiveState.SegmentMtu != segmentMtu {
+		t.Fatalf("expected segment MTU: %d, active: %d, passive: %d",
+			segmentMtu, activeState.SegmentMtu, passiveState.SegmentMtu)
 	}
 
-	if mtu := passiveState.Configuration.TransferMru; activeState.TransferMtu != mtu {
-		t.Fatalf("active transfer MTU %d != %d", activeState.TransferMtu, mtu)
-	}
-	if mtu := activeState.Configuration.TransferMru; passiveState.TransferMtu != mtu {
-		t.Fatalf("passive transfer MTU %d != %d", passiveState.TransferMtu, mtu)
+	transferMtu := passiveState.Configuration.TransferMru
+	if activeState.TransferMtu != transferMtu || passiveState.TransferMtu != transferMtu {
+		t.Fatalf("expected transfer MTU: %d, active: %d, passive: %d",
+			transferMtu, activeState.TransferMtu, passiveState.TransferMtu)
 	}
 
 	if nodeId := passiveState.Configuration.NodeId; activeState.PeerNodeId != nodeId {
@@ -91,3 +89,47 @@ func TestSessInitStage(t *testing.T) {
 		t.Fatalf("passive node ID %v != %v", passiveState.PeerNodeId, nodeId)
 	}
 }
+
+func TestSessInitStageCriticalExtension(t *testing.T) {
+	msgIn := make(chan msgs.Message)
+	msgOut := make(chan msgs.Message, 2)
+
+	sessInit := &SessInitStage{}
+	state := &State{
+		Configuration: Configuration{
+			ActivePeer:  true,
+			Keepalive:   30,
+			SegmentMru:  65535,
+			TransferMru: 0xFFFFFFFF,
+			NodeId:      bpv7.MustNewEndpointID("dtn://active/"),
+		},
+		MsgIn:  msgIn,
+		MsgOut: msgOut,
+	}
+
+	finChan := make(chan struct{})
+	go func() { sessInit.Handle(state, make(chan struct{})); close(finChan) }()
+
+	ciIn := msgs.NewSessionInitMessage(30, 65535, 0xFFFFFFFF, "dtn://passive/")
+	ciIn.SessionExtensions = []msgs.SessionExtensionItem{
+		{Flags: msgs.SessionExtensionCritical, Type: 0x2342, Value: []byte("unknown")},
+	}
+	msgIn <- ciIn
+
+	select {
+	case <-finChan:
+	case <-time.After(250 * time.Millisecond):
+		t.Fatal("timeout")
+	}
+
+	if state.StageError == nil {
+		t.Fatal("unsupported critical Session Extension Item was accepted")
+	}
+
+	if _, ok := (<-msgOut).(*msgs.SessionInitMessage); !ok {
+		t.Fatal("first message is not a SESS_INIT")
+	}
+	if msg, ok := (<-msgOut).(*msgs.SessionTerminationMessage); !ok || msg.ReasonCode != msgs.TerminationContactFailure {
+		t.Fatalf("expected SESS_TERM with a contact failure, got %v", msg)
+	}
+}
@@ -53,17 +53,22 @@ type State struct {
 	// StageError reports back the failure of a stage.
 	StageError error
 
+	// TerminateChan is closed to request a graceful session termination by a SESS_TERM handshake.
+	TerminateChan <-chan struct{}
+
 	// CONTACT STAGE
 	// ContactFlags are the received ContactFlags.
 	ContactFlags msgs.ContactFlags
+	// UseTls is true if both peers are capable of TLS, as negotiated by their ContactFlags.
+	UseTls bool
 	// CONTACT STAGE END
 
 	// SESS INIT STAGE
 	// Keepalive is the minimum of the own configured and the received keepalive. Zero indicates a disabled keepalive.
 	Keepalive uint16
-	// SegmentMtu is the peer's segment MTU.
+	// SegmentMtu is the minimum of the own and the peer's segment MRU.
 	SegmentMtu uint64
-	// TransferMtu is the peer's transfer MTU.
+	// TransferMtu is the minimum of the own and the peer's transfer MRU, limiting the size of outgoing bundles.
 	TransferMtu uint64
 	// PeerNodeId is the peer's node ID.
 	PeerNodeId bpv7.EndpointID
@@ -30,12 +30,15 @@ type StageHandler struct {
 	currentStage      StageSetup
 	currentStageMutex sync.RWMutex
 
-	errChan   chan error
-	closeChan chan struct{}
+	errChan       chan error
+	closeChan     chan struct{}
+	terminateChan chan struct{}
 }
 
 // NewStageHandler for a slice of Stages, Message channels and a Configuration.
 func NewStageHandler(stages []StageSetup, msgIn <-chan msgs.Message, msgOut chan<- msgs.Message, config Configuration) (sh *StageHandler) {
+	terminateChan := make(chan struct{})
+
 	sh = &StageHandler{
 		stages: stages,
 		state: &State{
@@ -45,10 +48,12 @@ func NewStageHandler(stages []StageSetup, msgIn <-chan msgs.Message, msgOut chan
 			ExchangeMsgIn:  make(chan msgs.Message, 32),
 			ExchangeMsgOut: make(chan msgs.Message, 32),
 			StageError:     nil,
+			TerminateChan:  terminateChan,
 		},
 
-		errChan:   make(chan error),
-		closeChan: make(chan struct{}),
+		errChan:       make(chan error),
+		closeChan:     make(chan struct{}),
+		terminateChan: terminateChan,
 	}
 
 	go sh.handler()
@@ -103,6 +108,12 @@ func (sh *StageHandler) Exchanges() (incoming <-chan msgs.Message, outgoing chan
 	return sh.state.ExchangeMsgIn, sh.state.ExchangeMsgOut
 }
 
+// Terminate requests a graceful session termination. An established session sends a SESS_TERM and finishes with
+// StageClose after the peer's reply. Thus, this might take a while and Close should be called afterwards anyway.
+func (sh *StageHandler) Terminate() {
+	close(sh.terminateChan)
+}
+
 // Close this StageHandler and the current Stage.
 func (sh *StageHandler) Close() error {
 	close(sh.closeChan)
@@ -9,10 +9,56 @@ import (
 	"errors"
 	"io"
 	"sync/atomic"
+	"time"
 
+	"github.com/dtn7/dtn7-go/pkg/cla"
 	"github.com/dtn7/dtn7-go/pkg/cla/tcpclv4/internal/msgs"
 )
 
+const (
+	// writeTimeout is the default maximum duration for writing one chunk of writeChunkSize bytes to a writeDeadliner.
+	writeTimeout = 30 * time.Second
+	// writeChunkSize limits each write to a writeDeadliner, compare deadlineWriter.
+	writeChunkSize = 64 * 1024
+)
+
+// writeDeadliner is an io.Writer supporting write deadlines, e.g., a net.Conn.
+type writeDeadliner interface {
+	io.Writer
+	SetWriteDeadline(t time.Time) error
+}
+
+// deadlineWriter bounds each write to a writeDeadliner by a timeout, e.g., writeTimeout. Larger writes are split into
+// chunks, each with a new deadline. Thus, a stalled connection fails instead of blocking forever, while a slow one can
+// continue.
+type deadlineWriter struct {
+	w       writeDeadliner
+	timeout time.Duration
+}
+
+func (dw deadlineWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > writeChunkSize {
+			chunk = chunk[:writeChunkSize]
+		}
+
+		if err = dw.w.SetWriteDeadline(time.Now().Add(dw.timeout)); err != nil {
+			return
+		}
+
+		var m int
+		m, err = dw.w.Write(chunk)
+		n += m
+		p = p[m:]
+
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
 // MessageSwitchReaderWriter exchanges msgs.Messages from an io.Reader and io.Writer to channels. If one of the
 // io.Reader or the io.Writer is closeable, closing should be performed after the MessageSwitcher has finished.
 type MessageSwitchReaderWriter struct {
@@ -27,8 +73,17 @@ type MessageSwitchReaderWriter struct {
 	finished uint32
 }
 
-// NewMessageSwitchReaderWriter for an io.Reader and io.Writer to exchange msgs.Messages to channels.
-func NewMessageSwitchReaderWriter(in io.Reader, out io.Writer) (ms *MessageSwitchReaderWriter) {
+// NewMessageSwitchReaderWriter for an io.Reader and io.Writer to exchange msgs.Messages to channels. If the io.Writer
+// supports write deadlines, e.g., a net.Conn, a stalled write fails after a timeout. All exchanged bytes are counted
+// by the optional ByteCounts.
+func NewMessageSwitchReaderWriter(in io.Reader, out io.Writer, counts *cla.ByteCounts) (ms *MessageSwitchReaderWriter) {
+	if wd, ok := out.(writeDeadliner); ok {
+		out = deadlineWriter{w: wd, timeout: writeTimeout}
+	}
+
+	in = counts.CountingReader(in)
+	out = counts.CountingWriter(out)
+
 	ms = &MessageSwitchReaderWriter{
 		in:  in,
 		out: out,
@@ -50,6 +105,12 @@ func (ms *MessageSwitchReaderWriter) sendErr(err error) {
 	}
 }
 
+// rejectAndFail is an outgoing MSG_REJECT, after which the MessageSwitchReaderWriter fails with the error.
+type rejectAndFail struct {
+	*msgs.MessageRejectionMessage
+	err error
+}
+
 func (ms *MessageSwitchReaderWriter) handleIn() {
 	in := bufio.NewReader(ms.in)
 
@@ -58,7 +119,12 @@ func (ms *MessageSwitchReaderWriter) handleIn() {
 			return
 		}
 
-		if msg, err := msgs.ReadMessage(in); err != nil {
+		var unknownErr *msgs.UnknownMessageError
+		if msg, err := msgs.ReadMessage(in); errors.As(err, &unknownErr) {
+			// The unknown message's length is unknown as well. Thus, the stream cannot be continued after rejecting.
+			ms.outChan <- rejectAndFail{msgs.NewUnknownMessageRejection(unknownErr), err}
+			return
+		} else if err != nil {
 			ms.sendErr(err)
 			return
 		} else {
@@ -83,6 +149,11 @@ func (ms *MessageSwitchReaderWriter) handleOut() {
 			ms.sendErr(err)
 			return
 		}
+
+		if raf, ok := msg.(rejectAndFail); ok {
+			ms.sendErr(raf.err)
+			return
+		}
 	}
 }
 
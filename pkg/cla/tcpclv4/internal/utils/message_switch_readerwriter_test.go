@@ -5,7 +5,11 @@
 package utils
 
 import (
+	"bytes"
+	"errors"
 	"io"
+	"io/ioutil"
+	"net"
 	"testing"
 	"time"
 
@@ -16,7 +20,7 @@ func TestMessageSwitchSimple(t *testing.T) {
 	const keepaliveSends = 1000
 
 	in, out := io.Pipe()
-	ms := NewMessageSwitchReaderWriter(in, out)
+	ms := NewMessageSwitchReaderWriter(in, out, nil)
 	incoming, outgoing, errChan := ms.Exchange()
 
 	go func() {
@@ -44,3 +48,82 @@ func TestMessageSwitchSimple(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestMessageSwitchRejectUnknown(t *testing.T) {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+
+	ms := NewMessageSwitchReaderWriter(inReader, outWriter, nil)
+	_, _, errChan := ms.Exchange()
+
+	go func() { _, _ = inWriter.Write([]byte{0xEE, 0x23, 0x42}) }()
+
+	rejectChan := make(chan msgs.Message, 1)
+	go func() {
+		if msg, err := msgs.ReadMessage(outReader); err == nil {
+			rejectChan <- msg
+		}
+	}()
+
+	select {
+	case msg := <-rejectChan:
+		if mrm, ok := msg.(*msgs.MessageRejectionMessage); !ok {
+			t.Fatalf("msg is %T", msg)
+		} else if mrm.ReasonCode != msgs.RejectionTypeUnknown || mrm.MessageHeader != 0xEE {
+			t.Fatalf("unexpected %v", mrm)
+		}
+
+	case <-time.After(250 * time.Millisecond):
+		t.Fatal("timeout")
+	}
+
+	var unknownErr *msgs.UnknownMessageError
+	select {
+	case err := <-errChan:
+		if !errors.As(err, &unknownErr) || unknownErr.TypeCode != 0xEE {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+	case <-time.After(250 * time.Millisecond):
+		t.Fatal("timeout")
+	}
+}
+
+func TestDeadlineWriter(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() { _ = client.Close(); _ = server.Close() }()
+
+	dw := deadlineWriter{w: client, timeout: 100 * time.Millisecond}
+	data := bytes.Repeat([]byte{0x23}, 3*writeChunkSize)
+
+	// A reading peer receives all data in multiple chunks.
+	readChan := make(chan []byte)
+	go func() {
+		buff, _ := ioutil.ReadAll(io.LimitReader(server, int64(len(data))))
+		readChan <- buff
+	}()
+
+	if n, err := dw.Write(data); err != nil || n != len(data) {
+		t.Fatalf("writing %d bytes errored: %v", n, err)
+	} else if buff := <-readChan; !bytes.Equal(buff, data) {
+		t.Fatalf("read %d bytes, expected %d", len(buff), len(data))
+	}
+
+	// A stalled peer results in a timeout.
+	errChan := make(chan error)
+	go func() {
+		_, err := dw.Write(data)
+		errChan <- err
+	}()
+
+	select {
+	case err := <-errChan:
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Fatalf("expected a timeout, got %v", err)
+		}
+
+	case <-time.After(time.Second):
+		t.Fatal("stalled write did not time out")
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/gorilla/websocket"
 
+	"github.com/dtn7/dtn7-go/pkg/cla"
 	"github.com/dtn7/dtn7-go/pkg/cla/tcpclv4/internal/msgs"
 )
 
@@ -18,6 +19,7 @@ import (
 type MessageSwitchWebSocket struct {
 	conn        *websocket.Conn
 	messageType int
+	counts      *cla.ByteCounts
 
 	inChan  chan msgs.Message
 	outChan chan msgs.Message
@@ -27,11 +29,13 @@ type MessageSwitchWebSocket struct {
 	finished uint32
 }
 
-// NewMessageSwitchWebSocket for a *websocket.Conn to exchange msgs.Messages to channels.
-func NewMessageSwitchWebSocket(conn *websocket.Conn) (ms *MessageSwitchWebSocket) {
+// NewMessageSwitchWebSocket for a *websocket.Conn to exchange msgs.Messages to channels. The WebSocket messages'
+// payloads are counted by the optional ByteCounts.
+func NewMessageSwitchWebSocket(conn *websocket.Conn, counts *cla.ByteCounts) (ms *MessageSwitchWebSocket) {
 	ms = &MessageSwitchWebSocket{
 		conn:        conn,
 		messageType: websocket.BinaryMessage,
+		counts:      counts,
 
 		inChan:  make(chan msgs.Message, 32),
 		outChan: make(chan msgs.Message, 32),
@@ -62,7 +66,14 @@ func (ms *MessageSwitchWebSocket) handleIn() {
 		} else if mt != ms.messageType {
 			ms.sendErr(fmt.Errorf("expected message type %d instead of %d", ms.messageType, mt))
 			return
-		} else if msg, err := msgs.ReadMessage(r); err != nil {
+		} else if msg, err := msgs.ReadMessage(ms.counts.CountingReader(r)); err != nil {
+			// Each WebSocket message contains one TCPCLv4 message. Thus, an unknown one can be rejected and skipped.
+			var unknownErr *msgs.UnknownMessageError
+			if errors.As(err, &unknownErr) {
+				ms.outChan <- msgs.NewUnknownMessageRejection(unknownErr)
+				continue
+			}
+
 			ms.sendErr(err)
 			return
 		} else {
@@ -80,7 +91,7 @@ func (ms *MessageSwitchWebSocket) handleOut() {
 		if wc, err := ms.conn.NextWriter(ms.messageType); err != nil {
 			ms.sendErr(err)
 			return
-		} else if err := msg.Marshal(wc); err != nil {
+		} else if err := msg.Marshal(ms.counts.CountingWriter(wc)); err != nil {
 			ms.sendErr(err)
 			return
 		} else if err := wc.Close(); err != nil {
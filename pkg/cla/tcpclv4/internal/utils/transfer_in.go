@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla/tcpclv4/internal/msgs"
@@ -17,8 +18,11 @@ import (
 type IncomingTransfer struct {
 	Id uint64
 
-	endFlag bool
-	buf     *bytes.Buffer
+	mutex sync.Mutex
+
+	startFlag bool
+	endFlag   bool
+	buf       *bytes.Buffer
 }
 
 // NewIncomingTransfer creates a new IncomingTransfer for the given Transfer ID.
@@ -29,18 +33,24 @@ func NewIncomingTransfer(id uint64) *IncomingTransfer {
 	}
 }
 
-func (t IncomingTransfer) String() string {
+func (t *IncomingTransfer) String() string {
 	return fmt.Sprintf("INCOMING_TRANSFER(%d)", t.Id)
 }
 
 // IsFinished indicates if this Transfer is finished.
-func (t IncomingTransfer) IsFinished() bool {
+func (t *IncomingTransfer) IsFinished() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
 	return t.endFlag
 }
 
 // NextSegment reads data from a XFER_SEGMENT and returns a XFER_ACK or an error.
 func (t *IncomingTransfer) NextSegment(dtm *msgs.DataTransmissionMessage) (dam *msgs.DataAcknowledgementMessage, err error) {
-	if t.IsFinished() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.endFlag {
 		err = fmt.Errorf("transfer has already received an end flag")
 		return
 	}
@@ -50,6 +60,16 @@ func (t *IncomingTransfer) NextSegment(dtm *msgs.DataTransmissionMessage) (dam *
 		return
 	}
 
+	if isStart := dtm.Flags&msgs.SegmentStart != 0; isStart == t.startFlag {
+		if isStart {
+			err = fmt.Errorf("transfer has already received a start flag")
+		} else {
+			err = fmt.Errorf("transfer's first XFER_SEGMENT has no start flag")
+		}
+		return
+	}
+	t.startFlag = true
+
 	if n, dtmErr := t.buf.Write(dtm.Data); dtmErr != nil && dtmErr != io.EOF {
 		err = dtmErr
 		return
@@ -66,13 +86,31 @@ func (t *IncomingTransfer) NextSegment(dtm *msgs.DataTransmissionMessage) (dam *
 	return
 }
 
-// ToBundle returns the Bundle for a finished Transfer.
-func (t *IncomingTransfer) ToBundle() (bndl bpv7.Bundle, err error) {
-	if !t.IsFinished() {
+// ToBundle returns the Bundle for a finished Transfer, decoded in the given DecodeMode.
+func (t *IncomingTransfer) ToBundle(mode bpv7.DecodeMode) (bndl bpv7.Bundle, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.endFlag {
 		err = fmt.Errorf("transfer has not been finished")
 		return
 	}
 
-	err = bndl.UnmarshalCbor(t.buf)
+	err = bndl.UnmarshalCborMode(t.buf, mode)
+	return
+}
+
+// ToFragment creates a fragment from the received data of an unfinished Transfer, e.g., after its session broke down.
+// This is only possible if some payload was received, as described for bpv7.ReactiveFragment.
+func (t *IncomingTransfer) ToFragment() (frag bpv7.Bundle, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.endFlag {
+		err = fmt.Errorf("transfer has already been finished")
+		return
+	}
+
+	frag, err = bpv7.ReactiveFragment(t.buf.Bytes())
 	return
 }
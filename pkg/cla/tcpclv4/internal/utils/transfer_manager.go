@@ -5,6 +5,7 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
 	"github.com/dtn7/dtn7-go/pkg/cla/tcpclv4/internal/msgs"
 )
 
@@ -28,10 +30,16 @@ type TransferManager struct {
 
 	segmentMtu uint64
 
-	inTransfers sync.Map // map[uint64]*IncomingTransfer
+	inTransfers   sync.Map // map[uint64]*IncomingTransfer
+	inRefused     map[uint64]struct{}
+	inTransferMru uint64
+	inDecodeMode  bpv7.DecodeMode
 
 	outNextId   uint64
 	outFeedback sync.Map // map[uint64]chan msgs.Message
+	outInFlight chan struct{}
+	outResume   *TransferResumption
+	outTimeout  time.Duration
 
 	stopChan chan struct{}
 	stopped  uint32
@@ -48,6 +56,10 @@ func NewTransferManager(msgIn <-chan msgs.Message, msgOut chan<- msgs.Message, s
 
 		segmentMtu: segmentMtu,
 
+		inRefused: make(map[uint64]struct{}),
+
+		outTimeout: 10 * time.Second,
+
 		stopChan: make(chan struct{}),
 	}
 
@@ -56,6 +68,35 @@ func NewTransferManager(msgIn <-chan msgs.Message, msgOut chan<- msgs.Message, s
 	return
 }
 
+// SetMaxTransfers limits the amount of concurrent outgoing transfers. Further transfers wait until a slot frees. A
+// zero value disables this limit, which is the default. This method must be called before sending any Bundles.
+func (tm *TransferManager) SetMaxTransfers(n int) {
+	if n > 0 {
+		tm.outInFlight = make(chan struct{}, n)
+	} else {
+		tm.outInFlight = nil
+	}
+}
+
+// SetTransferMru limits the size of incoming transfers. A transfer exceeding this limit is refused by a XFER_REFUSE
+// and its further segments are dropped. A zero value disables this limit, which is the default. This method must be
+// called before receiving any Bundles.
+func (tm *TransferManager) SetTransferMru(mru uint64) {
+	tm.inTransferMru = mru
+}
+
+// SetDecodeMode for incoming Bundles, which defaults to the bpv7.StrictDecoding. This method must be called before
+// receiving any Bundles.
+func (tm *TransferManager) SetDecodeMode(mode bpv7.DecodeMode) {
+	tm.inDecodeMode = mode
+}
+
+// SetResumption enables resuming interrupted outgoing transfers, tracked by a TransferResumption shared across this
+// peer's sessions. This method must be called before sending any Bundles.
+func (tm *TransferManager) SetResumption(tr *TransferResumption) {
+	tm.outResume = tr
+}
+
 // Exchange channels for incoming Bundles or errors.
 func (tm *TransferManager) Exchange() (bundles <-chan bpv7.Bundle, errChan <-chan error) {
 	bundles = tm.chanBundles
@@ -84,35 +125,47 @@ func (tm *TransferManager) handle() {
 			switch msg := msg.(type) {
 			// Related to outgoing messages
 			case *msgs.DataAcknowledgementMessage:
-				if ackChan, ok := tm.outFeedback.Load(msg.TransferId); !ok {
-					tm.chanErrors <- fmt.Errorf("received acknowledgement for unknown message %d", msg.TransferId)
+				if err := tm.outFeedbackMsg(msg.TransferId, msg); err != nil {
+					tm.chanErrors <- err
 					return
-				} else {
-					ackChan.(chan msgs.Message) <- msg
 				}
 
 			case *msgs.TransferRefusalMessage:
-				if ackChan, ok := tm.outFeedback.Load(msg.TransferId); !ok {
-					tm.chanErrors <- fmt.Errorf("received refusal for unknown message %d", msg.TransferId)
+				if err := tm.outFeedbackMsg(msg.TransferId, msg); err != nil {
+					tm.chanErrors <- err
 					return
-				} else {
-					ackChan.(chan msgs.Message) <- msg
 				}
 
 			// Related to incoming messages
 			case *msgs.DataTransmissionMessage:
+				if _, refused := tm.inRefused[msg.TransferId]; refused {
+					// Drop segments already in flight when the transfer was refused.
+					if msg.Flags&msgs.SegmentEnd != 0 {
+						delete(tm.inRefused, msg.TransferId)
+					}
+					continue
+				}
+
 				transferI, _ := tm.inTransfers.LoadOrStore(msg.TransferId, NewIncomingTransfer(msg.TransferId))
 				transfer := transferI.(*IncomingTransfer)
 
 				if dam, err := transfer.NextSegment(msg); err != nil {
 					tm.chanErrors <- err
 					return
+				} else if tm.inTransferMru > 0 && dam.AckLen > tm.inTransferMru {
+					tm.inTransfers.Delete(msg.TransferId)
+					if msg.Flags&msgs.SegmentEnd == 0 {
+						tm.inRefused[msg.TransferId] = struct{}{}
+					}
+
+					tm.msgOut <- msgs.NewTransferRefusalMessage(msgs.RefusalNoResources, msg.TransferId)
+					continue
 				} else {
 					tm.msgOut <- dam
 				}
 
 				if transfer.IsFinished() {
-					if b, err := transfer.ToBundle(); err != nil {
+					if b, err := transfer.ToBundle(tm.inDecodeMode); err != nil {
 						tm.chanErrors <- err
 						return
 					} else {
@@ -130,9 +183,95 @@ func (tm *TransferManager) handle() {
 	}
 }
 
+// outFeedbackMsg passes a XFER_ACK or XFER_REFUSE to its outgoing transfer. Late messages for an already finished or
+// aborted transfer are dropped, while an unassigned Transfer ID results in an error.
+func (tm *TransferManager) outFeedbackMsg(tid uint64, msg msgs.Message) error {
+	if ackChan, ok := tm.outFeedback.Load(tid); ok {
+		ackChan.(chan msgs.Message) <- msg
+		return nil
+	} else if tid < atomic.LoadUint64(&tm.outNextId) {
+		return nil
+	} else {
+		return fmt.Errorf("received %v for unknown transfer %d", msg, tid)
+	}
+}
+
+// InterruptedBundles creates fragments of all unfinished incoming transfers, e.g., after the session broke down. Thus,
+// the already received payload does not need to be transferred again. Transfers without any received payload are
+// dropped. This method should be called after closing.
+func (tm *TransferManager) InterruptedBundles() (bs []bpv7.Bundle) {
+	tm.inTransfers.Range(func(_, transferI interface{}) bool {
+		transfer := transferI.(*IncomingTransfer)
+		if transfer.IsFinished() {
+			return true
+		}
+
+		if frag, err := transfer.ToFragment(); err == nil {
+			bs = append(bs, frag)
+		}
+		return true
+	})
+	return
+}
+
+// AbandonedTransferError is returned for an outgoing transfer which was given up before being acknowledged, e.g.,
+// because its context is done or the peer stopped acknowledging segments. TCPCLv4 does not allow cancelling a started
+// transfer. Thus, the session must be terminated to let the peer drop or fragment its incomplete incoming transfer.
+type AbandonedTransferError struct {
+	Id  uint64
+	Err error
+}
+
+func (e *AbandonedTransferError) Error() string {
+	return fmt.Sprintf("transfer %d was abandoned: %v", e.Id, e.Err)
+}
+
+func (e *AbandonedTransferError) Unwrap() error {
+	return e.Err
+}
+
+// refusalError maps a XFER_REFUSE's reason code to a cla.RefusalError.
+func refusalError(code msgs.TransferRefusalCode) *cla.RefusalError {
+	var kind cla.RefusalKind
+	switch code {
+	case msgs.RefusalCompleted:
+		kind = cla.RefusalDuplicate
+	case msgs.RefusalNoResources, msgs.RefusalRetransmit, msgs.RefusalSessionTerminating:
+		kind = cla.RefusalTemporary
+	default:
+		kind = cla.RefusalPermanent
+	}
+
+	return cla.NewRefusalError(kind, code.String())
+}
+
 // Send an outgoing Bundle. This method blocks until the Bundle was sent successfully or an error arises.
 func (tm *TransferManager) Send(b bpv7.Bundle) error {
-	transfer := NewBundleOutgoingTransfer(atomic.AddUint64(&tm.outNextId, 1)-1, b)
+	return tm.SendContext(context.Background(), b)
+}
+
+// SendContext sends an outgoing Bundle like Send, but aborts the transfer when the context is done. An aborted
+// transfer results in an AbandonedTransferError and will not be resumed.
+func (tm *TransferManager) SendContext(ctx context.Context, b bpv7.Bundle) error {
+	if tm.outInFlight != nil {
+		select {
+		case tm.outInFlight <- struct{}{}:
+			defer func() { <-tm.outInFlight }()
+
+		case <-tm.stopChan:
+			return fmt.Errorf("TransferManager was stopped")
+
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for a transfer slot was aborted: %w", ctx.Err())
+		}
+	}
+
+	sent := b
+	if tm.outResume != nil {
+		sent = tm.outResume.resume(b)
+	}
+
+	transfer := NewBundleOutgoingTransfer(atomic.AddUint64(&tm.outNextId, 1)-1, sent)
 
 	ackChan := make(chan msgs.Message, 32)
 	tm.outFeedback.Store(transfer.Id, ackChan)
@@ -140,6 +279,8 @@ func (tm *TransferManager) Send(b bpv7.Bundle) error {
 
 	// Signal abortion from "this" main Goroutine back to the sending one.
 	var stopped uint32
+	done := make(chan struct{})
+	defer close(done)
 
 	// Signal errors or total length from the sending Goroutine back to "this" main one.
 	errChan := make(chan error, 1)
@@ -165,15 +306,31 @@ func (tm *TransferManager) Send(b bpv7.Bundle) error {
 				return
 			}
 
-			tm.msgOut <- dtm
-			l += len(dtm.Data)
+			select {
+			case tm.msgOut <- dtm:
+				l += len(dtm.Data)
+			case <-done:
+				return
+			}
 		}
 	}()
 
 	var inLen, outLen int
+
+	// Keep the acknowledged length of a transfer interrupted by a broken session to resume it within the next one. This
+	// must not be called for a transfer abandoned within a still established session.
+	interrupt := func() {
+		if tm.outResume != nil {
+			tm.outResume.interrupt(b, sent, uint64(inLen))
+		}
+	}
+
 	for {
 		select {
 		case err := <-errChan:
+			if atomic.LoadUint32(&tm.stopped) != 0 {
+				interrupt()
+			}
 			return err
 
 		case outLen = <-lenChan:
@@ -188,15 +345,30 @@ func (tm *TransferManager) Send(b bpv7.Bundle) error {
 					return nil
 				}
 
+			case *msgs.TransferRefusalMessage:
+				atomic.StoreUint32(&stopped, 1)
+				return refusalError(response.ReasonCode)
+
 			default:
 				atomic.StoreUint32(&stopped, 1)
 				return fmt.Errorf("received unexpected message: %T, %v", response, response)
 			}
 
-		case <-time.After(10 * time.Second):
+		case <-tm.stopChan:
+			atomic.StoreUint32(&stopped, 1)
+			interrupt()
+			return fmt.Errorf("TransferManager was stopped")
+
+		case <-ctx.Done():
+			atomic.StoreUint32(&stopped, 1)
+			return &AbandonedTransferError{Id: transfer.Id, Err: ctx.Err()}
+
+		case <-time.After(tm.outTimeout):
 			atomic.StoreUint32(&stopped, 1)
-			return fmt.Errorf("timeout: waiting for segment acknowledgement; id = %d, stopped = %t",
-				transfer.Id, atomic.LoadUint32(&tm.stopped) != 0)
+			return &AbandonedTransferError{
+				Id:  transfer.Id,
+				Err: fmt.Errorf("timeout: waiting for segment acknowledgement after %v", tm.outTimeout),
+			}
 		}
 	}
 }
@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package utils
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// interruptedTransfer is an outgoing transfer whose session broke down.
+type interruptedTransfer struct {
+	sent   bpv7.Bundle
+	ackLen uint64
+}
+
+// TransferResumption tracks the acknowledged length of interrupted outgoing transfers across sessions.
+//
+// TCPCLv4 itself does not allow resuming a transfer in another session. Thus, reactive fragmentation is used instead:
+// the receiving TransferManager creates a fragment from an incomplete transfer's acknowledged data and the sending
+// TransferManager only sends the remaining payload as another fragment on the next attempt. If the Bundle must not
+// be fragmented or the acknowledged data do not contain any payload, the whole Bundle is sent again.
+type TransferResumption struct {
+	mutex       sync.Mutex
+	interrupted map[bpv7.BundleID]interruptedTransfer
+}
+
+// NewTransferResumption creates an empty TransferResumption, which should be kept across a peer's sessions.
+func NewTransferResumption() *TransferResumption {
+	return &TransferResumption{
+		interrupted: make(map[bpv7.BundleID]interruptedTransfer),
+	}
+}
+
+// interrupt stores the acknowledged length of an interrupted transfer for the original Bundle. The sent Bundle might
+// already be a remainder fragment of the original one, which needs to be sent again even if nothing was acknowledged.
+func (tr *TransferResumption) interrupt(original, sent bpv7.Bundle, ackLen uint64) {
+	if ackLen == 0 && sent.ID() == original.ID() {
+		return
+	}
+
+	tr.mutex.Lock()
+	tr.interrupted[original.ID()] = interruptedTransfer{sent: sent, ackLen: ackLen}
+	tr.mutex.Unlock()
+}
+
+// resume returns the Bundle to be sent for the original Bundle. This is either the remainder fragment of a previously
+// interrupted transfer or the original Bundle itself.
+func (tr *TransferResumption) resume(original bpv7.Bundle) bpv7.Bundle {
+	tr.mutex.Lock()
+	it, ok := tr.interrupted[original.ID()]
+	delete(tr.interrupted, original.ID())
+	tr.mutex.Unlock()
+
+	if !ok {
+		return original
+	} else if it.ackLen == 0 {
+		return it.sent
+	}
+
+	remainder, err := it.sent.ReactiveRemainder(int(it.ackLen))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"bundle":     original.ID(),
+			"ack_length": it.ackLen,
+		}).WithError(err).Debug("Interrupted transfer cannot be resumed, sending whole Bundle")
+		return original
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":     original.ID(),
+		"ack_length": it.ackLen,
+		"offset":     remainder.PrimaryBlock.FragmentOffset,
+	}).Info("Resuming interrupted transfer by sending the remaining payload")
+	return remainder
+}
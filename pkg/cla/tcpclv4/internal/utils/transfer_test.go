@@ -5,13 +5,18 @@
 package utils
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
 	"github.com/dtn7/dtn7-go/pkg/cla/tcpclv4/internal/msgs"
 )
 
@@ -61,7 +66,7 @@ func TestTransfer(t *testing.T) {
 				}
 			}
 
-			if bndlIn, err := in.ToBundle(); err != nil {
+			if bndlIn, err := in.ToBundle(bpv7.StrictDecoding); err != nil {
 				t.Fatal(err)
 			} else if !reflect.DeepEqual(bndlOut, bndlIn) {
 				t.Fatalf("Bundles differ")
@@ -70,6 +75,84 @@ func TestTransfer(t *testing.T) {
 	}
 }
 
+func TestTransferSegmentBoundary(t *testing.T) {
+	bndlOut, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("30m").
+		PayloadBlock(testGetRandomData(1024)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := bndlOut.MarshalCbor(&buf); err != nil {
+		t.Fatal(err)
+	}
+	bndlLen := uint64(buf.Len())
+
+	// The serialized Bundle's length is a multiple of these MTUs, thus its last segment is a full one.
+	for _, mtu := range []uint64{bndlLen, bndlLen / 2, 1} {
+		if bndlLen%mtu != 0 {
+			continue
+		}
+
+		t.Run(fmt.Sprintf("%d", mtu), func(t *testing.T) {
+			out := NewBundleOutgoingTransfer(23, bndlOut)
+			in := NewIncomingTransfer(23)
+
+			for segments := uint64(1); ; segments++ {
+				dtm, err := out.NextSegment(mtu)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				dam, err := in.NextSegment(dtm)
+				if err != nil {
+					t.Fatal(err)
+				} else if dam.AckLen != segments*mtu {
+					t.Fatalf("acknowledged %d bytes after %d segments", dam.AckLen, segments)
+				}
+
+				if dtm.Flags&msgs.SegmentEnd != 0 {
+					break
+				} else if segments*mtu >= bndlLen {
+					t.Fatal("last segment has no end flag")
+				}
+			}
+
+			if _, err := out.NextSegment(mtu); err != io.EOF {
+				t.Fatalf("expected EOF after the last segment, got %v", err)
+			}
+
+			if bndlIn, err := in.ToBundle(bpv7.StrictDecoding); err != nil {
+				t.Fatal(err)
+			} else if !reflect.DeepEqual(bndlOut, bndlIn) {
+				t.Fatalf("Bundles differ")
+			}
+		})
+	}
+}
+
+func TestIncomingTransferStartFlag(t *testing.T) {
+	// A transfer must start with a start flag.
+	in := NewIncomingTransfer(1)
+	if _, err := in.NextSegment(msgs.NewDataTransmissionMessage(0, 1, []byte("hello"))); err == nil {
+		t.Fatal("first segment without a start flag was accepted")
+	}
+
+	// A transfer must not start twice.
+	in = NewIncomingTransfer(1)
+	if _, err := in.NextSegment(msgs.NewDataTransmissionMessage(msgs.SegmentStart, 1, []byte("hello"))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.NextSegment(msgs.NewDataTransmissionMessage(msgs.SegmentStart, 1, []byte("world"))); err == nil {
+		t.Fatal("second segment with a start flag was accepted")
+	}
+}
+
 func TestTransferManager(t *testing.T) {
 	msgIn := make(chan msgs.Message)
 	msgOut := make(chan msgs.Message)
@@ -127,3 +210,383 @@ func TestTransferManager(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestTransferManagerRefusal(t *testing.T) {
+	tests := []struct {
+		code msgs.TransferRefusalCode
+		kind cla.RefusalKind
+	}{
+		{msgs.RefusalUnknown, cla.RefusalPermanent},
+		{msgs.RefusalCompleted, cla.RefusalDuplicate},
+		{msgs.RefusalNoResources, cla.RefusalTemporary},
+		{msgs.RefusalRetransmit, cla.RefusalTemporary},
+		{msgs.RefusalNotAcceptable, cla.RefusalPermanent},
+		{msgs.RefusalExtensionFailure, cla.RefusalPermanent},
+		{msgs.RefusalSessionTerminating, cla.RefusalTemporary},
+	}
+
+	bndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("30m").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.code.String(), func(t *testing.T) {
+			msgIn := make(chan msgs.Message)
+			msgOut := make(chan msgs.Message, 16)
+
+			tm := NewTransferManager(msgIn, msgOut, 1024)
+			defer func() { _ = tm.Close() }()
+
+			errChan := make(chan error)
+			go func() { errChan <- tm.Send(bndl) }()
+
+			dtm := (<-msgOut).(*msgs.DataTransmissionMessage)
+			msgIn <- msgs.NewTransferRefusalMessage(test.code, dtm.TransferId)
+
+			var refusal *cla.RefusalError
+			if err := <-errChan; !errors.As(err, &refusal) {
+				t.Fatalf("expected a RefusalError, got %v", err)
+			} else if refusal.Kind != test.kind {
+				t.Fatalf("expected %v, got %v", test.kind, refusal.Kind)
+			}
+		})
+	}
+}
+
+func TestTransferManagerRefuseTransferMru(t *testing.T) {
+	msgIn := make(chan msgs.Message)
+	msgOut := make(chan msgs.Message)
+
+	tm1 := NewTransferManager(msgIn, msgOut, 1024)
+	tm2 := NewTransferManager(msgOut, msgIn, 1024)
+	tm2.SetTransferMru(4096)
+
+	defer func() { _ = tm1.Close() }()
+	defer func() { _ = tm2.Close() }()
+
+	_, tm1Errs := tm1.Exchange()
+	tm2Bundles, tm2Errs := tm2.Exchange()
+
+	// The first Bundle exceeds the receiver's transfer MRU and must be refused, while the session stays usable for
+	// the second one.
+	for _, test := range []struct {
+		size    int
+		refused bool
+	}{{16384, true}, {1024, false}} {
+		bndlOut, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("30m").
+			PayloadBlock(testGetRandomData(test.size)).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sendErr := make(chan error, 1)
+		go func() { sendErr <- tm1.Send(bndlOut) }()
+
+		var refusal *cla.RefusalError
+		select {
+		case err := <-sendErr:
+			if !test.refused && err != nil {
+				t.Fatalf("sending %d bytes resulted in %v", test.size, err)
+			} else if !test.refused {
+				// The transfer might be acknowledged before the receiver passes on its Bundle.
+				select {
+				case bndlIn := <-tm2Bundles:
+					if !reflect.DeepEqual(bndlIn, bndlOut) {
+						t.Fatalf("bundles differ: %v, %v", bndlIn, bndlOut)
+					}
+				case <-time.After(time.Second):
+					t.Fatal("timeout while waiting for the Bundle")
+				}
+			} else if !errors.As(err, &refusal) || refusal.Kind != cla.RefusalTemporary {
+				t.Fatalf("expected a temporary RefusalError, got %v", err)
+			}
+
+		case err := <-tm1Errs:
+			t.Fatal(err)
+
+		case err := <-tm2Errs:
+			t.Fatal(err)
+
+		case bndlIn := <-tm2Bundles:
+			if test.refused {
+				t.Fatalf("refused Bundle of %d bytes was received", test.size)
+			} else if !reflect.DeepEqual(bndlIn, bndlOut) {
+				t.Fatalf("bundles differ: %v, %v", bndlIn, bndlOut)
+			} else if err := <-sendErr; err != nil {
+				t.Fatal(err)
+			}
+
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+}
+
+func TestTransferManagerMaxTransfers(t *testing.T) {
+	msgIn := make(chan msgs.Message)
+	msgOut := make(chan msgs.Message, 16)
+
+	tm := NewTransferManager(msgIn, msgOut, 65535)
+	tm.SetMaxTransfers(1)
+	defer func() { _ = tm.Close() }()
+
+	bndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("30m").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errChan1, errChan2 := make(chan error), make(chan error)
+	go func() { errChan1 <- tm.Send(bndl) }()
+	dtm1 := (<-msgOut).(*msgs.DataTransmissionMessage)
+
+	go func() { errChan2 <- tm.Send(bndl) }()
+
+	select {
+	case msg := <-msgOut:
+		t.Fatalf("second transfer did not wait for the first one: %v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	msgIn <- msgs.NewDataAcknowledgementMessage(dtm1.Flags, dtm1.TransferId, uint64(len(dtm1.Data)))
+	if err := <-errChan1; err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-msgOut:
+		dtm2 := msg.(*msgs.DataTransmissionMessage)
+		if dtm2.TransferId == dtm1.TransferId {
+			t.Fatalf("second transfer reused the transfer ID %d", dtm2.TransferId)
+		}
+
+		msgIn <- msgs.NewDataAcknowledgementMessage(dtm2.Flags, dtm2.TransferId, uint64(len(dtm2.Data)))
+		if err := <-errChan2; err != nil {
+			t.Fatal(err)
+		}
+
+	case <-time.After(time.Second):
+		t.Fatal("second transfer did not start after the first one completed")
+	}
+}
+
+func TestTransferManagerResumption(t *testing.T) {
+	payload := testGetRandomData(65536)
+	bndl, err := bpv7.Builder().
+		CRC(bpv7.CRC32).
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("30m").
+		HopCountBlock(64).
+		PayloadBlock(payload).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resumption := NewTransferResumption()
+
+	// The first session breaks down after eight acknowledged segments.
+	msgIn1 := make(chan msgs.Message)
+	msgOut1 := make(chan msgs.Message, 128)
+
+	tm1 := NewTransferManager(msgIn1, msgOut1, 1024)
+	tm1.SetResumption(resumption)
+
+	errChan1 := make(chan error)
+	go func() { errChan1 <- tm1.Send(bndl) }()
+
+	in1 := NewIncomingTransfer(0)
+	for i := 0; i < 8; i++ {
+		dtm := (<-msgOut1).(*msgs.DataTransmissionMessage)
+
+		if dam, err := in1.NextSegment(dtm); err != nil {
+			t.Fatal(err)
+		} else {
+			msgIn1 <- dam
+		}
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if err := tm1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errChan1; err == nil {
+		t.Fatal("interrupted transfer did not fail")
+	}
+
+	head, err := in1.ToFragment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	headPayloadBlock, err := head.PayloadBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	headLen := len(headPayloadBlock.Value.(*bpv7.PayloadBlock).Data())
+
+	// The second session only transfers the remaining payload, starting at the acknowledged offset.
+	msgIn2 := make(chan msgs.Message)
+	msgOut2 := make(chan msgs.Message)
+
+	tm2 := NewTransferManager(msgIn2, msgOut2, 1024)
+	tm2.SetResumption(resumption)
+	tm3 := NewTransferManager(msgOut2, msgIn2, 1024)
+	tm3Bundles, tm3Errs := tm3.Exchange()
+
+	errChan2 := make(chan error, 1)
+	go func() { errChan2 <- tm2.Send(bndl) }()
+
+	var tail bpv7.Bundle
+	select {
+	case err := <-tm3Errs:
+		t.Fatal(err)
+	case tail = <-tm3Bundles:
+	case <-time.After(time.Second):
+		t.Fatal("resumed transfer timed out")
+	}
+
+	if err := <-errChan2; err != nil {
+		t.Fatal(err)
+	}
+
+	if !tail.PrimaryBlock.BundleControlFlags.Has(bpv7.IsFragment) {
+		t.Fatal("resumed transfer sent the whole Bundle")
+	} else if off := tail.PrimaryBlock.FragmentOffset; off != uint64(headLen) {
+		t.Fatalf("resumed transfer starts at offset %d, expected %d", off, headLen)
+	}
+
+	if reassembled, err := bpv7.ReassembleFragments([]bpv7.Bundle{head, tail}); err != nil {
+		t.Fatal(err)
+	} else if pb, err := reassembled.PayloadBlock(); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(pb.Value.(*bpv7.PayloadBlock).Data(), payload) {
+		t.Fatal("reassembled payload differs")
+	}
+
+	if err := tm2.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm3.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTransferManagerAckTimeout(t *testing.T) {
+	bndl, err := bpv7.Builder().
+		CRC(bpv7.CRC32).
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("30m").
+		PayloadBlock(testGetRandomData(65536)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resumption := NewTransferResumption()
+
+	// The session stays up, but the peer stops acknowledging after eight segments.
+	msgIn := make(chan msgs.Message)
+	msgOut := make(chan msgs.Message, 128)
+
+	tm := NewTransferManager(msgIn, msgOut, 1024)
+	tm.SetResumption(resumption)
+	tm.outTimeout = 100 * time.Millisecond
+	defer func() { _ = tm.Close() }()
+
+	errChan := make(chan error)
+	go func() { errChan <- tm.Send(bndl) }()
+
+	in := NewIncomingTransfer(0)
+	for i := 0; i < 8; i++ {
+		dtm := (<-msgOut).(*msgs.DataTransmissionMessage)
+
+		if dam, err := in.NextSegment(dtm); err != nil {
+			t.Fatal(err)
+		} else {
+			msgIn <- dam
+		}
+	}
+
+	select {
+	case err := <-errChan:
+		var abandoned *AbandonedTransferError
+		if !errors.As(err, &abandoned) {
+			t.Fatalf("expected an AbandonedTransferError, got %v", err)
+		}
+
+	case <-time.After(time.Second):
+		t.Fatal("unacknowledged transfer did not time out")
+	}
+
+	// The peer's incomplete transfer is still open. Thus, only the remainder must not be sent on the next attempt.
+	if sent := resumption.resume(bndl); sent.ID() != bndl.ID() {
+		t.Fatalf("timed out transfer would be resumed by %v", sent.ID())
+	}
+}
+
+func TestTransferManagerSendContext(t *testing.T) {
+	// The unbuffered msgOut is never read, resulting in a blocked transfer.
+	msgIn := make(chan msgs.Message)
+	msgOut := make(chan msgs.Message)
+
+	resumption := NewTransferResumption()
+
+	tm := NewTransferManager(msgIn, msgOut, 65535)
+	tm.SetResumption(resumption)
+	defer func() { _ = tm.Close() }()
+
+	bndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("30m").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	errChan := make(chan error)
+	go func() { errChan <- tm.SendContext(ctx, bndl) }()
+
+	select {
+	case err := <-errChan:
+		var abandoned *AbandonedTransferError
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected a deadline error, got %v", err)
+		} else if !errors.As(err, &abandoned) {
+			t.Fatalf("expected an AbandonedTransferError, got %v", err)
+		}
+
+	case <-time.After(time.Second):
+		t.Fatal("blocked transfer was not aborted")
+	}
+
+	if sent := resumption.resume(bndl); sent.ID() != bndl.ID() {
+		t.Fatalf("aborted transfer would be resumed by %v", sent.ID())
+	}
+}
@@ -0,0 +1,17 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package udpcl provides a connectionless convergence layer based on UDP datagrams.
+//
+// Each bundle is sent as a single datagram, solely containing its CBOR encoding. Thus, there is neither a connection
+// setup nor any acknowledgement, which makes this convergence layer suitable for LANs, e.g., in combination with
+// the discovery and multicast addresses. Bundles exceeding a datagram's size are refused by the UDPClient, which
+// reports its MTU to get bundles fragmented while being forwarded, compare cla.MTUReporter.
+//
+// As MTCP, this convergence layer is unidirectional: the UDPServer implements the ConvergenceReceiver and the
+// UDPClient the ConvergenceSender interfaces defined in the parent cla package.
+package udpcl
+
+// MaxDatagramSize is the largest payload of a UDP datagram over IPv4.
+const MaxDatagramSize = 65507
@@ -0,0 +1,187 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package udpcl
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/cboring"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// UDPClient sends each bundle as a single UDP datagram to a UDPServer's address, which might also be a multicast
+// address. This struct implements a ConvergenceSender.
+type UDPClient struct {
+	address   string
+	peer      bpv7.EndpointID
+	permanent bool
+	mtu       int
+
+	conn       *net.UDPConn
+	remoteAddr *net.UDPAddr
+	mutex      sync.Mutex
+
+	reportChan chan cla.ConvergenceStatus
+	failChan   chan struct{}
+
+	stopSyn chan struct{}
+	stopAck chan struct{}
+
+	*cla.ByteCounts
+}
+
+// datagramWriter writes each call's data as a single datagram to the remote address.
+type datagramWriter struct {
+	conn       *net.UDPConn
+	remoteAddr *net.UDPAddr
+}
+
+func (dw datagramWriter) Write(p []byte) (int, error) {
+	return dw.conn.WriteToUDP(p, dw.remoteAddr)
+}
+
+// NewUDPClient creates a new UDPClient, sending to the given address, e.g., "192.0.2.23:4556", for the registered
+// endpoint ID. The permanent flag indicates if this UDPClient should never be removed from the core.
+func NewUDPClient(address string, peer bpv7.EndpointID, permanent bool) *UDPClient {
+	return &UDPClient{
+		address:   address,
+		peer:      peer,
+		permanent: permanent,
+		mtu:       MaxDatagramSize,
+
+		ByteCounts: new(cla.ByteCounts),
+	}
+}
+
+// SetMTU limits the size of the sent datagrams, e.g., to avoid IP fragmentation. Larger bundles will be refused.
+//
+// This should be set before starting the UDPClient.
+func (client *UDPClient) SetMTU(mtu int) {
+	client.mtu = mtu
+}
+
+// MTU returns the largest bundle size to be sent within one datagram.
+func (client *UDPClient) MTU() int {
+	return client.mtu
+}
+
+func (client *UDPClient) Start() (err error, retry bool) {
+	if client.remoteAddr, err = net.ResolveUDPAddr("udp", client.address); err != nil {
+		return
+	}
+
+	if client.conn, err = net.ListenUDP("udp", nil); err != nil {
+		retry = true
+		return
+	}
+
+	client.reportChan = make(chan cla.ConvergenceStatus)
+	client.failChan = make(chan struct{}, 1)
+	client.stopSyn = make(chan struct{})
+	client.stopAck = make(chan struct{})
+
+	go client.handler()
+	return
+}
+
+func (client *UDPClient) handler() {
+	defer func() {
+		_ = client.conn.Close()
+
+		close(client.reportChan)
+		close(client.stopAck)
+	}()
+
+	// As UDP is connectionless, the peer is assumed to be present until sending fails.
+	if !client.report(cla.NewConvergencePeerAppeared(client, client.GetPeerEndpointID())) {
+		return
+	}
+
+	for {
+		select {
+		case <-client.stopSyn:
+			return
+
+		case <-client.failChan:
+			if !client.report(cla.NewConvergencePeerDisappeared(client, client.GetPeerEndpointID())) {
+				return
+			}
+		}
+	}
+}
+
+// report a ConvergenceStatus, unless this UDPClient is being closed.
+func (client *UDPClient) report(cs cla.ConvergenceStatus) bool {
+	select {
+	case <-client.stopSyn:
+		return false
+	case client.reportChan <- cs:
+		return true
+	}
+}
+
+// Send a bundle within one datagram. A bundle exceeding the MTU results in an error, without reporting the peer
+// as disappeared.
+func (client *UDPClient) Send(bndl bpv7.Bundle) error {
+	buff := new(bytes.Buffer)
+	if err := cboring.Marshal(&bndl, buff); err != nil {
+		return err
+	} else if buff.Len() > client.mtu {
+		return fmt.Errorf("bundle %v of %d bytes exceeds the MTU of %d bytes", bndl.ID(), buff.Len(), client.mtu)
+	}
+
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if _, err := client.CountingWriter(datagramWriter{client.conn, client.remoteAddr}).Write(buff.Bytes()); err != nil {
+		log.WithFields(log.Fields{
+			"client": client,
+			"bundle": bndl.ID(),
+			"error":  err,
+		}).Warn("UDPClient failed to send bundle")
+
+		select {
+		case client.failChan <- struct{}{}:
+		default:
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (client *UDPClient) Channel() chan cla.ConvergenceStatus {
+	return client.reportChan
+}
+
+func (client *UDPClient) Close() error {
+	close(client.stopSyn)
+	<-client.stopAck
+
+	return nil
+}
+
+func (client *UDPClient) GetPeerEndpointID() bpv7.EndpointID {
+	return client.peer
+}
+
+func (client *UDPClient) Address() string {
+	return fmt.Sprintf("udp://%s", client.address)
+}
+
+func (client *UDPClient) IsPermanent() bool {
+	return client.permanent
+}
+
+func (client *UDPClient) String() string {
+	return client.Address()
+}
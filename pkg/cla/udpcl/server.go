@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package udpcl
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// UDPServer receives bundles, each within a single UDP datagram, and forwards them to its channel. If the listen
+// address is a multicast address, its group will be joined. This struct implements a ConvergenceReceiver.
+type UDPServer struct {
+	listenAddress string
+	reportChan    chan cla.ConvergenceStatus
+	endpointID    bpv7.EndpointID
+	permanent     bool
+
+	conn *net.UDPConn
+
+	stopSyn chan struct{}
+	stopAck chan struct{}
+
+	*cla.ByteCounts
+	cla.BundleDecoder
+}
+
+// NewUDPServer creates a new UDPServer for the given listen address, e.g., ":4556" or "224.23.23.23:4556". The
+// permanent flag indicates if this UDPServer should never be removed from the core.
+func NewUDPServer(listenAddress string, endpointID bpv7.EndpointID, permanent bool) *UDPServer {
+	return &UDPServer{
+		listenAddress: listenAddress,
+		reportChan:    make(chan cla.ConvergenceStatus),
+		endpointID:    endpointID,
+		permanent:     permanent,
+		stopSyn:       make(chan struct{}),
+		stopAck:       make(chan struct{}),
+
+		ByteCounts: new(cla.ByteCounts),
+	}
+}
+
+func (serv *UDPServer) Start() (error, bool) {
+	udpAddr, err := net.ResolveUDPAddr("udp", serv.listenAddress)
+	if err != nil {
+		return err, false
+	}
+
+	if udpAddr.IP != nil && udpAddr.IP.IsMulticast() {
+		serv.conn, err = net.ListenMulticastUDP("udp", nil, udpAddr)
+	} else {
+		serv.conn, err = net.ListenUDP("udp", udpAddr)
+	}
+	if err != nil {
+		return err, true
+	}
+
+	go serv.handler()
+	return nil, true
+}
+
+func (serv *UDPServer) handler() {
+	defer func() {
+		close(serv.reportChan)
+		close(serv.stopAck)
+	}()
+
+	// One additional byte detects truncated datagrams, exceeding the maximum size.
+	buff := make([]byte, MaxDatagramSize+1)
+	for {
+		n, remote, err := serv.conn.ReadFromUDP(buff)
+		if err != nil {
+			select {
+			case <-serv.stopSyn:
+				return
+			default:
+				log.WithFields(log.Fields{
+					"cla":   serv,
+					"error": err,
+				}).Warn("UDPServer failed to read datagram")
+				continue
+			}
+		}
+
+		if n > MaxDatagramSize {
+			log.WithFields(log.Fields{
+				"cla":    serv,
+				"remote": remote,
+			}).Warn("UDPServer received a truncated datagram")
+			continue
+		}
+
+		bndl := new(bpv7.Bundle)
+		if err := bndl.UnmarshalCborMode(serv.CountingReader(bytes.NewReader(buff[:n])), serv.DecodeMode()); err != nil {
+			log.WithFields(log.Fields{
+				"cla":    serv,
+				"remote": remote,
+				"error":  err,
+			}).Warn("UDPServer failed to read bundle")
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"cla":    serv,
+			"remote": remote,
+			"bundle": bndl.ID(),
+		}).Debug("UDPServer received a bundle")
+
+		select {
+		case <-serv.stopSyn:
+			return
+		case serv.reportChan <- cla.NewConvergenceReceivedBundle(serv, serv.endpointID, bndl):
+		}
+	}
+}
+
+func (serv *UDPServer) Channel() chan cla.ConvergenceStatus {
+	return serv.reportChan
+}
+
+func (serv *UDPServer) Close() error {
+	close(serv.stopSyn)
+
+	err := serv.conn.Close()
+	<-serv.stopAck
+
+	return err
+}
+
+func (serv *UDPServer) GetEndpointID() bpv7.EndpointID {
+	return serv.endpointID
+}
+
+func (serv *UDPServer) Address() string {
+	return fmt.Sprintf("udp://%s", serv.listenAddress)
+}
+
+func (serv *UDPServer) IsPermanent() bool {
+	return serv.permanent
+}
+
+func (serv *UDPServer) String() string {
+	return serv.Address()
+}
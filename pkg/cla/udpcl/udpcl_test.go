@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package udpcl
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+func getRandomPort(t *testing.T) int {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func testUDPBundle(t *testing.T, payload []byte) bpv7.Bundle {
+	bndl, err := bpv7.Builder().
+		Source("dtn://sender/").
+		Destination("dtn://receiver/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock(payload).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bndl
+}
+
+func TestUDPServerClient(t *testing.T) {
+	port := getRandomPort(t)
+
+	serv := NewUDPServer(fmt.Sprintf("localhost:%d", port), bpv7.MustNewEndpointID("dtn://receiver/"), false)
+	if err, _ := serv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = serv.Close() }()
+
+	client := NewUDPClient(fmt.Sprintf("localhost:%d", port), bpv7.MustNewEndpointID("dtn://receiver/"), false)
+	if err, _ := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for range client.Channel() {
+		}
+	}()
+	defer func() { _ = client.Close() }()
+
+	for i := 0; i < 5; i++ {
+		bndl := testUDPBundle(t, make([]byte, 1000*i))
+		if err := client.Send(bndl); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+			t.Fatalf("bundle %d was not received", i)
+
+		case cs := <-serv.Channel():
+			if cs.MessageType != cla.ReceivedBundle {
+				t.Fatalf("received status of type %v", cs.MessageType)
+			} else if b := cs.Message.(cla.ConvergenceReceivedBundle).Bundle; b.ID() != bndl.ID() {
+				t.Fatalf("received bundle %v, expected %v", b.ID(), bndl.ID())
+			}
+		}
+	}
+
+	if sent, received := client.BytesSent(), serv.BytesReceived(); sent == 0 || sent != received {
+		t.Fatalf("client sent %d bytes, server received %d bytes", sent, received)
+	}
+}
+
+func TestUDPClientMTU(t *testing.T) {
+	port := getRandomPort(t)
+
+	client := NewUDPClient(fmt.Sprintf("localhost:%d", port), bpv7.MustNewEndpointID("dtn://receiver/"), false)
+	client.SetMTU(512)
+	if err, _ := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for range client.Channel() {
+		}
+	}()
+	defer func() { _ = client.Close() }()
+
+	var reporter cla.MTUReporter = client
+	if mtu := reporter.MTU(); mtu != 512 {
+		t.Fatalf("MTU is %d, expected 512", mtu)
+	}
+
+	if err := client.Send(testUDPBundle(t, make([]byte, 128))); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Send(testUDPBundle(t, make([]byte, 1024))); err == nil {
+		t.Fatal("sending a bundle exceeding the MTU did not fail")
+	}
+}
@@ -14,4 +14,13 @@ const (
 
 	// port is the default multicast UDP port used for discovery.
 	port = 35039
+
+	// ipndAddress4 is the IPv4 multicast address of the IP Neighbor Discovery (IPND), used for IPND Beacons.
+	ipndAddress4 = "224.0.0.142"
+
+	// ipndAddress6 is the IPv6 multicast address of the IP Neighbor Discovery (IPND), used for IPND Beacons.
+	ipndAddress6 = "ff02::142"
+
+	// ipndPort is the UDP port of the IP Neighbor Discovery (IPND), used for IPND Beacons.
+	ipndPort = 4551
 )
@@ -0,0 +1,293 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package discovery
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// beaconVersion is the IP Neighbor Discovery (IPND) version of a Beacon, as also used by IBR-DTN.
+const beaconVersion = 0x02
+
+// Flags of a Beacon, indicating its optional fields.
+const (
+	beaconFlagEndpoint    = 0x01
+	beaconFlagServices    = 0x02
+	beaconFlagBloomFilter = 0x04
+	beaconFlagPeriod      = 0x08
+)
+
+// beaconServiceNames maps the announceable CLAs to the service names used within a Beacon. These names differ from
+// the services of BPv6 implementations, e.g., IBR-DTN's "tcpcl" or "udpcl", as their protocols are incompatible.
+var beaconServiceNames = map[cla.CLAType]string{
+	cla.MTCP:    "mtcp",
+	cla.TCPCLv4: "tcpclv4",
+	cla.UDPCL:   "udpcl-bpv7",
+}
+
+// BeaconService is a service, e.g., a CLA, announced within a Beacon. Its Parameters are a list of key-value pairs,
+// e.g., "port=4556;".
+type BeaconService struct {
+	Name       string
+	Parameters string
+}
+
+// parameters returns the Parameters as a map.
+func (service BeaconService) parameters() map[string]string {
+	params := make(map[string]string)
+	for _, param := range strings.Split(service.Parameters, ";") {
+		if kv := strings.SplitN(param, "=", 2); len(kv) == 2 {
+			params[kv[0]] = kv[1]
+		}
+	}
+	return params
+}
+
+// Beacon is a discovery message in the wire format of the IP Neighbor Discovery (IPND) draft, draft-irtf-dtnrg-ipnd,
+// as used by other implementations, e.g., IBR-DTN. An optional bloom filter is skipped while unmarshalling.
+type Beacon struct {
+	SequenceNumber uint16
+
+	// Endpoint is the announcing node's EndpointID. It is omitted for dtn:none.
+	Endpoint bpv7.EndpointID
+
+	Services []BeaconService
+
+	// Period is the interval between two Beacons, transmitted in seconds. It is omitted for zero.
+	Period time.Duration
+}
+
+// newBeacon for a node's Announcements. If an Announcement's Endpoint differs from the node's, it is included as
+// the service's "eid" parameter.
+func newBeacon(nodeId bpv7.EndpointID, announcements []Announcement, period time.Duration) (beacon Beacon, err error) {
+	beacon = Beacon{Endpoint: nodeId, Period: period}
+
+	for _, announcement := range announcements {
+		name, ok := beaconServiceNames[announcement.Type]
+		if !ok {
+			err = fmt.Errorf("announcement's type %v is not supported within a beacon", announcement.Type)
+			return
+		}
+
+		params := fmt.Sprintf("port=%d;", announcement.Port)
+		if announcement.Endpoint.String() != nodeId.String() {
+			params += fmt.Sprintf("eid=%v;", announcement.Endpoint)
+		}
+
+		beacon.Services = append(beacon.Services, BeaconService{Name: name, Parameters: params})
+	}
+
+	return
+}
+
+// Announcements of this Beacon's services. Unknown services, e.g., from other implementations, are skipped.
+func (beacon *Beacon) Announcements() (announcements []Announcement) {
+	for _, service := range beacon.Services {
+		var claType cla.CLAType
+		var known bool
+		for t, name := range beaconServiceNames {
+			if name == service.Name {
+				claType, known = t, true
+				break
+			}
+		}
+		if !known {
+			continue
+		}
+
+		params := service.parameters()
+
+		port, err := strconv.ParseUint(params["port"], 10, 16)
+		if err != nil {
+			continue
+		}
+
+		endpoint := beacon.Endpoint
+		if eid, ok := params["eid"]; ok {
+			if endpoint, err = bpv7.NewEndpointID(eid); err != nil {
+				continue
+			}
+		}
+
+		announcements = append(announcements, Announcement{Type: claType, Endpoint: endpoint, Port: uint(port)})
+	}
+
+	return
+}
+
+// MarshalBinary creates the IPND wire format of this Beacon.
+func (beacon *Beacon) MarshalBinary() (data []byte, err error) {
+	buff := new(bytes.Buffer)
+
+	var flags byte
+	hasEndpoint := !beacon.Endpoint.Matches(bpv7.DtnNone())
+	if hasEndpoint {
+		flags |= beaconFlagEndpoint
+	}
+	if len(beacon.Services) > 0 {
+		flags |= beaconFlagServices
+	}
+	if beacon.Period > 0 {
+		flags |= beaconFlagPeriod
+	}
+
+	buff.WriteByte(beaconVersion)
+	buff.WriteByte(flags)
+	_ = binary.Write(buff, binary.BigEndian, beacon.SequenceNumber)
+
+	if hasEndpoint {
+		writeSdnvString(beacon.Endpoint.String(), buff)
+	}
+
+	if len(beacon.Services) > 0 {
+		writeSdnv(uint64(len(beacon.Services)), buff)
+		for _, service := range beacon.Services {
+			writeSdnvString(service.Name, buff)
+			writeSdnvString(service.Parameters, buff)
+		}
+	}
+
+	if beacon.Period > 0 {
+		writeSdnv(uint64(beacon.Period/time.Second), buff)
+	}
+
+	data = buff.Bytes()
+	return
+}
+
+// UnmarshalBinary parses a Beacon from its IPND wire format.
+func (beacon *Beacon) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	var header struct {
+		Version        uint8
+		Flags          uint8
+		SequenceNumber uint16
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return fmt.Errorf("reading beacon header failed: %v", err)
+	} else if header.Version != beaconVersion {
+		return fmt.Errorf("unsupported beacon version 0x%02x", header.Version)
+	}
+
+	*beacon = Beacon{SequenceNumber: header.SequenceNumber, Endpoint: bpv7.DtnNone()}
+
+	if header.Flags&beaconFlagEndpoint != 0 {
+		if eid, err := readSdnvString(r); err != nil {
+			return fmt.Errorf("reading beacon's endpoint failed: %v", err)
+		} else if beacon.Endpoint, err = parseBeaconEndpoint(eid); err != nil {
+			return err
+		}
+	}
+
+	if header.Flags&beaconFlagServices != 0 {
+		n, err := readSdnv(r)
+		if err != nil {
+			return fmt.Errorf("reading beacon's number of services failed: %v", err)
+		} else if n > uint64(r.Len()) {
+			return fmt.Errorf("beacon's number of services %d exceeds its length", n)
+		}
+
+		for i := uint64(0); i < n; i++ {
+			var service BeaconService
+			if service.Name, err = readSdnvString(r); err != nil {
+				return fmt.Errorf("reading beacon's service %d failed: %v", i, err)
+			} else if service.Parameters, err = readSdnvString(r); err != nil {
+				return fmt.Errorf("reading beacon's service %d failed: %v", i, err)
+			}
+			beacon.Services = append(beacon.Services, service)
+		}
+	}
+
+	if header.Flags&beaconFlagBloomFilter != 0 {
+		if _, err := readSdnvString(r); err != nil {
+			return fmt.Errorf("reading beacon's bloom filter failed: %v", err)
+		}
+	}
+
+	if header.Flags&beaconFlagPeriod != 0 {
+		if period, err := readSdnv(r); err != nil {
+			return fmt.Errorf("reading beacon's period failed: %v", err)
+		} else {
+			beacon.Period = time.Duration(period) * time.Second
+		}
+	}
+
+	return nil
+}
+
+// parseBeaconEndpoint parses a Beacon's EndpointID. Other implementations announce "dtn" node IDs without a
+// trailing slash, e.g., "dtn://node", which are converted into node IDs, e.g., "dtn://node/".
+func parseBeaconEndpoint(eid string) (bpv7.EndpointID, error) {
+	endpoint, err := bpv7.NewEndpointID(eid)
+	if err != nil && strings.HasPrefix(eid, "dtn://") && !strings.Contains(eid[len("dtn://"):], "/") {
+		endpoint, err = bpv7.NewEndpointID(eid + "/")
+	}
+	if err != nil {
+		return bpv7.DtnNone(), fmt.Errorf("parsing beacon's endpoint %q failed: %v", eid, err)
+	}
+	return endpoint, nil
+}
+
+// writeSdnv writes a Self-Delimiting Numeric Value, RFC 6256.
+func writeSdnv(n uint64, buff *bytes.Buffer) {
+	var tmp [10]byte
+	i := len(tmp) - 1
+
+	tmp[i] = byte(n & 0x7f)
+	for n >>= 7; n > 0; n >>= 7 {
+		i--
+		tmp[i] = byte(n&0x7f) | 0x80
+	}
+
+	buff.Write(tmp[i:])
+}
+
+// readSdnv reads a Self-Delimiting Numeric Value, RFC 6256.
+func readSdnv(r *bytes.Reader) (n uint64, err error) {
+	for {
+		var b byte
+		if b, err = r.ReadByte(); err != nil {
+			return
+		} else if n > math.MaxUint64>>7 {
+			err = fmt.Errorf("SDNV exceeds 64 bit")
+			return
+		}
+
+		n = n<<7 | uint64(b&0x7f)
+		if b&0x80 == 0 {
+			return
+		}
+	}
+}
+
+// writeSdnvString writes a string, prefixed by its length as an SDNV.
+func writeSdnvString(s string, buff *bytes.Buffer) {
+	writeSdnv(uint64(len(s)), buff)
+	buff.WriteString(s)
+}
+
+// readSdnvString reads a string, prefixed by its length as an SDNV.
+func readSdnvString(r *bytes.Reader) (string, error) {
+	l, err := readSdnv(r)
+	if err != nil {
+		return "", err
+	} else if l > uint64(r.Len()) {
+		return "", fmt.Errorf("length %d exceeds the remaining %d bytes", l, r.Len())
+	}
+
+	data := make([]byte, l)
+	_, _ = r.Read(data)
+	return string(data), nil
+}
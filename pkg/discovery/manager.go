@@ -7,6 +7,8 @@ package discovery
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -17,26 +19,116 @@ import (
 	"github.com/dtn7/dtn7-go/pkg/cla"
 	"github.com/dtn7/dtn7-go/pkg/cla/mtcp"
 	"github.com/dtn7/dtn7-go/pkg/cla/tcpclv4"
+	"github.com/dtn7/dtn7-go/pkg/cla/udpcl"
 )
 
+// peerTimeoutIntervals is the amount of announcement intervals without an Announcement after which a discovered
+// peer is considered gone.
+const peerTimeoutIntervals = 3
+
+// Format of the sent discovery messages. Received messages are accepted in both formats.
+type Format int
+
+const (
+	// FormatCBOR sends CBOR encoded Announcements, as all dtn7 versions understand.
+	FormatCBOR Format = iota
+
+	// FormatIPND sends IP Neighbor Discovery (IPND) Beacons, compare Beacon.
+	FormatIPND
+)
+
+func (format Format) String() string {
+	switch format {
+	case FormatCBOR:
+		return "cbor"
+	case FormatIPND:
+		return "ipnd"
+	default:
+		return "unknown"
+	}
+}
+
+// ManagerConfig configures the discovery messages of a Manager. Its zero value sends CBOR encoded Announcements to
+// dtn7's default multicast addresses.
+type ManagerConfig struct {
+	// Format of the sent discovery messages.
+	Format Format
+
+	// Address4 and Address6 are the multicast addresses and Port is the UDP port. Empty values default to dtn7's
+	// addresses and port for FormatCBOR and to the IPND's 224.0.0.142, ff02::142 and 4551 for FormatIPND.
+	Address4 string
+	Address6 string
+	Port     uint16
+}
+
+// withDefaults returns this ManagerConfig with the Format's default addresses and port for empty values.
+func (config ManagerConfig) withDefaults() ManagerConfig {
+	defAddress4, defAddress6, defPort := address4, address6, uint16(port)
+	if config.Format == FormatIPND {
+		defAddress4, defAddress6, defPort = ipndAddress4, ipndAddress6, ipndPort
+	}
+
+	if config.Address4 == "" {
+		config.Address4 = defAddress4
+	}
+	if config.Address6 == "" {
+		config.Address6 = defAddress6
+	}
+	if config.Port == 0 {
+		config.Port = defPort
+	}
+	return config
+}
+
+// discoveredPeer is a peer's CLA, created for a received Announcement.
+type discoveredPeer struct {
+	conv     cla.Convergable
+	lastSeen time.Time
+}
+
 // Manager publishes and receives Announcements.
 type Manager struct {
-	NodeId       bpv7.EndpointID
-	RegisterFunc func(cla.Convergable) `json:"-"`
+	NodeId         bpv7.EndpointID
+	RegisterFunc   func(cla.Convergable) `json:"-"`
+	UnregisterFunc func(cla.Convergable) `json:"-"`
+
+	peers       map[string]*discoveredPeer
+	peersMutex  sync.Mutex
+	peerTimeout time.Duration
 
-	stopChan4 chan struct{}
-	stopChan6 chan struct{}
+	format         Format
+	announcements  []byte
+	beacon         Beacon
+	sequenceNumber uint32
+
+	stopChan4      chan struct{}
+	stopChan6      chan struct{}
+	stopChanExpiry chan struct{}
 }
 
-// NewManager for Announcements will be created and started.
+// NewManager for Announcements will be created and started. The Announcements are sent either CBOR encoded or as an
+// IPND Beacon, as configured by the ManagerConfig, while both formats are received.
+//
+// A CLA is registered by registerFunc for each newly discovered peer. If a peer's Announcements are missing for
+// multiple announcement intervals, its CLA is unregistered again by unregisterFunc.
 func NewManager(
-	nodeId bpv7.EndpointID, registerFunc func(cla.Convergable),
+	nodeId bpv7.EndpointID, registerFunc, unregisterFunc func(cla.Convergable),
 	announcements []Announcement, announcementInterval time.Duration,
-	ipv4, ipv6 bool) (*Manager, error) {
+	ipv4, ipv6 bool, config ManagerConfig) (*Manager, error) {
+
+	config = config.withDefaults()
 
 	var manager = &Manager{
-		NodeId:       nodeId,
-		RegisterFunc: registerFunc,
+		NodeId:         nodeId,
+		RegisterFunc:   registerFunc,
+		UnregisterFunc: unregisterFunc,
+
+		peers:       make(map[string]*discoveredPeer),
+		peerTimeout: peerTimeoutIntervals * announcementInterval,
+
+		format: config.Format,
+
+		stopChanExpiry: make(chan struct{}),
 	}
 	if ipv4 {
 		manager.stopChan4 = make(chan struct{})
@@ -50,12 +142,23 @@ func NewManager(
 		"IPv4":          ipv4,
 		"IPv6":          ipv6,
 		"announcements": announcements,
+		"format":        config.Format,
+		"address4":      config.Address4,
+		"address6":      config.Address6,
+		"port":          config.Port,
 	}).Info("Starting Manager")
 
 	msg, err := MarshalAnnouncements(announcements)
 	if err != nil {
 		return nil, err
 	}
+	manager.announcements = msg
+
+	beacon, err := newBeacon(nodeId, announcements, announcementInterval)
+	if err != nil {
+		return nil, err
+	}
+	manager.beacon = beacon
 
 	sets := []struct {
 		active           bool
@@ -64,8 +167,8 @@ func NewManager(
 		ipVersion        peerdiscovery.IPVersion
 		notify           func(discovered peerdiscovery.Discovered)
 	}{
-		{ipv4, address4, manager.stopChan4, peerdiscovery.IPv4, manager.notify},
-		{ipv6, address6, manager.stopChan6, peerdiscovery.IPv6, manager.notify6},
+		{ipv4, config.Address4, manager.stopChan4, peerdiscovery.IPv4, manager.notify},
+		{ipv6, config.Address6, manager.stopChan6, peerdiscovery.IPv6, manager.notify6},
 	}
 
 	for _, set := range sets {
@@ -75,9 +178,9 @@ func NewManager(
 
 		set := peerdiscovery.Settings{
 			Limit:            -1,
-			Port:             fmt.Sprintf("%d", port),
+			Port:             fmt.Sprintf("%d", config.Port),
 			MulticastAddress: set.multicastAddress,
-			Payload:          msg,
+			PayloadFunc:      manager.nextPayload,
 			Delay:            announcementInterval,
 			TimeLimit:        -1,
 			StopChan:         set.stopChan,
@@ -103,9 +206,24 @@ func NewManager(
 		}
 	}
 
+	go manager.handleExpiry(announcementInterval)
+
 	return manager, nil
 }
 
+// nextPayload returns the next discovery message in the configured Format. Each Beacon has the next sequence number.
+func (manager *Manager) nextPayload() []byte {
+	if manager.format != FormatIPND {
+		return manager.announcements
+	}
+
+	beacon := manager.beacon
+	beacon.SequenceNumber = uint16(atomic.AddUint32(&manager.sequenceNumber, 1))
+
+	data, _ := beacon.MarshalBinary()
+	return data
+}
+
 func (manager *Manager) notify6(discovered peerdiscovery.Discovered) {
 	discovered.Address = fmt.Sprintf("[%s]", discovered.Address)
 
@@ -113,7 +231,7 @@ func (manager *Manager) notify6(discovered peerdiscovery.Discovered) {
 }
 
 func (manager *Manager) notify(discovered peerdiscovery.Discovered) {
-	announcements, err := UnmarshalAnnouncements(discovered.Payload)
+	announcements, err := parsePayload(discovered.Payload)
 	if err != nil {
 		log.WithError(err).WithFields(log.Fields{
 			"discovery": manager,
@@ -128,6 +246,19 @@ func (manager *Manager) notify(discovered peerdiscovery.Discovered) {
 	}
 }
 
+// parsePayload of a discovery message, either an IPND Beacon or the CBOR Announcements of older dtn7 versions.
+func parsePayload(payload []byte) ([]Announcement, error) {
+	if len(payload) > 0 && payload[0] == beaconVersion {
+		var beacon Beacon
+		if err := beacon.UnmarshalBinary(payload); err != nil {
+			return nil, err
+		}
+		return beacon.Announcements(), nil
+	}
+
+	return UnmarshalAnnouncements(payload)
+}
+
 func (manager *Manager) handleDiscovery(announcement Announcement, addr string) {
 	if manager.NodeId.SameNode(announcement.Endpoint) {
 		return
@@ -139,13 +270,29 @@ func (manager *Manager) handleDiscovery(announcement Announcement, addr string)
 		"message":   announcement,
 	}).Debug("Peer discovery received a message")
 
+	peerAddr := fmt.Sprintf("%s:%d", addr, announcement.Port)
+	peerKey := fmt.Sprintf("%v/%s", announcement.Type, peerAddr)
+
+	// A known peer's CLA is registered again, which is a no-op for a running CLA, but restarts a dropped one.
+	manager.peersMutex.Lock()
+	if peer, known := manager.peers[peerKey]; known {
+		peer.lastSeen = time.Now()
+		manager.peersMutex.Unlock()
+
+		manager.RegisterFunc(peer.conv)
+		return
+	}
+
 	var convergable cla.Convergable
 	switch announcement.Type {
 	case cla.MTCP:
-		convergable = mtcp.NewMTCPClient(fmt.Sprintf("%s:%d", addr, announcement.Port), announcement.Endpoint, false)
+		convergable = mtcp.NewMTCPClient(peerAddr, announcement.Endpoint, false)
 
 	case cla.TCPCLv4:
-		convergable = tcpclv4.DialTCP(fmt.Sprintf("%s:%d", addr, announcement.Port), manager.NodeId, false)
+		convergable = tcpclv4.DialTCP(peerAddr, manager.NodeId, false)
+
+	case cla.UDPCL:
+		convergable = udpcl.NewUDPClient(peerAddr, announcement.Endpoint, false)
 
 	default:
 		log.WithFields(log.Fields{
@@ -154,12 +301,62 @@ func (manager *Manager) handleDiscovery(announcement Announcement, addr string)
 			"type":      announcement.Type,
 			"type-no":   uint(announcement.Type),
 		}).Warn("Announcement's Type is unknown or unsupported")
+
+		manager.peersMutex.Unlock()
 		return
 	}
 
+	manager.peers[peerKey] = &discoveredPeer{conv: convergable, lastSeen: time.Now()}
+	manager.peersMutex.Unlock()
+
+	log.WithFields(log.Fields{
+		"discovery": manager,
+		"peer":      peerAddr,
+		"endpoint":  announcement.Endpoint,
+	}).Info("Peer discovery found a new peer")
+
 	manager.RegisterFunc(convergable)
 }
 
+// handleExpiry periodically removes peers without recent Announcements until the Manager is closed.
+func (manager *Manager) handleExpiry(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-manager.stopChanExpiry:
+			return
+
+		case now := <-ticker.C:
+			manager.expirePeers(now)
+		}
+	}
+}
+
+// expirePeers unregisters the CLAs of all peers whose last Announcement is older than the peer timeout.
+func (manager *Manager) expirePeers(now time.Time) {
+	manager.peersMutex.Lock()
+	defer manager.peersMutex.Unlock()
+
+	for peerKey, peer := range manager.peers {
+		if now.Sub(peer.lastSeen) <= manager.peerTimeout {
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"discovery": manager,
+			"peer":      peerKey,
+			"last_seen": peer.lastSeen,
+		}).Info("Peer discovery lost a peer, unregistering its CLA")
+
+		delete(manager.peers, peerKey)
+		if manager.UnregisterFunc != nil {
+			manager.UnregisterFunc(peer.conv)
+		}
+	}
+}
+
 // Close this Manager.
 func (manager *Manager) Close() {
 	for _, c := range []chan struct{}{manager.stopChan4, manager.stopChan6} {
@@ -167,6 +364,8 @@ func (manager *Manager) Close() {
 			c <- struct{}{}
 		}
 	}
+
+	close(manager.stopChanExpiry)
 }
 
 func (manager *Manager) String() string {
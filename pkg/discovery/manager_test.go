@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package discovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+func TestManagerPeerExpiry(t *testing.T) {
+	var registered, unregistered []cla.Convergable
+
+	manager := &Manager{
+		NodeId:         bpv7.MustNewEndpointID("dtn://me/"),
+		RegisterFunc:   func(conv cla.Convergable) { registered = append(registered, conv) },
+		UnregisterFunc: func(conv cla.Convergable) { unregistered = append(unregistered, conv) },
+		peers:          make(map[string]*discoveredPeer),
+		peerTimeout:    time.Minute,
+	}
+
+	announcements := []Announcement{
+		{Type: cla.MTCP, Endpoint: bpv7.MustNewEndpointID("dtn://peer-1/"), Port: 8000},
+		{Type: cla.MTCP, Endpoint: bpv7.MustNewEndpointID("dtn://peer-2/"), Port: 8000},
+		{Type: cla.MTCP, Endpoint: bpv7.MustNewEndpointID("dtn://me/"), Port: 8000},
+	}
+	addrs := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+
+	for i := 0; i < 2; i++ {
+		for j := range announcements {
+			manager.handleDiscovery(announcements[j], addrs[j])
+		}
+	}
+
+	// Both peers are registered twice with the same CLA, but the own Announcement is ignored.
+	if l := len(registered); l != 4 {
+		t.Fatalf("%d registrations, expected 4", l)
+	} else if l := len(manager.peers); l != 2 {
+		t.Fatalf("%d known peers, expected 2", l)
+	} else if registered[0] != registered[2] || registered[1] != registered[3] {
+		t.Fatalf("known peers were registered with new CLAs: %v", registered)
+	}
+
+	manager.expirePeers(time.Now())
+	if l := len(unregistered); l != 0 {
+		t.Fatalf("%d peers expired, expected none", l)
+	}
+
+	// Only the first peer stops announcing itself.
+	manager.peers["MTCP/10.0.0.1:8000"].lastSeen = time.Now().Add(-2 * time.Minute)
+
+	manager.expirePeers(time.Now())
+	if l := len(unregistered); l != 1 {
+		t.Fatalf("%d peers expired, expected 1", l)
+	} else if unregistered[0] != registered[0] {
+		t.Fatalf("unregistered %v, expected %v", unregistered[0], registered[0])
+	} else if l := len(manager.peers); l != 1 {
+		t.Fatalf("%d known peers, expected 1", l)
+	}
+
+	// A reappearing peer is registered again with a new CLA.
+	manager.handleDiscovery(announcements[0], addrs[0])
+	if l := len(registered); l != 5 {
+		t.Fatalf("%d registrations, expected 5", l)
+	} else if registered[4] == registered[0] {
+		t.Fatal("reappearing peer was registered with its old CLA")
+	}
+}
+
+func TestManagerConfigDefaults(t *testing.T) {
+	tests := []struct {
+		config   ManagerConfig
+		expected ManagerConfig
+	}{
+		{ManagerConfig{}, ManagerConfig{FormatCBOR, "224.23.23.23", "ff02::23", 35039}},
+		{ManagerConfig{Format: FormatIPND}, ManagerConfig{FormatIPND, "224.0.0.142", "ff02::142", 4551}},
+		{ManagerConfig{FormatIPND, "239.0.0.1", "", 9000}, ManagerConfig{FormatIPND, "239.0.0.1", "ff02::142", 9000}},
+	}
+
+	for _, test := range tests {
+		if config := test.config.withDefaults(); config != test.expected {
+			t.Fatalf("%v has defaults %v, expected %v", test.config, config, test.expected)
+		}
+	}
+}
+
+func TestManagerPayloadFormat(t *testing.T) {
+	nodeId := bpv7.MustNewEndpointID("dtn://me/")
+	announcements := []Announcement{{Type: cla.MTCP, Endpoint: nodeId, Port: 8000}}
+
+	msg, err := MarshalAnnouncements(announcements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	beacon, err := newBeacon(nodeId, announcements, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []Format{FormatCBOR, FormatIPND} {
+		manager := &Manager{format: format, announcements: msg, beacon: beacon}
+
+		payload := manager.nextPayload()
+		if isBeacon := payload[0] == beaconVersion; isBeacon != (format == FormatIPND) {
+			t.Fatalf("%v payload is a beacon: %t", format, isBeacon)
+		}
+
+		if parsed, err := parsePayload(payload); err != nil {
+			t.Fatal(err)
+		} else if len(parsed) != 1 || parsed[0] != announcements[0] {
+			t.Fatalf("%v payload contains %v, expected %v", format, parsed, announcements)
+		}
+	}
+}
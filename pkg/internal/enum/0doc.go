@@ -0,0 +1,7 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package enum maps the values of enumerations, e.g., policies, to human readable names. These are used both for
+// their String methods and for parsing them, e.g., from a configuration.
+package enum
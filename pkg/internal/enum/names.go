@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package enum
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Names of an enumeration's values, indexed by the value starting at zero. Aliases are alternative names, which are
+// only accepted by Parse.
+type Names struct {
+	kind    string
+	names   []string
+	aliases map[string]uint64
+}
+
+// NewNames for an enumeration of the given kind, e.g., "CRC policy", used within errors. The names are assigned to
+// the values zero, one, and so on.
+func NewNames(kind string, names ...string) *Names {
+	return &Names{
+		kind:    kind,
+		names:   names,
+		aliases: make(map[string]uint64),
+	}
+}
+
+// Alias adds alternative names for a value, accepted by Parse. An empty alias allows parsing an empty name, e.g., to
+// select a default value.
+func (n *Names) Alias(value uint64, aliases ...string) *Names {
+	for _, alias := range aliases {
+		n.aliases[strings.ToLower(alias)] = value
+	}
+	return n
+}
+
+// String returns the name of a value or "unknown".
+func (n *Names) String(value uint64) string {
+	if value >= uint64(len(n.names)) {
+		return "unknown"
+	}
+	return n.names[value]
+}
+
+// Parse a value from its name or an alias. The name is case-insensitive and surrounding whitespace is ignored. For
+// an unknown name, zero and an error are returned.
+func (n *Names) Parse(name string) (uint64, error) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+
+	for value, known := range n.names {
+		if normalized == known {
+			return uint64(value), nil
+		}
+	}
+	if value, ok := n.aliases[normalized]; ok {
+		return value, nil
+	}
+
+	return 0, fmt.Errorf("unknown %s %q", n.kind, name)
+}
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package enum
+
+import "testing"
+
+func TestNames(t *testing.T) {
+	names := NewNames("test policy", "none", "some").Alias(0, "").Alias(1, "Any", "all")
+
+	tests := []struct {
+		name  string
+		value uint64
+		valid bool
+	}{
+		{"none", 0, true},
+		{"", 0, true},
+		{" SOME ", 1, true},
+		{"any", 1, true},
+		{"all", 1, true},
+		{"other", 0, false},
+	}
+
+	for _, test := range tests {
+		if value, err := names.Parse(test.name); (err == nil) != test.valid {
+			t.Fatalf("parsing %q errored: %v", test.name, err)
+		} else if value != test.value {
+			t.Fatalf("parsing %q resulted in %d, expected %d", test.name, value, test.value)
+		}
+	}
+
+	for value, expected := range []string{"none", "some", "unknown"} {
+		if name := names.String(uint64(value)); name != expected {
+			t.Fatalf("value %d has name %q, expected %q", value, name, expected)
+		}
+	}
+}
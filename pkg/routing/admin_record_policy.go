@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/internal/enum"
+)
+
+// AdminRecordPolicy describes how locally received administrative records of an unknown type are handled, compare
+// bpv7.AdministrativeRecordManager. Such bundles are deleted under each policy and are never delivered as a normal
+// payload.
+type AdminRecordPolicy int
+
+const (
+	// AdminRecordPolicyLog deletes unknown administrative records and logs a warning, which is the default.
+	AdminRecordPolicyLog AdminRecordPolicy = iota
+
+	// AdminRecordPolicyDrop deletes unknown administrative records silently.
+	AdminRecordPolicyDrop
+
+	// AdminRecordPolicyReport deletes unknown administrative records, logs a warning, and sends a deletion status
+	// report to the record's report-to endpoint.
+	AdminRecordPolicyReport
+)
+
+var adminRecordPolicyNames = enum.NewNames("administrative record policy", "log", "drop", "report").Alias(0, "")
+
+func (policy AdminRecordPolicy) String() string {
+	return adminRecordPolicyNames.String(uint64(policy))
+}
+
+// ParseAdminRecordPolicy from its name, as returned by String. An empty name results in AdminRecordPolicyLog.
+func ParseAdminRecordPolicy(name string) (AdminRecordPolicy, error) {
+	policy, err := adminRecordPolicyNames.Parse(name)
+	return AdminRecordPolicy(policy), err
+}
+
+// SetAdminRecordPolicy sets the AdminRecordPolicy for locally received administrative records of an unknown type.
+func (c *Core) SetAdminRecordPolicy(policy AdminRecordPolicy) {
+	c.settingsMutex.Lock()
+	c.adminRecordPolicy = policy
+	c.settingsMutex.Unlock()
+}
+
+// unknownAdministrativeRecord checks if a bundle contains an administrative record of an unknown type.
+func unknownAdministrativeRecord(b bpv7.Bundle) (typeCode uint64, unknown bool) {
+	pb, err := b.PayloadBlock()
+	if err != nil {
+		return
+	}
+
+	_, err = bpv7.NewAdministrativeRecordFromCbor(pb.Value.(*bpv7.PayloadBlock).Data())
+
+	var unknownErr *bpv7.UnknownAdministrativeRecordError
+	if errors.As(err, &unknownErr) {
+		return unknownErr.TypeCode, true
+	}
+	return
+}
+
+// checkUnknownAdministrativeRecord handles a locally received administrative record of an unknown type based on the
+// AdminRecordPolicy. If false is returned, the bundle was deleted and must not be processed.
+func (c *Core) checkUnknownAdministrativeRecord(bp BundleDescriptor) bool {
+	typeCode, unknown := unknownAdministrativeRecord(*bp.MustBundle())
+	if !unknown {
+		return true
+	}
+
+	c.settingsMutex.RLock()
+	policy := c.adminRecordPolicy
+	c.settingsMutex.RUnlock()
+
+	logger := log.WithFields(log.Fields{
+		"bundle":    bp.ID(),
+		"type_code": typeCode,
+		"policy":    policy,
+	})
+
+	switch policy {
+	case AdminRecordPolicyDrop:
+		logger.Debug("Dropping administrative record of an unknown type")
+
+	case AdminRecordPolicyReport:
+		logger.Warn("Deleting administrative record of an unknown type, reporting it")
+
+		// Administrative records never request status reports, compare statusReportRequested.
+		if reportTo := bp.MustBundle().PrimaryBlock.ReportTo; reportTo != bpv7.DtnNone() {
+			if err := c.EmitStatusReport(bp.Id, bpv7.DeletedBundle, bpv7.BlockUnsupported, reportTo); err != nil {
+				logger.WithError(err).Warn("Failed to report deleted administrative record")
+			}
+		}
+
+	default:
+		logger.Warn("Deleting administrative record of an unknown type")
+	}
+
+	c.bundleDeletion(bp, bpv7.BlockUnsupported)
+	return false
+}
@@ -79,6 +79,11 @@ func (manager *AgentManager) HasEndpoint(eid bpv7.EndpointID) bool {
 	return agent.AppAgentHasEndpoint(manager.mux, eid)
 }
 
+// IsStale checks if a Bundle is too old for all ApplicationAgents registered for its destination.
+func (manager *AgentManager) IsStale(b bpv7.Bundle) bool {
+	return manager.mux.IsStale(b)
+}
+
 // Deliver a Bundle to a registered ApplicationAgent, addressed by the Bundle's destination.
 func (manager *AgentManager) Deliver(descriptor BundleDescriptor) error {
 	b, bErr := descriptor.Bundle()
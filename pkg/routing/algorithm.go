@@ -8,6 +8,7 @@ package routing
 import (
 	"fmt"
 	"regexp"
+	"sync"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla"
@@ -47,13 +48,27 @@ type Algorithm interface {
 	ReportPeerDisappeared(peer cla.Convergence)
 }
 
+// KnownBundleNotifier is an optional interface for an Algorithm to be notified about an already known bundle, which
+// was received again from another peer. This peer obviously possesses this bundle.
+type KnownBundleNotifier interface {
+	// NotifyKnownBundle notifies about a known bundle, received again from the given peer.
+	NotifyKnownBundle(descriptor BundleDescriptor, peer bpv7.EndpointID)
+}
+
 // RoutingConf contains necessary configuration data to initialize a routing algorithm.
 type RoutingConf struct {
 	// Algorithm is one of the implemented routing algorithms.
 	//
-	// One of: "epidemic", "spray", "binary_spray", "dtlsr", "prophet", "sensor-mule"
+	// One of: "epidemic", "gossip", "spray", "binary_spray", "dtlsr", "prophet", "metric", "sensor-mule", "flooding",
+	// "static" or the name of a custom algorithm, compare RegisterAlgorithm.
 	Algorithm string
 
+	// EpidemicConf contains data to initialize "epidemic"
+	EpidemicConf EpidemicConfig
+
+	// GossipConf contains data to initialize "gossip"
+	GossipConf GossipConfig
+
 	// SprayConf contains data to initialize "spray" or "binary_spray"
 	SprayConf SprayConfig
 
@@ -63,42 +78,106 @@ type RoutingConf struct {
 	// ProphetConf contains data to initialize "prophet"
 	ProphetConf ProphetConfig
 
+	// MetricConf contains data to initialize "metric"
+	MetricConf MetricConfig
+
 	// SensorNetworkMuleConfig contains data to initialize "sensor-mule"
 	SensorMuleConf SensorNetworkMuleConfig `toml:"sensor-mule-conf"`
+
+	// StaticConf contains data to initialize "static"
+	StaticConf StaticConfig
+
+	// CustomConf contains arbitrary data to initialize a custom algorithm, compare RegisterAlgorithm.
+	CustomConf map[string]interface{}
 }
 
-// RoutingAlgorithm from its configuration.
-func (routingConf RoutingConf) RoutingAlgorithm(c *Core) (algo Algorithm, err error) {
-	switch routingConf.Algorithm {
-	case "epidemic":
-		algo = NewEpidemicRouting(c)
+// AlgorithmConstructor creates an Algorithm for a Core based on a RoutingConf, compare RegisterAlgorithm.
+type AlgorithmConstructor func(c *Core, routingConf RoutingConf) (Algorithm, error)
 
-	case "spray":
-		algo = NewSprayAndWait(c, routingConf.SprayConf)
+var (
+	algorithms      = make(map[string]AlgorithmConstructor)
+	algorithmsMutex sync.Mutex
+)
 
-	case "binary_spray":
-		algo = NewBinarySpray(c, routingConf.SprayConf)
+func init() {
+	builtins := map[string]AlgorithmConstructor{
+		"epidemic": func(c *Core, routingConf RoutingConf) (Algorithm, error) {
+			return NewEpidemicRouting(c, routingConf.EpidemicConf), nil
+		},
+		"gossip": func(c *Core, routingConf RoutingConf) (Algorithm, error) {
+			return NewGossipRouting(c, routingConf.GossipConf), nil
+		},
+		"spray": func(c *Core, routingConf RoutingConf) (Algorithm, error) {
+			return NewSprayAndWait(c, routingConf.SprayConf), nil
+		},
+		"binary_spray": func(c *Core, routingConf RoutingConf) (Algorithm, error) {
+			return NewBinarySpray(c, routingConf.SprayConf), nil
+		},
+		"dtlsr": func(c *Core, routingConf RoutingConf) (Algorithm, error) {
+			return NewDTLSR(c, routingConf.DTLSRConf), nil
+		},
+		"prophet": func(c *Core, routingConf RoutingConf) (Algorithm, error) {
+			return NewProphet(c, routingConf.ProphetConf), nil
+		},
+		"metric": func(c *Core, routingConf RoutingConf) (Algorithm, error) {
+			return NewMetricRouting(c, routingConf.MetricConf), nil
+		},
+		"flooding": func(c *Core, _ RoutingConf) (Algorithm, error) {
+			return NewFloodingRouting(c), nil
+		},
+		"static": func(c *Core, routingConf RoutingConf) (Algorithm, error) {
+			if sr, err := NewStaticRouting(c, routingConf.StaticConf); err != nil {
+				return nil, err
+			} else {
+				return sr, nil
+			}
+		},
+		"sensor-mule": func(c *Core, routingConf RoutingConf) (Algorithm, error) {
+			if muleAlgo, err := routingConf.SensorMuleConf.Algorithm.RoutingAlgorithm(c); err != nil {
+				return nil, err
+			} else if sensorNode, err := regexp.Compile(routingConf.SensorMuleConf.SensorNodeRegex); err != nil {
+				return nil, err
+			} else {
+				return NewSensorNetworkMuleRouting(muleAlgo, sensorNode), nil
+			}
+		},
+	}
 
-	case "dtlsr":
-		algo = NewDTLSR(c, routingConf.DTLSRConf)
+	for name, constructor := range builtins {
+		_ = RegisterAlgorithm(name, constructor)
+	}
+}
 
-	case "prophet":
-		algo = NewProphet(c, routingConf.ProphetConf)
+// RegisterAlgorithm makes a routing Algorithm available by its name, which can be selected in a RoutingConf.
+// This allows custom algorithms outside this package. The name must neither be empty nor already be registered.
+//
+// Algorithms should be registered before creating a Core, e.g., within an init function.
+func RegisterAlgorithm(name string, constructor AlgorithmConstructor) error {
+	algorithmsMutex.Lock()
+	defer algorithmsMutex.Unlock()
+
+	if name == "" {
+		return fmt.Errorf("routing algorithm's name is empty")
+	} else if _, exists := algorithms[name]; exists {
+		return fmt.Errorf("routing algorithm %s is already registered", name)
+	}
 
-	case "sensor-mule":
-		if muleAlgo, muleAlgoErr := routingConf.SensorMuleConf.Algorithm.RoutingAlgorithm(c); muleAlgoErr != nil {
-			err = muleAlgoErr
-		} else if sensorNode, sensorNodeErr := regexp.Compile(routingConf.SensorMuleConf.SensorNodeRegex); sensorNodeErr != nil {
-			err = sensorNodeErr
-		} else {
-			algo = NewSensorNetworkMuleRouting(muleAlgo, sensorNode)
-		}
+	algorithms[name] = constructor
+	return nil
+}
+
+// RoutingAlgorithm from its configuration.
+func (routingConf RoutingConf) RoutingAlgorithm(c *Core) (algo Algorithm, err error) {
+	algorithmsMutex.Lock()
+	constructor, ok := algorithms[routingConf.Algorithm]
+	algorithmsMutex.Unlock()
 
-	default:
+	if !ok {
 		err = fmt.Errorf("unknown routing algorithm %s", routingConf.Algorithm)
+		return
 	}
 
-	return
+	return constructor(c, routingConf)
 }
 
 // sendMetadataBundle can be used by routing algorithm to send relevant metadata to peers
@@ -6,30 +6,85 @@
 package routing
 
 import (
+	"sync"
+	"time"
+
 	log "github.com/sirupsen/logrus"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla"
 )
 
+// EpidemicConfig describes an EpidemicRouting.
+type EpidemicConfig struct {
+	// ReplicationCap limits the amount of peers this node forwards each bundle to. Zero means no limit.
+	ReplicationCap int `toml:"replication-cap"`
+
+	// SummaryInterval enables the exchange of summary vectors, sent to each new peer and periodically within this
+	// interval, e.g., "30s". An empty string disables summary vectors.
+	SummaryInterval string `toml:"summary-interval"`
+}
+
 // EpidemicRouting is an implementation of a Algorithm and behaves in a
 // flooding-based epidemic way.
+//
+// If configured, neighbors exchange summary vectors of their stored bundles. Afterwards, only bundles unknown to a
+// peer are offered. Bundles already stored by the whole neighborhood are pruned, compare pruneBundles.
 type EpidemicRouting struct {
 	c *Core
+
+	config EpidemicConfig
+
+	// summaries are the latest summary vectors received from each peer.
+	summaries      map[bpv7.EndpointID]map[bpv7.BundleID]struct{}
+	summariesMutex sync.Mutex
 }
 
 // NewEpidemicRouting creates a new EpidemicRouting Algorithm interacting
 // with the given Core.
-func NewEpidemicRouting(c *Core) *EpidemicRouting {
-	log.Debug("Initialised epidemic routing")
+func NewEpidemicRouting(c *Core, config EpidemicConfig) *EpidemicRouting {
+	if config.ReplicationCap < 0 {
+		log.WithField("replication_cap", config.ReplicationCap).Warn("Epidemic replication cap is negative, using none")
+		config.ReplicationCap = 0
+	}
 
-	return &EpidemicRouting{c: c}
+	er := &EpidemicRouting{
+		c:         c,
+		config:    config,
+		summaries: make(map[bpv7.EndpointID]map[bpv7.BundleID]struct{}),
+	}
+
+	if config.SummaryInterval != "" {
+		if interval, err := time.ParseDuration(config.SummaryInterval); err != nil {
+			log.WithField("summary_interval", config.SummaryInterval).WithError(err).Warn(
+				"Unable to parse epidemic summary interval, disabling summary vectors")
+			er.config.SummaryInterval = ""
+		} else if err := c.cron.Register("epidemic_summary", er.summaryCron, interval); err != nil {
+			log.WithError(err).Warn("Could not register epidemic summary job")
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"replication_cap":  er.config.ReplicationCap,
+		"summary_interval": er.config.SummaryInterval,
+	}).Debug("Initialised epidemic routing")
+
+	return er
+}
+
+// summaryVectors checks if summary vectors are exchanged.
+func (er *EpidemicRouting) summaryVectors() bool {
+	return er.config.SummaryInterval != ""
 }
 
 // NotifyNewBundle tells the EpidemicRouting about new bundles.
 //
 // In our case, the PreviousNodeBlock will be inspected.
 func (er *EpidemicRouting) NotifyNewBundle(bp BundleDescriptor) {
+	if er.receiveSummary(bp) {
+		return
+	}
+
 	bi, biErr := er.c.store.QueryId(bp.Id)
 	if biErr != nil {
 		log.WithFields(log.Fields{
@@ -57,29 +112,34 @@ func (er *EpidemicRouting) NotifyNewBundle(bp BundleDescriptor) {
 		return
 	}
 
-	sentEids, ok := bi.Properties["routing/epidemic/sent"].([]bpv7.EndpointID)
-	if !ok {
-		sentEids = make([]bpv7.EndpointID, 0)
-	}
-
-	// Check if PreviousNodeBlock is already known
-	for _, eids := range sentEids {
-		if eids == prevNode {
-			return
-		}
-	}
-
 	log.WithFields(log.Fields{
 		"bundle": bp.ID(),
 		"eid":    prevNode,
 	}).Debug("EpidemicRouting received an incoming bundle and checked its PreviousNodeBlock")
 
-	bi.Properties["routing/epidemic/sent"] = append(sentEids, prevNode)
-	if err := er.c.store.Update(bi); err != nil {
+	er.markSent(bp.Id, prevNode)
+}
+
+// NotifyKnownBundle marks the peer as already possessing this bundle.
+func (er *EpidemicRouting) NotifyKnownBundle(bp BundleDescriptor, peer bpv7.EndpointID) {
+	if !er.c.store.KnowsBundle(bp.Id) {
 		log.WithFields(log.Fields{
-			"error": err,
-		}).Warn("Updating BundleItem failed")
+			"bundle": bp.ID(),
+		}).Warn("Failed to proceed a non-stored Bundle")
+		return
 	}
+
+	log.WithFields(log.Fields{
+		"bundle": bp.ID(),
+		"eid":    peer,
+	}).Debug("EpidemicRouting received a known bundle from another peer")
+
+	er.markSent(bp.Id, peer)
+}
+
+// markSent adds a peer to the Core's SeenCache as already having this bundle.
+func (er *EpidemicRouting) markSent(bid bpv7.BundleID, peer bpv7.EndpointID) {
+	er.c.SeenBundles().AddPeer(bid, peer)
 }
 
 func (er *EpidemicRouting) clasForBundle(bp BundleDescriptor, updateDb bool) (css []cla.ConvergenceSender, del bool) {
@@ -92,25 +152,44 @@ func (er *EpidemicRouting) clasForBundle(bp BundleDescriptor, updateDb bool) (cs
 		return nil, false
 	}
 
-	css, sentEids := filterCLAs(bi, er.c.claManager.Sender(), "epidemic")
+	css = er.c.SeenBundles().unseenSenders(bp.Id, er.c.claManager.Sender())
+
+	if er.config.ReplicationCap > 0 {
+		replications, _ := bi.Properties["routing/epidemic/replications"].(int)
+
+		remaining := er.config.ReplicationCap - replications
+		if remaining < 0 {
+			remaining = 0
+		}
+		if len(css) > remaining {
+			css = css[:remaining]
+		}
+
+		bi.Properties["routing/epidemic/replications"] = replications + len(css)
+	}
 
 	log.WithFields(log.Fields{
 		"bundle": bp.ID(),
-		"sent":   sentEids,
+		"sent":   er.c.SeenBundles().Peers(bp.Id),
 	}).Debug("EpidemicRouting is processing an outgoing bundle")
 
 	if updateDb {
-		bi.Properties["routing/epidemic/sent"] = sentEids
-		if err := er.c.store.Update(bi); err != nil {
-			log.WithFields(log.Fields{
-				"error": err,
-			}).Warn("Updating BundleItem failed")
+		for _, cs := range css {
+			er.markSent(bp.Id, cs.GetPeerEndpointID())
+		}
+
+		if er.config.ReplicationCap > 0 {
+			if err := er.c.store.Update(bi); err != nil {
+				log.WithFields(log.Fields{
+					"error": err,
+				}).Warn("Updating BundleItem failed")
+			}
 		}
 	}
 
 	log.WithFields(log.Fields{
 		"bundle":              bp.ID(),
-		"sent":                sentEids,
+		"sent":                er.c.SeenBundles().Peers(bp.Id),
 		"convergence-senders": css,
 	}).Debug("EpidemicRouting selected Convergence Senders for an outbounding bundle")
 
@@ -149,8 +228,12 @@ func (er *EpidemicRouting) DispatchingAllowed(bp BundleDescriptor) bool {
 	return len(css) > 0
 }
 
-// SenderForBundle returns the Core's ConvergenceSenders.
+// SenderForBundle returns the Core's ConvergenceSenders. Summary vectors are not forwarded, but only sent directly.
 func (er *EpidemicRouting) SenderForBundle(bp BundleDescriptor) (css []cla.ConvergenceSender, del bool) {
+	if isSummaryBundle(bp) {
+		return nil, true
+	}
+
 	return er.clasForBundle(bp, true)
 }
 
@@ -163,25 +246,20 @@ func (er *EpidemicRouting) ReportFailure(bp BundleDescriptor, sender cla.Converg
 		return
 	}
 
-	sentEids, ok := bi.Properties["routing/epidemic/sent"].([]bpv7.EndpointID)
-	if !ok {
-		sentEids = make([]bpv7.EndpointID, 0)
-	}
-
 	log.WithFields(log.Fields{
 		"bundle":  bp.ID(),
 		"bad_cla": sender,
-		"sent":    sentEids,
+		"sent":    er.c.SeenBundles().Peers(bp.Id),
 	}).Debug("EpidemicRouting failed to transmit to CLA")
 
-	for i := 0; i < len(sentEids); i++ {
-		if sentEids[i] == sender.GetPeerEndpointID() {
-			sentEids = append(sentEids[:i], sentEids[i+1:]...)
-			break
-		}
+	er.c.SeenBundles().RemovePeer(bp.Id, sender.GetPeerEndpointID())
+
+	replications, ok := bi.Properties["routing/epidemic/replications"].(int)
+	if !ok || replications == 0 {
+		return
 	}
 
-	bi.Properties["routing/epidemic/sent"] = sentEids
+	bi.Properties["routing/epidemic/replications"] = replications - 1
 	if err := er.c.store.Update(bi); err != nil {
 		log.WithFields(log.Fields{
 			"error": err,
@@ -189,9 +267,21 @@ func (er *EpidemicRouting) ReportFailure(bp BundleDescriptor, sender cla.Converg
 	}
 }
 
-func (_ *EpidemicRouting) ReportPeerAppeared(_ cla.Convergence) {}
+// ReportPeerAppeared sends this node's summary vector to a new peer, if configured.
+func (er *EpidemicRouting) ReportPeerAppeared(peer cla.Convergence) {
+	if cs, ok := peer.(cla.ConvergenceSender); ok && er.summaryVectors() {
+		er.sendSummary(cs.GetPeerEndpointID())
+	}
+}
 
-func (_ *EpidemicRouting) ReportPeerDisappeared(_ cla.Convergence) {}
+// ReportPeerDisappeared forgets a peer's summary vector, as it is no longer part of the neighborhood.
+func (er *EpidemicRouting) ReportPeerDisappeared(peer cla.Convergence) {
+	if cs, ok := peer.(cla.ConvergenceSender); ok {
+		er.summariesMutex.Lock()
+		delete(er.summaries, cs.GetPeerEndpointID())
+		er.summariesMutex.Unlock()
+	}
+}
 
 func (_ *EpidemicRouting) String() string {
 	return "epidemic"
@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// isSummaryBundle checks if a bundle carries an EpidemicRouting's summary vector.
+func isSummaryBundle(bp BundleDescriptor) bool {
+	_, err := bp.MustBundle().ExtensionBlock(bpv7.ExtBlockTypeSummaryVectorBlock)
+	return err == nil
+}
+
+// sendSummary sends this node's summary vector, the BundleIDs of all stored bundles, to a peer.
+func (er *EpidemicRouting) sendSummary(peer bpv7.EndpointID) {
+	bis, err := er.c.store.QueryAll()
+	if err != nil {
+		log.WithError(err).Warn("Failed to fetch bundles for the summary vector")
+		return
+	}
+
+	bids := make([]bpv7.BundleID, 0, len(bis))
+	for _, bi := range bis {
+		bids = append(bids, bi.BId)
+	}
+
+	log.WithFields(log.Fields{
+		"peer":    peer,
+		"bundles": len(bids),
+	}).Debug("EpidemicRouting sends its summary vector")
+
+	if err := sendMetadataBundle(er.c, er.c.NodeId, peer, bpv7.NewSummaryVectorBlock(bids)); err != nil {
+		log.WithField("peer", peer).WithError(err).Warn("Unable to send summary vector")
+	}
+}
+
+// receiveSummary inspects a bundle for a peer's summary vector and returns true for such a bundle.
+//
+// All listed bundles are marked as being sent to this peer and will not be offered to it anymore.
+func (er *EpidemicRouting) receiveSummary(bp BundleDescriptor) bool {
+	svBlock, err := bp.MustBundle().ExtensionBlock(bpv7.ExtBlockTypeSummaryVectorBlock)
+	if err != nil {
+		return false
+	}
+
+	peer := bp.MustBundle().PrimaryBlock.SourceNode
+	if !bp.MustBundle().PrimaryBlock.Destination.SameNode(er.c.NodeId) || peer.SameNode(er.c.NodeId) {
+		return true
+	}
+
+	bids := svBlock.Value.(*bpv7.SummaryVectorBlock).BundleIDs()
+	summary := make(map[bpv7.BundleID]struct{}, len(bids))
+
+	for _, bid := range bids {
+		summary[bid] = struct{}{}
+
+		if er.c.store.KnowsBundle(bid) {
+			er.markSent(bid, peer)
+		}
+	}
+
+	er.summariesMutex.Lock()
+	er.summaries[peer] = summary
+	er.summariesMutex.Unlock()
+
+	log.WithFields(log.Fields{
+		"peer":    peer,
+		"bundles": len(bids),
+	}).Debug("EpidemicRouting received a summary vector")
+
+	return true
+}
+
+// summaryCron periodically exchanges summary vectors with all neighbors and prunes bundles afterwards.
+func (er *EpidemicRouting) summaryCron() {
+	for _, cs := range er.c.claManager.Sender() {
+		er.sendSummary(cs.GetPeerEndpointID())
+	}
+
+	er.pruneBundles()
+}
+
+// pruneBundles deletes pending bundles which are already stored by every current neighbor, based on their latest
+// summary vectors. Bundles from or for this node are kept, as well as all bundles while some neighbor's summary vector
+// is missing. Pruning is no deletion in terms of the Bundle Protocol; thus, no status reports are sent.
+func (er *EpidemicRouting) pruneBundles() {
+	css := er.c.claManager.Sender()
+	if len(css) == 0 {
+		return
+	}
+
+	er.summariesMutex.Lock()
+	summaries := make([]map[bpv7.BundleID]struct{}, 0, len(css))
+	for _, cs := range css {
+		summary, ok := er.summaries[cs.GetPeerEndpointID()]
+		if !ok {
+			er.summariesMutex.Unlock()
+			return
+		}
+		summaries = append(summaries, summary)
+	}
+	er.summariesMutex.Unlock()
+
+	bis, err := er.c.store.QueryPending()
+	if err != nil {
+		log.WithError(err).Warn("Failed to fetch pending bundles for pruning")
+		return
+	}
+
+	for _, bi := range bis {
+		bp := NewBundleDescriptor(bi.BId, er.c.store)
+		if b, err := bp.Bundle(); err != nil || bi.AwaitingAck ||
+			er.c.HasEndpoint(b.PrimaryBlock.SourceNode) || er.c.HasEndpoint(b.PrimaryBlock.Destination) {
+			continue
+		}
+
+		known := true
+		for _, summary := range summaries {
+			if _, ok := summary[bi.BId]; !ok {
+				known = false
+				break
+			}
+		}
+		if !known {
+			continue
+		}
+
+		log.WithField("bundle", bi.BId).Info("EpidemicRouting prunes a bundle known to the whole neighborhood")
+
+		if err := er.c.store.Delete(bi.BId); err != nil {
+			log.WithField("bundle", bi.BId).WithError(err).Warn("Failed to prune bundle")
+		}
+	}
+}
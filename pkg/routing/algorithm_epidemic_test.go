@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// sentPayloadBundles returns the IDs of all bundles sent by a mockConvSender, except summary vectors.
+func sentPayloadBundles(m *mockConvSender) (bids []bpv7.BundleID) {
+	for _, b := range m.sent() {
+		if _, err := b.ExtensionBlock(bpv7.ExtBlockTypeSummaryVectorBlock); err != nil {
+			bids = append(bids, b.ID())
+		}
+	}
+	return
+}
+
+func TestEpidemicRoutingReplicationCap(t *testing.T) {
+	conf := RoutingConf{Algorithm: "epidemic", EpidemicConf: EpidemicConfig{ReplicationCap: 2}}
+	testCoreRouting(t, conf, func(c *Core) {
+		peers := make([]*mockConvSender, 3)
+		for i := range peers {
+			peers[i] = newMockConvSender(fmt.Sprintf("mock://%d", i), bpv7.MustNewEndpointID(fmt.Sprintf("dtn://%d/", i)))
+			c.RegisterConvergable(peers[i])
+		}
+
+		b := testCoreBundle(t, "dtn://core/", "dtn://dst/")
+		c.SendBundle(&b)
+		c.checkPendingBundles()
+
+		var replications int
+		for _, peer := range peers {
+			replications += len(sentPayloadBundles(peer))
+		}
+		if replications != 2 {
+			t.Fatalf("bundle was replicated %d times, expected 2", replications)
+		}
+	})
+}
+
+func TestEpidemicRoutingSummaryVector(t *testing.T) {
+	conf := RoutingConf{Algorithm: "epidemic", EpidemicConf: EpidemicConfig{SummaryInterval: "1h"}}
+	testCoreRouting(t, conf, func(c *Core) {
+		er := c.routing.(*EpidemicRouting)
+
+		known := testCoreBundle(t, "dtn://core/", "dtn://dst/")
+		c.SendBundle(&known)
+
+		relayed := testCoreBundle(t, "dtn://other/", "dtn://far/")
+		relayedCopy := relayed
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &relayedCopy})
+
+		summary, err := bpv7.Builder().
+			BundleCtrlFlags(bpv7.MustNotFragmented).
+			Source("dtn://peer/").
+			Destination(c.NodeId).
+			CreationTimestampNow().
+			Lifetime("1m").
+			PayloadBlock(byte(1)).
+			Canonical(bpv7.NewSummaryVectorBlock([]bpv7.BundleID{known.ID(), relayed.ID()})).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &summary})
+
+		peer := newMockConvSender("mock://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+		c.RegisterConvergable(peer)
+
+		unknown := testCoreBundle(t, "dtn://core/", "dtn://dst/")
+		c.SendBundle(&unknown)
+		c.checkPendingBundles()
+
+		if bids := sentPayloadBundles(peer); len(bids) != 1 || bids[0] != unknown.ID() {
+			t.Fatalf("expected only the unknown bundle to be offered, got %v", bids)
+		}
+
+		// The relayed bundle is known to the whole neighborhood, the own bundle is kept nevertheless.
+		er.pruneBundles()
+
+		if c.store.KnowsBundle(relayed.ID()) {
+			t.Fatal("bundle known to the whole neighborhood was not pruned")
+		} else if !c.store.KnowsBundle(known.ID()) {
+			t.Fatal("bundle from this node was pruned")
+		}
+
+		er.ReportPeerDisappeared(peer)
+		if _, ok := er.summaries[peer.GetPeerEndpointID()]; ok {
+			t.Fatal("summary vector of a disappeared peer is kept")
+		}
+	})
+}
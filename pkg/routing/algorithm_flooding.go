@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// FloodingRouting is a simple Algorithm, forwarding each bundle to all currently connected peers except its
+// previous node. In contrast to the EpidemicRouting, a bundle is deleted after being forwarded once and not carried
+// to peers appearing later. If no peer is connected, the bundle is kept until the next forwarding attempt.
+type FloodingRouting struct {
+	c *Core
+}
+
+// NewFloodingRouting creates a new FloodingRouting Algorithm interacting with the given Core.
+func NewFloodingRouting(c *Core) *FloodingRouting {
+	log.Debug("Initialised flooding routing")
+
+	return &FloodingRouting{c: c}
+}
+
+// NotifyNewBundle stores the bundle's previous node, as the PreviousNodeBlock is replaced before forwarding.
+func (fr *FloodingRouting) NotifyNewBundle(bp BundleDescriptor) {
+	pnBlock, err := bp.MustBundle().ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock)
+	if err != nil {
+		return
+	}
+
+	bi, biErr := fr.c.store.QueryId(bp.Id)
+	if biErr != nil {
+		log.WithFields(log.Fields{
+			"error": biErr,
+		}).Warn("Failed to proceed a non-stored Bundle")
+		return
+	}
+
+	bi.Properties["routing/flooding/previous"] = pnBlock.Value.(*bpv7.PreviousNodeBlock).Endpoint()
+	if err := fr.c.store.Update(bi); err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Warn("Updating BundleItem failed")
+	}
+}
+
+// DispatchingAllowed always allows dispatching.
+func (_ *FloodingRouting) DispatchingAllowed(_ BundleDescriptor) bool {
+	return true
+}
+
+// SenderForBundle returns all ConvergenceSenders except the one of the bundle's previous node. The bundle should be
+// deleted afterwards.
+func (fr *FloodingRouting) SenderForBundle(bp BundleDescriptor) (css []cla.ConvergenceSender, del bool) {
+	prevNode := bpv7.DtnNone()
+	if bi, err := fr.c.store.QueryId(bp.Id); err == nil {
+		if eid, ok := bi.Properties["routing/flooding/previous"].(bpv7.EndpointID); ok {
+			prevNode = eid
+		}
+	}
+
+	for _, cs := range fr.c.claManager.Sender() {
+		if prevNode == bpv7.DtnNone() || !cs.GetPeerEndpointID().SameNode(prevNode) {
+			css = append(css, cs)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":              bp.ID(),
+		"previous_node":       prevNode,
+		"convergence-senders": css,
+	}).Debug("FloodingRouting selected Convergence Senders for an outbounding bundle")
+
+	del = true
+	return
+}
+
+func (_ *FloodingRouting) ReportFailure(_ BundleDescriptor, _ cla.ConvergenceSender) {}
+
+func (_ *FloodingRouting) ReportPeerAppeared(_ cla.Convergence) {}
+
+func (_ *FloodingRouting) ReportPeerDisappeared(_ cla.Convergence) {}
+
+func (_ *FloodingRouting) String() string {
+	return "flooding"
+}
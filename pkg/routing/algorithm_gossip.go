@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// GossipConfig describes a GossipRouting.
+type GossipConfig struct {
+	// Probability for each eligible ConvergenceSender to be selected, within (0, 1].
+	Probability float64
+
+	// MaxSenders limits the amount of selected ConvergenceSenders for each forwarding attempt. Zero means no limit.
+	MaxSenders int `toml:"max-senders"`
+}
+
+// GossipRouting is a probabilistic variant of the EpidemicRouting. Instead of flooding a bundle to all eligible
+// ConvergenceSenders, each sender is only selected with a configured probability. This reduces the overhead in dense
+// networks. Unselected senders might still receive the bundle in a later forwarding attempt.
+type GossipRouting struct {
+	*EpidemicRouting
+
+	config GossipConfig
+
+	rnd      *rand.Rand
+	rndMutex sync.Mutex
+}
+
+// NewGossipRouting creates a new GossipRouting Algorithm interacting with the given Core.
+func NewGossipRouting(c *Core, config GossipConfig) *GossipRouting {
+	if config.Probability <= 0 || config.Probability > 1 {
+		log.WithField("probability", config.Probability).Warn("Gossip probability is out of range, using 0.5")
+		config.Probability = 0.5
+	}
+
+	log.WithFields(log.Fields{
+		"probability": config.Probability,
+		"max_senders": config.MaxSenders,
+	}).Debug("Initialised gossip routing")
+
+	return &GossipRouting{
+		EpidemicRouting: NewEpidemicRouting(c, EpidemicConfig{}),
+		config:          config,
+		rnd:             rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// selectRandomSenders selects each ConvergenceSender with the given probability. At most maxSenders senders will be
+// returned, unless maxSenders is zero.
+func selectRandomSenders(rnd *rand.Rand, css []cla.ConvergenceSender, probability float64, maxSenders int) (selected []cla.ConvergenceSender) {
+	for _, i := range rnd.Perm(len(css)) {
+		if maxSenders > 0 && len(selected) >= maxSenders {
+			break
+		}
+
+		if rnd.Float64() < probability {
+			selected = append(selected, css[i])
+		}
+	}
+	return
+}
+
+// SenderForBundle returns a random subset of the eligible ConvergenceSenders.
+func (gr *GossipRouting) SenderForBundle(bp BundleDescriptor) (css []cla.ConvergenceSender, del bool) {
+	eligible, _ := gr.clasForBundle(bp, false)
+
+	gr.rndMutex.Lock()
+	css = selectRandomSenders(gr.rnd, eligible, gr.config.Probability, gr.config.MaxSenders)
+	gr.rndMutex.Unlock()
+
+	for _, cs := range css {
+		gr.markSent(bp.Id, cs.GetPeerEndpointID())
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":              bp.ID(),
+		"eligible":            len(eligible),
+		"convergence-senders": css,
+	}).Debug("GossipRouting selected Convergence Senders for an outbounding bundle")
+
+	del = false
+	return
+}
+
+func (_ *GossipRouting) String() string {
+	return "gossip"
+}
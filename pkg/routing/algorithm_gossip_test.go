@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+func TestSelectRandomSenders(t *testing.T) {
+	const (
+		senderNo = 100
+		trials   = 1000
+	)
+
+	css := make([]cla.ConvergenceSender, senderNo)
+	for i := 0; i < senderNo; i++ {
+		css[i] = newMockConvSender(fmt.Sprintf("mock://%d", i), bpv7.MustNewEndpointID(fmt.Sprintf("dtn://%d/", i)))
+	}
+
+	rnd := rand.New(rand.NewSource(23))
+
+	var selected int
+	for i := 0; i < trials; i++ {
+		selected += len(selectRandomSenders(rnd, css, 0.5, 0))
+	}
+
+	if avg := float64(selected) / trials; avg < 0.45*senderNo || avg > 0.55*senderNo {
+		t.Fatalf("average of %f selected senders is not roughly the half of %d", avg, senderNo)
+	}
+
+	for i := 0; i < trials; i++ {
+		if l := len(selectRandomSenders(rnd, css, 0.5, 10)); l > 10 {
+			t.Fatalf("selected %d senders, exceeding the maximum of 10", l)
+		}
+	}
+}
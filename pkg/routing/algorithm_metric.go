@@ -0,0 +1,274 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/storage"
+)
+
+// MetricConfig contains data to initialize "metric".
+type MetricConfig struct {
+	// LinkCost is added to a bundle's RoutingMetricBlock when forwarding it. An unset value defaults to 1.
+	LinkCost uint64
+}
+
+// MetricRouting is an Algorithm forwarding bundles along the path of the lowest known cost, based on the
+// bpv7.RoutingMetricBlock.
+//
+// Costs are learned from received bundles: a bundle from some source node, received from a peer with a metric,
+// indicates that this node is reachable via this peer for this cost. A bundle is only forwarded to the connected
+// peers of the lowest cost for its destination. If no cost is known, the bundle is flooded like by EpidemicRouting.
+type MetricRouting struct {
+	c        *Core
+	linkCost uint64
+
+	// costs maps source nodes to the peers they were received from and the last received metric.
+	costs      map[bpv7.EndpointID]map[bpv7.EndpointID]uint64
+	costsMutex sync.Mutex
+}
+
+// NewMetricRouting creates a new MetricRouting Algorithm interacting with the given Core.
+func NewMetricRouting(c *Core, config MetricConfig) *MetricRouting {
+	linkCost := config.LinkCost
+	if linkCost == 0 {
+		linkCost = 1
+	}
+
+	log.WithField("link_cost", linkCost).Debug("Initialised metric routing")
+
+	return &MetricRouting{
+		c:        c,
+		linkCost: linkCost,
+		costs:    make(map[bpv7.EndpointID]map[bpv7.EndpointID]uint64),
+	}
+}
+
+// NotifyNewBundle learns the cost to the bundle's source node from its RoutingMetricBlock and PreviousNodeBlock.
+//
+// The received metric is stored to be updated when forwarding the bundle. Bundles created at this node start with a
+// metric of zero.
+func (mr *MetricRouting) NotifyNewBundle(bp BundleDescriptor) {
+	bi, biErr := mr.c.store.QueryId(bp.Id)
+	if biErr != nil {
+		log.WithFields(log.Fields{
+			"error": biErr,
+		}).Warn("Failed to proceed a non-stored Bundle")
+		return
+	}
+
+	bndl := bp.MustBundle()
+	source := bndl.PrimaryBlock.SourceNode
+
+	metric, hasMetric := bndl.RoutingMetric()
+	if hasMetric {
+		bi.Properties["routing/metric/received"] = metric
+	} else if mr.c.HasEndpoint(source) {
+		bi.Properties["routing/metric/received"] = uint64(0)
+	}
+
+	pnBlock, pnErr := bndl.ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock)
+	if pnErr == nil {
+		prevNode := pnBlock.Value.(*bpv7.PreviousNodeBlock).Endpoint()
+
+		sentEids, _ := bi.Properties["routing/metric/sent"].([]bpv7.EndpointID)
+		bi.Properties["routing/metric/sent"] = append(sentEids, prevNode)
+
+		if hasMetric && !mr.c.HasEndpoint(source) {
+			mr.learnCost(source, prevNode, metric)
+		}
+	}
+
+	if err := mr.c.store.Update(bi); err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Warn("Updating BundleItem failed")
+	}
+}
+
+// learnCost to reach a node via a peer.
+func (mr *MetricRouting) learnCost(node, peer bpv7.EndpointID, metric uint64) {
+	mr.costsMutex.Lock()
+	defer mr.costsMutex.Unlock()
+
+	for knownNode, peers := range mr.costs {
+		if knownNode.SameNode(node) {
+			peers[peer] = metric
+			return
+		}
+	}
+
+	mr.costs[node] = map[bpv7.EndpointID]uint64{peer: metric}
+
+	log.WithFields(log.Fields{
+		"node":   node,
+		"peer":   peer,
+		"metric": metric,
+	}).Debug("MetricRouting learned a new cost")
+}
+
+// cheapestSenders selects the ConvergenceSenders of the lowest known cost towards a destination. If no cost is
+// known for any sender, nil and false are returned.
+func (mr *MetricRouting) cheapestSenders(dst bpv7.EndpointID, senders []cla.ConvergenceSender) (css []cla.ConvergenceSender, known bool) {
+	mr.costsMutex.Lock()
+	defer mr.costsMutex.Unlock()
+
+	var peers map[bpv7.EndpointID]uint64
+	for knownNode, knownPeers := range mr.costs {
+		if knownNode.SameNode(dst) {
+			peers = knownPeers
+			break
+		}
+	}
+
+	var minCost uint64
+	for _, cs := range senders {
+		for peer, cost := range peers {
+			if !peer.SameNode(cs.GetPeerEndpointID()) {
+				continue
+			}
+
+			if !known || cost < minCost {
+				css = []cla.ConvergenceSender{cs}
+				minCost = cost
+				known = true
+			} else if cost == minCost {
+				css = append(css, cs)
+			}
+			break
+		}
+	}
+
+	return
+}
+
+func (mr *MetricRouting) clasForBundle(bp BundleDescriptor, updateDb bool) (css []cla.ConvergenceSender, del bool) {
+	bi, biErr := mr.c.store.QueryId(bp.Id)
+	if biErr != nil {
+		log.WithFields(log.Fields{
+			"bundle": bp.ID(),
+			"error":  biErr,
+		}).Warn("Failed to proceed a non-stored Bundle")
+		return nil, false
+	}
+
+	sentEids, _ := bi.Properties["routing/metric/sent"].([]bpv7.EndpointID)
+
+	senders := mr.c.claManager.Sender()
+	if cheapest, known := mr.cheapestSenders(bp.MustBundle().PrimaryBlock.Destination, senders); known {
+		senders = cheapest
+	}
+
+	css, _ = filterCLAs(bi, senders, "metric")
+
+	log.WithFields(log.Fields{
+		"bundle":              bp.ID(),
+		"sent":                sentEids,
+		"convergence-senders": css,
+	}).Debug("MetricRouting selected Convergence Senders for an outbounding bundle")
+
+	if updateDb && len(css) > 0 {
+		for _, cs := range css {
+			sentEids = append(sentEids, cs.GetPeerEndpointID())
+		}
+
+		bi.Properties["routing/metric/sent"] = sentEids
+		if err := mr.c.store.Update(bi); err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Warn("Updating BundleItem failed")
+		}
+
+		mr.updateMetric(bp, bi)
+	}
+
+	del = false
+	return
+}
+
+// updateMetric sets the bundle's RoutingMetricBlock to its received metric plus this node's link cost. A missing
+// block is added for bundles created at this node.
+func (mr *MetricRouting) updateMetric(bp BundleDescriptor, bi storage.BundleItem) {
+	received, ok := bi.Properties["routing/metric/received"].(uint64)
+	if !ok {
+		return
+	}
+
+	metric := bpv7.NewRoutingMetricBlock(received)
+	metric.Add(mr.linkCost)
+
+	bndl := bp.MustBundle()
+	if rmBlock, err := bndl.ExtensionBlock(bpv7.ExtBlockTypeRoutingMetricBlock); err == nil {
+		rmBlock.Value = metric
+		_ = rmBlock.UpdateCRC()
+	} else {
+		bndl.AddExtensionBlock(bpv7.NewCanonicalBlock(0, bpv7.ReplicateBlock, metric))
+	}
+}
+
+// DispatchingAllowed only allows dispatching, iff the bundle is addressed to this Node or if a CLA was selected.
+func (mr *MetricRouting) DispatchingAllowed(bp BundleDescriptor) bool {
+	if mr.c.HasEndpoint(bp.MustBundle().PrimaryBlock.Destination) {
+		return true
+	}
+
+	css, _ := mr.clasForBundle(bp, false)
+	if len(css) == 0 {
+		if bi, err := mr.c.store.QueryId(bp.Id); err == nil {
+			bi.Pending = true
+			if err := mr.c.store.Update(bi); err != nil {
+				log.WithFields(log.Fields{
+					"error": err,
+				}).Warn("Updating BundleItem failed")
+			}
+		}
+	}
+
+	return len(css) > 0
+}
+
+// SenderForBundle returns the ConvergenceSenders of the lowest known cost.
+func (mr *MetricRouting) SenderForBundle(bp BundleDescriptor) (css []cla.ConvergenceSender, del bool) {
+	return mr.clasForBundle(bp, true)
+}
+
+// ReportFailure removes the failed peer from the bundle's sent list.
+func (mr *MetricRouting) ReportFailure(bp BundleDescriptor, sender cla.ConvergenceSender) {
+	bi, biErr := mr.c.store.QueryId(bp.Id)
+	if biErr != nil {
+		log.WithFields(log.Fields{
+			"error": biErr,
+		}).Warn("Failed to proceed a non-stored Bundle")
+		return
+	}
+
+	sentEids, _ := bi.Properties["routing/metric/sent"].([]bpv7.EndpointID)
+	for i := 0; i < len(sentEids); i++ {
+		if sentEids[i] == sender.GetPeerEndpointID() {
+			sentEids = append(sentEids[:i], sentEids[i+1:]...)
+			break
+		}
+	}
+
+	bi.Properties["routing/metric/sent"] = sentEids
+	if err := mr.c.store.Update(bi); err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Warn("Updating BundleItem failed")
+	}
+}
+
+func (_ *MetricRouting) ReportPeerAppeared(_ cla.Convergence) {}
+
+func (_ *MetricRouting) ReportPeerDisappeared(_ cla.Convergence) {}
+
+func (_ *MetricRouting) String() string {
+	return "metric"
+}
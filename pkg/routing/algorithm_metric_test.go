@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+func TestMetricRoutingPrefersLowerMetric(t *testing.T) {
+	testCoreRouting(t, RoutingConf{Algorithm: "metric", MetricConf: MetricConfig{LinkCost: 1}}, func(c *Core) {
+		peers := map[string]*mockConvSender{}
+		for _, peer := range []string{"a", "b", "c"} {
+			peers[peer] = newMockConvSender("mock://"+peer, bpv7.MustNewEndpointID("dtn://"+peer+"/"))
+			c.RegisterConvergable(peers[peer])
+		}
+
+		// Learn that dtn://src/ is reachable via dtn://a/ for 5 and via dtn://b/ for 2.
+		for _, learn := range []struct {
+			source string
+			prev   string
+			metric int
+		}{
+			{"dtn://src/x", "dtn://a/", 5},
+			{"dtn://src/y", "dtn://b/", 2},
+		} {
+			b, err := bpv7.Builder().
+				BundleCtrlFlags(0).
+				Source(learn.source).
+				Destination("dtn://core/").
+				CreationTimestampNow().
+				Lifetime("10m").
+				PreviousNodeBlock(learn.prev).
+				RoutingMetricBlock(learn.metric).
+				PayloadBlock([]byte("hello world")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+		}
+
+		// A bundle towards dtn://src/ should only be sent to dtn://b/, the sender of the lower metric. As this bundle
+		// was received with a metric of 3, it should be forwarded with a metric of 4.
+		b, err := bpv7.Builder().
+			BundleCtrlFlags(0).
+			Source("dtn://other/").
+			Destination("dtn://src/app").
+			CreationTimestampNow().
+			Lifetime("10m").
+			PreviousNodeBlock("dtn://c/").
+			RoutingMetricBlock(3).
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+
+		if sent := peers["a"].sent(); len(sent) != 0 {
+			t.Fatalf("dtn://a/ received %d bundles", len(sent))
+		} else if sent := peers["c"].sent(); len(sent) != 0 {
+			t.Fatalf("dtn://c/ received %d bundles, but was the previous node", len(sent))
+		}
+
+		sent := peers["b"].sent()
+		if len(sent) != 1 {
+			t.Fatalf("dtn://b/ received %d bundles, expected one", len(sent))
+		} else if sent[0].ID() != b.ID() {
+			t.Fatalf("dtn://b/ received %v, expected %v", sent[0].ID(), b.ID())
+		} else if metric, ok := sent[0].RoutingMetric(); !ok || metric != 4 {
+			t.Fatalf("forwarded bundle's metric is %d (%t), expected 4", metric, ok)
+		}
+
+		// Without any known cost, bundles are flooded. Bundles of this node start with the link cost as their metric.
+		bUnknown := testCoreBundle(t, "dtn://core/", "dtn://unknown/")
+		c.SendBundle(&bUnknown)
+
+		for _, peer := range []string{"a", "b", "c"} {
+			sent := peers[peer].sent()
+			if len(sent) == 0 || sent[len(sent)-1].ID() != bUnknown.ID() {
+				t.Fatalf("dtn://%s/ did not receive the flooded bundle", peer)
+			} else if metric, ok := sent[len(sent)-1].RoutingMetric(); !ok || metric != 1 {
+				t.Fatalf("flooded bundle's metric is %d (%t), expected 1", metric, ok)
+			}
+		}
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/dtn7/dtn7-go/pkg/cla"
 )
 
+// ProphetConfig describes a Prophet. Unset values default to those of the PRoPHET paper, compare defaults.
 type ProphetConfig struct {
 	// PInit ist the prophet initialisation constant
 	PInit float64
@@ -26,6 +27,23 @@ type ProphetConfig struct {
 	AgeInterval string
 }
 
+// defaults replaces unset or invalid values by those of the PRoPHET paper and an AgeInterval of one minute.
+func (config ProphetConfig) defaults() ProphetConfig {
+	if config.PInit <= 0 || config.PInit > 1 {
+		config.PInit = 0.75
+	}
+	if config.Beta <= 0 || config.Beta > 1 {
+		config.Beta = 0.25
+	}
+	if config.Gamma <= 0 || config.Gamma > 1 {
+		config.Gamma = 0.98
+	}
+	if config.AgeInterval == "" {
+		config.AgeInterval = "1m"
+	}
+	return config
+}
+
 type Prophet struct {
 	c *Core
 	// predictabilities are this node's delivery probabilities for other nodes
@@ -39,6 +57,8 @@ type Prophet struct {
 }
 
 func NewProphet(c *Core, config ProphetConfig) *Prophet {
+	config = config.defaults()
+
 	log.WithFields(log.Fields{
 		"p_init":       config.PInit,
 		"beta":         config.Beta,
@@ -60,11 +80,11 @@ func NewProphet(c *Core, config ProphetConfig) *Prophet {
 		}).Fatal("Unable to parse duration")
 	}
 
-	err = c.cron.Register("dtlsr_recompute", prophet.ageCron, ageInterval)
+	err = c.cron.Register("prophet_age", prophet.ageCron, ageInterval)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"reason": err.Error(),
-		}).Warn("Could not register DTLSR recompute job")
+		}).Warn("Could not register Prophet age job")
 	}
 
 	// register our custom metadata-block
@@ -211,12 +231,9 @@ func (prophet *Prophet) NotifyNewBundle(bp BundleDescriptor) {
 	}
 
 	// handle non-metadata bundles
-	// TODO: this is basically copy-pasted from routing_epidemic - extract this code into a reusable function
-
-	bundleItem, err := prophet.c.store.QueryId(bp.Id)
-	if err != nil {
+	if !prophet.c.store.KnowsBundle(bp.Id) {
 		log.WithFields(log.Fields{
-			"error": err.Error(),
+			"bundle": bp.ID(),
 		}).Warn("Failed to proceed a non-stored Bundle")
 		return
 	}
@@ -237,29 +254,12 @@ func (prophet *Prophet) NotifyNewBundle(bp BundleDescriptor) {
 		return
 	}
 
-	sentEids, ok := bundleItem.Properties["routing/prophet/sent"].([]bpv7.EndpointID)
-	if !ok {
-		sentEids = make([]bpv7.EndpointID, 0)
-	}
-
-	// Check if PreviousNodeBlock is already known
-	for _, eids := range sentEids {
-		if eids == prevNode {
-			return
-		}
-	}
-
 	log.WithFields(log.Fields{
 		"bundle": bp.ID(),
 		"eid":    prevNode,
 	}).Debug("Prophet received an incomming bundle and checked its PreviousNodeBlock")
 
-	bundleItem.Properties["routing/prophet/sent"] = append(sentEids, prevNode)
-	if err := prophet.c.store.Update(bundleItem); err != nil {
-		log.WithFields(log.Fields{
-			"error": err,
-		}).Warn("Updating BundleItem failed")
-	}
+	prophet.c.SeenBundles().AddPeer(bp.Id, prevNode)
 }
 
 // TODO: dummy implementation
@@ -286,22 +286,19 @@ func (prophet *Prophet) SenderForBundle(bp BundleDescriptor) (sender []cla.Conve
 
 	delete = false
 
-	bundleItem, err := prophet.c.store.QueryId(bp.Id)
-	if err != nil {
+	if !prophet.c.store.KnowsBundle(bp.Id) {
 		log.WithFields(log.Fields{
-			"error": err.Error(),
+			"bundle": bp.ID(),
 		}).Warn("Failed to proceed a non-stored Bundle")
 		return
 	}
 
-	sentEids, ok := bundleItem.Properties["routing/prophet/sent"].([]bpv7.EndpointID)
-	if !ok {
-		sentEids = make([]bpv7.EndpointID, 0)
-	}
-
 	destination := bndl.PrimaryBlock.Destination
 	sender = make([]cla.ConvergenceSender, 0)
 
+	prophet.dataMutex.RLock()
+	defer prophet.dataMutex.RUnlock()
+
 	for _, cs := range prophet.c.claManager.Sender() {
 		peerID := cs.GetPeerEndpointID()
 		peerPred := prophet.peerPredictabilities[peerID][destination]
@@ -319,21 +316,14 @@ func (prophet *Prophet) SenderForBundle(bp BundleDescriptor) (sender []cla.Conve
 				"peerPred":    peerPred,
 			}).Debug("Found possible forwarding candidate")
 
-			skip := false
-			for _, eid := range sentEids {
-				if peerID == eid {
-					skip = true
-					log.WithFields(log.Fields{
-						"bundle": bndl.ID(),
-						"peer":   peerID,
-					}).Debug("Peer already has this bundle")
-					break
-				}
-			}
-
-			if !skip {
+			if prophet.c.SeenBundles().HasPeer(bp.Id, peerID) {
+				log.WithFields(log.Fields{
+					"bundle": bndl.ID(),
+					"peer":   peerID,
+				}).Debug("Peer already has this bundle")
+			} else {
 				sender = append(sender, cs)
-				sentEids = append(sentEids, peerID)
+				prophet.c.SeenBundles().AddPeer(bp.Id, peerID)
 				log.WithFields(log.Fields{
 					"bundle": bndl.ID(),
 					"peer":   peerID,
@@ -358,16 +348,9 @@ func (prophet *Prophet) SenderForBundle(bp BundleDescriptor) (sender []cla.Conve
 		return
 	}
 
-	bundleItem.Properties["routing/prophet/sent"] = sentEids
-	if err := prophet.c.store.Update(bundleItem); err != nil {
-		log.WithFields(log.Fields{
-			"error": err.Error(),
-		}).Warn("Updating BundleItem failed")
-	}
-
 	log.WithFields(log.Fields{
 		"bundle":              bndl.ID(),
-		"sent":                sentEids,
+		"sent":                prophet.c.SeenBundles().Peers(bp.Id),
 		"convergence-senders": sender,
 	}).Debug("Prophet selected Convergence Senders for an outgoing bundle")
 
@@ -375,50 +358,17 @@ func (prophet *Prophet) SenderForBundle(bp BundleDescriptor) (sender []cla.Conve
 }
 
 func (prophet *Prophet) ReportFailure(bp BundleDescriptor, sender cla.ConvergenceSender) {
-	bundleItem, err := prophet.c.store.QueryId(bp.Id)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"bundle": bp.ID(),
-			"error":  err.Error(),
-		}).Warn("Failed to get bundle metadata")
-		return
-	}
-
-	sentEids, ok := bundleItem.Properties["routing/prophet/sent"].([]bpv7.EndpointID)
-	if !ok {
-		// this shouldn't really happen, no?
-		log.WithFields(log.Fields{
-			"bundle": bp.ID(),
-		}).Warn("Bundle had no stored sender-list")
-		return
-	}
-
 	log.WithFields(log.Fields{
 		"bundle": bp.ID(),
 		"peer":   sender,
 	}).Info("Failed to transmit bundle")
 
-	for i := 0; i < len(sentEids); i++ {
-		if sentEids[i] == sender.GetPeerEndpointID() {
-			sentEids = append(sentEids[:i], sentEids[i+1:]...)
-			break
-		}
-	}
-
-	bundleItem.Properties["routing/prophet/sent"] = sentEids
-
-	if err := prophet.c.store.Update(bundleItem); err != nil {
-		log.WithFields(log.Fields{
-			"bundle": bp.ID(),
-			"error":  err,
-		}).Warn("Updating BundleItem failed")
-		return
-	}
+	prophet.c.SeenBundles().RemovePeer(bp.Id, sender.GetPeerEndpointID())
 
 	log.WithFields(log.Fields{
 		"bundle": bp.ID(),
 		"peer":   sender,
-		"clas":   sentEids,
+		"clas":   prophet.c.SeenBundles().Peers(bp.Id),
 	}).Debug("Removed peer from sent list")
 }
 
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestProphetPredictabilities(t *testing.T) {
+	testCoreRouting(t, RoutingConf{Algorithm: "prophet"}, func(c *Core) {
+		prophet := c.routing.(*Prophet)
+		if conf := (ProphetConfig{PInit: 0.75, Beta: 0.25, Gamma: 0.98, AgeInterval: "1m"}); prophet.config != conf {
+			t.Fatalf("unset config did not result in the defaults: %v", prophet.config)
+		}
+
+		peer := bpv7.MustNewEndpointID("dtn://peer/")
+		dst := bpv7.MustNewEndpointID("dtn://dst/")
+
+		prophet.dataMutex.Lock()
+		prophet.encounter(peer)
+		prophet.agePred(peer)
+		prophet.peerPredictabilities[peer] = map[bpv7.EndpointID]float64{dst: 0.5}
+		prophet.transitivity(peer)
+		pPeer, pDst := prophet.predictabilities[peer], prophet.predictabilities[dst]
+		prophet.dataMutex.Unlock()
+
+		if expected := 0.75 * 0.98; math.Abs(pPeer-expected) > 1e-9 {
+			t.Fatalf("predictability for the peer is %f, expected %f", pPeer, expected)
+		}
+		if expected := 0.75 * 0.98 * 0.5 * 0.25; math.Abs(pDst-expected) > 1e-9 {
+			t.Fatalf("transitive predictability is %f, expected %f", pDst, expected)
+		}
+	})
+}
+
+func TestProphetSenderForBundle(t *testing.T) {
+	testCoreRouting(t, RoutingConf{Algorithm: "prophet"}, func(c *Core) {
+		prophet := c.routing.(*Prophet)
+
+		good := newMockConvSender("mock://good", bpv7.MustNewEndpointID("dtn://good/"))
+		bad := newMockConvSender("mock://bad", bpv7.MustNewEndpointID("dtn://bad/"))
+
+		prophet.dataMutex.Lock()
+		prophet.predictabilities[bpv7.MustNewEndpointID("dtn://dst/")] = 0.2
+		prophet.peerPredictabilities[good.GetPeerEndpointID()] = map[bpv7.EndpointID]float64{
+			bpv7.MustNewEndpointID("dtn://dst/"): 0.5,
+		}
+		prophet.peerPredictabilities[bad.GetPeerEndpointID()] = map[bpv7.EndpointID]float64{
+			bpv7.MustNewEndpointID("dtn://dst/"): 0.1,
+		}
+		prophet.dataMutex.Unlock()
+
+		c.RegisterConvergable(good)
+		c.RegisterConvergable(bad)
+
+		b := testCoreBundle(t, "dtn://core/", "dtn://dst/")
+		c.SendBundle(&b)
+
+		forwarded := func(m *mockConvSender) bool {
+			for _, sent := range m.sent() {
+				if sent.ID() == b.ID() {
+					return true
+				}
+			}
+			return false
+		}
+
+		if !forwarded(good) {
+			t.Fatal("bundle was not forwarded to the peer of a higher predictability")
+		} else if forwarded(bad) {
+			t.Fatal("bundle was forwarded to the peer of a lower predictability")
+		}
+	})
+}
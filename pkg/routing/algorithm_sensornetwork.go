@@ -10,6 +10,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla"
 )
 
@@ -50,6 +51,13 @@ func (snm *SensorNetworkMuleRouting) NotifyNewBundle(bp BundleDescriptor) {
 	snm.algorithm.NotifyNewBundle(bp)
 }
 
+// NotifyKnownBundle will be handled by the underlying algorithm, if supported.
+func (snm *SensorNetworkMuleRouting) NotifyKnownBundle(bp BundleDescriptor, peer bpv7.EndpointID) {
+	if notifier, ok := snm.algorithm.(KnownBundleNotifier); ok {
+		notifier.NotifyKnownBundle(bp, peer)
+	}
+}
+
 // DispatchingAllowed if the underlying algorithm says so.
 func (snm *SensorNetworkMuleRouting) DispatchingAllowed(bp BundleDescriptor) bool {
 	return snm.algorithm.DispatchingAllowed(bp)
@@ -32,14 +32,19 @@ type SprayAndWait struct {
 	dataMutex sync.RWMutex
 }
 
-// sprayMetaData stores bundle-specific metadata
+// sprayMetaData stores bundle-specific metadata. The nodes known to have a bundle are kept in the Core's SeenCache.
 type sprayMetaData struct {
-	// sent is the list of nodes to which we have already relayed this bundle
-	sent []bpv7.EndpointID
 	// remainingCopies is the number of copies we have to distribute before we enter wait-mode
 	remainingCopies uint64
 }
 
+// markSprayPreviousNode adds a bundle's previous node to the nodes which are known to have this bundle.
+func markSprayPreviousNode(c *Core, bp BundleDescriptor) {
+	if pnBlock, err := bp.MustBundle().ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock); err == nil {
+		c.SeenBundles().AddPeer(bp.Id, pnBlock.Value.(*bpv7.PreviousNodeBlock).Endpoint())
+	}
+}
+
 // cleanupMetaData goes through stored metadata, determines if the corresponding bundle is still alive
 // and deletes metadata for expired bundles
 func cleanupMetaData(c *Core, metadata *map[bpv7.BundleID]sprayMetaData) {
@@ -86,7 +91,6 @@ func (sw *SprayAndWait) GarbageCollect() {
 func (sw *SprayAndWait) NotifyNewBundle(bp BundleDescriptor) {
 	if sw.c.HasEndpoint(bp.MustBundle().PrimaryBlock.SourceNode) {
 		metadata := sprayMetaData{
-			sent:            make([]bpv7.EndpointID, 0),
 			remainingCopies: sw.l,
 		}
 
@@ -99,14 +103,11 @@ func (sw *SprayAndWait) NotifyNewBundle(bp BundleDescriptor) {
 		}).Debug("SprayAndWait initialised new bundle from this host")
 	} else {
 		metadata := sprayMetaData{
-			sent:            make([]bpv7.EndpointID, 0),
 			remainingCopies: 1,
 		}
 
 		// if the bundle has a PreviousNodeBlock, add it to the list of nodes which we know to have the bundle
-		if pnBlock, err := bp.MustBundle().ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock); err == nil {
-			metadata.sent = append(metadata.sent, pnBlock.Value.(*bpv7.PreviousNodeBlock).Endpoint())
-		}
+		markSprayPreviousNode(sw.c, bp)
 
 		sw.dataMutex.Lock()
 		sw.bundleData[bp.Id] = metadata
@@ -144,25 +145,15 @@ func (sw *SprayAndWait) SenderForBundle(bp BundleDescriptor) (css []cla.Converge
 		return nil, false
 	}
 
-	for _, cs := range sw.c.claManager.Sender() {
+	for _, cs := range sw.c.SeenBundles().unseenSenders(bp.Id, sw.c.claManager.Sender()) {
 		// if we ran out of copies, then don't send it to any further peers
 		if metadata.remainingCopies < 2 {
 			break
 		}
 
-		var skip = false
-		for _, eid := range metadata.sent {
-			if cs.GetPeerEndpointID() == eid {
-				skip = true
-				break
-			}
-		}
-
-		if !skip {
-			css = append(css, cs)
-			metadata.sent = append(metadata.sent, cs.GetPeerEndpointID())
-			metadata.remainingCopies = metadata.remainingCopies - 1
-		}
+		css = append(css, cs)
+		sw.c.SeenBundles().AddPeer(bp.Id, cs.GetPeerEndpointID())
+		metadata.remainingCopies = metadata.remainingCopies - 1
 	}
 
 	sw.dataMutex.Lock()
@@ -198,12 +189,7 @@ func (sw *SprayAndWait) ReportFailure(bp BundleDescriptor, sender cla.Convergenc
 
 	metadata.remainingCopies = metadata.remainingCopies + 1
 
-	for i := 0; i < len(metadata.sent); i++ {
-		if metadata.sent[i] == sender.GetPeerEndpointID() {
-			metadata.sent = append(metadata.sent[:i], metadata.sent[i+1:]...)
-			break
-		}
-	}
+	sw.c.SeenBundles().RemovePeer(bp.Id, sender.GetPeerEndpointID())
 
 	sw.dataMutex.Lock()
 	sw.bundleData[bp.Id] = metadata
@@ -214,6 +200,11 @@ func (_ *SprayAndWait) ReportPeerAppeared(_ cla.Convergence) {}
 
 func (_ *SprayAndWait) ReportPeerDisappeared(_ cla.Convergence) {}
 
+// NotifyKnownBundle adds the peer to the list of nodes which are known to have this bundle.
+func (sw *SprayAndWait) NotifyKnownBundle(bp BundleDescriptor, peer bpv7.EndpointID) {
+	sw.c.SeenBundles().AddPeer(bp.Id, peer)
+}
+
 // BinarySpray implements the binary Spray and Wait routing protocol
 // In this case, each node hands over floor(copies/2) during the spray phase
 type BinarySpray struct {
@@ -271,14 +262,11 @@ func (bs *BinarySpray) NotifyNewBundle(bp BundleDescriptor) {
 	if metadataBlock, err := bp.MustBundle().ExtensionBlock(bpv7.ExtBlockTypeBinarySprayBlock); err == nil {
 		binarySprayBlock := metadataBlock.Value.(*bpv7.BinarySprayBlock)
 		metadata := sprayMetaData{
-			sent:            make([]bpv7.EndpointID, 0),
 			remainingCopies: binarySprayBlock.RemainingCopies(),
 		}
 
 		// if the bundle has a PreviousNodeBlock, add it to the list of nodes which we know to have the bundle
-		if pnBlock, err := bp.MustBundle().ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock); err == nil {
-			metadata.sent = append(metadata.sent, pnBlock.Value.(*bpv7.PreviousNodeBlock).Endpoint())
-		}
+		markSprayPreviousNode(bs.c, bp)
 
 		bs.dataMutex.Lock()
 		bs.bundleData[bp.Id] = metadata
@@ -290,7 +278,6 @@ func (bs *BinarySpray) NotifyNewBundle(bp BundleDescriptor) {
 		}).Debug("SprayAndWait received bundle from foreign host")
 	} else {
 		metadata := sprayMetaData{
-			sent:            make([]bpv7.EndpointID, 0),
 			remainingCopies: bs.l,
 		}
 
@@ -331,36 +318,26 @@ func (bs *BinarySpray) SenderForBundle(bp BundleDescriptor) (css []cla.Convergen
 		return nil, false
 	}
 
-	for _, cs := range bs.c.claManager.Sender() {
-		var skip = false
-		for _, eid := range metadata.sent {
-			if cs.GetPeerEndpointID() == eid {
-				skip = true
-				break
-			}
+	for _, cs := range bs.c.SeenBundles().unseenSenders(bp.Id, bs.c.claManager.Sender()) {
+		css = append(css, cs)
+		bs.c.SeenBundles().AddPeer(bp.Id, cs.GetPeerEndpointID())
+
+		// we send half our remaining copies
+		sendCopies := metadata.remainingCopies / 2
+		metadata.remainingCopies = metadata.remainingCopies - sendCopies
+
+		// if the bundle already has a metadata-block
+		if metadataBlock, err := bp.MustBundle().ExtensionBlock(bpv7.ExtBlockTypeBinarySprayBlock); err == nil {
+			binarySprayBlock := metadataBlock.Value.(*bpv7.BinarySprayBlock)
+			binarySprayBlock.SetCopies(sendCopies)
+		} else {
+			// if it doesn't, then create one
+			metadataBlock := bpv7.NewBinarySprayBlock(sendCopies)
+			bp.MustBundle().AddExtensionBlock(bpv7.NewCanonicalBlock(0, 0, metadataBlock))
 		}
 
-		if !skip {
-			css = append(css, cs)
-			metadata.sent = append(metadata.sent, cs.GetPeerEndpointID())
-
-			// we send half our remaining copies
-			sendCopies := metadata.remainingCopies / 2
-			metadata.remainingCopies = metadata.remainingCopies - sendCopies
-
-			// if the bundle already has a metadata-block
-			if metadataBlock, err := bp.MustBundle().ExtensionBlock(bpv7.ExtBlockTypeBinarySprayBlock); err == nil {
-				binarySprayBlock := metadataBlock.Value.(*bpv7.BinarySprayBlock)
-				binarySprayBlock.SetCopies(sendCopies)
-			} else {
-				// if it doesn't, then create one
-				metadataBlock := bpv7.NewBinarySprayBlock(sendCopies)
-				bp.MustBundle().AddExtensionBlock(bpv7.NewCanonicalBlock(0, 0, metadataBlock))
-			}
-
-			// we currently only send a bundle to a single peer at once
-			break
-		}
+		// we currently only send a bundle to a single peer at once
+		break
 	}
 
 	bs.dataMutex.Lock()
@@ -406,18 +383,14 @@ func (bs *BinarySpray) ReportFailure(bp BundleDescriptor, sender cla.Convergence
 	}
 	binarySprayBlock.SetCopies(metadata.remainingCopies + binarySprayBlock.RemainingCopies())
 
-	for i := 0; i < len(metadata.sent); i++ {
-		if metadata.sent[i] == sender.GetPeerEndpointID() {
-			metadata.sent = append(metadata.sent[:i], metadata.sent[i+1:]...)
-			break
-		}
-	}
-
-	bs.dataMutex.Lock()
-	bs.bundleData[bp.Id] = metadata
-	bs.dataMutex.Unlock()
+	bs.c.SeenBundles().RemovePeer(bp.Id, sender.GetPeerEndpointID())
 }
 
 func (_ *BinarySpray) ReportPeerAppeared(_ cla.Convergence) {}
 
 func (_ *BinarySpray) ReportPeerDisappeared(_ cla.Convergence) {}
+
+// NotifyKnownBundle adds the peer to the list of nodes which are known to have this bundle.
+func (bs *BinarySpray) NotifyKnownBundle(bp BundleDescriptor, peer bpv7.EndpointID) {
+	bs.c.SeenBundles().AddPeer(bp.Id, peer)
+}
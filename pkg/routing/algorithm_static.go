@@ -0,0 +1,177 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// StaticConfig describes a StaticRouting.
+type StaticConfig struct {
+	// Routes map destination prefixes to the node ID of their next hop, e.g., "dtn://far/" to "dtn://gateway/".
+	//
+	// A prefix consists of an endpoint's scheme and the beginning of its scheme-specific part. The route with the
+	// longest prefix matching a bundle's destination is used. A wildcard node, e.g., "dtn://*/" or "ipn:*", defines a
+	// default route for all destinations of this scheme without a more specific route.
+	Routes map[string]string
+}
+
+// staticRoute is a parsed route of a StaticConfig.
+type staticRoute struct {
+	scheme string
+	prefix string
+	via    bpv7.EndpointID
+}
+
+// StaticRouting is an Algorithm forwarding bundles along manually configured routes. A bundle is only forwarded to
+// the next hop of the route with the longest prefix matching its destination. It is kept until this next hop is
+// connected. Bundles without a matching route are not forwarded, except by a direct delivery to their destination.
+//
+// The routes can be replaced at runtime by UpdateRoutes, e.g., after obtaining this Algorithm by
+// Core.RoutingAlgorithm.
+type StaticRouting struct {
+	c *Core
+
+	routes      []staticRoute
+	routesMutex sync.RWMutex
+}
+
+// NewStaticRouting creates a new StaticRouting Algorithm interacting with the given Core. An error is returned for an
+// invalid route.
+func NewStaticRouting(c *Core, config StaticConfig) (*StaticRouting, error) {
+	routes, err := parseStaticRoutes(config)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithField("routes", len(routes)).Debug("Initialised static routing")
+
+	return &StaticRouting{c: c, routes: routes}, nil
+}
+
+// parseStaticRoutes of a StaticConfig, ordered by descending prefix length. Thus, the first matching route is the
+// most specific one.
+func parseStaticRoutes(config StaticConfig) ([]staticRoute, error) {
+	routes := make([]staticRoute, 0, len(config.Routes))
+	for destination, via := range config.Routes {
+		parts := strings.SplitN(destination, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("static route's destination %s has no scheme", destination)
+		}
+
+		scheme, prefix := parts[0], parts[1]
+		if prefix == "//*/" || prefix == "*" {
+			prefix = ""
+		}
+
+		viaEid, err := bpv7.NewEndpointID(via)
+		if err != nil {
+			return nil, fmt.Errorf("static route's next hop %s is invalid: %w", via, err)
+		}
+
+		routes = append(routes, staticRoute{scheme: scheme, prefix: prefix, via: viaEid})
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if len(routes[i].prefix) != len(routes[j].prefix) {
+			return len(routes[i].prefix) > len(routes[j].prefix)
+		}
+		return routes[i].scheme+":"+routes[i].prefix < routes[j].scheme+":"+routes[j].prefix
+	})
+
+	return routes, nil
+}
+
+// UpdateRoutes replaces all routes by those of the given StaticConfig. On an invalid route, an error is returned and
+// the previous routes are kept. Bundles already stored are checked against the new routes at their next dispatching.
+func (sr *StaticRouting) UpdateRoutes(config StaticConfig) error {
+	routes, err := parseStaticRoutes(config)
+	if err != nil {
+		return err
+	}
+
+	sr.routesMutex.Lock()
+	sr.routes = routes
+	sr.routesMutex.Unlock()
+
+	log.WithField("routes", len(routes)).Info("Updated static routes")
+
+	return nil
+}
+
+// nextHop of the route with the longest prefix matching a destination's scheme-specific part.
+func (sr *StaticRouting) nextHop(destination bpv7.EndpointID) (via bpv7.EndpointID, ok bool) {
+	if destination.EndpointType == nil {
+		return
+	}
+
+	scheme := destination.EndpointType.SchemeName()
+	ssp := strings.TrimPrefix(destination.String(), scheme+":")
+
+	sr.routesMutex.RLock()
+	defer sr.routesMutex.RUnlock()
+
+	for _, route := range sr.routes {
+		if route.scheme == scheme && strings.HasPrefix(ssp, route.prefix) {
+			return route.via, true
+		}
+	}
+	return
+}
+
+// NotifyNewBundle is ignored, as routes are static.
+func (_ *StaticRouting) NotifyNewBundle(_ BundleDescriptor) {}
+
+// DispatchingAllowed always allows dispatching.
+func (_ *StaticRouting) DispatchingAllowed(_ BundleDescriptor) bool {
+	return true
+}
+
+// SenderForBundle returns the ConvergenceSenders of the next hop. The bundle should be deleted afterwards.
+func (sr *StaticRouting) SenderForBundle(bp BundleDescriptor) (css []cla.ConvergenceSender, del bool) {
+	destination := bp.MustBundle().PrimaryBlock.Destination
+
+	via, ok := sr.nextHop(destination)
+	if !ok {
+		log.WithFields(log.Fields{
+			"bundle":      bp.ID(),
+			"destination": destination,
+		}).Debug("StaticRouting has no route for an outbounding bundle")
+		return nil, false
+	}
+
+	for _, cs := range sr.c.claManager.Sender() {
+		if cs.GetPeerEndpointID().SameNode(via) {
+			css = append(css, cs)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":              bp.ID(),
+		"via":                 via,
+		"convergence-senders": css,
+	}).Debug("StaticRouting selected Convergence Senders for an outbounding bundle")
+
+	del = len(css) > 0
+	return
+}
+
+func (_ *StaticRouting) ReportFailure(_ BundleDescriptor, _ cla.ConvergenceSender) {}
+
+func (_ *StaticRouting) ReportPeerAppeared(_ cla.Convergence) {}
+
+func (_ *StaticRouting) ReportPeerDisappeared(_ cla.Convergence) {}
+
+func (_ *StaticRouting) String() string {
+	return "static"
+}
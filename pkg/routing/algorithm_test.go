@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// customTestRouting is a custom Algorithm, registered as "custom-test".
+type customTestRouting struct {
+	*FloodingRouting
+
+	option interface{}
+}
+
+func init() {
+	_ = RegisterAlgorithm("custom-test", func(c *Core, routingConf RoutingConf) (Algorithm, error) {
+		return &customTestRouting{FloodingRouting: NewFloodingRouting(c), option: routingConf.CustomConf["option"]}, nil
+	})
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	if err := RegisterAlgorithm("epidemic", nil); err == nil {
+		t.Fatal("registering a built-in algorithm's name again did not fail")
+	} else if err := RegisterAlgorithm("", nil); err == nil {
+		t.Fatal("registering an empty name did not fail")
+	}
+
+	conf := RoutingConf{Algorithm: "custom-test", CustomConf: map[string]interface{}{"option": "foo"}}
+	testCoreRouting(t, conf, func(c *Core) {
+		if algo, ok := c.routing.(*customTestRouting); !ok {
+			t.Fatalf("routing algorithm is %T", c.routing)
+		} else if algo.option != "foo" {
+			t.Fatalf("custom configuration was not passed, option is %v", algo.option)
+		}
+	})
+
+	if _, err := (RoutingConf{Algorithm: "unknown"}).RoutingAlgorithm(nil); err == nil {
+		t.Fatal("unknown routing algorithm did not fail")
+	}
+}
+
+func TestFloodingRouting(t *testing.T) {
+	testCoreRouting(t, RoutingConf{Algorithm: "flooding"}, func(c *Core) {
+		prev := newMockConvSender("mock://prev", bpv7.MustNewEndpointID("dtn://prev/"))
+		other := newMockConvSender("mock://other", bpv7.MustNewEndpointID("dtn://other/"))
+		c.RegisterConvergable(prev)
+		c.RegisterConvergable(other)
+
+		b, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("10m").
+			PreviousNodeBlock("dtn://prev/").
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+
+		if l := len(prev.sent()); l != 0 {
+			t.Fatalf("bundle was sent back to its previous node %d times", l)
+		} else if l := len(other.sent()); l != 1 {
+			t.Fatalf("bundle was sent %d times to another peer, expected once", l)
+		} else if c.store.KnowsBundle(b.ID()) {
+			t.Fatal("flooded bundle is still stored")
+		}
+	})
+}
+
+func TestStaticRouting(t *testing.T) {
+	if _, err := (RoutingConf{
+		Algorithm:  "static",
+		StaticConf: StaticConfig{Routes: map[string]string{"far/": "dtn://gw/"}},
+	}).RoutingAlgorithm(nil); err == nil {
+		t.Fatal("invalid static route did not fail")
+	}
+
+	conf := RoutingConf{
+		Algorithm: "static",
+		StaticConf: StaticConfig{Routes: map[string]string{"dtn://far/": "dtn://gw/"}},
+	}
+	testCoreRouting(t, conf, func(c *Core) {
+		other := newMockConvSender("mock://other", bpv7.MustNewEndpointID("dtn://other/"))
+		c.RegisterConvergable(other)
+
+		routed := testCoreBundle(t, "dtn://core/", "dtn://far/app")
+		unrouted := testCoreBundle(t, "dtn://core/unrouted", "dtn://elsewhere/")
+		c.SendBundle(&routed)
+		c.SendBundle(&unrouted)
+
+		if l := len(other.sent()); l != 0 {
+			t.Fatalf("bundles were sent %d times to a peer without a route", l)
+		} else if !c.store.KnowsBundle(routed.ID()) {
+			t.Fatal("bundle was dropped while its next hop is unavailable")
+		}
+
+		gw := newMockConvSender("mock://gw", bpv7.MustNewEndpointID("dtn://gw/"))
+		c.RegisterConvergable(gw)
+		c.checkPendingBundles()
+
+		if sent := gw.sent(); len(sent) != 1 || sent[0].ID() != routed.ID() {
+			t.Fatalf("expected the routed bundle to be sent to the next hop, got %d bundles", len(sent))
+		} else if c.store.KnowsBundle(routed.ID()) {
+			t.Fatal("forwarded bundle is still stored")
+		} else if len(other.sent()) != 0 {
+			t.Fatal("bundle was sent to a peer without a route")
+		}
+
+		sr := c.RoutingAlgorithm().(*StaticRouting)
+		if err := sr.UpdateRoutes(StaticConfig{Routes: map[string]string{"dtn://far/": "invalid"}}); err == nil {
+			t.Fatal("invalid static route update did not fail")
+		}
+		if err := sr.UpdateRoutes(StaticConfig{Routes: map[string]string{
+			"dtn://far/": "dtn://gw/",
+			"dtn://*/":   "dtn://other/",
+		}}); err != nil {
+			t.Fatal(err)
+		}
+		c.checkPendingBundles()
+
+		if sent := other.sent(); len(sent) != 1 || sent[0].ID() != unrouted.ID() {
+			t.Fatalf("expected the formerly unrouted bundle to be sent to the default route, got %d bundles", len(sent))
+		} else if c.store.KnowsBundle(unrouted.ID()) {
+			t.Fatal("forwarded bundle is still stored")
+		}
+	})
+}
+
+func TestStaticRoutingNextHop(t *testing.T) {
+	sr, err := NewStaticRouting(nil, StaticConfig{Routes: map[string]string{
+		"dtn://*/":           "dtn://default/",
+		"dtn://far/":         "dtn://gw/",
+		"dtn://far/special/": "dtn://special/",
+		"ipn:23.":            "dtn://ipn-gw/",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		destination string
+		via         string
+	}{
+		{"dtn://far/", "dtn://gw/"},
+		{"dtn://far/app", "dtn://gw/"},
+		{"dtn://far/special/app", "dtn://special/"},
+		{"dtn://farther/", "dtn://default/"},
+		{"dtn://elsewhere/", "dtn://default/"},
+		{"ipn:23.1", "dtn://ipn-gw/"},
+		{"ipn:42.1", ""},
+	}
+
+	for _, test := range tests {
+		via, ok := sr.nextHop(bpv7.MustNewEndpointID(test.destination))
+		if test.via == "" {
+			if ok {
+				t.Fatalf("destination %s is routed via %v", test.destination, via)
+			}
+		} else if !ok {
+			t.Fatalf("destination %s has no route", test.destination)
+		} else if via != bpv7.MustNewEndpointID(test.via) {
+			t.Fatalf("destination %s is routed via %v, expected %s", test.destination, via, test.via)
+		}
+	}
+}
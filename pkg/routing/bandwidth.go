@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// BandwidthLimiter caps the total outbound bandwidth across all CLAs by a token bucket, shared by all transmissions.
+// This allows running a node on a metered uplink, independent of the amount of peers.
+type BandwidthLimiter struct {
+	sync.Mutex
+
+	rate       float64
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewBandwidthLimiter creates a new BandwidthLimiter for a rate in bytes per second. Bursts of up to one second's
+// worth of bytes are allowed.
+func NewBandwidthLimiter(bytesPerSecond uint64) *BandwidthLimiter {
+	return &BandwidthLimiter{
+		rate:       float64(bytesPerSecond),
+		tokens:     float64(bytesPerSecond),
+		lastRefill: time.Now(),
+	}
+}
+
+// reserve n bytes and return the duration to wait before sending them.
+func (bl *BandwidthLimiter) reserve(n int) time.Duration {
+	bl.Lock()
+	defer bl.Unlock()
+
+	now := time.Now()
+	bl.tokens += now.Sub(bl.lastRefill).Seconds() * bl.rate
+	if bl.tokens > bl.rate {
+		bl.tokens = bl.rate
+	}
+	bl.lastRefill = now
+
+	// Tokens might become negative for transmissions larger than the available tokens. The resulting debt delays
+	// this and all following transmissions.
+	bl.tokens -= float64(n)
+	if bl.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-bl.tokens / bl.rate * float64(time.Second))
+}
+
+// Wait blocks until n bytes might be sent without exceeding the bandwidth limit.
+func (bl *BandwidthLimiter) Wait(n int) {
+	if delay := bl.reserve(n); delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// byteCounter is an io.Writer only counting the written bytes.
+type byteCounter int
+
+func (bc *byteCounter) Write(p []byte) (int, error) {
+	*bc += byteCounter(len(p))
+	return len(p), nil
+}
+
+// SetBandwidthLimit caps the total outbound bandwidth of all CLAs to this amount of bytes per second. Zero disables
+// the limit.
+func (c *Core) SetBandwidthLimit(bytesPerSecond uint64) {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	if bytesPerSecond == 0 {
+		c.bandwidth = nil
+	} else {
+		c.bandwidth = NewBandwidthLimiter(bytesPerSecond)
+	}
+}
+
+// waitBandwidth blocks until the bundle might be sent within the bandwidth limit, if one is set.
+func (c *Core) waitBandwidth(b bpv7.Bundle) {
+	c.settingsMutex.RLock()
+	limiter := c.bandwidth
+	c.settingsMutex.RUnlock()
+
+	if limiter == nil {
+		return
+	}
+
+	var size byteCounter
+	if err := b.WriteBundle(&size); err != nil {
+		log.WithField("bundle", b.ID()).WithError(err).Warn("Failed to calculate bundle size for bandwidth limit")
+		return
+	}
+
+	limiter.Wait(int(size))
+}
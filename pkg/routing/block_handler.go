@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// BlockAction is a BlockHandler's decision about a received canonical block.
+type BlockAction int
+
+const (
+	// BlockKeep keeps the possibly modified block within the bundle.
+	BlockKeep BlockAction = iota
+
+	// BlockRemove removes the block from the bundle.
+	BlockRemove
+
+	// BlockDeleteBundle deletes the whole bundle.
+	BlockDeleteBundle
+)
+
+func (action BlockAction) String() string {
+	switch action {
+	case BlockKeep:
+		return "keep"
+	case BlockRemove:
+		return "remove"
+	case BlockDeleteBundle:
+		return "delete bundle"
+	default:
+		return "unknown"
+	}
+}
+
+// BlockHandler processes canonical blocks of a specific block type code for each received bundle, e.g., a routing
+// hint or a geo-location block. The block might be modified in place. Furthermore, the BundleDescriptor might be
+// altered, e.g., by adding Constraints or Tags, to influence the further processing.
+type BlockHandler interface {
+	// HandleBlock processes a received bundle's block and returns the action to be performed on this block.
+	HandleBlock(block *bpv7.CanonicalBlock, descriptor *BundleDescriptor) BlockAction
+}
+
+// BlockHandlerFunc is a function implementing the BlockHandler interface.
+type BlockHandlerFunc func(block *bpv7.CanonicalBlock, descriptor *BundleDescriptor) BlockAction
+
+// HandleBlock calls the BlockHandlerFunc itself.
+func (f BlockHandlerFunc) HandleBlock(block *bpv7.CanonicalBlock, descriptor *BundleDescriptor) BlockAction {
+	return f(block, descriptor)
+}
@@ -69,14 +69,17 @@ func NewBundleDescriptorFromBundle(b bpv7.Bundle, store *storage.Store) BundleDe
 // Sync this BundleDescriptor to the store.
 func (descriptor BundleDescriptor) Sync() error {
 	if !descriptor.store.KnowsBundle(descriptor.Id.Scrub()) {
-		return descriptor.store.Push(*descriptor.bndl)
-	} else if bi, err := descriptor.store.QueryId(descriptor.Id.Scrub()); err != nil {
+		if err := descriptor.store.Push(*descriptor.bndl); err != nil || len(descriptor.Constraints) == 0 {
+			return err
+		}
+	}
+
+	if bi, err := descriptor.store.QueryId(descriptor.Id.Scrub()); err != nil {
 		return err
 	} else if len(descriptor.Constraints) == 0 {
 		return descriptor.store.Delete(descriptor.Id)
 	} else {
-		bi.Pending = !descriptor.HasConstraint(ReassemblyPending_) &&
-			(descriptor.HasConstraint(ForwardPending) || descriptor.HasConstraint(Contraindicated))
+		descriptor.updateIndexedFields(&bi)
 
 		bi.Properties["bundlepack/receiver"] = descriptor.Receiver
 		bi.Properties["bundlepack/timestamp"] = descriptor.Timestamp
@@ -161,6 +164,25 @@ func (descriptor *BundleDescriptor) PurgeConstraints() {
 	}
 }
 
+// updateIndexedFields sets the BundleItem's fields derived from this BundleDescriptor's constraints, which are used by
+// the store's queries and its eviction.
+func (descriptor BundleDescriptor) updateIndexedFields(bi *storage.BundleItem) {
+	bi.Pending = !descriptor.HasConstraint(ReassemblyPending_) &&
+		(descriptor.HasConstraint(ForwardPending) || descriptor.HasConstraint(Contraindicated))
+	bi.AwaitingAck = descriptor.HasConstraint(AckPending)
+	bi.Protected = descriptor.HasConstraint(LocalEndpoint) || descriptor.HasConstraint(ReassemblyPending_)
+	bi.Constraints = descriptor.constraintSet()
+}
+
+// constraintSet of all constraints to be indexed by the store, compare storage.Store.QueryByConstraint.
+func (descriptor BundleDescriptor) constraintSet() storage.ConstraintSet {
+	constraints := make([]int, 0, len(descriptor.Constraints))
+	for c := range descriptor.Constraints {
+		constraints = append(constraints, int(c))
+	}
+	return storage.NewConstraintSet(constraints...)
+}
+
 // HasTag checks if this BundleDescriptor has a Tag assigned.
 func (descriptor *BundleDescriptor) HasTag(tag Tag) bool {
 	_, ok := descriptor.Tags[tag]
@@ -178,7 +200,7 @@ func (descriptor *BundleDescriptor) RemoveTag(tag Tag) {
 }
 
 // UpdateBundleAge updates the bundle's Bundle Age block based on its reception
-// timestamp, if such a block exists.
+// timestamp, if such a block exists. The age is in milliseconds, as the lifetime.
 func (descriptor *BundleDescriptor) UpdateBundleAge() (uint64, error) {
 	bndl, err := descriptor.Bundle()
 	if err != nil {
@@ -191,7 +213,8 @@ func (descriptor *BundleDescriptor) UpdateBundleAge() (uint64, error) {
 	}
 
 	age := ageBlock.Value.(*bpv7.BundleAgeBlock)
-	return age.Increment(uint64(time.Since(descriptor.Timestamp)) / 1000), nil
+	newAge := age.Increment(uint64(time.Since(descriptor.Timestamp).Milliseconds()))
+	return newAge, ageBlock.UpdateCRC()
 }
 
 func (descriptor BundleDescriptor) String() string {
@@ -1,4 +1,4 @@
-// SPDX-FileCopyrightText: 2019, 2020 Alvar Penning
+// SPDX-FileCopyrightText: 2019, 2020, 2021 Alvar Penning
 //
 // SPDX-License-Identifier: GPL-3.0-or-later
 
@@ -26,6 +26,14 @@ const (
 	// LocalEndpoint is assigned to a bundle after delivery to a local endpoint.
 	// This constraint demands storage until the endpoint removes this constraint.
 	LocalEndpoint Constraint = iota
+
+	// Retained is assigned to a delivered bundle to keep it stored for a later Core.Replay until its lifetime has
+	// expired, compare Core.SetRetainDelivered. This Constraint was not defined in dtn-bpbis.
+	Retained Constraint = iota
+
+	// AckPending is assigned to a bundle, sent by this node with a delivery guarantee, after its forwarding until its
+	// delivery is acknowledged, compare bpv7.DeliveryGuarantee. This Constraint was not defined in dtn-bpbis.
+	AckPending Constraint = iota
 )
 
 func (c Constraint) String() string {
@@ -45,6 +53,12 @@ func (c Constraint) String() string {
 	case LocalEndpoint:
 		return "local endpoint"
 
+	case Retained:
+		return "retained"
+
+	case AckPending:
+		return "acknowledgement pending"
+
 	default:
 		return "unknown"
 	}
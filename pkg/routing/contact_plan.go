@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// Contact is a scheduled period during which a peer is reachable, e.g., a satellite's pass or a data mule's visit.
+type Contact struct {
+	// Peer is the node reachable during this contact.
+	Peer bpv7.EndpointID
+
+	// Start and End of this contact. A zero End means the contact does not end.
+	Start time.Time
+	End   time.Time
+}
+
+// active checks if this contact has started but not yet ended at the given time.
+func (contact Contact) active(t time.Time) bool {
+	return !t.Before(contact.Start) && (contact.End.IsZero() || t.Before(contact.End))
+}
+
+// SetContactPlan sets the scheduled contacts of this node, replacing the previous plan. Contacts might overlap and
+// do not need to be sorted. The plan can be replaced at any time, e.g., after receiving an updated schedule.
+func (c *Core) SetContactPlan(contacts []Contact) {
+	c.contactPlanMutex.Lock()
+	defer c.contactPlanMutex.Unlock()
+
+	c.contactPlan = append([]Contact(nil), contacts...)
+}
+
+// NextContact returns when the node of the given destination will be reachable next, based on the contact plan, and
+// the ConvergenceSender to reach it. For an ongoing contact, the current time is returned.
+//
+// The ConvergenceSender is nil if no CLA for this peer is currently registered, e.g., if it will only be discovered at
+// the contact's start. If the contact plan does not contain any upcoming contact to this node, false is returned.
+func (c *Core) NextContact(dst bpv7.EndpointID) (next time.Time, cs cla.ConvergenceSender, ok bool) {
+	now := time.Now()
+
+	c.contactPlanMutex.RLock()
+	for _, contact := range c.contactPlan {
+		if !contact.Peer.SameNode(dst) || (!contact.End.IsZero() && !now.Before(contact.End)) {
+			continue
+		}
+
+		start := contact.Start
+		if contact.active(now) {
+			start = now
+		}
+
+		if !ok || start.Before(next) {
+			next, ok = start, true
+		}
+	}
+	c.contactPlanMutex.RUnlock()
+
+	if !ok {
+		return
+	}
+
+	if css := c.senderForDestination(dst); len(css) > 0 {
+		cs = css[0]
+	}
+	return
+}
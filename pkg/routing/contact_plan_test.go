@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestCoreNextContact(t *testing.T) {
+	testCore(t, func(c *Core) {
+		peer := bpv7.MustNewEndpointID("dtn://peer/")
+		mule := bpv7.MustNewEndpointID("dtn://mule/")
+		gone := bpv7.MustNewEndpointID("dtn://gone/")
+
+		now := time.Now()
+		c.SetContactPlan([]Contact{
+			{Peer: peer, Start: now.Add(2 * time.Hour), End: now.Add(3 * time.Hour)},
+			{Peer: peer, Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)},
+			{Peer: peer, Start: now.Add(time.Hour), End: now.Add(90 * time.Minute)},
+			{Peer: mule, Start: now.Add(-time.Minute)},
+			{Peer: gone, Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)},
+		})
+
+		peerCs := newMockConvSender("mock://peer", peer)
+		c.RegisterConvergable(peerCs)
+
+		if next, cs, ok := c.NextContact(bpv7.MustNewEndpointID("dtn://peer/app")); !ok {
+			t.Fatal("no next contact to the peer")
+		} else if !next.Equal(now.Add(time.Hour)) {
+			t.Fatalf("next contact is at %v, expected %v", next, now.Add(time.Hour))
+		} else if cs != peerCs {
+			t.Fatalf("next contact's sender is %v, expected %v", cs, peerCs)
+		}
+
+		if next, cs, ok := c.NextContact(mule); !ok {
+			t.Fatal("no next contact to the mule")
+		} else if next.Before(now) || time.Since(next) > time.Second {
+			t.Fatalf("ongoing contact is at %v, expected now", next)
+		} else if cs != nil {
+			t.Fatalf("unregistered mule has a sender, %v", cs)
+		}
+
+		if _, _, ok := c.NextContact(gone); ok {
+			t.Fatal("past contact was returned")
+		} else if _, _, ok := c.NextContact(bpv7.MustNewEndpointID("dtn://unknown/")); ok {
+			t.Fatal("contact to an unplanned node was returned")
+		}
+	})
+}
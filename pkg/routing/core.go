@@ -6,9 +6,11 @@
 package routing
 
 import (
+	"context"
 	"crypto/ed25519"
 	"encoding/gob"
 	"fmt"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -31,9 +33,57 @@ type Core struct {
 	idKeeper     IdKeeper
 	routing      Algorithm
 	signPriv     ed25519.PrivateKey
+	trustedKeys  map[bpv7.EndpointID]ed25519.PublicKey
 
 	store *storage.Store
 
+	// settingsMutex guards the Core's settings, which might be changed by their setters at any time. Readers should
+	// only hold a snapshot of a setting and not the lock itself.
+	settingsMutex sync.RWMutex
+
+	acceptFilter  func(bpv7.PrimaryBlock) bool
+	blockHandlers map[uint64]BlockHandler
+	throttle      *Throttle
+	bandwidth     *BandwidthLimiter
+	crcPolicy     CRCPolicy
+
+	adminRecordPolicy AdminRecordPolicy
+	transmitPolicy    TransmitPolicy
+
+	futurePolicy    FuturePolicy
+	futureTolerance time.Duration
+
+	destinationRewriter func(bpv7.EndpointID) (bpv7.EndpointID, bool)
+	forwardingTimeout   time.Duration
+	forwardConcurrency  int
+	fragmentMinPayload  int32
+	lifetimeExtender    func(bpv7.Bundle) (time.Duration, bool)
+
+	seen    *SeenCache
+	latency *latencyRecorder
+	metrics *coreMetrics
+
+	outbound *storage.OutboundQueue
+
+	deliveryCallback func(bpv7.BundleID, time.Duration)
+	deliveryOrder    *deliveryOrder
+	retainDelivered  bool
+	reassembler      *Reassembler
+
+	contactPlan      []Contact
+	contactPlanMutex sync.RWMutex
+
+	statusBatches      map[string]*statusReportBatch
+	statusBatchesMutex sync.Mutex
+
+	shutdownMode    ShutdownMode
+	shutdownTimeout time.Duration
+	transfers       sync.WaitGroup
+	transfersMutex  sync.RWMutex
+	draining        bool
+	transfersCtx    context.Context
+	abortTransfers  context.CancelFunc
+
 	stopSyn chan struct{}
 	stopAck chan struct{}
 }
@@ -55,6 +105,7 @@ func NewCore(storePath string, nodeId bpv7.EndpointID, inspectAllBundles bool, r
 	gob.Register(bpv7.IpnEndpoint{})
 	gob.Register(map[Constraint]bool{})
 	gob.Register(time.Time{})
+	gob.Register([]ForwardEvent{})
 
 	if !nodeId.IsSingleton() {
 		return nil, fmt.Errorf("passed Node ID MUST be a singleton; %s is not", nodeId)
@@ -68,6 +119,7 @@ func NewCore(storePath string, nodeId bpv7.EndpointID, inspectAllBundles bool, r
 		return nil, err
 	} else {
 		c.store = store
+		c.store.SetEvictionHandler(c.evictBundle)
 	}
 
 	c.agentManager = NewAgentManager(c)
@@ -76,6 +128,15 @@ func NewCore(storePath string, nodeId bpv7.EndpointID, inspectAllBundles bool, r
 
 	c.idKeeper = NewIdKeeper()
 
+	if seen, err := NewPersistentSeenCache(seenCacheCapacity, seenCacheWindow, c.store); err != nil {
+		return nil, err
+	} else {
+		c.seen = seen
+	}
+	c.latency = newLatencyRecorder()
+	c.reassembler = NewReassembler(c)
+	c.recoverStore()
+
 	if ra, raErr := routingConf.RoutingAlgorithm(c); raErr != nil {
 		return nil, raErr
 	} else {
@@ -93,15 +154,21 @@ func NewCore(storePath string, nodeId bpv7.EndpointID, inspectAllBundles bool, r
 		}
 	}
 
+	c.transfersCtx, c.abortTransfers = context.WithCancel(context.Background())
+
 	c.stopSyn = make(chan struct{})
 	c.stopAck = make(chan struct{})
 
 	if err := c.cron.Register("pending_bundles", c.checkPendingBundles, 10*time.Second); err != nil {
 		log.WithError(err).Warn("Failed to register pending_bundles at cron")
 	}
-	if err := c.cron.Register("clean_store", c.store.DeleteExpired, 10*time.Minute); err != nil {
-		log.WithError(err).Warn("Failed to register clean_store at cron")
+	if err := c.cron.Register("clean_seen", c.seen.clean, time.Minute); err != nil {
+		log.WithError(err).Warn("Failed to register clean_seen at cron")
 	}
+	c.SetRetransmissionInterval(defaultRetransmissionInterval)
+	c.SetShutdown(GracefulShutdown, defaultShutdownTimeout)
+	c.SetJanitorInterval(defaultJanitorInterval)
+	c.SetForwardConcurrency(defaultForwardConcurrency)
 
 	go c.handler()
 
@@ -114,6 +181,118 @@ func (c *Core) SetRoutingAlgorithm(routing Algorithm) {
 	c.routing = routing
 }
 
+// RoutingAlgorithm returns the Core's routing Algorithm, e.g., to replace a StaticRouting's routes at runtime.
+func (c *Core) RoutingAlgorithm() Algorithm {
+	return c.routing
+}
+
+// AcceptFilter sets a filter function which is consulted for each received bundle before it enters the store. If
+// the filter returns false, the bundle will be dropped. A nil filter accepts all bundles, which is the default.
+//
+// This acts as a firewall for incoming bundles.
+func (c *Core) AcceptFilter(filter func(bpv7.PrimaryBlock) bool) {
+	c.settingsMutex.Lock()
+	c.acceptFilter = filter
+	c.settingsMutex.Unlock()
+}
+
+// RegisterBlockHandler sets a BlockHandler for canonical blocks of the given block type code. This handler will be
+// invoked for each such block of a received bundle. Registering a nil BlockHandler removes the previous one.
+func (c *Core) RegisterBlockHandler(blockType uint64, handler BlockHandler) {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	if c.blockHandlers == nil {
+		c.blockHandlers = make(map[uint64]BlockHandler)
+	}
+
+	if handler == nil {
+		delete(c.blockHandlers, blockType)
+	} else {
+		c.blockHandlers[blockType] = handler
+	}
+}
+
+// SetDeliveryOrder enables an ordered local delivery of bundles by their creation timestamp. Each bundle is held
+// back for the window's duration to reorder out-of-order arrivals for the same destination, e.g., for streamed data.
+// A zero window disables ordering, which is the default. Bundles held back by a previous window are still delivered.
+func (c *Core) SetDeliveryOrder(window time.Duration) {
+	var order *deliveryOrder
+	if window > 0 {
+		order = newDeliveryOrder(window, c.deliverLocal)
+	}
+
+	c.settingsMutex.Lock()
+	previous := c.deliveryOrder
+	c.deliveryOrder = order
+	c.settingsMutex.Unlock()
+
+	if previous != nil {
+		previous.close()
+	}
+}
+
+// SetThrottle enables an adaptive intake throttling for received bundles, based on the store's utilization. While
+// being throttled, received bundles are refused. The store's capacity is set by SetStoreCapacity. A zero MaxRate
+// disables throttling.
+func (c *Core) SetThrottle(config ThrottleConfig) {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	c.cron.Unregister("throttle")
+
+	if config.MaxRate <= 0 {
+		c.throttle = nil
+		return
+	}
+
+	c.throttle = NewThrottle(config, c.store)
+	c.throttle.Update()
+
+	if err := c.cron.Register("throttle", c.throttle.Update, time.Second); err != nil {
+		log.WithError(err).Warn("Failed to register throttle at cron")
+	}
+}
+
+// SetStoreCapacity limits the amount of stored bundles. If exceeded, bundles selected by the EvictionStrategy will
+// be deleted for depleted storage. Bundles awaiting their local delivery, reassembly, or acknowledgement are never
+// evicted. A zero capacity means no limit, which is the default.
+func (c *Core) SetStoreCapacity(capacity int, strategy storage.EvictionStrategy) {
+	c.store.SetCapacity(capacity, strategy)
+}
+
+// evictBundle deletes a bundle selected by the store's EvictionStrategy, compare SetStoreCapacity.
+func (c *Core) evictBundle(bi storage.BundleItem) {
+	bp := NewBundleDescriptor(bi.BId, c.store)
+	if _, err := bp.Bundle(); err != nil {
+		// Bundles which cannot be loaded, e.g., past their lifetime, are removed without further ado.
+		if err := c.store.Delete(bi.BId); err != nil {
+			log.WithField("bundle", bi.Id).WithError(err).Warn("Failed to evict bundle")
+		}
+		return
+	}
+
+	c.bundleDeletion(bp, bpv7.DepletedStorage)
+}
+
+// SetOutboundQueue enables a persistent per-peer queue for outgoing bundles. Bundles will be queued before being sent
+// and re-attempted for their peer after a failure or a restart. A nil OutboundQueue disables this feature.
+func (c *Core) SetOutboundQueue(queue *storage.OutboundQueue) {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	c.cron.Unregister("outbound_queue")
+
+	c.outbound = queue
+	if queue == nil {
+		return
+	}
+
+	if err := c.cron.Register("outbound_queue", c.retryOutbound, 10*time.Second); err != nil {
+		log.WithError(err).Warn("Failed to register outbound_queue at cron")
+	}
+}
+
 // checkPendingBundles queries pending bundle (packs) from the store and
 // tries to dispatch them.
 func (c *Core) checkPendingBundles() {
@@ -125,13 +304,44 @@ func (c *Core) checkPendingBundles() {
 		for _, bi := range bis {
 			log.WithFields(log.Fields{
 				"bundle": bi.Id,
-			}).Info("Retrying bundle from store")
+			}).Debug("Retrying bundle from store")
 
 			c.dispatching(NewBundleDescriptor(bi.BId, c.store))
 		}
 	}
 }
 
+// dispatchForPeer re-dispatches all pending bundles, e.g., contraindicated ones, after a peer has appeared. Bundles
+// addressed to this peer are dispatched first, thus a direct delivery is attempted promptly.
+func (c *Core) dispatchForPeer(peer bpv7.EndpointID) {
+	bis, err := c.store.QueryPending()
+	if err != nil {
+		log.WithError(err).Warn("Failed to fetch pending bundles")
+		return
+	}
+
+	var others []BundleDescriptor
+	for _, bi := range bis {
+		bp := NewBundleDescriptor(bi.BId, c.store)
+		if b, err := bp.Bundle(); err != nil || !b.PrimaryBlock.Destination.SameNode(peer) {
+			others = append(others, bp)
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"bundle": bi.Id,
+			"peer":   peer,
+		}).Info("Destination of pending bundle appeared, re-dispatching")
+
+		c.dispatching(bp)
+	}
+
+	for _, bp := range others {
+		log.WithField("bundle", bp.ID()).Debug("Retrying bundle from store")
+		c.dispatching(bp)
+	}
+}
+
 // handler does the Core's background tasks
 func (c *Core) handler() {
 	for {
@@ -140,6 +350,15 @@ func (c *Core) handler() {
 		case <-c.stopSyn:
 			c.cron.Stop()
 
+			c.settingsMutex.RLock()
+			order := c.deliveryOrder
+			c.settingsMutex.RUnlock()
+
+			if order != nil {
+				order.close()
+			}
+			c.reassembler.close()
+
 			if err := c.claManager.Close(); err != nil {
 				log.WithError(err).Warn("Closing CLA Manager while shutting down errored")
 			}
@@ -156,16 +375,16 @@ func (c *Core) handler() {
 			switch cs.MessageType {
 			case cla.ReceivedBundle:
 				crb := cs.Message.(cla.ConvergenceReceivedBundle)
-
-				bp := NewBundleDescriptorFromBundle(*crb.Bundle, c.store)
-				bp.Receiver = crb.Endpoint
-				_ = bp.Sync()
-
-				c.receive(bp)
+				c.receiveConvergence(crb)
 
 			case cla.PeerAppeared:
 				c.routing.ReportPeerAppeared(cs.Sender)
-				c.checkPendingBundles()
+				if peer, ok := cs.Message.(bpv7.EndpointID); ok {
+					c.dispatchForPeer(peer)
+				} else {
+					c.checkPendingBundles()
+				}
+				c.retryOutbound()
 
 			case cla.PeerDisappeared:
 				c.routing.ReportPeerDisappeared(cs.Sender)
@@ -182,15 +401,51 @@ func (c *Core) handler() {
 }
 
 // Close shuts the Core down and notifies all bounded ConvergenceReceivers to
-// also close the connection.
+// also close the connection. The ShutdownMode is set by SetShutdown.
 func (c *Core) Close() {
-	close(c.stopSyn)
-	<-c.stopAck
+	c.settingsMutex.RLock()
+	mode := c.shutdownMode
+	c.settingsMutex.RUnlock()
+
+	c.Shutdown(mode)
 }
 
 // RegisterApplicationAgent adds a new ApplicationAgent to this Core's list.
-func (c *Core) RegisterApplicationAgent(app agent.ApplicationAgent) {
+//
+// An endpoint is owned either by ApplicationAgents or by CLAs, compare RegisterCLA. The first registration takes
+// precedence; a conflicting ApplicationAgent, listening on an EndpointID already registered for a CLA, results in an
+// error. Multiple ApplicationAgents might share an endpoint. Endpoints registered later by an ApplicationAgent, e.g.,
+// by a RestAgent's client, are not checked.
+func (c *Core) RegisterApplicationAgent(app agent.ApplicationAgent) error {
+	if err := c.checkAgentEndpoints(app.Endpoints()); err != nil {
+		return err
+	}
+
 	c.agentManager.Register(app)
+	return nil
+}
+
+// checkAgentEndpoints returns an error if one of these endpoints is already registered for a CLA.
+func (c *Core) checkAgentEndpoints(eids []bpv7.EndpointID) error {
+	for _, eid := range eids {
+		if c.claManager.HasEndpointID(eid) {
+			return fmt.Errorf("endpoint %v is already registered for a CLA", eid)
+		}
+	}
+	return nil
+}
+
+// Subscribe to an endpoint with a callback function, called for each Bundle delivered to this endpoint. This is a
+// lightweight alternative to implementing an agent.ApplicationAgent. The returned function unsubscribes again.
+func (c *Core) Subscribe(eid bpv7.EndpointID, handler func(bpv7.Bundle)) (unsubscribe func(), err error) {
+	// Check the endpoint before creating the CallbackAgent, whose Goroutine only ends after being registered.
+	if err = c.checkAgentEndpoints([]bpv7.EndpointID{eid}); err != nil {
+		return
+	}
+
+	ca := agent.NewCallback(eid, handler)
+	c.agentManager.Register(ca)
+	return ca.Close, nil
 }
 
 // senderForDestination returns an array of ConvergenceSenders whose endpoint ID
@@ -231,6 +486,9 @@ func (c *Core) HasEndpoint(endpoint bpv7.EndpointID) bool {
 
 // SendStatusReport creates a new status report in response to the given
 // BundleDescriptor and transmits it.
+//
+// While a received bundle is being processed, its status reports are collected and sent as a single status report,
+// asserting all status information, compare batchStatusReports.
 func (c *Core) SendStatusReport(descriptor BundleDescriptor, status bpv7.StatusInformationPos, reason bpv7.StatusReportReason) {
 	// Don't respond to other administrative records
 	bndl, _ := descriptor.Bundle()
@@ -238,18 +496,37 @@ func (c *Core) SendStatusReport(descriptor BundleDescriptor, status bpv7.StatusI
 		return
 	}
 
+	// Status reports must not be sent to the null endpoint
+	if bndl.PrimaryBlock.ReportTo.Matches(bpv7.DtnNone()) {
+		log.WithFields(log.Fields{
+			"bundle": descriptor.ID(),
+			"status": status,
+		}).Debug("Not sending a status report for a bundle to be reported to dtn:none")
+		return
+	}
+
 	// Don't respond to ourself
 	if c.HasEndpoint(bndl.PrimaryBlock.ReportTo) {
 		return
 	}
 
+	if c.addToStatusReportBatch(descriptor, status, reason) {
+		return
+	}
+
+	c.transmitStatusReport(descriptor, bpv7.NewStatusReport(*bndl, status, reason, bpv7.DtnTimeNow()))
+}
+
+// transmitStatusReport sends a status report for the given BundleDescriptor to its report-to endpoint.
+func (c *Core) transmitStatusReport(descriptor BundleDescriptor, sr *bpv7.StatusReport) {
+	bndl, _ := descriptor.Bundle()
+
 	log.WithFields(log.Fields{
 		"bundle": descriptor.ID(),
-		"status": status,
-		"reason": reason,
+		"status": sr.StatusInformations(),
+		"reason": sr.ReportReason,
 	}).Info("Sending a status report for a bundle")
 
-	var sr = bpv7.NewStatusReport(*bndl, status, reason, bpv7.DtnTimeNow())
 	var ar, arErr = bpv7.AdministrativeRecordToCbor(sr)
 	if arErr != nil {
 		log.WithFields(log.Fields{
@@ -295,16 +572,62 @@ func (c *Core) SendStatusReport(descriptor BundleDescriptor, status bpv7.StatusI
 	c.SendBundle(&outBndl)
 }
 
+// EmitStatusReport creates a status report for the referenced bundle and sends it to reportTo. In contrast to
+// SendStatusReport, the referenced bundle does not need to be known. This might be used by gateways, translating
+// status information from other systems.
+func (c *Core) EmitStatusReport(subject bpv7.BundleID, status bpv7.StatusInformationPos, reason bpv7.StatusReportReason, reportTo bpv7.EndpointID) error {
+	sr := bpv7.NewStatusReportForID(subject, status, reason, bpv7.DtnTimeNow())
+	ar, err := bpv7.AdministrativeRecordToCbor(sr)
+	if err != nil {
+		return err
+	}
+
+	outBndl, err := bpv7.Builder().
+		BundleCtrlFlags(bpv7.AdministrativeRecordPayload).
+		Source(c.NodeId).
+		Destination(reportTo).
+		CreationTimestampNow().
+		Lifetime("60m").
+		Canonical(ar).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":    subject,
+		"status":    status,
+		"reason":    reason,
+		"report_to": reportTo,
+	}).Info("Emitting a status report")
+
+	c.SendBundle(&outBndl)
+	return nil
+}
+
 // RegisterConvergable is the exposed Register method from the CLA Manager.
 func (c *Core) RegisterConvergable(conv cla.Convergable) {
 	c.claManager.Register(conv)
 }
 
+// UnregisterConvergable is the exposed Unregister method from the CLA Manager.
+func (c *Core) UnregisterConvergable(conv cla.Convergable) {
+	c.claManager.Unregister(conv)
+}
+
 // RegisterCLA registers a CLA with the clamanager (just as the RegisterConvergable-method)
 // but also adds the CLAs endpoint id to the set of registered IDs for its type.
-func (c *Core) RegisterCLA(conv cla.Convergable, claType cla.CLAType, eid bpv7.EndpointID) {
+//
+// An error is returned if an ApplicationAgent already listens on this endpoint id, compare
+// RegisterApplicationAgent.
+func (c *Core) RegisterCLA(conv cla.Convergable, claType cla.CLAType, eid bpv7.EndpointID) error {
+	if c.agentManager.HasEndpoint(eid) {
+		return fmt.Errorf("endpoint %v is already registered for an ApplicationAgent", eid)
+	}
+
 	c.claManager.RegisterEndpointID(claType, eid)
 	c.claManager.Register(conv)
+	return nil
 }
 
 // RegisteredCLAs returns the EndpointIDs of all registered CLAs of the specified type.
@@ -312,3 +635,26 @@ func (c *Core) RegisterCLA(conv cla.Convergable, claType cla.CLAType, eid bpv7.E
 func (c *Core) RegisteredCLAs(claType cla.CLAType) []bpv7.EndpointID {
 	return c.claManager.EndpointIDs(claType)
 }
+
+// TransferredBytes returns the total amount of bytes sent and received by all CLAs supporting the cla.ByteCounter,
+// including the convergence layers' own headers.
+func (c *Core) TransferredBytes() (sent, received uint64) {
+	return c.claManager.TransferredBytes()
+}
+
+// SetCLARetryBackoff configures the exponential backoff between the activation attempts of failing CLAs, starting
+// with base and increasing up to max, compare cla.Manager.SetRetryBackoff.
+func (c *Core) SetCLARetryBackoff(base, max time.Duration) {
+	c.claManager.SetRetryBackoff(base, max)
+}
+
+// SetDecodeMode for bundles received by all CLAs supporting the cla.DecodeModeSetter, compare
+// cla.Manager.SetDecodeMode. CLAs use the bpv7.StrictDecoding by default.
+func (c *Core) SetDecodeMode(mode bpv7.DecodeMode) {
+	c.claManager.SetDecodeMode(mode)
+}
+
+// CLAStates returns the connection states of all supervised CLAs.
+func (c *Core) CLAStates() []cla.ConvergenceState {
+	return c.claManager.States()
+}
@@ -0,0 +1,2439 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/storage"
+)
+
+// testCore creates a new Core with a temporary store and an epidemic routing for the scenario.
+func testCore(t testing.TB, scenario func(c *Core)) {
+	testCoreRouting(t, RoutingConf{Algorithm: "epidemic"}, scenario)
+}
+
+// testCoreRouting is like testCore, but for another routing algorithm.
+func testCoreRouting(t testing.TB, routingConf RoutingConf, scenario func(c *Core)) {
+	filePath, err := ioutil.TempFile("", "core")
+	if err != nil {
+		t.Fatal(err)
+	} else if err = os.Remove(filePath.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filePath.Name()
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	c, err := NewCore(dir, bpv7.MustNewEndpointID("dtn://core/"), false, routingConf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenario(c)
+
+	c.Close()
+}
+
+// testCoreBundle from src to dst for testing purpose.
+func testCoreBundle(t *testing.T, src, dst string) bpv7.Bundle {
+	b, err := bpv7.Builder().
+		Source(src).
+		Destination(dst).
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestCoreAcceptFilter(t *testing.T) {
+	testCore(t, func(c *Core) {
+		evil := bpv7.MustNewEndpointID("dtn://evil/")
+		c.AcceptFilter(func(pb bpv7.PrimaryBlock) bool {
+			return !pb.SourceNode.SameNode(evil)
+		})
+
+		bEvil := testCoreBundle(t, "dtn://evil/", "dtn://dst/")
+		bGood := testCoreBundle(t, "dtn://good/", "dtn://dst/")
+
+		peer := bpv7.MustNewEndpointID("dtn://peer/")
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: peer, Bundle: &bEvil})
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: peer, Bundle: &bGood})
+
+		if c.store.KnowsBundle(bEvil.ID()) {
+			t.Fatalf("rejected bundle %v entered the store", bEvil.ID())
+		}
+		if !c.store.KnowsBundle(bGood.ID()) {
+			t.Fatalf("accepted bundle %v is not stored", bGood.ID())
+		}
+	})
+}
+
+func TestCoreForwardHistory(t *testing.T) {
+	testCore(t, func(c *Core) {
+		peer := bpv7.MustNewEndpointID("dtn://peer/")
+		sender := newMockConvSender("mock://peer", peer)
+		c.RegisterConvergable(sender)
+
+		b := testCoreBundle(t, "dtn://core/", "dtn://dst/")
+		c.SendBundle(&b)
+
+		if l := len(sender.sent()); l != 1 {
+			t.Fatalf("mock sender sent %d bundles, expected 1", l)
+		}
+
+		history, err := c.ForwardHistory(b.ID())
+		if err != nil {
+			t.Fatal(err)
+		} else if len(history) != 1 {
+			t.Fatalf("expected one forward event, got %v", history)
+		} else if history[0].Peer != peer {
+			t.Fatalf("forward event's peer is %v, expected %v", history[0].Peer, peer)
+		}
+	})
+}
+
+func TestCoreStoreEviction(t *testing.T) {
+	testCore(t, func(c *Core) {
+		c.SetStoreCapacity(2, nil)
+
+		var bndls []bpv7.Bundle
+		for i := 0; i < 3; i++ {
+			b, err := bpv7.Builder().
+				BundleCtrlFlags(bpv7.StatusRequestDeletion).
+				Source(fmt.Sprintf("dtn://src-%d/", i)).
+				Destination("dtn://dst/").
+				ReportTo("dtn://relay/").
+				CreationTimestampNow().
+				Lifetime(fmt.Sprintf("%dm", 10+i)).
+				PayloadBlock([]byte("hello world")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			bndls = append(bndls, b)
+
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+		}
+
+		if c.store.KnowsBundle(bndls[0].ID()) {
+			t.Fatal("bundle expiring first was not evicted")
+		}
+
+		bis, err := c.store.QueryAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var reported bool
+		for _, bi := range bis {
+			bp := NewBundleDescriptor(bi.BId, c.store)
+			b, err := bp.Bundle()
+			if err != nil || !b.IsAdministrativeRecord() {
+				continue
+			}
+
+			ar, err := b.AdministrativeRecord()
+			if err != nil {
+				t.Fatal(err)
+			} else if sr, ok := ar.(*bpv7.StatusReport); ok && sr.RefBundle == bndls[0].ID() {
+				if sr.ReportReason != bpv7.DepletedStorage {
+					t.Fatalf("status report's reason is %v, expected depleted storage", sr.ReportReason)
+				}
+				reported = true
+			}
+		}
+		if !reported {
+			t.Fatal("no deletion status report was created for the evicted bundle")
+		}
+	})
+}
+
+func TestCoreThrottle(t *testing.T) {
+	testCore(t, func(c *Core) {
+		const capacity = 10
+
+		c.SetStoreCapacity(capacity, nil)
+		c.SetThrottle(ThrottleConfig{MaxRate: 1000})
+
+		peer := bpv7.MustNewEndpointID("dtn://peer/")
+		var bndls []bpv7.Bundle
+		for i := 0; i < 2*capacity; i++ {
+			b := testCoreBundle(t, fmt.Sprintf("dtn://src-%d/", i), "dtn://dst/")
+			bndls = append(bndls, b)
+
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: peer, Bundle: &b})
+			c.throttle.Update()
+		}
+
+		if n := c.store.Count(); n != capacity {
+			t.Fatalf("store holds %d bundles, expected throttling at %d", n, capacity)
+		}
+
+		for _, b := range bndls[:capacity] {
+			if err := c.store.Delete(b.ID()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		c.throttle.Update()
+		time.Sleep(10 * time.Millisecond)
+
+		b := testCoreBundle(t, "dtn://late/", "dtn://dst/")
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: peer, Bundle: &b})
+		if !c.store.KnowsBundle(b.ID()) {
+			t.Fatalf("throttling was not released after the store drained")
+		}
+	})
+}
+
+func TestCoreSubscribe(t *testing.T) {
+	testCore(t, func(c *Core) {
+		app := bpv7.MustNewEndpointID("dtn://core/app")
+
+		bndlChan := make(chan bpv7.Bundle, 1)
+		unsubscribe, err := c.Subscribe(app, func(b bpv7.Bundle) { bndlChan <- b })
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b := testCoreBundle(t, "dtn://src/", "dtn://core/app")
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: bpv7.MustNewEndpointID("dtn://peer/"), Bundle: &b})
+
+		select {
+		case <-time.After(time.Second):
+			t.Fatal("subscribed closure was not called")
+
+		case bIn := <-bndlChan:
+			if bIn.ID() != b.ID() {
+				t.Fatalf("closure received %v, expected %v", bIn.ID(), b.ID())
+			}
+		}
+
+		unsubscribe()
+
+		for i := 0; c.agentManager.HasEndpoint(app); i++ {
+			if i >= 100 {
+				t.Fatal("endpoint is still registered after unsubscribing")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
+
+func TestCoreEndpointConflict(t *testing.T) {
+	testCore(t, func(c *Core) {
+		// A CLA's endpoint cannot be claimed by an ApplicationAgent afterwards.
+		claEid := bpv7.MustNewEndpointID("dtn://core/cla")
+		if err := c.RegisterCLA(newMockConvSender("cla", claEid), cla.TCPCLv4, claEid); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Subscribe(claEid, func(_ bpv7.Bundle) {}); err == nil {
+			t.Fatal("subscribing to a CLA's endpoint did not fail")
+		} else if c.agentManager.HasEndpoint(claEid) {
+			t.Fatal("conflicting agent was registered")
+		}
+
+		// An ApplicationAgent's endpoint cannot be claimed by a CLA afterwards.
+		app := bpv7.MustNewEndpointID("dtn://core/app")
+		unsubscribe, err := c.Subscribe(app, func(_ bpv7.Bundle) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unsubscribe()
+
+		if err := c.RegisterCLA(newMockConvSender("app", app), cla.TCPCLv4, app); err == nil {
+			t.Fatal("registering a CLA for an agent's endpoint did not fail")
+		} else if c.claManager.HasEndpointID(app) {
+			t.Fatal("conflicting CLA endpoint was registered")
+		}
+
+		// Multiple ApplicationAgents might share an endpoint.
+		unsubscribe2, err := c.Subscribe(app, func(_ bpv7.Bundle) {})
+		if err != nil {
+			t.Fatalf("subscribing twice to an agent's endpoint failed: %v", err)
+		}
+		defer unsubscribe2()
+	})
+}
+
+func TestCoreSubscribeRejectedGoroutines(t *testing.T) {
+	testCore(t, func(c *Core) {
+		claEid := bpv7.MustNewEndpointID("dtn://core/cla")
+		if err := c.RegisterCLA(newMockConvSender("cla", claEid), cla.TCPCLv4, claEid); err != nil {
+			t.Fatal(err)
+		}
+
+		before := runtime.NumGoroutine()
+		for i := 0; i < 20; i++ {
+			if _, err := c.Subscribe(claEid, func(_ bpv7.Bundle) {}); err == nil {
+				t.Fatal("subscribing to a CLA's endpoint did not fail")
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+
+		// Allow some slack for the Core's other Goroutines, but not one leaked Goroutine per rejected subscription.
+		if after := runtime.NumGoroutine(); after > before+5 {
+			t.Fatalf("rejected subscriptions leaked Goroutines: %d before, %d after", before, after)
+		}
+	})
+}
+
+func TestCoreDeliveryLatency(t *testing.T) {
+	testCore(t, func(c *Core) {
+		latencies := make(chan time.Duration, 3)
+		c.DeliveryCallback(func(_ bpv7.BundleID, latency time.Duration) { latencies <- latency })
+
+		app := bpv7.MustNewEndpointID("dtn://core/app")
+		unsubscribe, err := c.Subscribe(app, func(_ bpv7.Bundle) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unsubscribe()
+
+		bCreated, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination(app).
+			CreationTimestampTime(time.Now().Add(-5 * time.Minute)).
+			Lifetime("10m").
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bAged, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination(app).
+			CreationTimestampEpoch().
+			Lifetime("10m").
+			BundleAgeBlock(2000).
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// A skewed clock must not result in a negative latency.
+		bFuture, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination(app).
+			CreationTimestampTime(time.Now().Add(time.Minute)).
+			Lifetime("10m").
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			bndl     bpv7.Bundle
+			min, max time.Duration
+		}{
+			{bCreated, 5 * time.Minute, 5*time.Minute + 5*time.Second},
+			{bAged, 2 * time.Second, 7 * time.Second},
+			{bFuture, 0, 0},
+		}
+
+		peer := bpv7.MustNewEndpointID("dtn://peer/")
+		for _, test := range tests {
+			b := test.bndl
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: peer, Bundle: &b})
+
+			select {
+			case <-time.After(time.Second):
+				t.Fatalf("no latency was reported for %v", b.ID())
+
+			case latency := <-latencies:
+				if latency < test.min || latency > test.max {
+					t.Fatalf("latency %v for %v is not within [%v, %v]", latency, b.ID(), test.min, test.max)
+				}
+			}
+		}
+
+		h := c.DeliveryLatency()
+		if h.Count != 3 {
+			t.Fatalf("histogram counted %d latencies, expected 3", h.Count)
+		} else if h.Counts[0] != 1 || h.Counts[1] != 1 || h.Counts[3] != 1 {
+			t.Fatalf("histogram has unexpected buckets: %v", h.Counts)
+		}
+	})
+}
+
+func TestSendReaction(t *testing.T) {
+	tests := []struct {
+		err      error
+		reaction forwardReaction
+	}{
+		{nil, forwardSent},
+		{fmt.Errorf("connection reset"), forwardFailed},
+		{cla.NewRefusalError(cla.RefusalDuplicate, "completed"), forwardSent},
+		{cla.NewRefusalError(cla.RefusalTemporary, "no resources"), forwardRetry},
+		{cla.NewRefusalError(cla.RefusalPermanent, "not acceptable"), forwardFailed},
+		{fmt.Errorf("wrapped: %w", cla.NewRefusalError(cla.RefusalTemporary, "retransmit")), forwardRetry},
+		{context.Canceled, forwardRetry},
+		{context.DeadlineExceeded, forwardFailed},
+	}
+
+	for _, test := range tests {
+		if reaction := sendReaction(test.err); reaction != test.reaction {
+			t.Fatalf("reaction for %v is %d, expected %d", test.err, reaction, test.reaction)
+		}
+	}
+}
+
+func TestCoreForwardRefusal(t *testing.T) {
+	tests := []struct {
+		kind    cla.RefusalKind
+		history int
+	}{
+		{cla.RefusalDuplicate, 1},
+		{cla.RefusalTemporary, 0},
+		{cla.RefusalPermanent, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.kind.String(), func(t *testing.T) {
+			testCore(t, func(c *Core) {
+				peer := bpv7.MustNewEndpointID("dtn://peer/")
+				sender := newMockConvSender("mock://peer", peer)
+				sender.sendErr = cla.NewRefusalError(test.kind, "test")
+				c.RegisterConvergable(sender)
+
+				b := testCoreBundle(t, "dtn://core/", "dtn://dst/")
+				c.SendBundle(&b)
+
+				if history, err := c.ForwardHistory(b.ID()); err != nil {
+					t.Fatal(err)
+				} else if len(history) != test.history {
+					t.Fatalf("expected %d forward events, got %v", test.history, history)
+				}
+
+				sent := c.SeenBundles().Peers(b.ID())
+				if blamed := len(sent) == 0; blamed != (test.kind == cla.RefusalPermanent) {
+					t.Fatalf("peer was blamed: %t, sent list: %v", blamed, sent)
+				}
+			})
+		})
+	}
+}
+
+func TestCoreSettingsConcurrent(t *testing.T) {
+	testCore(t, func(c *Core) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+
+			for i := 0; i < 50; i++ {
+				c.SetCRCPolicy(CRCPolicy(i % 3))
+				c.SetFuturePolicy(FuturePolicyClamp, time.Duration(i)*time.Second)
+				c.SetTransmitPolicy(TransmitPolicy(i % 2))
+				c.SetRetainDelivered(i%2 == 0)
+				c.SetForwardConcurrency(i)
+				c.AcceptFilter(func(bpv7.PrimaryBlock) bool { return true })
+				c.DestinationRewriter(func(eid bpv7.EndpointID) (bpv7.EndpointID, bool) { return eid, false })
+			}
+		}()
+
+		for i := 0; i < 50; i++ {
+			b := testCoreBundle(t, fmt.Sprintf("dtn://src-%d/", i), "dtn://dst/")
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+		}
+
+		<-done
+	})
+}
+
+func TestCoreKnownBundleNotification(t *testing.T) {
+	testCore(t, func(c *Core) {
+		receiveFrom := func(prev string) {
+			b, err := bpv7.Builder().
+				Source("dtn://src/").
+				Destination("dtn://dst/").
+				CreationTimestampTime(time.Unix(1600000000, 0)).
+				Lifetime("87600h").
+				PreviousNodeBlock(prev).
+				PayloadBlock([]byte("hello world")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+		}
+
+		receiveFrom("dtn://a/")
+		receiveFrom("dtn://b/")
+
+		sent := c.SeenBundles().Peers(bpv7.BundleID{
+			SourceNode: bpv7.MustNewEndpointID("dtn://src/"),
+			Timestamp:  bpv7.NewCreationTimestamp(bpv7.DtnTimeFromTime(time.Unix(1600000000, 0)), 0),
+		})
+		if len(sent) != 2 {
+			t.Fatalf("expected both peers to be marked, got %v", sent)
+		} else if sent[1] != bpv7.MustNewEndpointID("dtn://b/") {
+			t.Fatalf("re-receiving peer was not marked, got %v", sent)
+		}
+	})
+}
+
+func TestCoreEmitStatusReport(t *testing.T) {
+	testCore(t, func(c *Core) {
+		reportTo := bpv7.MustNewEndpointID("dtn://gateway/")
+		sender := newMockConvSender("mock://gateway", reportTo)
+		c.RegisterConvergable(sender)
+
+		subject := testCoreBundle(t, "dtn://src/", "dtn://dst/").ID()
+		if err := c.EmitStatusReport(subject, bpv7.DeliveredBundle, bpv7.NoInformation, reportTo); err != nil {
+			t.Fatal(err)
+		}
+
+		sent := sender.sent()
+		if len(sent) != 1 {
+			t.Fatalf("mock sender sent %d bundles, expected 1", len(sent))
+		}
+
+		b := sent[0]
+		if !b.IsAdministrativeRecord() {
+			t.Fatal("sent bundle is no administrative record")
+		} else if b.PrimaryBlock.Destination != reportTo {
+			t.Fatalf("status report is addressed to %v, expected %v", b.PrimaryBlock.Destination, reportTo)
+		} else if err := b.CheckValid(); err != nil {
+			t.Fatal(err)
+		}
+
+		ar, err := b.AdministrativeRecord()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sr, ok := ar.(*bpv7.StatusReport)
+		if !ok {
+			t.Fatalf("administrative record is a %T, not a status report", ar)
+		} else if sr.RefBundle != subject {
+			t.Fatalf("status report references %v, expected %v", sr.RefBundle, subject)
+		} else if sips := sr.StatusInformations(); len(sips) != 1 || sips[0] != bpv7.DeliveredBundle {
+			t.Fatalf("status report asserts %v", sips)
+		}
+	})
+}
+
+func TestCoreOutboundQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	peer := bpv7.MustNewEndpointID("dtn://peer/")
+	b := testCoreBundle(t, "dtn://core/", "dtn://dst/")
+
+	startCore := func() (*Core, *storage.OutboundQueue) {
+		c, err := NewCore(dir, bpv7.MustNewEndpointID("dtn://core/"), false, RoutingConf{Algorithm: "epidemic"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := storage.NewOutboundQueue(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.SetOutboundQueue(q)
+		return c, q
+	}
+
+	// The first transmission fails due to a lost connection, e.g., right before a crash.
+	c, q := startCore()
+	broken := newMockConvSender("mock://peer", peer)
+	broken.sendErr = fmt.Errorf("connection lost")
+	c.RegisterConvergable(broken)
+	c.SendBundle(&b)
+	c.Close()
+
+	if pending, err := q.Pending(peer); err != nil {
+		t.Fatal(err)
+	} else if len(pending) != 1 {
+		t.Fatalf("expected one queued bundle, got %d", len(pending))
+	}
+
+	// After a restart, the queued bundle is still pending and must only be re-attempted by the forward path.
+	c, q = startCore()
+	defer c.Close()
+
+	sender := newMockConvSender("mock://peer", peer)
+	c.RegisterConvergable(sender)
+	c.retryOutbound()
+
+	if n := len(sender.sent()); n != 0 {
+		t.Fatalf("pending bundle was sent %d times from the outbound queue", n)
+	}
+
+	c.checkPendingBundles()
+
+	sent := 0
+	for _, sentBundle := range sender.sent() {
+		if sentBundle.ID() == b.ID() {
+			sent++
+		}
+	}
+	if sent != 1 {
+		t.Fatalf("queued bundle was sent %d times, sent: %v", sent, sender.sent())
+	}
+
+	if pending, err := q.Pending(peer); err != nil {
+		t.Fatal(err)
+	} else if len(pending) != 0 {
+		t.Fatalf("expected an empty queue, got %d bundles", len(pending))
+	}
+
+	// A queued bundle which is no longer pending in the store is sent from the outbound queue.
+	other := testCoreBundle(t, "dtn://core/other", "dtn://dst/")
+	if err := q.Enqueue(peer, other); err != nil {
+		t.Fatal(err)
+	}
+	c.retryOutbound()
+
+	if sent := sender.sent(); len(sent) != 2 || sent[1].ID() != other.ID() {
+		t.Fatalf("queued bundle was not re-attempted, sent: %v", sent)
+	}
+
+	if pending, err := q.Pending(peer); err != nil {
+		t.Fatal(err)
+	} else if len(pending) != 0 {
+		t.Fatalf("expected an empty queue, got %d bundles", len(pending))
+	}
+}
+
+func TestCoreDispatchForPeer(t *testing.T) {
+	testCore(t, func(c *Core) {
+		peer := bpv7.MustNewEndpointID("dtn://peer/")
+
+		bPeer := testCoreBundle(t, "dtn://core/a", "dtn://peer/app")
+		bOther := testCoreBundle(t, "dtn://core/b", "dtn://other/")
+
+		// Both bundles are forwarded to a relay and become contraindicated afterwards.
+		relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+		c.RegisterConvergable(relay)
+
+		for _, b := range []bpv7.Bundle{bPeer, bOther} {
+			b := b
+			c.SendBundle(&b)
+
+			if bp := NewBundleDescriptor(b.ID(), c.store); !bp.HasConstraint(Contraindicated) {
+				t.Fatalf("bundle %v is not contraindicated: %v", b.ID(), bp)
+			}
+		}
+
+		sender := newMockConvSender("mock://peer", peer)
+		c.RegisterConvergable(sender)
+		c.dispatchForPeer(peer)
+
+		// The peer's bundle is dispatched first; each pending bundle is dispatched exactly once.
+		if sent := sender.sent(); len(sent) != 2 {
+			t.Fatalf("expected two sent bundles, got %v", sent)
+		} else if sent[0].ID() != bPeer.ID() || sent[1].ID() != bOther.ID() {
+			t.Fatalf("sent bundles %v, expected %v first", sent, bPeer.ID())
+		}
+
+		if bp := NewBundleDescriptor(bPeer.ID(), c.store); bp.HasConstraints() {
+			t.Fatalf("delivered bundle still has constraints: %v", bp)
+		}
+	})
+}
+
+func TestCorePeerAppearedContraindicated(t *testing.T) {
+	testCore(t, func(c *Core) {
+		relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+		c.RegisterConvergable(relay)
+
+		b := testCoreBundle(t, "dtn://core/", "dtn://other/")
+		c.SendBundle(&b)
+
+		if bp := NewBundleDescriptor(b.ID(), c.store); !bp.HasConstraint(Contraindicated) {
+			t.Fatalf("bundle %v is not contraindicated: %v", b.ID(), bp)
+		}
+
+		// A peer coming online reports its appearance, which should result in another forwarding attempt.
+		peer := bpv7.MustNewEndpointID("dtn://peer/")
+		sender := newMockConvSender("mock://peer", peer)
+		c.RegisterConvergable(sender)
+		sender.reportChan <- cla.NewConvergencePeerAppeared(sender, peer)
+
+		for i := 0; len(sender.sent()) == 0; i++ {
+			if i >= 100 {
+				t.Fatal("contraindicated bundle was not forwarded to the appeared peer")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if sent := sender.sent(); sent[0].ID() != b.ID() {
+			t.Fatalf("sent bundle %v, expected %v", sent[0].ID(), b.ID())
+		}
+	})
+}
+
+func TestCoreBlockHandler(t *testing.T) {
+	testCore(t, func(c *Core) {
+		const hintBlockType uint64 = 220
+
+		var handled []string
+		c.RegisterBlockHandler(hintBlockType, BlockHandlerFunc(
+			func(block *bpv7.CanonicalBlock, descriptor *BundleDescriptor) BlockAction {
+				data, _ := block.Value.(*bpv7.GenericExtensionBlock).MarshalBinary()
+				handled = append(handled, string(data))
+				return BlockRemove
+			}))
+
+		relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+		c.RegisterConvergable(relay)
+
+		// Without a handler, this unknown block would result in the bundle's deletion.
+		b, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("10m").
+			Canonical(bpv7.NewGenericExtensionBlock([]byte("hint"), hintBlockType), bpv7.DeleteBundle).
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: bpv7.MustNewEndpointID("dtn://peer/"), Bundle: &b})
+
+		if len(handled) != 1 || handled[0] != "hint" {
+			t.Fatalf("handler was called for %v", handled)
+		}
+
+		if sent := relay.sent(); len(sent) != 1 {
+			t.Fatalf("expected one forwarded bundle, got %d", len(sent))
+		} else if sent[0].HasExtensionBlock(hintBlockType) {
+			t.Fatalf("forwarded bundle still contains the stripped block")
+		}
+	})
+}
+
+func TestCoreCRCPolicy(t *testing.T) {
+	tests := []struct {
+		policy CRCPolicy
+		sent   int
+	}{
+		{CRCPolicyRecompute, 1},
+		{CRCPolicyDelete, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			testCore(t, func(c *Core) {
+				const noteBlockType uint64 = 221
+
+				c.SetCRCPolicy(test.policy)
+
+				// This faulty BlockHandler modifies a block without recalculating its CRC.
+				c.RegisterBlockHandler(noteBlockType, BlockHandlerFunc(
+					func(block *bpv7.CanonicalBlock, _ *BundleDescriptor) BlockAction {
+						_ = block.Value.(*bpv7.GenericExtensionBlock).UnmarshalBinary([]byte("modified"))
+						return BlockKeep
+					}))
+
+				relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+				c.RegisterConvergable(relay)
+
+				bOut, err := bpv7.Builder().
+					CRC(bpv7.CRC32).
+					Source("dtn://src/").
+					Destination("dtn://dst/").
+					CreationTimestampNow().
+					Lifetime("10m").
+					Canonical(bpv7.NewGenericExtensionBlock([]byte("note"), noteBlockType)).
+					PayloadBlock([]byte("hello world")).
+					Build()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// Serialize the bundle to calculate its CRC values, as for a received bundle.
+				buff := new(bytes.Buffer)
+				if err := bOut.WriteBundle(buff); err != nil {
+					t.Fatal(err)
+				}
+				b, err := bpv7.ParseBundle(buff)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: bpv7.MustNewEndpointID("dtn://peer/"), Bundle: &b})
+
+				sent := relay.sent()
+				if len(sent) != test.sent {
+					t.Fatalf("expected %d forwarded bundles, got %d", test.sent, len(sent))
+				}
+				for _, bSent := range sent {
+					if err := bSent.VerifyCRC(); err != nil {
+						t.Fatalf("forwarded bundle has invalid CRC values: %v", err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestCoreDeliveryOrder(t *testing.T) {
+	testCore(t, func(c *Core) {
+		c.SetDeliveryOrder(250 * time.Millisecond)
+
+		app := bpv7.MustNewEndpointID("dtn://core/app")
+		delivered := make(chan bpv7.BundleID, 3)
+		unsubscribe, err := c.Subscribe(app, func(b bpv7.Bundle) { delivered <- b.ID() })
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unsubscribe()
+
+		created := time.Now().Add(-time.Minute)
+		var bndls []bpv7.Bundle
+		for i := 0; i < 3; i++ {
+			b, err := bpv7.Builder().
+				Source("dtn://src/").
+				Destination(app).
+				CreationTimestampTime(created.Add(time.Duration(i) * time.Second)).
+				Lifetime("10m").
+				PayloadBlock([]byte("hello world")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			bndls = append(bndls, b)
+		}
+
+		peer := bpv7.MustNewEndpointID("dtn://peer/")
+		for _, i := range []int{1, 0, 2} {
+			b := bndls[i]
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: peer, Bundle: &b})
+		}
+
+		for i := 0; i < len(bndls); i++ {
+			select {
+			case <-time.After(3 * time.Second):
+				t.Fatalf("bundle %d was not delivered", i)
+
+			case bid := <-delivered:
+				if bid != bndls[i].ID() {
+					t.Fatalf("delivered %v as bundle %d, expected %v", bid, i, bndls[i].ID())
+				}
+			}
+		}
+	})
+}
+
+func TestCoreAdministrativeRecordNoReports(t *testing.T) {
+	testCore(t, func(c *Core) {
+		relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+		c.RegisterConvergable(relay)
+
+		requests := bpv7.StatusRequestReception | bpv7.StatusRequestForward |
+			bpv7.StatusRequestDelivery | bpv7.StatusRequestDeletion
+
+		// One administrative record is forwarded, the other one is delivered locally.
+		for _, dst := range []bpv7.EndpointID{bpv7.MustNewEndpointID("dtn://dst/"), c.NodeId} {
+			ref := testCoreBundle(t, "dtn://other/", "dtn://dst/")
+			ar, err := bpv7.AdministrativeRecordToCbor(bpv7.NewStatusReport(ref, bpv7.ReceivedBundle, bpv7.NoInformation, bpv7.DtnTimeNow()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := bpv7.Builder().
+				BundleCtrlFlags(bpv7.AdministrativeRecordPayload).
+				Source("dtn://src/").
+				Destination(dst).
+				ReportTo("dtn://relay/").
+				CreationTimestampNow().
+				Lifetime("10m").
+				Canonical(ar).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Such flags are invalid for administrative records and must be ignored.
+			b.PrimaryBlock.BundleControlFlags |= requests
+
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+		}
+
+		for _, bSent := range relay.sent() {
+			if bSent.PrimaryBlock.SourceNode == c.NodeId {
+				t.Fatalf("status report was sent for an administrative record: %v", bSent.ID())
+			}
+		}
+	})
+}
+
+func TestCoreDestinationRewriter(t *testing.T) {
+	testCore(t, func(c *Core) {
+		dtnDst := bpv7.MustNewEndpointID("dtn://gateway-target/")
+		ipnDst := bpv7.MustNewEndpointID("ipn:23.1")
+		c.DestinationRewriter(func(eid bpv7.EndpointID) (bpv7.EndpointID, bool) {
+			return ipnDst, eid == dtnDst
+		})
+
+		relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+		c.RegisterConvergable(relay)
+
+		b := testCoreBundle(t, "dtn://src/", dtnDst.String())
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: bpv7.MustNewEndpointID("dtn://peer/"), Bundle: &b})
+
+		sent := relay.sent()
+		if len(sent) != 1 {
+			t.Fatalf("expected one forwarded bundle, got %d", len(sent))
+		} else if dst := sent[0].PrimaryBlock.Destination; dst != ipnDst {
+			t.Fatalf("forwarded bundle has destination %v, expected %v", dst, ipnDst)
+		} else if sent[0].ID() != b.ID() {
+			t.Fatalf("forwarded bundle has ID %v, expected %v", sent[0].ID(), b.ID())
+		}
+
+		// The rewritten primary block must have a valid CRC value.
+		pb := sent[0].PrimaryBlock
+		if err := pb.UpdateCRC(); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(pb.CRC, sent[0].PrimaryBlock.CRC) {
+			t.Fatalf("primary block's CRC is %x, expected %x", sent[0].PrimaryBlock.CRC, pb.CRC)
+		}
+
+		// The rewritten destination must be persisted, e.g., to survive a restart.
+		if bi, err := c.store.QueryId(b.ID()); err != nil {
+			t.Fatal(err)
+		} else if stored, err := bi.Parts[0].Load(); err != nil {
+			t.Fatal(err)
+		} else if dst := stored.PrimaryBlock.Destination; dst != ipnDst {
+			t.Fatalf("stored bundle has destination %v, expected %v", dst, ipnDst)
+		}
+	})
+}
+
+func TestCoreLifetimeExtender(t *testing.T) {
+	testCore(t, func(c *Core) {
+		c.LifetimeExtender(func(b bpv7.Bundle) (time.Duration, bool) {
+			return time.Hour, b.PrimaryBlock.Destination == bpv7.MustNewEndpointID("dtn://far-away/")
+		})
+
+		relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+		c.RegisterConvergable(relay)
+
+		bFar := testCoreBundle(t, "dtn://src/", "dtn://far-away/")
+		bNear := testCoreBundle(t, "dtn://src/near", "dtn://near/")
+		for _, b := range []*bpv7.Bundle{&bFar, &bNear} {
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: bpv7.MustNewEndpointID("dtn://peer/"), Bundle: b})
+		}
+
+		sent := relay.sent()
+		if len(sent) != 2 {
+			t.Fatalf("expected two forwarded bundles, got %d", len(sent))
+		}
+
+		for _, b := range sent {
+			expected := uint64(10 * time.Minute / time.Millisecond)
+			if b.ID() == bFar.ID() {
+				expected = uint64(time.Hour / time.Millisecond)
+			}
+
+			if b.PrimaryBlock.Lifetime != expected {
+				t.Fatalf("bundle %v has a lifetime of %d ms, expected %d ms", b.ID(), b.PrimaryBlock.Lifetime, expected)
+			}
+
+			// The primary block must have a valid CRC value.
+			pb := b.PrimaryBlock
+			if err := pb.UpdateCRC(); err != nil {
+				t.Fatal(err)
+			} else if !bytes.Equal(pb.CRC, b.PrimaryBlock.CRC) {
+				t.Fatalf("primary block's CRC is %x, expected %x", b.PrimaryBlock.CRC, pb.CRC)
+			}
+		}
+
+		// The store must keep the bundle for its extended lifetime.
+		if bi, err := c.store.QueryId(bFar.ID()); err != nil {
+			t.Fatal(err)
+		} else if bi.Expires.Before(time.Now().Add(50 * time.Minute)) {
+			t.Fatalf("stored bundle expires at %v", bi.Expires)
+		}
+	})
+}
+
+func TestCoreAgentMaxStaleness(t *testing.T) {
+	testCore(t, func(c *Core) {
+		delivered := make(chan bpv7.EndpointID, 4)
+		for _, app := range []struct {
+			eid          string
+			maxStaleness time.Duration
+		}{
+			{"dtn://core/tight", time.Second},
+			{"dtn://core/tolerant", time.Hour},
+		} {
+			eid := bpv7.MustNewEndpointID(app.eid)
+			ca := agent.NewCallback(eid, func(bpv7.Bundle) { delivered <- eid })
+			ca.SetMaxStaleness(app.maxStaleness)
+			if err := c.RegisterApplicationAgent(ca); err != nil {
+				t.Fatal(err)
+			}
+			defer ca.Close()
+		}
+
+		for _, dst := range []string{"dtn://core/tight", "dtn://core/tolerant"} {
+			b, err := bpv7.Builder().
+				Source("dtn://src/" + dst[len("dtn://core/"):]).
+				Destination(dst).
+				CreationTimestampTime(time.Now().Add(-time.Minute)).
+				Lifetime("10m").
+				PayloadBlock([]byte("old news")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+
+			if c.store.KnowsBundle(b.ID()) {
+				t.Fatalf("bundle %v is still stored", b.ID())
+			}
+		}
+
+		select {
+		case eid := <-delivered:
+			if eid != bpv7.MustNewEndpointID("dtn://core/tolerant") {
+				t.Fatalf("stale bundle was delivered to %v", eid)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("bundle was not delivered to the tolerant agent")
+		}
+
+		// A fresh bundle is delivered to the tight agent as well.
+		b := testCoreBundle(t, "dtn://src/", "dtn://core/tight")
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+
+		select {
+		case eid := <-delivered:
+			if eid != bpv7.MustNewEndpointID("dtn://core/tight") {
+				t.Fatalf("fresh bundle was delivered to %v", eid)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("fresh bundle was not delivered to the tight agent")
+		}
+	})
+}
+
+// mtuConvSender is a mockConvSender reporting an MTU.
+type mtuConvSender struct {
+	*mockConvSender
+	mtu int
+}
+
+func (m mtuConvSender) MTU() int {
+	return m.mtu
+}
+
+func TestCoreForwardFragmentation(t *testing.T) {
+	const mtu = 256
+
+	testCore(t, func(c *Core) {
+		relay := mtuConvSender{newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/")), mtu}
+		c.RegisterConvergable(relay)
+
+		payload := make([]byte, 1000)
+		for i := range payload {
+			payload[i] = byte(i)
+		}
+
+		b, err := bpv7.Builder().
+			CRC(bpv7.CRC32).
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("10m").
+			HopCountBlock(64).
+			PayloadBlock(payload).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: bpv7.MustNewEndpointID("dtn://peer/"), Bundle: &b})
+
+		sent := relay.sent()
+		if len(sent) < 2 {
+			t.Fatalf("expected multiple fragments, got %d bundles", len(sent))
+		}
+
+		var frags []bpv7.Bundle
+		for _, frag := range sent {
+			if !frag.PrimaryBlock.HasFragmentation() {
+				t.Fatalf("bundle %v is no fragment", frag.ID())
+			} else if frag.PrimaryBlock.TotalDataLength != uint64(len(payload)) {
+				t.Fatalf("fragment's total data length is %d", frag.PrimaryBlock.TotalDataLength)
+			}
+
+			// Each fragment must fit the MTU and its CRC values must be valid, checked while unmarshalling.
+			buff := new(bytes.Buffer)
+			if err := frag.MarshalCbor(buff); err != nil {
+				t.Fatal(err)
+			} else if buff.Len() > mtu {
+				t.Fatalf("fragment of %d bytes exceeds the MTU", buff.Len())
+			}
+
+			var fragParsed bpv7.Bundle
+			if err := fragParsed.UnmarshalCbor(buff); err != nil {
+				t.Fatal(err)
+			}
+			frags = append(frags, fragParsed)
+		}
+
+		if reassembled, err := bpv7.ReassembleFragments(frags); err != nil {
+			t.Fatal(err)
+		} else if reassembled.ID() != b.ID() {
+			t.Fatalf("reassembled bundle has ID %v, expected %v", reassembled.ID(), b.ID())
+		} else if pb, err := reassembled.PayloadBlock(); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(pb.Value.(*bpv7.PayloadBlock).Data(), payload) {
+			t.Fatal("reassembled payload differs")
+		}
+
+		// A bundle which must not be fragmented cannot be sent.
+		bUnfragmentable, err := bpv7.Builder().
+			BundleCtrlFlags(bpv7.MustNotFragmented).
+			Source("dtn://src/unfragmentable").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("10m").
+			PayloadBlock(payload).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: bpv7.MustNewEndpointID("dtn://peer/"), Bundle: &bUnfragmentable})
+
+		if l := len(relay.sent()); l != len(sent) {
+			t.Fatalf("unfragmentable bundle was sent, %d bundles in total", l)
+		}
+	})
+}
+
+func TestCoreFragmentMinPayload(t *testing.T) {
+	const mtu = 256
+
+	testCore(t, func(c *Core) {
+		c.SetFragmentMinPayload(mtu)
+
+		relay := mtuConvSender{newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/")), mtu}
+		c.RegisterConvergable(relay)
+
+		b, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("10m").
+			PayloadBlock(make([]byte, 1000)).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The MTU cannot hold the minimum payload next to the blocks' overhead, the bundle is kept for later.
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: bpv7.MustNewEndpointID("dtn://peer/"), Bundle: &b})
+
+		if l := len(relay.sent()); l != 0 {
+			t.Fatalf("%d fragments were sent below the minimum payload", l)
+		} else if !NewBundleDescriptor(b.ID(), c.store).HasConstraint(Contraindicated) {
+			t.Fatal("bundle is not contraindicated")
+		}
+
+		// Without a minimum, the pending bundle is fragmented.
+		c.SetFragmentMinPayload(0)
+		c.checkPendingBundles()
+
+		if l := len(relay.sent()); l < 2 {
+			t.Fatalf("expected multiple fragments, got %d bundles", l)
+		}
+	})
+}
+
+func TestCoreForwardingTimeout(t *testing.T) {
+	testCore(t, func(c *Core) {
+		c.SetForwardingTimeout(100 * time.Millisecond)
+
+		// Without any peer, this bundle with a lifetime of ten minutes stays pending.
+		b := testCoreBundle(t, "dtn://src/", "dtn://dst/")
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: bpv7.MustNewEndpointID("dtn://peer/"), Bundle: &b})
+
+		c.sweepForwardingTimeout()
+		if !c.store.KnowsBundle(b.ID()) {
+			t.Fatal("bundle was deleted before its forwarding timeout")
+		}
+
+		time.Sleep(150 * time.Millisecond)
+
+		c.sweepForwardingTimeout()
+		if c.store.KnowsBundle(b.ID()) {
+			t.Fatal("bundle is still stored after its forwarding timeout")
+		}
+	})
+}
+
+func TestCoreFuturePolicy(t *testing.T) {
+	tests := []struct {
+		policy  FuturePolicy
+		stored  bool
+		clamped bool
+		reports int
+	}{
+		{FuturePolicyAccept, true, false, 0},
+		{FuturePolicyClamp, true, true, 0},
+		{FuturePolicyReject, false, false, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			testCore(t, func(c *Core) {
+				c.SetFuturePolicy(test.policy, time.Minute)
+
+				relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+				c.RegisterConvergable(relay)
+
+				created := time.Now().Add(time.Hour)
+				b, err := bpv7.Builder().
+					BundleCtrlFlags(bpv7.StatusRequestDeletion).
+					Source("dtn://src/").
+					Destination("dtn://dst/").
+					ReportTo("dtn://relay/").
+					CreationTimestampTime(created).
+					Lifetime("10m").
+					PayloadBlock([]byte("hello future")).
+					Build()
+				if err != nil {
+					t.Fatal(err)
+				}
+				bid := b.ID()
+
+				c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+
+				var forwarded, reports []bpv7.Bundle
+				for _, bSent := range relay.sent() {
+					if bSent.IsAdministrativeRecord() {
+						reports = append(reports, bSent)
+					} else {
+						forwarded = append(forwarded, bSent)
+					}
+				}
+
+				if len(reports) != test.reports {
+					t.Fatalf("expected %d status reports, got %d", test.reports, len(reports))
+				}
+				for _, report := range reports {
+					ar, err := report.AdministrativeRecord()
+					if err != nil {
+						t.Fatal(err)
+					} else if sr, ok := ar.(*bpv7.StatusReport); !ok {
+						t.Fatalf("administrative record is a %T, not a status report", ar)
+					} else if sr.RefBundle != bid {
+						t.Fatalf("status report references %v, expected %v", sr.RefBundle, bid)
+					} else if sips := sr.StatusInformations(); len(sips) != 1 || sips[0] != bpv7.DeletedBundle {
+						t.Fatalf("status report asserts %v", sips)
+					}
+				}
+
+				if !test.stored {
+					if c.store.KnowsBundle(bid) {
+						t.Fatalf("rejected bundle %v entered the store", bid)
+					} else if len(forwarded) != 0 {
+						t.Fatalf("rejected bundle was forwarded %d times", len(forwarded))
+					}
+					return
+				}
+
+				if len(forwarded) != 1 {
+					t.Fatalf("expected one forwarded bundle, got %d", len(forwarded))
+				}
+
+				isFuture := forwarded[0].PrimaryBlock.CreationTimestamp.DtnTime().Time().After(time.Now())
+				if isFuture == test.clamped {
+					t.Fatalf("forwarded bundle's creation timestamp %v is unexpected, clamped: %t",
+						forwarded[0].PrimaryBlock.CreationTimestamp, test.clamped)
+				}
+			})
+		})
+	}
+}
+
+func TestCoreLifetimeFutureBundle(t *testing.T) {
+	testCore(t, func(c *Core) {
+		b, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampTime(time.Now().Add(time.Hour)).
+			Lifetime("10m").
+			PayloadBlock([]byte("hello future")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bp := NewBundleDescriptorFromBundle(b, c.store)
+		if c.isLifetimeExceeded(bp) {
+			t.Fatal("lifetime of a fresh future-dated bundle is exceeded")
+		}
+
+		bp.Timestamp = time.Now().Add(-20 * time.Minute)
+		if !c.isLifetimeExceeded(bp) {
+			t.Fatal("future-dated bundle, received 20 minutes ago, outlived its 10 minutes lifetime")
+		}
+	})
+}
+
+func TestCoreBundleAgeMilliseconds(t *testing.T) {
+	testCore(t, func(c *Core) {
+		peer := newMockConvSender("mock://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+		c.RegisterConvergable(peer)
+
+		b, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("10m").
+			BundleAgeBlock(0).
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bp := NewBundleDescriptorFromBundle(b, c.store)
+		bp.Timestamp = time.Now().Add(-2 * time.Second)
+
+		if age, err := bp.UpdateBundleAge(); err != nil {
+			t.Fatal(err)
+		} else if age < 2000 || age >= 3000 {
+			t.Fatalf("bundle age after two seconds is %d, expected about 2000 ms", age)
+		}
+
+		// An age of two seconds is far below the lifetime of 600000 ms; the bundle must be forwarded.
+		bp.Timestamp = time.Now().Add(-2 * time.Second)
+		c.forward(bp)
+
+		if sent := peer.sent(); len(sent) != 1 {
+			t.Fatalf("bundle aged two seconds was not forwarded, %d transmissions", len(sent))
+		}
+	})
+}
+
+func TestCoreStatusReportFragment(t *testing.T) {
+	testCore(t, func(c *Core) {
+		b, err := bpv7.Builder().
+			Source("ipn:23.42").
+			Destination("ipn:64.1").
+			CreationTimestampNow().
+			Lifetime("10m").
+			PayloadBlock(make([]byte, 1024)).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		frags, err := b.Fragment(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := c.store.Push(frags[0]); err != nil {
+			t.Fatal(err)
+		}
+
+		report := func(subject bpv7.Bundle) {
+			sr := bpv7.NewStatusReport(subject, bpv7.DeliveredBundle, bpv7.NoInformation, bpv7.DtnTimeNow())
+			ar, err := bpv7.AdministrativeRecordToCbor(sr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			bReport, err := bpv7.Builder().
+				BundleCtrlFlags(bpv7.AdministrativeRecordPayload).
+				Source("ipn:64.1").
+				Destination(c.NodeId).
+				CreationTimestampNow().
+				Lifetime("10m").
+				Canonical(ar).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !c.checkAdministrativeRecord(NewBundleDescriptorFromBundle(bReport, c.store)) {
+				t.Fatal("status report was not accepted")
+			}
+		}
+
+		// A delivery report for a fragment which is not stored must not delete the stored fragment.
+		report(frags[1])
+		if !c.store.KnowsBundle(b.ID()) {
+			t.Fatal("report for another fragment deleted the stored bundle")
+		}
+
+		report(frags[0])
+		if c.store.KnowsBundle(b.ID()) {
+			t.Fatal("report for the stored fragment did not delete the bundle")
+		}
+	})
+}
+
+func TestCoreBuiltAdministrativeRecord(t *testing.T) {
+	testCore(t, func(c *Core) {
+		b := testCoreBundle(t, "dtn://core/", "dtn://dst/")
+		c.SendBundle(&b)
+
+		bReport, err := bpv7.Builder().
+			Source("dtn://dst/").
+			Destination(c.NodeId).
+			CreationTimestampNow().
+			Lifetime("10m").
+			PayloadBlock(bpv7.NewStatusReport(b, bpv7.DeliveredBundle, bpv7.NoInformation, bpv7.DtnTimeNow())).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !c.checkAdministrativeRecord(NewBundleDescriptorFromBundle(bReport, c.store)) {
+			t.Fatal("built status report was not accepted")
+		} else if c.store.KnowsBundle(b.ID()) {
+			t.Fatal("delivery report did not delete the reported bundle")
+		}
+	})
+}
+
+func TestCoreSignedStatusReport(t *testing.T) {
+	testCore(t, func(c *Core) {
+		reporter := bpv7.MustNewEndpointID("dtn://reporter/")
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, otherPriv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c.SetTrustedKeys(map[bpv7.EndpointID]ed25519.PublicKey{reporter: pub})
+
+		b := testCoreBundle(t, "dtn://src/", "dtn://dst/")
+		if err := c.store.Push(b); err != nil {
+			t.Fatal(err)
+		}
+
+		// report a delivery of b, optionally signed, and alter the report afterwards.
+		report := func(seq uint64, key ed25519.PrivateKey, tamper bool) {
+			sr := bpv7.NewStatusReport(b, bpv7.DeliveredBundle, bpv7.NoInformation, bpv7.DtnTimeNow())
+			ar, err := bpv7.AdministrativeRecordToCbor(sr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			bReport, err := bpv7.Builder().
+				BundleCtrlFlags(bpv7.AdministrativeRecordPayload).
+				Source(reporter).
+				Destination(c.NodeId).
+				CreationTimestampNow().
+				Lifetime("10m").
+				Canonical(ar).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			bReport.PrimaryBlock.CreationTimestamp = bpv7.NewCreationTimestamp(bpv7.DtnTimeNow(), seq)
+
+			if key != nil {
+				sb, err := bpv7.NewSignatureBlock(bReport, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				bReport.AddExtensionBlock(bpv7.NewCanonicalBlock(0, bpv7.ReplicateBlock|bpv7.DeleteBundle, sb))
+			}
+
+			if tamper {
+				bReport.PrimaryBlock.Lifetime *= 2
+			}
+
+			bp := NewBundleDescriptor(bReport.ID(), c.store)
+			bp.bndl = &bReport
+			if !c.checkAdministrativeRecord(bp) {
+				t.Fatal("status report was not parsed")
+			}
+		}
+
+		tests := []struct {
+			name   string
+			key    ed25519.PrivateKey
+			tamper bool
+		}{
+			{"unsigned", nil, false},
+			{"untrusted key", otherPriv, false},
+			{"tampered", priv, true},
+		}
+		for i, test := range tests {
+			report(uint64(i), test.key, test.tamper)
+			if !c.store.KnowsBundle(b.ID()) {
+				t.Fatalf("%s status report deleted the bundle", test.name)
+			}
+		}
+
+		report(uint64(len(tests)), priv, false)
+		if c.store.KnowsBundle(b.ID()) {
+			t.Fatal("signed status report did not delete the bundle")
+		}
+	})
+}
+
+func TestCorePendingReassemblies(t *testing.T) {
+	testCore(t, func(c *Core) {
+		b, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("10m").
+			PayloadBlock(make([]byte, 1024)).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		frags, err := b.Fragment(256)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(frags) < 4 {
+			t.Fatalf("expected at least four fragments, got %d", len(frags))
+		}
+
+		// Store the first two and the last fragment, missing those in between.
+		var expected []ReassemblyRange
+		for _, frag := range []bpv7.Bundle{frags[0], frags[1], frags[len(frags)-1]} {
+			if err := c.store.Push(frag); err != nil {
+				t.Fatal(err)
+			}
+
+			pb, err := frag.PayloadBlock()
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected = append(expected, ReassemblyRange{
+				Offset: frag.PrimaryBlock.FragmentOffset,
+				Length: uint64(len(pb.Value.(*bpv7.PayloadBlock).Data())),
+			})
+		}
+		expected = []ReassemblyRange{
+			{Offset: 0, Length: expected[0].Length + expected[1].Length},
+			expected[2],
+		}
+
+		infos, err := c.PendingReassemblies()
+		if err != nil {
+			t.Fatal(err)
+		} else if len(infos) != 1 {
+			t.Fatalf("expected one pending reassembly, got %d", len(infos))
+		}
+
+		info := infos[0]
+		if info.Id != b.ID() {
+			t.Fatalf("pending reassembly of %v, expected %v", info.Id, b.ID())
+		} else if info.Source != b.PrimaryBlock.SourceNode {
+			t.Fatalf("pending reassembly's source is %v, expected %v", info.Source, b.PrimaryBlock.SourceNode)
+		} else if info.TotalDataLength != 1024 {
+			t.Fatalf("pending reassembly's total length is %d", info.TotalDataLength)
+		} else if !reflect.DeepEqual(info.Received, expected) {
+			t.Fatalf("pending reassembly received %v, expected %v", info.Received, expected)
+		}
+
+		if err := c.CancelReassembly(b.ID()); err != nil {
+			t.Fatal(err)
+		} else if c.store.KnowsBundle(b.ID()) {
+			t.Fatal("cancelled reassembly's fragments are still stored")
+		} else if infos, err := c.PendingReassemblies(); err != nil {
+			t.Fatal(err)
+		} else if len(infos) != 0 {
+			t.Fatalf("%d reassemblies are still pending after cancelling", len(infos))
+		}
+
+		// Complete bundles cannot be cancelled.
+		complete := testCoreBundle(t, "dtn://src/", "dtn://dst/")
+		if err := c.store.Push(complete); err != nil {
+			t.Fatal(err)
+		} else if err := c.CancelReassembly(complete.ID()); err == nil {
+			t.Fatal("cancelling a complete bundle did not fail")
+		}
+	})
+}
+
+// testCoreFragmentedBundle creates a bundle from src to dst with a random payload of 1024 bytes.
+func testCoreFragmentedBundle(t *testing.T, src, dst string) (b bpv7.Bundle, payload []byte) {
+	payload = make([]byte, 1024)
+	_, _ = rand.Read(payload)
+
+	b, err := bpv7.Builder().
+		BundleCtrlFlags(bpv7.StatusRequestDeletion).
+		Source(src).
+		Destination(dst).
+		ReportTo("dtn://relay/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock(payload).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestCoreReassembly(t *testing.T) {
+	testCore(t, func(c *Core) {
+		delivered := make(chan bpv7.Bundle, 4)
+		ca := agent.NewCallback(bpv7.MustNewEndpointID("dtn://core/app"), func(b bpv7.Bundle) { delivered <- b })
+		if err := c.RegisterApplicationAgent(ca); err != nil {
+			t.Fatal(err)
+		}
+		defer ca.Close()
+
+		b, payload := testCoreFragmentedBundle(t, "dtn://src/", "dtn://core/app")
+
+		smallFrags, err := b.Fragment(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		largeFrags, err := b.Fragment(512)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Duplicate and overlapping fragments, the last one completes the payload.
+		frags := append([]bpv7.Bundle{}, largeFrags[:len(largeFrags)-1]...)
+		frags = append(frags, smallFrags[0], largeFrags[0], smallFrags[len(smallFrags)/2])
+		frags = append(frags, largeFrags[len(largeFrags)-1])
+
+		for i := range frags {
+			select {
+			case <-delivered:
+				t.Fatalf("bundle was delivered after %d of %d fragments", i, len(frags))
+			default:
+			}
+
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &frags[i]})
+		}
+
+		select {
+		case rb := <-delivered:
+			if rb.PrimaryBlock.HasFragmentation() {
+				t.Fatal("delivered bundle is still a fragment")
+			} else if rb.ID() != b.ID() {
+				t.Fatalf("delivered bundle has ID %v, expected %v", rb.ID(), b.ID())
+			} else if pb, err := rb.PayloadBlock(); err != nil {
+				t.Fatal(err)
+			} else if !bytes.Equal(pb.Value.(*bpv7.PayloadBlock).Data(), payload) {
+				t.Fatal("reassembled payload differs")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("reassembled bundle was not delivered")
+		}
+
+		select {
+		case <-delivered:
+			t.Fatal("bundle was delivered twice")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		if infos, err := c.PendingReassemblies(); err != nil {
+			t.Fatal(err)
+		} else if len(infos) != 0 {
+			t.Fatalf("%d reassemblies are still pending", len(infos))
+		}
+	})
+}
+
+func TestCoreReassemblyTimeout(t *testing.T) {
+	testCore(t, func(c *Core) {
+		c.SetReassemblyTimeout(50 * time.Millisecond)
+
+		ca := agent.NewCallback(bpv7.MustNewEndpointID("dtn://core/app"), func(bpv7.Bundle) {
+			t.Error("incomplete bundle was delivered")
+		})
+		if err := c.RegisterApplicationAgent(ca); err != nil {
+			t.Fatal(err)
+		}
+		defer ca.Close()
+
+		b, _ := testCoreFragmentedBundle(t, "dtn://src/", "dtn://core/app")
+		frags, err := b.Fragment(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := range frags[:len(frags)-1] {
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &frags[i]})
+		}
+
+		if !c.store.KnowsBundle(b.ID()) {
+			t.Fatal("fragments are not stored")
+		}
+
+		time.Sleep(200 * time.Millisecond)
+
+		if c.store.KnowsBundle(b.ID()) {
+			t.Fatal("fragments are still stored after the reassembly timeout")
+		}
+
+		// A late fragment starts a new reassembly instead of completing the old one.
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &frags[len(frags)-1]})
+		if infos, err := c.PendingReassemblies(); err != nil {
+			t.Fatal(err)
+		} else if len(infos) != 1 {
+			t.Fatalf("expected one pending reassembly, got %d", len(infos))
+		}
+	})
+}
+
+func TestCoreReassemblyChecksFragments(t *testing.T) {
+	testCore(t, func(c *Core) {
+		delivered := make(chan bpv7.Bundle, 1)
+		ca := agent.NewCallback(bpv7.MustNewEndpointID("dtn://core/app"), func(b bpv7.Bundle) { delivered <- b })
+		if err := c.RegisterApplicationAgent(ca); err != nil {
+			t.Fatal(err)
+		}
+		defer ca.Close()
+
+		b, _ := testCoreFragmentedBundle(t, "dtn://src/", "dtn://core/app")
+		frags, err := b.Fragment(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &frags[0]})
+
+		// A fragment of the ongoing reassembly whose lifetime is exceeded must be dropped without its siblings.
+		expired := frags[1]
+		expired.PrimaryBlock.Lifetime = 0
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &expired})
+
+		if infos, err := c.PendingReassemblies(); err != nil {
+			t.Fatal(err)
+		} else if len(infos) != 1 {
+			t.Fatalf("expected one pending reassembly, got %d", len(infos))
+		} else if l := len(infos[0].Received); l != 1 || infos[0].Received[0].Offset != 0 {
+			t.Fatalf("pending reassembly received %v, expected only the first fragment", infos[0].Received)
+		}
+
+		for i := range frags[1:] {
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &frags[i+1]})
+		}
+
+		select {
+		case rb := <-delivered:
+			if rb.ID() != b.ID() {
+				t.Fatalf("delivered bundle has ID %v, expected %v", rb.ID(), b.ID())
+			}
+		case <-time.After(time.Second):
+			t.Fatal("reassembled bundle was not delivered")
+		}
+	})
+}
+
+// testCorePayloadlessBundle from src to dst, requesting deletion reports to dtn://relay/.
+func testCorePayloadlessBundle(t *testing.T, src, dst string) bpv7.Bundle {
+	b, err := bpv7.Builder().
+		BundleCtrlFlags(bpv7.StatusRequestDeletion).
+		Source(src).
+		Destination(dst).
+		ReportTo("dtn://relay/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock([]byte("probe")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.RemoveExtensionBlockByBlockNumber(1)
+	if _, err := b.PayloadBlock(); err == nil {
+		t.Fatal("bundle still has a payload block")
+	}
+	return b
+}
+
+func TestCorePayloadlessForward(t *testing.T) {
+	testCore(t, func(c *Core) {
+		relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+		c.RegisterConvergable(relay)
+
+		b := testCorePayloadlessBundle(t, "dtn://src/", "dtn://dst/")
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+
+		if sent := relay.sent(); len(sent) != 1 {
+			t.Fatalf("expected one forwarded bundle, got %d", len(sent))
+		} else if sent[0].ID() != b.ID() {
+			t.Fatalf("forwarded %v, expected %v", sent[0].ID(), b.ID())
+		} else if sent[0].IsAdministrativeRecord() {
+			t.Fatal("forwarding a payload-less bundle resulted in a status report")
+		}
+	})
+}
+
+func TestCorePayloadlessDelivery(t *testing.T) {
+	testCore(t, func(c *Core) {
+		relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+		c.RegisterConvergable(relay)
+
+		app := bpv7.MustNewEndpointID("dtn://core/app")
+		delivered := make(chan bpv7.BundleID, 2)
+		unsubscribe, err := c.Subscribe(app, func(b bpv7.Bundle) { delivered <- b.ID() })
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unsubscribe()
+
+		b := testCorePayloadlessBundle(t, "dtn://src/", app.String())
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+
+		if c.store.KnowsBundle(b.ID()) {
+			t.Fatal("payload-less bundle is still stored")
+		}
+
+		sent := relay.sent()
+		if len(sent) != 1 {
+			t.Fatalf("expected one status report, got %d bundles", len(sent))
+		}
+		ar, err := sent[0].AdministrativeRecord()
+		if err != nil {
+			t.Fatal(err)
+		} else if sr, ok := ar.(*bpv7.StatusReport); !ok {
+			t.Fatalf("administrative record is a %T, not a status report", ar)
+		} else if sips := sr.StatusInformations(); len(sips) != 1 || sips[0] != bpv7.DeletedBundle {
+			t.Fatalf("status report asserts %v", sips)
+		} else if sr.ReportReason != bpv7.BlockUnintelligible {
+			t.Fatalf("status report's reason is %v", sr.ReportReason)
+		}
+
+		// An empty payload block is fine.
+		bEmpty, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination(app).
+			CreationTimestampNow().
+			Lifetime("10m").
+			PayloadBlock([]byte{}).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &bEmpty})
+
+		select {
+		case <-time.After(time.Second):
+			t.Fatal("bundle with an empty payload was not delivered")
+		case bid := <-delivered:
+			if bid != bEmpty.ID() {
+				t.Fatalf("delivered %v, expected %v", bid, bEmpty.ID())
+			}
+		}
+	})
+}
+
+func TestCoreBandwidthLimit(t *testing.T) {
+	testCore(t, func(c *Core) {
+		const bytesPerSecond = 100000
+		c.SetBandwidthLimit(bytesPerSecond)
+
+		var senders []*mockConvSender
+		for i := 0; i < 3; i++ {
+			sender := newMockConvSender(fmt.Sprintf("mock://peer-%d", i), bpv7.MustNewEndpointID(fmt.Sprintf("dtn://peer-%d/", i)))
+			c.RegisterConvergable(sender)
+			senders = append(senders, sender)
+		}
+
+		start := time.Now()
+		for i := 0; i < 5; i++ {
+			b, err := bpv7.Builder().
+				Source(fmt.Sprintf("dtn://core/%d", i)).
+				Destination("dtn://dst/").
+				CreationTimestampNow().
+				Lifetime("10m").
+				PayloadBlock(make([]byte, 10000)).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c.SendBundle(&b)
+		}
+		elapsed := time.Since(start)
+
+		var total byteCounter
+		for _, sender := range senders {
+			sent := sender.sent()
+			if len(sent) != 5 {
+				t.Fatalf("sender %v sent %d bundles, expected 5", sender, len(sent))
+			}
+			for _, b := range sent {
+				if err := b.WriteBundle(&total); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		// One second's worth of bytes might be sent as an initial burst; the rest must be limited.
+		if limit := bytesPerSecond * (1 + elapsed.Seconds()); float64(total) > limit {
+			t.Fatalf("sent %d bytes within %v, exceeding the limit of %.0f bytes", total, elapsed, limit)
+		}
+	})
+}
+
+func TestCoreReplay(t *testing.T) {
+	testCore(t, func(c *Core) {
+		c.SetRetainDelivered(true)
+
+		app := bpv7.MustNewEndpointID("dtn://core/app")
+
+		delivered := make(chan bpv7.BundleID, 1)
+		unsubscribe, err := c.Subscribe(app, func(b bpv7.Bundle) { delivered <- b.ID() })
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b := testCoreBundle(t, "dtn://src/", app.String())
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: bpv7.MustNewEndpointID("dtn://peer/"), Bundle: &b})
+
+		select {
+		case <-time.After(time.Second):
+			t.Fatal("bundle was not delivered")
+		case <-delivered:
+		}
+		unsubscribe()
+
+		// The delivered bundle is replayed to a newly registered agent.
+		redelivered := make(chan bpv7.BundleID, 1)
+		unsubscribe, err = c.Subscribe(app, func(b bpv7.Bundle) { redelivered <- b.ID() })
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unsubscribe()
+
+		if err := c.Replay(b.ID()); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+			t.Fatal("replayed bundle was not delivered")
+		case bid := <-redelivered:
+			if bid != b.ID() {
+				t.Fatalf("delivered %v, expected %v", bid, b.ID())
+			}
+		}
+
+		unknown := testCoreBundle(t, "dtn://unknown/", app.String())
+		if err := c.Replay(unknown.ID()); err == nil {
+			t.Fatal("replaying an unknown bundle did not error")
+		}
+	})
+}
+
+func TestCorePreviousNodeBlock(t *testing.T) {
+	testCore(t, func(c *Core) {
+		relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+		c.RegisterConvergable(relay)
+
+		// The first bundle carries a PreviousNodeBlock to be replaced, the second one lacks it.
+		bldrs := []*bpv7.BundleBuilder{
+			bpv7.Builder().Source("dtn://src/a").PreviousNodeBlock("dtn://peer/", bpv7.StatusReportBlock),
+			bpv7.Builder().Source("dtn://src/b"),
+		}
+
+		// The replaced PreviousNodeBlock keeps its block control flags.
+		flags := map[string]bpv7.BlockControlFlags{
+			"dtn://src/a": bpv7.StatusReportBlock | bpv7.ReplicateBlock,
+			"dtn://src/b": 0,
+		}
+
+		for _, bldr := range bldrs {
+			b, err := bldr.
+				Destination("dtn://dst/").
+				CreationTimestampNow().
+				Lifetime("10m").
+				PayloadBlock([]byte("hello world")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+		}
+
+		sent := relay.sent()
+		if len(sent) != len(bldrs) {
+			t.Fatalf("expected %d forwarded bundles, got %d", len(bldrs), len(sent))
+		}
+
+		for _, b := range sent {
+			if pnBlock, err := b.ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock); err != nil {
+				t.Fatalf("forwarded bundle %v has no PreviousNodeBlock: %v", b.ID(), err)
+			} else if prev := pnBlock.Value.(*bpv7.PreviousNodeBlock).Endpoint(); prev != c.NodeId {
+				t.Fatalf("forwarded bundle %v has previous node %v, expected %v", b.ID(), prev, c.NodeId)
+			} else if f := flags[b.PrimaryBlock.SourceNode.String()]; pnBlock.BlockControlFlags != f {
+				t.Fatalf("forwarded bundle %v's PreviousNodeBlock has flags %v, expected %v",
+					b.ID(), pnBlock.BlockControlFlags, f)
+			}
+		}
+	})
+}
+
+func TestNewCoreNonSingletonNodeId(t *testing.T) {
+	dir, err := ioutil.TempDir("", "core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	if c, err := NewCore(dir, bpv7.MustNewEndpointID("dtn://node/~group"), false, RoutingConf{Algorithm: "epidemic"}, nil); err == nil {
+		c.Close()
+		t.Fatal("non-singleton node ID was accepted")
+	}
+}
+
+func TestCoreUnknownAdminRecordPolicy(t *testing.T) {
+	for _, policy := range []AdminRecordPolicy{AdminRecordPolicyLog, AdminRecordPolicyDrop, AdminRecordPolicyReport} {
+		t.Run(policy.String(), func(t *testing.T) {
+			testCore(t, func(c *Core) {
+				c.SetAdminRecordPolicy(policy)
+
+				delivered := make(chan bpv7.Bundle, 1)
+				ca := agent.NewCallback(bpv7.MustNewEndpointID("dtn://core/app"), func(b bpv7.Bundle) { delivered <- b })
+				if err := c.RegisterApplicationAgent(ca); err != nil {
+					t.Fatal(err)
+				}
+				defer ca.Close()
+
+				// CBOR array of an unregistered record type code 99 and some content.
+				b, err := bpv7.Builder().
+					BundleCtrlFlags(bpv7.AdministrativeRecordPayload).
+					Source("dtn://src/").
+					Destination("dtn://core/app").
+					CreationTimestampNow().
+					Lifetime("10m").
+					PayloadBlock([]byte{0x82, 0x18, 0x63, 0x00}).
+					Build()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+
+				select {
+				case <-delivered:
+					t.Fatal("unknown administrative record was delivered")
+				case <-time.After(100 * time.Millisecond):
+				}
+
+				if c.store.KnowsBundle(b.ID()) {
+					t.Fatal("unknown administrative record is still stored")
+				}
+
+				reports, err := c.store.QueryBySource(c.NodeId)
+				if err != nil {
+					t.Fatal(err)
+				} else if expected := policy == AdminRecordPolicyReport; (len(reports) == 1) != expected {
+					t.Fatalf("%d status reports were created, expected one: %t", len(reports), expected)
+				}
+			})
+		})
+	}
+}
+
+func TestCoreDeliveryGuarantee(t *testing.T) {
+	guaranteedBundle := func(t *testing.T, src, dst string, guarantee bpv7.DeliveryGuarantee) bpv7.Bundle {
+		b, err := bpv7.Builder().
+			Source(src).
+			Destination(dst).
+			CreationTimestampNow().
+			Lifetime("10m").
+			DeliveryGuaranteeBlock(guarantee).
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	t.Run("at-least-once", func(t *testing.T) {
+		testCore(t, func(c *Core) {
+			peer := newMockConvSender("mock://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+			c.RegisterConvergable(peer)
+
+			b := guaranteedBundle(t, "dtn://core/", "dtn://peer/app", bpv7.AtLeastOnce)
+			c.SendBundle(&b)
+
+			if sent := peer.sent(); len(sent) != 1 {
+				t.Fatalf("expected one transmission, got %d", len(sent))
+			} else if pb := sent[0].PrimaryBlock; !pb.BundleControlFlags.Has(bpv7.StatusRequestDelivery) {
+				t.Fatal("sent bundle does not request a delivery report")
+			} else if pb.ReportTo != c.NodeId {
+				t.Fatalf("sent bundle reports to %v", pb.ReportTo)
+			}
+
+			// Without an acknowledgement, the bundle is retransmitted.
+			c.retransmitBundles()
+			c.retransmitBundles()
+			if sent := peer.sent(); len(sent) != 3 {
+				t.Fatalf("expected three transmissions, got %d", len(sent))
+			}
+
+			sr := bpv7.NewStatusReport(peer.sent()[0], bpv7.DeliveredBundle, bpv7.NoInformation, bpv7.DtnTimeNow())
+			ar, err := bpv7.AdministrativeRecordToCbor(sr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ack, err := bpv7.Builder().
+				BundleCtrlFlags(bpv7.AdministrativeRecordPayload).
+				Source("dtn://peer/").
+				Destination(c.NodeId).
+				CreationTimestampNow().
+				Lifetime("10m").
+				Canonical(ar).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &ack})
+
+			// After the acknowledgement, the bundle is neither stored nor retransmitted.
+			if c.store.KnowsBundle(b.ID()) {
+				t.Fatal("acknowledged bundle is still stored")
+			}
+			c.retransmitBundles()
+			if sent := peer.sent(); len(sent) != 3 {
+				t.Fatalf("acknowledged bundle was retransmitted, %d transmissions", len(sent))
+			}
+		})
+	})
+
+	t.Run("best-effort", func(t *testing.T) {
+		testCore(t, func(c *Core) {
+			peer := newMockConvSender("mock://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+			c.RegisterConvergable(peer)
+
+			b := guaranteedBundle(t, "dtn://core/", "dtn://peer/app", bpv7.BestEffort)
+			c.SendBundle(&b)
+
+			if sent := peer.sent(); len(sent) != 1 {
+				t.Fatalf("expected one transmission, got %d", len(sent))
+			}
+
+			c.retransmitBundles()
+			c.checkPendingBundles()
+			if sent := peer.sent(); len(sent) != 1 {
+				t.Fatalf("best-effort bundle was retransmitted, %d transmissions", len(sent))
+			} else if c.store.KnowsBundle(b.ID()) {
+				t.Fatal("best-effort bundle is still stored")
+			}
+
+			// A failed best-effort bundle is dropped instead of being kept for a later attempt.
+			peer.Lock()
+			peer.sendErr = fmt.Errorf("test")
+			peer.Unlock()
+
+			failed := guaranteedBundle(t, "dtn://core/", "dtn://peer/app", bpv7.BestEffort)
+			c.SendBundle(&failed)
+			if c.store.KnowsBundle(failed.ID()) {
+				t.Fatal("failed best-effort bundle is still stored")
+			}
+
+			unguaranteed := testCoreBundle(t, "dtn://core/", "dtn://peer/app")
+			c.SendBundle(&unguaranteed)
+			if !c.store.KnowsBundle(unguaranteed.ID()) {
+				t.Fatal("failed bundle without a guarantee was not kept")
+			}
+		})
+	})
+
+	for _, guarantee := range []bpv7.DeliveryGuarantee{bpv7.AtLeastOnce, bpv7.ExactlyOnce} {
+		guarantee := guarantee
+		t.Run("destination-"+guarantee.String(), func(t *testing.T) {
+			testCore(t, func(c *Core) {
+				src := newMockConvSender("mock://src", bpv7.MustNewEndpointID("dtn://src/"))
+				c.RegisterConvergable(src)
+
+				app := bpv7.MustNewEndpointID("dtn://core/app")
+				deliveries := make(chan bpv7.BundleID, 2)
+				unsubscribe, err := c.Subscribe(app, func(b bpv7.Bundle) { deliveries <- b.ID() })
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer unsubscribe()
+
+				b := guaranteedBundle(t, "dtn://src/", app.String(), guarantee)
+				b.PrimaryBlock.BundleControlFlags |= bpv7.StatusRequestDelivery
+				b.PrimaryBlock.ReportTo = bpv7.MustNewEndpointID("dtn://src/")
+
+				// The second reception is a retransmission, e.g., after the first acknowledgement was lost.
+				for i := 0; i < 2; i++ {
+					retransmission := b
+					c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &retransmission})
+				}
+
+				expectedDeliveries := 2
+				if guarantee == bpv7.ExactlyOnce {
+					expectedDeliveries = 1
+				}
+
+				for i := 0; i < expectedDeliveries; i++ {
+					select {
+					case <-deliveries:
+					case <-time.After(time.Second):
+						t.Fatalf("expected %d deliveries, got %d", expectedDeliveries, i)
+					}
+				}
+				select {
+				case <-deliveries:
+					t.Fatalf("bundle was delivered more than %d times", expectedDeliveries)
+				case <-time.After(100 * time.Millisecond):
+				}
+
+				if acks := len(src.sent()); acks != 2 {
+					t.Fatalf("expected two acknowledgements, got %d", acks)
+				}
+			})
+		})
+	}
+}
+
+func TestCorePayloadIntegrity(t *testing.T) {
+	testCore(t, func(c *Core) {
+		app := bpv7.MustNewEndpointID("dtn://core/app")
+
+		bndlChan := make(chan bpv7.Bundle, 2)
+		if _, err := c.Subscribe(app, func(b bpv7.Bundle) { bndlChan <- b }); err != nil {
+			t.Fatal(err)
+		}
+
+		relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+		c.RegisterConvergable(relay)
+
+		// corruptBundle creates a bundle with a PayloadIntegrityBlock, whose payload was modified afterwards.
+		corruptBundle := func(dst string) bpv7.Bundle {
+			b, err := bpv7.Builder().
+				Source("dtn://src/").
+				Destination(dst).
+				CreationTimestampNow().
+				Lifetime("10m").
+				PayloadBlock([]byte("hello world")).
+				PayloadIntegrityBlock().
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pb, err := b.PayloadBlock()
+			if err != nil {
+				t.Fatal(err)
+			}
+			(*pb.Value.(*bpv7.PayloadBlock))[0] ^= 0xff
+
+			return b
+		}
+
+		// A relay ignores the PayloadIntegrityBlock and forwards the corrupted bundle.
+		bRelay := corruptBundle("dtn://dst/")
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &bRelay})
+
+		if sent := relay.sent(); len(sent) != 1 || sent[0].ID() != bRelay.ID() {
+			t.Fatalf("relay received %v, expected the corrupted bundle", sent)
+		}
+
+		// The destination detects the corruption and does not deliver the bundle.
+		bLocal := corruptBundle(app.String())
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &bLocal})
+
+		select {
+		case bIn := <-bndlChan:
+			t.Fatalf("corrupted bundle %v was delivered", bIn.ID())
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		if c.store.KnowsBundle(bLocal.ID()) {
+			t.Fatal("corrupted bundle is still stored")
+		}
+
+		// An intact bundle is still delivered.
+		bIntact, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination(app).
+			CreationTimestampNow().
+			Lifetime("10m").
+			PayloadBlock([]byte("hello world")).
+			PayloadIntegrityBlock().
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &bIntact})
+
+		select {
+		case bIn := <-bndlChan:
+			if bIn.ID() != bIntact.ID() {
+				t.Fatalf("delivered %v, expected %v", bIn.ID(), bIntact.ID())
+			}
+		case <-time.After(time.Second):
+			t.Fatal("intact bundle was not delivered")
+		}
+	})
+}
+
+func TestCoreTransmitPolicy(t *testing.T) {
+	tests := []struct {
+		policy TransmitPolicy
+		sent   int
+	}{
+		{TransmitPolicyOriginate, 0},
+		{TransmitPolicyRelay, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			testCore(t, func(c *Core) {
+				c.SetTransmitPolicy(test.policy)
+
+				relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+				c.RegisterConvergable(relay)
+
+				// Re-inject a bundle originated elsewhere.
+				b := testCoreBundle(t, "dtn://foreign/", "dtn://dst/")
+				c.SendBundle(&b)
+
+				if sent := relay.sent(); len(sent) != test.sent {
+					t.Fatalf("relay received %d bundles, expected %d", len(sent), test.sent)
+				}
+				if known := c.store.KnowsBundle(b.ID()); known != (test.sent > 0) {
+					t.Fatalf("bundle is stored: %t", known)
+				}
+			})
+		})
+	}
+
+	for _, policy := range []TransmitPolicy{TransmitPolicyOriginate, TransmitPolicyRelay} {
+		if parsed, err := ParseTransmitPolicy(policy.String()); err != nil || parsed != policy {
+			t.Fatalf("parsing %v resulted in %v, %v", policy, parsed, err)
+		}
+	}
+	if _, err := ParseTransmitPolicy("whatever"); err == nil {
+		t.Fatal("unknown transmit policy was parsed")
+	}
+}
+
+func TestCoreReceiveExpired(t *testing.T) {
+	testCore(t, func(c *Core) {
+		app := bpv7.MustNewEndpointID("dtn://core/app")
+
+		bndlChan := make(chan bpv7.Bundle, 2)
+		if _, err := c.Subscribe(app, func(b bpv7.Bundle) { bndlChan <- b }); err != nil {
+			t.Fatal(err)
+		}
+
+		relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+		c.RegisterConvergable(relay)
+
+		// Build bundles without a validity check, which would reject the exceeded lifetime.
+		expiredBundle := func(dst string, ts bpv7.CreationTimestamp, age uint64) bpv7.Bundle {
+			canonicals := []bpv7.CanonicalBlock{
+				bpv7.NewCanonicalBlock(1, 0, bpv7.NewPayloadBlock([]byte("hello past")))}
+			if ts.IsZeroTime() {
+				canonicals = append(canonicals, bpv7.NewCanonicalBlock(2, 0, bpv7.NewBundleAgeBlock(age)))
+			}
+
+			primary := bpv7.NewPrimaryBlock(
+				0,
+				bpv7.MustNewEndpointID(dst),
+				bpv7.MustNewEndpointID("dtn://src/"),
+				ts,
+				uint64((time.Microsecond).Milliseconds()))
+			return bpv7.MustNewBundle(primary, canonicals)
+		}
+
+		past := bpv7.NewCreationTimestamp(bpv7.DtnTimeFromTime(time.Now().Add(-time.Minute)), 0)
+		epoch := bpv7.NewCreationTimestamp(bpv7.DtnTimeEpoch, 0)
+
+		tests := []struct {
+			name string
+			bndl bpv7.Bundle
+		}{
+			{"relay", expiredBundle("dtn://dst/", past, 0)},
+			{"local", expiredBundle(app.String(), past, 0)},
+			{"bundle age", expiredBundle("dtn://dst/", epoch, 60000)},
+		}
+
+		for _, test := range tests {
+			bndl := test.bndl
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &bndl})
+
+			if c.store.KnowsBundle(bndl.ID()) {
+				t.Fatalf("%s: expired bundle is still stored", test.name)
+			}
+		}
+
+		if sent := relay.sent(); len(sent) != 0 {
+			t.Fatalf("relay received expired bundles %v", sent)
+		}
+
+		select {
+		case bIn := <-bndlChan:
+			t.Fatalf("expired bundle %v was delivered", bIn.ID())
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+}
+
+func TestCoreForwardLifetimeContext(t *testing.T) {
+	testCore(t, func(c *Core) {
+		blocking := newBlockingConvSender("mock://blocking", bpv7.MustNewEndpointID("dtn://dst/"))
+		defer close(blocking.release)
+		c.RegisterConvergable(blocking)
+
+		bndl, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("500ms").
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &bndl})
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("forwarding to a blocked CLA was not aborted after the bundle's lifetime")
+		}
+	})
+}
@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/internal/enum"
+)
+
+// CRCPolicy describes how the CRC values of a bundle are verified before being forwarded. This safeguard catches
+// in-memory corruption or modifications without recalculating the CRC.
+type CRCPolicy int
+
+const (
+	// CRCPolicyNone skips the verification, which is the default.
+	CRCPolicyNone CRCPolicy = iota
+
+	// CRCPolicyRecompute recalculates mismatching CRC values and logs a warning.
+	CRCPolicyRecompute
+
+	// CRCPolicyDelete deletes bundles with mismatching CRC values.
+	CRCPolicyDelete
+)
+
+var crcPolicyNames = enum.NewNames("CRC policy", "none", "recompute", "delete").Alias(0, "")
+
+func (policy CRCPolicy) String() string {
+	return crcPolicyNames.String(uint64(policy))
+}
+
+// ParseCRCPolicy from its name, as returned by String. An empty name results in CRCPolicyNone.
+func ParseCRCPolicy(name string) (CRCPolicy, error) {
+	policy, err := crcPolicyNames.Parse(name)
+	return CRCPolicy(policy), err
+}
+
+// SetCRCPolicy sets the CRCPolicy for verifying bundles before being forwarded.
+func (c *Core) SetCRCPolicy(policy CRCPolicy) {
+	c.settingsMutex.Lock()
+	c.crcPolicy = policy
+	c.settingsMutex.Unlock()
+}
+
+// checkCRC verifies a bundle's CRC values before forwarding based on the CRCPolicy. If false is returned, the
+// bundle was deleted and must not be forwarded.
+func (c *Core) checkCRC(bp BundleDescriptor) bool {
+	c.settingsMutex.RLock()
+	policy := c.crcPolicy
+	c.settingsMutex.RUnlock()
+
+	if policy == CRCPolicyNone {
+		return true
+	}
+
+	err := bp.MustBundle().VerifyCRC()
+	if err == nil {
+		return true
+	}
+
+	logger := log.WithFields(log.Fields{
+		"bundle": bp.ID(),
+		"policy": policy,
+	}).WithError(err)
+
+	if policy == CRCPolicyDelete {
+		logger.Warn("Bundle has mismatching CRC values, deleting it")
+		c.bundleDeletion(bp, bpv7.BlockUnintelligible)
+		return false
+	}
+
+	logger.Warn("Bundle has mismatching CRC values, recalculating them")
+	if err := bp.MustBundle().UpdateCRC(); err != nil {
+		log.WithField("bundle", bp.ID()).WithError(err).Warn("Recalculating CRC values errored")
+	}
+	return true
+}
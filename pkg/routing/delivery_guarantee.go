@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// defaultRetransmissionInterval between retransmissions of unacknowledged bundles, compare SetRetransmissionInterval.
+const defaultRetransmissionInterval = time.Minute
+
+// SetRetransmissionInterval sets the interval between retransmissions of bundles sent with an at-least-once or
+// exactly-once bpv7.DeliveryGuarantee, until their delivery is acknowledged. The interval must be at least one second
+// and defaults to one minute.
+func (c *Core) SetRetransmissionInterval(interval time.Duration) {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	c.cron.Unregister("retransmit_bundles")
+
+	if err := c.cron.Register("retransmit_bundles", c.retransmitBundles, interval); err != nil {
+		log.WithError(err).Warn("Failed to register retransmit_bundles at cron")
+	}
+}
+
+// prepareDeliveryGuarantee requests a delivery status report as the acknowledgement for an outgoing bundle with an
+// at-least-once or exactly-once guarantee. Without a report-to endpoint, the report is sent to this node.
+func (c *Core) prepareDeliveryGuarantee(bndl *bpv7.Bundle) {
+	if guarantee, ok := bndl.DeliveryGuarantee(); !ok || guarantee == bpv7.BestEffort {
+		return
+	}
+
+	bndl.PrimaryBlock.BundleControlFlags |= bpv7.StatusRequestDelivery
+	if bndl.PrimaryBlock.ReportTo == bpv7.DtnNone() {
+		bndl.PrimaryBlock.ReportTo = c.NodeId
+	}
+}
+
+// awaitsAcknowledgement checks if this node is the source of a bundle with an at-least-once or exactly-once
+// guarantee. Those bundles are kept for retransmissions after being forwarded until their delivery is acknowledged.
+func (c *Core) awaitsAcknowledgement(bp BundleDescriptor) bool {
+	guarantee, ok := bp.MustBundle().DeliveryGuarantee()
+	if !ok || guarantee == bpv7.BestEffort {
+		return false
+	}
+
+	src := bp.MustBundle().PrimaryBlock.SourceNode
+	return src != bpv7.DtnNone() && c.HasEndpoint(src)
+}
+
+// isBestEffort checks if a bundle has a best-effort guarantee. Those bundles are not kept for later attempts.
+func isBestEffort(bp BundleDescriptor) bool {
+	guarantee, ok := bp.MustBundle().DeliveryGuarantee()
+	return ok && guarantee == bpv7.BestEffort
+}
+
+// bundleAckPending keeps a forwarded bundle until its delivery is acknowledged by a status report, which deletes it.
+func (c *Core) bundleAckPending(bp BundleDescriptor) {
+	log.WithField("bundle", bp.ID()).Info("Bundle awaits the acknowledgement of its delivery")
+
+	bp.PurgeConstraints()
+	bp.AddConstraint(AckPending)
+	_ = bp.Sync()
+}
+
+// retransmitBundles forwards all bundles again whose delivery was not yet acknowledged. As for other forwarded
+// bundles, routing algorithms might exclude peers which have already received a bundle.
+func (c *Core) retransmitBundles() {
+	bis, err := c.store.QueryAwaitingAck()
+	if err != nil {
+		log.WithError(err).Warn("Failed to fetch bundles awaiting an acknowledgement")
+		return
+	}
+
+	for _, bi := range bis {
+		bp := NewBundleDescriptor(bi.BId, c.store)
+		if _, err := bp.Bundle(); err != nil {
+			continue
+		}
+
+		log.WithField("bundle", bp.ID()).Info("Retransmitting unacknowledged bundle")
+
+		bp.RemoveConstraint(AckPending)
+		c.forward(bp)
+	}
+}
+
+// reacknowledge a retransmitted exactly-once bundle, which was already delivered and is retained. The previous
+// acknowledgement might have been lost.
+func (c *Core) reacknowledge(bp BundleDescriptor) {
+	if guarantee, _ := bp.MustBundle().DeliveryGuarantee(); guarantee != bpv7.ExactlyOnce || !bp.HasConstraint(Retained) {
+		return
+	}
+
+	if statusReportRequested(*bp.MustBundle(), bpv7.StatusRequestDelivery) {
+		log.WithField("bundle", bp.ID()).Info("Acknowledging the delivery of a retransmitted bundle again")
+		c.SendStatusReport(bp, bpv7.DeliveredBundle, bpv7.NoInformation)
+	}
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// deliveryOrderItem is a buffered bundle, waiting for its delivery.
+type deliveryOrderItem struct {
+	id      uint64
+	bp      BundleDescriptor
+	created bpv7.CreationTimestamp
+}
+
+// before checks if this item's bundle was created before the other one's.
+func (item deliveryOrderItem) before(other deliveryOrderItem) bool {
+	if a, b := item.created.DtnTime(), other.created.DtnTime(); a != b {
+		return a < b
+	}
+	return item.created.SequenceNumber() < other.created.SequenceNumber()
+}
+
+// deliveryOrder buffers bundles for local delivery to reorder them by their creation timestamp. Each bundle is held
+// back for the window's duration. Afterwards, it is delivered together with all buffered bundles for the same
+// destination which were created before.
+type deliveryOrder struct {
+	sync.Mutex
+
+	window  time.Duration
+	deliver func(BundleDescriptor)
+
+	queues map[bpv7.EndpointID][]deliveryOrderItem
+	nextId uint64
+	closed bool
+
+	// releaseMutex serializes releases, including their deliveries. Otherwise, bundles released concurrently might
+	// overtake each other.
+	releaseMutex sync.Mutex
+}
+
+// newDeliveryOrder creates a deliveryOrder, passing reordered bundles to the deliver function.
+func newDeliveryOrder(window time.Duration, deliver func(BundleDescriptor)) *deliveryOrder {
+	return &deliveryOrder{
+		window:  window,
+		deliver: deliver,
+		queues:  make(map[bpv7.EndpointID][]deliveryOrderItem),
+	}
+}
+
+// add a bundle to be delivered after the window.
+func (do *deliveryOrder) add(bp BundleDescriptor) {
+	dst := bp.MustBundle().PrimaryBlock.Destination
+
+	do.Lock()
+	if do.closed {
+		do.Unlock()
+		do.deliver(bp)
+		return
+	}
+
+	item := deliveryOrderItem{
+		id:      do.nextId,
+		bp:      bp,
+		created: bp.MustBundle().PrimaryBlock.CreationTimestamp,
+	}
+	do.nextId++
+
+	queue := append(do.queues[dst], item)
+	sort.SliceStable(queue, func(i, j int) bool { return queue[i].before(queue[j]) })
+	do.queues[dst] = queue
+	do.Unlock()
+
+	time.AfterFunc(do.window, func() { do.release(dst, item) })
+}
+
+// release the given item together with all previously created bundles for this destination. If the item was
+// already released together with a later created bundle, nothing happens.
+func (do *deliveryOrder) release(dst bpv7.EndpointID, item deliveryOrderItem) {
+	do.releaseMutex.Lock()
+	defer do.releaseMutex.Unlock()
+
+	do.Lock()
+	if do.closed {
+		do.Unlock()
+		return
+	}
+	queue := do.queues[dst]
+
+	n := -1
+	for i, queued := range queue {
+		if queued.id == item.id {
+			n = i + 1
+			break
+		}
+	}
+	if n < 0 {
+		do.Unlock()
+		return
+	}
+
+	released := queue[:n]
+	if n == len(queue) {
+		delete(do.queues, dst)
+	} else {
+		do.queues[dst] = append([]deliveryOrderItem(nil), queue[n:]...)
+	}
+	do.Unlock()
+
+	for _, releasedItem := range released {
+		do.deliver(releasedItem.bp)
+	}
+}
+
+// close delivers all buffered bundles right away and waits for ongoing deliveries. Bundles added afterwards will be
+// delivered directly.
+func (do *deliveryOrder) close() {
+	do.releaseMutex.Lock()
+	defer do.releaseMutex.Unlock()
+
+	do.Lock()
+	queues := do.queues
+	do.queues = make(map[bpv7.EndpointID][]deliveryOrderItem)
+	do.closed = true
+	do.Unlock()
+
+	for _, queue := range queues {
+		for _, item := range queue {
+			do.deliver(item.bp)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// DestinationRewriter sets a function to rewrite the destination of each bundle before it is dispatched. If the
+// function returns true, its returned EndpointID replaces the bundle's destination. Otherwise, the bundle remains
+// unchanged. This allows gateways to map between naming schemes, e.g., from a dtn name to an ipn address.
+//
+// A nil rewriter disables this feature, which is the default.
+func (c *Core) DestinationRewriter(rewriter func(bpv7.EndpointID) (bpv7.EndpointID, bool)) {
+	c.settingsMutex.Lock()
+	c.destinationRewriter = rewriter
+	c.settingsMutex.Unlock()
+}
+
+// rewriteDestination of a bundle based on the DestinationRewriter, if one is set. The rewritten bundle is persisted
+// in the store, thus it keeps its new destination after a restart.
+func (c *Core) rewriteDestination(bndl *bpv7.Bundle) {
+	c.settingsMutex.RLock()
+	rewriter := c.destinationRewriter
+	c.settingsMutex.RUnlock()
+
+	if rewriter == nil {
+		return
+	}
+
+	dst := bndl.PrimaryBlock.Destination
+	newDst, ok := rewriter(dst)
+	if !ok || newDst == dst {
+		return
+	}
+
+	logger := log.WithFields(log.Fields{
+		"bundle":      bndl.ID(),
+		"destination": dst,
+		"rewritten":   newDst,
+	})
+
+	bndl.PrimaryBlock.Destination = newDst
+	if err := bndl.PrimaryBlock.UpdateCRC(); err != nil {
+		logger.WithError(err).Warn("Failed to update CRC of a bundle with a rewritten destination")
+		bndl.PrimaryBlock.Destination = dst
+		_ = bndl.PrimaryBlock.UpdateCRC()
+		return
+	}
+
+	if c.store.KnowsBundle(bndl.ID()) {
+		if err := c.store.UpdateBundle(*bndl); err != nil {
+			logger.WithError(err).Warn("Failed to store bundle with a rewritten destination")
+		}
+	}
+
+	logger.Info("Rewrote bundle's destination")
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// forwardHistoryKey is the BundleItem's property key for the ForwardEvents.
+const forwardHistoryKey = "core/forwarded"
+
+// ForwardEvent records a successful transmission of a bundle to a peer.
+//
+// This information is dtn7-specific and is only kept locally for debugging multi-hop paths. It is not part of the
+// standardized status reports.
+type ForwardEvent struct {
+	Peer bpv7.EndpointID
+	Time time.Time
+}
+
+// recordForwardEvents appends the given peers as ForwardEvents to a stored bundle's history.
+func (c *Core) recordForwardEvents(bp BundleDescriptor, peers []bpv7.EndpointID) {
+	if len(peers) == 0 {
+		return
+	}
+
+	bi, err := c.store.QueryId(bp.Id)
+	if err != nil {
+		log.WithField("bundle", bp.ID()).WithError(err).Debug("Failed to record forward events for unknown bundle")
+		return
+	}
+
+	history, _ := bi.Properties[forwardHistoryKey].([]ForwardEvent)
+	now := time.Now()
+	for _, peer := range peers {
+		history = append(history, ForwardEvent{Peer: peer, Time: now})
+	}
+	bi.Properties[forwardHistoryKey] = history
+
+	if err := c.store.Update(bi); err != nil {
+		log.WithField("bundle", bp.ID()).WithError(err).Warn("Failed to record forward events")
+	}
+}
+
+// ForwardHistory returns all ForwardEvents recorded for a stored bundle, identified by its BundleID.
+func (c *Core) ForwardHistory(bid bpv7.BundleID) ([]ForwardEvent, error) {
+	bi, err := c.store.QueryId(bid)
+	if err != nil {
+		return nil, err
+	}
+
+	history, _ := bi.Properties[forwardHistoryKey].([]ForwardEvent)
+	return history, nil
+}
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"sync"
+
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// defaultForwardConcurrency is the default number of ConvergenceSenders a bundle is sent to at once.
+const defaultForwardConcurrency = 16
+
+// SetForwardConcurrency limits the number of ConvergenceSenders a single bundle is sent to in parallel while being
+// forwarded. The remaining senders wait for a free worker. A value of zero or less resets the default.
+func (c *Core) SetForwardConcurrency(n int) {
+	if n <= 0 {
+		n = defaultForwardConcurrency
+	}
+
+	c.settingsMutex.Lock()
+	c.forwardConcurrency = n
+	c.settingsMutex.Unlock()
+}
+
+// forwardPool calls f for each ConvergenceSender by a bounded pool of workers, compare SetForwardConcurrency. It
+// returns after all calls have finished.
+func (c *Core) forwardPool(nodes []cla.ConvergenceSender, f func(cla.ConvergenceSender)) {
+	c.settingsMutex.RLock()
+	workers := c.forwardConcurrency
+	c.settingsMutex.RUnlock()
+
+	if workers <= 0 {
+		workers = defaultForwardConcurrency
+	}
+	if workers > len(nodes) {
+		workers = len(nodes)
+	}
+
+	nodeChan := make(chan cla.ConvergenceSender)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for node := range nodeChan {
+				f(node)
+			}
+		}()
+	}
+
+	for _, node := range nodes {
+		nodeChan <- node
+	}
+	close(nodeChan)
+
+	wg.Wait()
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// concurrencyCounter tracks the current and maximum number of concurrent sends of countingConvSenders.
+type concurrencyCounter struct {
+	current int32
+	max     int32
+}
+
+// countingConvSender is a mockConvSender taking some time for each Send, tracked by a concurrencyCounter.
+type countingConvSender struct {
+	*mockConvSender
+	counter *concurrencyCounter
+}
+
+func (cs *countingConvSender) Send(bndl bpv7.Bundle) error {
+	n := atomic.AddInt32(&cs.counter.current, 1)
+	defer atomic.AddInt32(&cs.counter.current, -1)
+
+	for {
+		max := atomic.LoadInt32(&cs.counter.max)
+		if n <= max || atomic.CompareAndSwapInt32(&cs.counter.max, max, n) {
+			break
+		}
+	}
+
+	time.Sleep(time.Millisecond)
+	return cs.mockConvSender.Send(bndl)
+}
+
+// testCountingSenders creates n countingConvSenders for distinct peers, sharing one concurrencyCounter.
+func testCountingSenders(n int) (senders []cla.ConvergenceSender, counter *concurrencyCounter) {
+	counter = new(concurrencyCounter)
+	for i := 0; i < n; i++ {
+		senders = append(senders, &countingConvSender{
+			mockConvSender: newMockConvSender(
+				fmt.Sprintf("mock://peer-%d", i), bpv7.MustNewEndpointID(fmt.Sprintf("dtn://peer-%d/", i))),
+			counter: counter,
+		})
+	}
+	return
+}
+
+func TestCoreForwardConcurrency(t *testing.T) {
+	const (
+		senderNo    = 20
+		concurrency = 4
+	)
+
+	testCore(t, func(c *Core) {
+		senders, counter := testCountingSenders(senderNo)
+
+		c.SetRoutingAlgorithm(&fixedSendersRouting{senders: senders})
+		c.SetForwardConcurrency(concurrency)
+
+		bndl := testCoreBundle(t, "dtn://src/", "dtn://dst/")
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &bndl})
+
+		for _, sender := range senders {
+			if l := len(sender.(*countingConvSender).sent()); l != 1 {
+				t.Fatalf("%v sent %d bundles, expected 1", sender, l)
+			}
+		}
+
+		if max := atomic.LoadInt32(&counter.max); max > concurrency {
+			t.Fatalf("%d concurrent sends, expected at most %d", max, concurrency)
+		}
+	})
+}
+
+func BenchmarkCoreForwardConcurrency(b *testing.B) {
+	const senderNo = 500
+
+	for _, concurrency := range []int{1, defaultForwardConcurrency, senderNo} {
+		b.Run(fmt.Sprintf("%d", concurrency), func(b *testing.B) {
+			testCore(b, func(c *Core) {
+				senders, counter := testCountingSenders(senderNo)
+
+				c.SetRoutingAlgorithm(&fixedSendersRouting{senders: senders, delete: true})
+				c.SetForwardConcurrency(concurrency)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					bndl, err := bpv7.Builder().
+						Source("dtn://core/").
+						Destination("dtn://dst/").
+						CreationTimestampNow().
+						Lifetime("10m").
+						PayloadBlock([]byte("hello world")).
+						Build()
+					if err != nil {
+						b.Fatal(err)
+					}
+
+					c.SendBundle(&bndl)
+				}
+
+				b.StopTimer()
+				b.ReportMetric(float64(atomic.LoadInt32(&counter.max)), "max-concurrent-sends")
+			})
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// senderPeerKey identifies the peer behind a ConvergenceSender. Senders without a known peer, e.g., broadcasting
+// ones, are identified by their address.
+func senderPeerKey(cs cla.ConvergenceSender) string {
+	if peer := cs.GetPeerEndpointID(); !peer.Matches(bpv7.DtnNone()) {
+		return peer.String()
+	}
+	return cs.Address()
+}
+
+// filterForwardSenders removes redundant ConvergenceSenders before forwarding a bundle. Only the first sender for
+// each peer is kept and senders to the bundle's previous node are dropped, not to bounce the bundle right back.
+func filterForwardSenders(bp BundleDescriptor, nodes []cla.ConvergenceSender, prevNode bpv7.EndpointID) (filtered []cla.ConvergenceSender) {
+	known := make(map[string]bool)
+
+	for _, node := range nodes {
+		peer := node.GetPeerEndpointID()
+		key := senderPeerKey(node)
+
+		switch {
+		case !prevNode.Matches(bpv7.DtnNone()) && !peer.Matches(bpv7.DtnNone()) && peer.SameNode(prevNode):
+			log.WithFields(log.Fields{
+				"bundle": bp.ID(),
+				"cla":    node,
+				"peer":   peer,
+			}).Debug("Skipping sender to the bundle's previous node")
+
+		case known[key]:
+			log.WithFields(log.Fields{
+				"bundle": bp.ID(),
+				"cla":    node,
+				"peer":   key,
+			}).Debug("Skipping duplicate sender for the same peer")
+
+		default:
+			known[key] = true
+			filtered = append(filtered, node)
+		}
+	}
+
+	return
+}
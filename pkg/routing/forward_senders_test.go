@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// fixedSendersRouting is an Algorithm which always returns the same ConvergenceSenders and delete flag.
+type fixedSendersRouting struct {
+	senders []cla.ConvergenceSender
+	delete  bool
+}
+
+func (_ *fixedSendersRouting) NotifyNewBundle(_ BundleDescriptor) {}
+
+func (_ *fixedSendersRouting) DispatchingAllowed(_ BundleDescriptor) bool { return true }
+
+func (r *fixedSendersRouting) SenderForBundle(_ BundleDescriptor) ([]cla.ConvergenceSender, bool) {
+	return r.senders, r.delete
+}
+
+func (_ *fixedSendersRouting) ReportFailure(_ BundleDescriptor, _ cla.ConvergenceSender) {}
+
+func (_ *fixedSendersRouting) ReportPeerAppeared(_ cla.Convergence) {}
+
+func (_ *fixedSendersRouting) ReportPeerDisappeared(_ cla.Convergence) {}
+
+func TestCoreForwardFilterSenders(t *testing.T) {
+	testCore(t, func(c *Core) {
+		peer1 := newMockConvSender("mock://peer-1", bpv7.MustNewEndpointID("dtn://peer/"))
+		peer2 := newMockConvSender("mock://peer-2", bpv7.MustNewEndpointID("dtn://peer/"))
+		prev := newMockConvSender("mock://prev", bpv7.MustNewEndpointID("dtn://prev/"))
+		bcast1 := newMockConvSender("mock://bcast-1", bpv7.DtnNone())
+		bcast2 := newMockConvSender("mock://bcast-2", bpv7.DtnNone())
+
+		c.SetRoutingAlgorithm(&fixedSendersRouting{
+			senders: []cla.ConvergenceSender{peer1, peer2, prev, bcast1, bcast2, bcast1},
+		})
+
+		b, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("10m").
+			PreviousNodeBlock("dtn://prev/").
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+
+		tests := []struct {
+			cs       *mockConvSender
+			expected int
+		}{
+			{peer1, 1},
+			{peer2, 0},
+			{prev, 0},
+			{bcast1, 1},
+			{bcast2, 1},
+		}
+		for _, test := range tests {
+			if l := len(test.cs.sent()); l != test.expected {
+				t.Fatalf("%v sent %d bundles, expected %d", test.cs, l, test.expected)
+			}
+		}
+	})
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// SetForwardingTimeout limits how long this node attempts to forward a bundle, starting when the bundle entered the
+// store. Afterwards, the bundle is deleted even if its lifetime has not yet expired. This bounds the store residence
+// of bundles without a forwarding opportunity. A zero timeout disables this feature, which is the default.
+func (c *Core) SetForwardingTimeout(timeout time.Duration) {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	c.cron.Unregister("forwarding_timeout")
+
+	c.forwardingTimeout = timeout
+	if timeout <= 0 {
+		return
+	}
+
+	interval := time.Minute
+	if timeout < interval {
+		interval = timeout
+	}
+
+	if err := c.cron.Register("forwarding_timeout", c.sweepForwardingTimeout, interval); err != nil {
+		log.WithError(err).Warn("Failed to register forwarding_timeout at cron")
+	}
+}
+
+// getForwardingTimeout returns the forwarding timeout set by SetForwardingTimeout.
+func (c *Core) getForwardingTimeout() time.Duration {
+	c.settingsMutex.RLock()
+	defer c.settingsMutex.RUnlock()
+
+	return c.forwardingTimeout
+}
+
+// isForwardingTimedOut checks if the forwarding timeout has passed since the bundle entered the store.
+func (c *Core) isForwardingTimedOut(bp BundleDescriptor) bool {
+	timeout := c.getForwardingTimeout()
+	return timeout > 0 && time.Since(bp.Timestamp) > timeout
+}
+
+// sweepForwardingTimeout deletes all pending bundles whose forwarding timeout has passed.
+func (c *Core) sweepForwardingTimeout() {
+	bis, err := c.store.QueryPending()
+	if err != nil {
+		log.WithError(err).Warn("Failed to fetch pending bundles")
+		return
+	}
+
+	for _, bi := range bis {
+		bp := NewBundleDescriptor(bi.BId, c.store)
+		if !c.isForwardingTimedOut(bp) {
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"bundle":  bp.ID(),
+			"timeout": c.getForwardingTimeout(),
+		}).Info("Bundle's forwarding timeout has passed")
+
+		c.bundleDeletion(bp, bpv7.NoNextNodeContact)
+	}
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// SetFragmentMinPayload sets the minimum amount of payload bytes each fragment, except the last one, must carry when
+// a bundle is fragmented to fit a CLA's MTU. If a CLA's MTU is too small for this, the bundle is not sent to this CLA,
+// but contraindicated for a later attempt. A value below one, the default, allows fragments of any size.
+func (c *Core) SetFragmentMinPayload(minPayload int) {
+	atomic.StoreInt32(&c.fragmentMinPayload, int32(minPayload))
+}
+
+// fragmentForSender splits a bundle into fragments fitting the MTU of a ConvergenceSender, compare cla.MTUReporter.
+// Each fragment but the last must carry at least minPayload bytes of payload, compare bpv7.Bundle.FragmentMinPayload.
+// If the sender does not report an MTU or the bundle fits, the bundle is returned unchanged.
+func fragmentForSender(node cla.ConvergenceSender, b bpv7.Bundle, minPayload int) ([]bpv7.Bundle, error) {
+	reporter, ok := node.(cla.MTUReporter)
+	if !ok || reporter.MTU() <= 0 {
+		return []bpv7.Bundle{b}, nil
+	}
+
+	var size byteCounter
+	if err := b.WriteBundle(&size); err != nil {
+		return nil, err
+	} else if int(size) <= reporter.MTU() {
+		return []bpv7.Bundle{b}, nil
+	}
+
+	if b.PrimaryBlock.HasFragmentation() {
+		return nil, fmt.Errorf("bundle of %d bytes exceeds MTU of %d bytes, but is already a fragment", size, reporter.MTU())
+	}
+
+	frags, err := b.FragmentMinPayload(reporter.MTU(), minPayload)
+	if err != nil {
+		return nil, fmt.Errorf("bundle of %d bytes exceeds MTU of %d bytes: %w", size, reporter.MTU(), err)
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":    b.ID(),
+		"cla":       node,
+		"size":      size,
+		"mtu":       reporter.MTU(),
+		"fragments": len(frags),
+	}).Info("Fragmented bundle exceeding the CLA's MTU")
+
+	return frags, nil
+}
+
+// sendFragmented sends a bundle to a ConvergenceSender, fragmenting it if necessary, compare fragmentForSender. An
+// error of a single fragment or the context being done aborts the transmission.
+func sendFragmented(ctx context.Context, node cla.ConvergenceSender, b bpv7.Bundle, minPayload int) error {
+	frags, err := fragmentForSender(node, b, minPayload)
+	if err != nil {
+		return err
+	}
+
+	for _, frag := range frags {
+		if err := cla.SendContext(ctx, node, frag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/internal/enum"
+)
+
+// FuturePolicy describes how received bundles with a creation timestamp in the future are handled. Such bundles
+// might be the result of a clock skew or might be malicious, trying to extend their lifetime.
+type FuturePolicy int
+
+const (
+	// FuturePolicyAccept accepts future-dated bundles unchanged, which is the default.
+	FuturePolicyAccept FuturePolicy = iota
+
+	// FuturePolicyClamp sets the creation timestamp of future-dated bundles to the current time. Be aware that this
+	// changes the bundle's ID.
+	FuturePolicyClamp
+
+	// FuturePolicyReject refuses future-dated bundles, sending a deletion status report if requested.
+	FuturePolicyReject
+)
+
+var futurePolicyNames = enum.NewNames("future policy", "accept", "clamp", "reject").Alias(0, "")
+
+func (policy FuturePolicy) String() string {
+	return futurePolicyNames.String(uint64(policy))
+}
+
+// ParseFuturePolicy from its name, as returned by String. An empty name results in FuturePolicyAccept.
+func ParseFuturePolicy(name string) (FuturePolicy, error) {
+	policy, err := futurePolicyNames.Parse(name)
+	return FuturePolicy(policy), err
+}
+
+// SetFuturePolicy sets the FuturePolicy for received bundles. Bundles created up to the tolerance in the future,
+// e.g., due to slightly unsynchronized clocks, are accepted regardless of the policy.
+func (c *Core) SetFuturePolicy(policy FuturePolicy, tolerance time.Duration) {
+	c.settingsMutex.Lock()
+	c.futurePolicy = policy
+	c.futureTolerance = tolerance
+	c.settingsMutex.Unlock()
+}
+
+// checkFuture handles a received bundle's creation timestamp based on the FuturePolicy. If false is returned, the
+// bundle was refused and must not be processed.
+func (c *Core) checkFuture(crb cla.ConvergenceReceivedBundle) bool {
+	c.settingsMutex.RLock()
+	policy, tolerance := c.futurePolicy, c.futureTolerance
+	c.settingsMutex.RUnlock()
+
+	if policy == FuturePolicyAccept {
+		return true
+	}
+
+	ts := crb.Bundle.PrimaryBlock.CreationTimestamp
+	if ts.IsZeroTime() || !ts.DtnTime().Time().After(time.Now().Add(tolerance)) {
+		return true
+	}
+
+	logger := log.WithFields(log.Fields{
+		"bundle": crb.Bundle.ID(),
+		"cla":    crb.Endpoint,
+		"policy": policy,
+	})
+
+	if policy == FuturePolicyReject {
+		logger.Info("Received bundle was created in the future, refusing it")
+		c.refuseConvergence(crb, bpv7.NoInformation)
+		return false
+	}
+
+	logger.Info("Received bundle was created in the future, clamping its creation timestamp")
+	crb.Bundle.PrimaryBlock.CreationTimestamp = bpv7.NewCreationTimestamp(bpv7.DtnTimeNow(), ts.SequenceNumber())
+	return true
+}
+
+// isLifetimeExceeded checks the bundle's lifetime like bpv7.Bundle.IsLifetimeExceeded. However, a future-dated
+// bundle is treated as if it was created when this node got it and never has a negative age.
+func (c *Core) isLifetimeExceeded(bp BundleDescriptor) bool {
+	bndl := bp.MustBundle()
+	ts := bndl.PrimaryBlock.CreationTimestamp
+	if ts.IsZeroTime() {
+		return bndl.IsLifetimeExceeded()
+	}
+
+	created := ts.DtnTime().Time()
+	if created.After(bp.Timestamp) {
+		created = bp.Timestamp
+	}
+
+	return time.Now().After(created.Add(time.Duration(bndl.PrimaryBlock.Lifetime) * time.Millisecond))
+}
+
+// lifetimeEnd returns the point in time when a bundle's lifetime expires, like isLifetimeExceeded. For a bundle
+// without a creation time, its Bundle Age Block is used. If neither is available, false is returned.
+func (c *Core) lifetimeEnd(bp BundleDescriptor) (time.Time, bool) {
+	bndl := bp.MustBundle()
+	lifetime := time.Duration(bndl.PrimaryBlock.Lifetime) * time.Millisecond
+
+	ts := bndl.PrimaryBlock.CreationTimestamp
+	if ts.IsZeroTime() {
+		if bab, err := bndl.ExtensionBlock(bpv7.ExtBlockTypeBundleAgeBlock); err != nil {
+			return time.Time{}, false
+		} else {
+			age := time.Duration(bab.Value.(*bpv7.BundleAgeBlock).Age()) * time.Millisecond
+			return time.Now().Add(lifetime - age), true
+		}
+	}
+
+	created := ts.DtnTime().Time()
+	if created.After(bp.Timestamp) {
+		created = bp.Timestamp
+	}
+
+	return created.Add(lifetime), true
+}
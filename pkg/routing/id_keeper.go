@@ -5,6 +5,7 @@
 package routing
 
 import (
+	"math"
 	"sync"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
@@ -26,8 +27,10 @@ func newIdTuple(bndl *bpv7.Bundle) idTuple {
 }
 
 // IdKeeper keeps track of the creation timestamp's sequence number for
-// outbounding bundles.
+// outbounding bundles. It is safe for concurrent use, e.g., by multiple
+// goroutines sending bundles at once.
 type IdKeeper struct {
+	// data maps each idTuple to its last assigned sequence number.
 	data      map[idTuple]uint64
 	mutex     sync.Mutex
 	autoClean bool
@@ -42,18 +45,29 @@ func NewIdKeeper() IdKeeper {
 }
 
 // update updates the IdKeeper's state regarding this bundle and sets this
-// bundle's sequence number.
+// bundle's sequence number. For the same source and DTN time, the sequence
+// numbers are unique and increasing.
+//
+// If all sequence numbers of a DTN time are exhausted, the counter would roll
+// over and collide with earlier bundles. Thus, the bundle's creation time is
+// advanced by one until an unexhausted DTN time is found.
 func (idk *IdKeeper) update(bndl *bpv7.Bundle) {
 	var tpl = newIdTuple(bndl)
 
 	idk.mutex.Lock()
-	if state, ok := idk.data[tpl]; ok {
-		idk.data[tpl] = state + 1
-	} else {
-		idk.data[tpl] = 0
+	for {
+		if state, ok := idk.data[tpl]; !ok {
+			idk.data[tpl] = 0
+			break
+		} else if state < math.MaxUint64 {
+			idk.data[tpl] = state + 1
+			break
+		}
+
+		tpl.time++
 	}
 
-	bndl.PrimaryBlock.CreationTimestamp[1] = idk.data[tpl]
+	bndl.PrimaryBlock.CreationTimestamp = bpv7.NewCreationTimestamp(tpl.time, idk.data[tpl])
 	idk.mutex.Unlock()
 
 	if idk.autoClean {
@@ -61,7 +75,8 @@ func (idk *IdKeeper) update(bndl *bpv7.Bundle) {
 	}
 }
 
-// clean removes states which are older an hour and aren't the epoch time.
+// clean removes states which are older than 86.4 seconds, as the DTN time is
+// measured in milliseconds, and aren't the epoch time.
 func (idk *IdKeeper) clean() {
 	idk.mutex.Lock()
 
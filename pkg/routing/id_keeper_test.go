@@ -5,6 +5,9 @@
 package routing
 
 import (
+	"fmt"
+	"math"
+	"sync"
 	"testing"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
@@ -50,3 +53,140 @@ func TestIdKeeper(t *testing.T) {
 		t.Errorf("Second bundle's sequence number is %d", seq)
 	}
 }
+
+func TestIdKeeperConcurrent(t *testing.T) {
+	const (
+		routines = 4
+		bundles  = 1000
+	)
+
+	var keeper = NewIdKeeper()
+
+	var ids sync.Map
+	var wg sync.WaitGroup
+	wg.Add(routines)
+
+	for r := 0; r < routines; r++ {
+		go func() {
+			defer wg.Done()
+
+			var lastSeq uint64
+			for i := 0; i < bundles; i++ {
+				bndl, err := bpv7.Builder().
+					Source("dtn://src/").
+					Destination("dtn://dest/").
+					CreationTimestampEpoch().
+					Lifetime("60s").
+					PayloadBlock([]byte("hello world!")).
+					Build()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				keeper.update(&bndl)
+
+				seq := bndl.PrimaryBlock.CreationTimestamp.SequenceNumber()
+				if i > 0 && seq <= lastSeq {
+					t.Errorf("sequence number %d is not greater than %d", seq, lastSeq)
+					return
+				}
+				lastSeq = seq
+
+				if _, known := ids.LoadOrStore(bndl.ID().String(), struct{}{}); known {
+					t.Errorf("bundle ID %v was assigned twice", bndl.ID())
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	var count int
+	ids.Range(func(_, _ interface{}) bool {
+		count++
+		return true
+	})
+	if count != routines*bundles {
+		t.Fatalf("%d unique bundle IDs, expected %d", count, routines*bundles)
+	}
+}
+
+func TestIdKeeperOverflow(t *testing.T) {
+	var keeper = NewIdKeeper()
+	keeper.autoClean = false
+
+	var ts = bpv7.DtnTimeNow()
+
+	newBundle := func() bpv7.Bundle {
+		bndl, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dest/").
+			CreationTimestampTime(ts.Time()).
+			Lifetime("60s").
+			PayloadBlock([]byte("hello world!")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bndl
+	}
+
+	// The next DTN time is also almost exhausted, the one after is unused.
+	keeper.data[idTuple{bpv7.MustNewEndpointID("dtn://src/"), ts}] = math.MaxUint64 - 1
+	keeper.data[idTuple{bpv7.MustNewEndpointID("dtn://src/"), ts + 1}] = math.MaxUint64
+
+	tests := []bpv7.CreationTimestamp{
+		bpv7.NewCreationTimestamp(ts, math.MaxUint64),
+		bpv7.NewCreationTimestamp(ts+2, 0),
+		bpv7.NewCreationTimestamp(ts+2, 1),
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			bndl := newBundle()
+			keeper.update(&bndl)
+
+			if ct := bndl.PrimaryBlock.CreationTimestamp; ct != test {
+				t.Fatalf("creation timestamp is %v, expected %v", ct, test)
+			}
+		})
+	}
+}
+
+func TestCoreSendBundleIds(t *testing.T) {
+	const bundles = 100
+
+	testCore(t, func(c *Core) {
+		ids := make(map[bpv7.BundleID]struct{})
+
+		for i := 0; i < bundles; i++ {
+			bndl, err := bpv7.Builder().
+				Source("dtn://core/").
+				Destination("dtn://far/").
+				CreationTimestampEpoch().
+				Lifetime("10m").
+				PayloadBlock([]byte("hello world!")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The sequence number is set before the bundle is stored.
+			c.SendBundle(&bndl)
+			if !c.store.KnowsBundle(bndl.ID()) {
+				t.Fatalf("bundle %v is not stored", bndl.ID())
+			}
+			ids[bndl.ID()] = struct{}{}
+		}
+
+		if len(ids) != bundles {
+			t.Fatalf("%d unique bundle IDs, expected %d", len(ids), bundles)
+		} else if bis, err := c.store.QueryAll(); err != nil {
+			t.Fatal(err)
+		} else if len(bis) != bundles {
+			t.Fatalf("store contains %d bundles, expected %d", len(bis), bundles)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// defaultJanitorInterval between two passes of the janitor, compare SetJanitorInterval.
+const defaultJanitorInterval = 10 * time.Minute
+
+// SetJanitorInterval sets the interval between two passes of the janitor. Each pass deletes stored bundles whose
+// lifetime has expired and re-attempts forwarding contraindicated bundles. The interval must be at least one second and
+// defaults to ten minutes.
+func (c *Core) SetJanitorInterval(interval time.Duration) {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	c.cron.Unregister("janitor")
+
+	if err := c.cron.Register("janitor", c.janitor, interval); err != nil {
+		log.WithError(err).Warn("Failed to register janitor at cron")
+	}
+}
+
+// queryByConstraint fetches all stored bundles having the given Constraint by the store's index.
+func (c *Core) queryByConstraint(constraint Constraint) (bps []BundleDescriptor, err error) {
+	bis, err := c.store.QueryByConstraint(int(constraint))
+	if err != nil {
+		return
+	}
+
+	for _, bi := range bis {
+		bps = append(bps, NewBundleDescriptor(bi.BId, c.store))
+	}
+	return
+}
+
+// janitor deletes expired bundles and re-dispatches contraindicated ones.
+//
+// Pending bundles exceeding their lifetime, compare isLifetimeExceeded, are deleted with the requested deletion status
+// reports. As bundles past their creation timestamp's lifetime cannot be loaded anymore, those are silently removed
+// by the store afterwards.
+func (c *Core) janitor() {
+	bis, err := c.store.QueryPending()
+	if err != nil {
+		log.WithError(err).Warn("Janitor failed to fetch pending bundles")
+		return
+	}
+
+	for _, bi := range bis {
+		bp := NewBundleDescriptor(bi.BId, c.store)
+		if _, err := bp.Bundle(); err != nil || !c.isLifetimeExceeded(bp) {
+			continue
+		}
+
+		log.WithField("bundle", bp.ID()).Info("Janitor deletes bundle, its lifetime is exceeded")
+		c.bundleDeletion(bp, bpv7.LifetimeExpired)
+	}
+
+	c.store.DeleteExpired()
+
+	bps, err := c.queryByConstraint(Contraindicated)
+	if err != nil {
+		log.WithError(err).Warn("Janitor failed to fetch contraindicated bundles")
+		return
+	}
+
+	for _, bp := range bps {
+		log.WithField("bundle", bp.ID()).Debug("Janitor re-dispatches contraindicated bundle")
+		c.dispatching(bp)
+	}
+}
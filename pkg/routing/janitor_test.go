@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestCoreJanitorExpired(t *testing.T) {
+	testCore(t, func(c *Core) {
+		expiredBndl, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("500ms").
+			PayloadBlock([]byte("hello past")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expired := NewBundleDescriptorFromBundle(expiredBndl, c.store)
+		expired.AddConstraint(ForwardPending)
+		if err := expired.Sync(); err != nil {
+			t.Fatal(err)
+		}
+
+		retained := NewBundleDescriptorFromBundle(testCoreBundle(t, "dtn://src/", "dtn://core/"), c.store)
+		retained.AddConstraint(Retained)
+		if err := retained.Sync(); err != nil {
+			t.Fatal(err)
+		}
+
+		fresh := NewBundleDescriptorFromBundle(testCoreBundle(t, "dtn://src/", "dtn://dst/"), c.store)
+		fresh.AddConstraint(ForwardPending)
+		if err := fresh.Sync(); err != nil {
+			t.Fatal(err)
+		}
+
+		time.Sleep(600 * time.Millisecond)
+		c.janitor()
+
+		if c.store.KnowsBundle(expired.Id) {
+			t.Fatal("expired bundle is still stored")
+		}
+		if !c.store.KnowsBundle(retained.Id) {
+			t.Fatal("retained bundle was deleted")
+		}
+		if !c.store.KnowsBundle(fresh.Id) {
+			t.Fatal("fresh bundle was deleted")
+		}
+	})
+}
+
+func TestCoreJanitorContraindicated(t *testing.T) {
+	testCore(t, func(c *Core) {
+		peerEid := bpv7.MustNewEndpointID("dtn://peer/")
+		peer := newMockConvSender("mock://peer", peerEid)
+		c.RegisterConvergable(peer)
+		time.Sleep(100 * time.Millisecond)
+
+		bp := NewBundleDescriptorFromBundle(testCoreBundle(t, "dtn://src/", "dtn://peer/"), c.store)
+		bp.AddConstraint(Contraindicated)
+		if err := bp.Sync(); err != nil {
+			t.Fatal(err)
+		}
+
+		if bps, err := c.queryByConstraint(Contraindicated); err != nil {
+			t.Fatal(err)
+		} else if len(bps) != 1 || bps[0].Id != bp.Id {
+			t.Fatalf("contraindicated bundles are %v", bps)
+		}
+
+		c.janitor()
+
+		if sent := peer.sent(); len(sent) != 1 || sent[0].ID() != bp.Id {
+			t.Fatalf("peer received %v", sent)
+		}
+		if bps, err := c.queryByConstraint(Contraindicated); err != nil {
+			t.Fatal(err)
+		} else if len(bps) != 0 {
+			t.Fatalf("bundles are still contraindicated: %v", bps)
+		}
+	})
+}
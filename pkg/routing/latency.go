@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// latencyBuckets are the upper bounds of the LatencyHistogram's buckets. Latencies above the last bound are counted
+// in an additional overflow bucket.
+var latencyBuckets = []time.Duration{
+	time.Second, 10 * time.Second, time.Minute, 10 * time.Minute, time.Hour, 24 * time.Hour,
+}
+
+// LatencyHistogram counts end-to-end latencies of delivered bundles.
+type LatencyHistogram struct {
+	// Bounds are the buckets' upper bounds. Counts has one more entry for latencies exceeding the last bound.
+	Bounds []time.Duration
+	Counts []uint64
+
+	// Count is the total amount of observed latencies and Sum their sum.
+	Count uint64
+	Sum   time.Duration
+}
+
+// latencyRecorder is a concurrency safe wrapper around a LatencyHistogram.
+type latencyRecorder struct {
+	sync.Mutex
+	histogram LatencyHistogram
+}
+
+func newLatencyRecorder() *latencyRecorder {
+	return &latencyRecorder{
+		histogram: LatencyHistogram{
+			Bounds: latencyBuckets,
+			Counts: make([]uint64, len(latencyBuckets)+1),
+		},
+	}
+}
+
+// observe a latency, adding it to the histogram.
+func (lr *latencyRecorder) observe(latency time.Duration) {
+	if latency < 0 {
+		latency = 0
+	}
+
+	lr.Lock()
+	defer lr.Unlock()
+
+	i := 0
+	for ; i < len(lr.histogram.Bounds) && latency > lr.histogram.Bounds[i]; i++ {
+	}
+
+	lr.histogram.Counts[i]++
+	lr.histogram.Count++
+	lr.histogram.Sum += latency
+}
+
+// snapshot returns a copy of the current histogram.
+func (lr *latencyRecorder) snapshot() LatencyHistogram {
+	lr.Lock()
+	defer lr.Unlock()
+
+	h := lr.histogram
+	h.Counts = append([]uint64(nil), lr.histogram.Counts...)
+	return h
+}
+
+// deliveryLatency calculates the elapsed time from a bundle's creation until now. For bundles without a creation
+// time, the Bundle Age block is used instead, as specified in section 4.3.2. A bundle created in the future, e.g., by
+// a node with a skewed clock, results in a zero latency.
+func deliveryLatency(bp BundleDescriptor) (time.Duration, error) {
+	bndl, err := bp.Bundle()
+	if err != nil {
+		return 0, err
+	}
+
+	if ts := bndl.PrimaryBlock.CreationTimestamp; !ts.IsZeroTime() {
+		latency := time.Since(ts.DtnTime().Time())
+		if latency < 0 {
+			log.WithFields(log.Fields{
+				"bundle":  bp.ID(),
+				"latency": latency,
+			}).Debug("Bundle was created in the future, clamping its delivery latency to zero")
+			latency = 0
+		}
+		return latency, nil
+	}
+
+	ageBlock, err := bndl.ExtensionBlock(bpv7.ExtBlockTypeBundleAgeBlock)
+	if err != nil {
+		return 0, fmt.Errorf("bundle has neither a creation time nor a bundle age block")
+	}
+
+	age := time.Duration(ageBlock.Value.(*bpv7.BundleAgeBlock).Age()) * time.Millisecond
+	return age + time.Since(bp.Timestamp), nil
+}
+
+// DeliveryLatency returns a histogram of the end-to-end latencies of all locally delivered bundles.
+func (c *Core) DeliveryLatency() LatencyHistogram {
+	return c.latency.snapshot()
+}
+
+// DeliveryCallback sets a function, called with each locally delivered bundle's end-to-end latency. A nil callback
+// disables this feature, which is the default.
+func (c *Core) DeliveryCallback(callback func(bid bpv7.BundleID, latency time.Duration)) {
+	c.settingsMutex.Lock()
+	c.deliveryCallback = callback
+	c.settingsMutex.Unlock()
+}
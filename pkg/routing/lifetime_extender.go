@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// LifetimeExtender sets a policy function to extend the lifetime of each bundle before it is forwarded. If the
+// function returns true and a longer lifetime than the current one, the bundle's lifetime is replaced. Shortening a
+// lifetime is not possible.
+//
+// Changing a bundle's lifetime in transit is not intended by the Bundle Protocol. Thus, this should only be used by
+// trusted relays within controlled networks, e.g., to let bundles survive a long gap between contacts.
+//
+// A nil extender disables this feature, which is the default.
+func (c *Core) LifetimeExtender(extender func(bpv7.Bundle) (time.Duration, bool)) {
+	c.settingsMutex.Lock()
+	c.lifetimeExtender = extender
+	c.settingsMutex.Unlock()
+}
+
+// extendLifetime of a bundle to be forwarded based on the LifetimeExtender, if one is set.
+func (c *Core) extendLifetime(bp BundleDescriptor) {
+	c.settingsMutex.RLock()
+	extender := c.lifetimeExtender
+	c.settingsMutex.RUnlock()
+
+	if extender == nil {
+		return
+	}
+
+	bndl := bp.MustBundle()
+	lifetime, ok := extender(*bndl)
+	if !ok || lifetime < 0 {
+		return
+	}
+
+	oldMs, newMs := bndl.PrimaryBlock.Lifetime, uint64(lifetime.Milliseconds())
+	if newMs <= oldMs {
+		return
+	}
+
+	logger := log.WithFields(log.Fields{
+		"bundle":       bp.ID(),
+		"lifetime":     time.Duration(oldMs) * time.Millisecond,
+		"new_lifetime": lifetime,
+	})
+
+	bndl.PrimaryBlock.Lifetime = newMs
+	if err := bndl.PrimaryBlock.UpdateCRC(); err != nil {
+		logger.WithError(err).Warn("Failed to update CRC of a bundle with an extended lifetime")
+		bndl.PrimaryBlock.Lifetime = oldMs
+		_ = bndl.PrimaryBlock.UpdateCRC()
+		return
+	}
+
+	// The store would otherwise drop this bundle after its original lifetime.
+	if bi, err := c.store.QueryId(bp.Id); err == nil {
+		bi.Expires = bndl.PrimaryBlock.CreationTimestamp.DtnTime().Time().Add(time.Duration(newMs) * time.Millisecond)
+		if err := c.store.Update(bi); err != nil {
+			logger.WithError(err).Warn("Failed to update the expiration date of a bundle with an extended lifetime")
+		}
+	}
+
+	logger.Info("Extended bundle's lifetime")
+}
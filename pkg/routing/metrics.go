@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// metricsNamespace prefixes all of the Core's Prometheus metrics.
+const metricsNamespace = "dtn7"
+
+// forwardLatencyBuckets are the upper bounds, in seconds, of the forward latency histogram's buckets.
+var forwardLatencyBuckets = []float64{0.001, 0.01, 0.1, 0.5, 1, 5, 10, 30, 60, 300}
+
+// coreMetrics are the Prometheus metrics of the Core's bundle processing. A nil coreMetrics is valid and discards
+// all observations, which is the default.
+type coreMetrics struct {
+	received        prometheus.Counter
+	forwarded       prometheus.Counter
+	delivered       prometheus.Counter
+	deleted         *prometheus.CounterVec
+	contraindicated prometheus.Counter
+	forwardLatency  prometheus.Histogram
+	deliveryLatency prometheus.Histogram
+}
+
+func newCoreMetrics() *coreMetrics {
+	return &coreMetrics{
+		received: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "bundles_received_total",
+			Help:      "Number of bundles received, either from a CLA or a local application agent.",
+		}),
+		forwarded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "bundles_forwarded_total",
+			Help:      "Number of bundles successfully sent to at least one CLA.",
+		}),
+		delivered: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "bundles_delivered_total",
+			Help:      "Number of bundles delivered to a local application agent.",
+		}),
+		deleted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "bundles_deleted_total",
+			Help:      "Number of deleted bundles, labeled by the status report reason.",
+		}, []string{"reason"}),
+		contraindicated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "bundles_contraindicated_total",
+			Help:      "Number of bundles marked for contraindication.",
+		}),
+		forwardLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "forward_duration_seconds",
+			Help:      "Duration of a bundle's forwarding attempt to all selected CLAs.",
+			Buckets:   forwardLatencyBuckets,
+		}),
+		deliveryLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "delivery_latency_seconds",
+			Help:      "End-to-end latency of locally delivered bundles, from their creation until their delivery.",
+			Buckets:   deliveryLatencyBuckets(),
+		}),
+	}
+}
+
+// deliveryLatencyBuckets are the LatencyHistogram's bounds in seconds, compare Core.DeliveryLatency.
+func deliveryLatencyBuckets() []float64 {
+	buckets := make([]float64, len(latencyBuckets))
+	for i, bound := range latencyBuckets {
+		buckets[i] = bound.Seconds()
+	}
+	return buckets
+}
+
+// register all metrics at the Registerer. On failure, already registered metrics are unregistered again.
+func (cm *coreMetrics) register(reg prometheus.Registerer) error {
+	collectors := []prometheus.Collector{
+		cm.received, cm.forwarded, cm.delivered, cm.deleted, cm.contraindicated, cm.forwardLatency, cm.deliveryLatency,
+	}
+
+	for i, collector := range collectors {
+		if err := reg.Register(collector); err != nil {
+			for _, registered := range collectors[:i] {
+				reg.Unregister(registered)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (cm *coreMetrics) bundleReceived() {
+	if cm != nil {
+		cm.received.Inc()
+	}
+}
+
+func (cm *coreMetrics) bundleForwarded() {
+	if cm != nil {
+		cm.forwarded.Inc()
+	}
+}
+
+func (cm *coreMetrics) bundleDelivered() {
+	if cm != nil {
+		cm.delivered.Inc()
+	}
+}
+
+func (cm *coreMetrics) bundleDeleted(reason bpv7.StatusReportReason) {
+	if cm != nil {
+		cm.deleted.WithLabelValues(reason.String()).Inc()
+	}
+}
+
+func (cm *coreMetrics) bundleContraindicated() {
+	if cm != nil {
+		cm.contraindicated.Inc()
+	}
+}
+
+func (cm *coreMetrics) forwardDuration(d time.Duration) {
+	if cm != nil {
+		cm.forwardLatency.Observe(d.Seconds())
+	}
+}
+
+func (cm *coreMetrics) bundleDeliveryLatency(latency time.Duration) {
+	if cm != nil {
+		cm.deliveryLatency.Observe(latency.Seconds())
+	}
+}
+
+// SetMetrics registers the Core's Prometheus metrics for bundle processing at the given Registerer. Afterwards, they
+// can be exposed, e.g., by promhttp.HandlerFor. Metrics are disabled by default.
+func (c *Core) SetMetrics(reg prometheus.Registerer) error {
+	cm := newCoreMetrics()
+	if err := cm.register(reg); err != nil {
+		return err
+	}
+
+	c.settingsMutex.Lock()
+	c.metrics = cm
+	c.settingsMutex.Unlock()
+	return nil
+}
+
+// getMetrics returns the coreMetrics set by SetMetrics or nil, which is a valid receiver for all its methods.
+func (c *Core) getMetrics() *coreMetrics {
+	c.settingsMutex.RLock()
+	defer c.settingsMutex.RUnlock()
+
+	return c.metrics
+}
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+func TestCoreMetrics(t *testing.T) {
+	testCore(t, func(c *Core) {
+		reg := prometheus.NewRegistry()
+		if err := c.SetMetrics(reg); err != nil {
+			t.Fatal(err)
+		} else if err := c.SetMetrics(reg); err == nil {
+			t.Fatal("registering the metrics twice did not error")
+		}
+
+		app := bpv7.MustNewEndpointID("dtn://core/app")
+		delivered := make(chan struct{}, 1)
+		unsubscribe, err := c.Subscribe(app, func(_ bpv7.Bundle) { delivered <- struct{}{} })
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unsubscribe()
+
+		// Delivered bundles are not forwarded by the default routing algorithm, as there is no other CLA yet.
+		deliverBndl := testCoreBundle(t, "dtn://src-app/", app.String())
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &deliverBndl})
+
+		select {
+		case <-delivered:
+		case <-time.After(time.Second):
+			t.Fatal("bundle was not delivered")
+		}
+
+		peer := newMockConvSender("mock://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+		c.SetRoutingAlgorithm(&fixedSendersRouting{senders: []cla.ConvergenceSender{peer}})
+
+		forwardBndl := testCoreBundle(t, "dtn://src/", "dtn://dst/")
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &forwardBndl})
+
+		// This bundle's hop count will be exceeded on forwarding.
+		exceededBndl, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("10m").
+			HopCountBlock(1).
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		} else if hcBlock, err := exceededBndl.ExtensionBlock(bpv7.ExtBlockTypeHopCountBlock); err != nil {
+			t.Fatal(err)
+		} else {
+			hcBlock.Value.(*bpv7.HopCountBlock).Increment()
+		}
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &exceededBndl})
+
+		if l := len(peer.sent()); l != 1 {
+			t.Fatalf("peer received %d bundles, expected 1", l)
+		}
+
+		tests := []struct {
+			name     string
+			metric   prometheus.Collector
+			expected float64
+		}{
+			{"received", c.metrics.received, 3},
+			{"forwarded", c.metrics.forwarded, 1},
+			{"delivered", c.metrics.delivered, 1},
+			{"deleted", c.metrics.deleted.WithLabelValues(bpv7.HopLimitExceeded.String()), 1},
+			{"deleted", c.metrics.deleted.WithLabelValues(bpv7.NoInformation.String()), 0},
+		}
+		for _, test := range tests {
+			if v := testutil.ToFloat64(test.metric); v != test.expected {
+				t.Fatalf("%s counter is %v, expected %v", test.name, v, test.expected)
+			}
+		}
+
+		if n, err := testutil.GatherAndCount(reg, "dtn7_forward_duration_seconds"); err != nil {
+			t.Fatal(err)
+		} else if n != 1 {
+			t.Fatalf("gathered %d forward duration histograms, expected 1", n)
+		}
+
+		mfs, err := reg.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var latencySamples uint64
+		for _, mf := range mfs {
+			if mf.GetName() == "dtn7_delivery_latency_seconds" {
+				latencySamples = mf.GetMetric()[0].GetHistogram().GetSampleCount()
+			}
+		}
+		if latencySamples != 1 {
+			t.Fatalf("delivery latency histogram has %d samples, expected 1", latencySamples)
+		}
+	})
+}
+
+func TestCoreMetricsDisabled(t *testing.T) {
+	testCore(t, func(c *Core) {
+		if c.metrics != nil {
+			t.Fatal("metrics are enabled by default")
+		}
+
+		// A nil coreMetrics must discard all observations.
+		bndl := testCoreBundle(t, "dtn://src/", "dtn://core/")
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &bndl})
+	})
+}
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"sync"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// mockConvSender mocks a ConvergenceSender, recording all sent bundles.
+type mockConvSender struct {
+	sync.Mutex
+
+	reportChan chan cla.ConvergenceStatus
+
+	address        string
+	peerEndpointId bpv7.EndpointID
+
+	// sentBndls is an array of all sent bundles, sendErr will be returned by Send if set.
+	sentBndls []bpv7.Bundle
+	sendErr   error
+}
+
+func newMockConvSender(address string, eid bpv7.EndpointID) *mockConvSender {
+	return &mockConvSender{
+		reportChan:     make(chan cla.ConvergenceStatus),
+		address:        address,
+		peerEndpointId: eid,
+	}
+}
+
+func (m *mockConvSender) Start() (error, bool) { return nil, false }
+
+func (_ *mockConvSender) Close() error { return nil }
+
+func (m *mockConvSender) Channel() chan cla.ConvergenceStatus { return m.reportChan }
+
+func (m *mockConvSender) Address() string { return m.address }
+
+func (_ *mockConvSender) IsPermanent() bool { return true }
+
+func (m *mockConvSender) GetPeerEndpointID() bpv7.EndpointID { return m.peerEndpointId }
+
+func (m *mockConvSender) Send(bndl bpv7.Bundle) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.sendErr != nil {
+		return m.sendErr
+	}
+
+	m.sentBndls = append(m.sentBndls, bndl)
+	return nil
+}
+
+// sent returns a copy of all sent bundles.
+func (m *mockConvSender) sent() []bpv7.Bundle {
+	m.Lock()
+	defer m.Unlock()
+
+	return append([]bpv7.Bundle(nil), m.sentBndls...)
+}
+
+func (m *mockConvSender) String() string {
+	return m.address
+}
@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/storage"
+)
+
+// outboundSettled checks if a queued bundle is done after a transmission attempt, resulting in this error. This is
+// the case for a successful transmission or a permanent refusal. Otherwise, another attempt will be made later.
+func outboundSettled(err error) bool {
+	if sendReaction(err) == forwardSent {
+		return true
+	}
+
+	var refusal *cla.RefusalError
+	return errors.As(err, &refusal) && refusal.Kind == cla.RefusalPermanent
+}
+
+// getOutbound returns the OutboundQueue set by SetOutboundQueue or nil.
+func (c *Core) getOutbound() *storage.OutboundQueue {
+	c.settingsMutex.RLock()
+	defer c.settingsMutex.RUnlock()
+
+	return c.outbound
+}
+
+// enqueueOutbound persists a bundle for a peer before sending it, if an OutboundQueue is set.
+func (c *Core) enqueueOutbound(node cla.ConvergenceSender, b bpv7.Bundle) {
+	outbound := c.getOutbound()
+	if outbound == nil {
+		return
+	}
+
+	if err := outbound.Enqueue(node.GetPeerEndpointID(), b); err != nil {
+		log.WithFields(log.Fields{
+			"bundle": b.ID(),
+			"cla":    node,
+		}).WithError(err).Warn("Failed to enqueue bundle in outbound queue")
+	}
+}
+
+// dequeueOutbound removes a bundle from a peer's queue, if an OutboundQueue is set.
+func (c *Core) dequeueOutbound(node cla.ConvergenceSender, bid bpv7.BundleID) {
+	outbound := c.getOutbound()
+	if outbound == nil {
+		return
+	}
+
+	if err := outbound.Remove(node.GetPeerEndpointID(), bid); err != nil {
+		log.WithFields(log.Fields{
+			"bundle": bid,
+			"cla":    node,
+		}).WithError(err).Warn("Failed to remove bundle from outbound queue")
+	}
+}
+
+// transferContext bounds sending a bundle by its lifetime, e.g., for a blocked CLA, and aborts it when shutting down.
+func (c *Core) transferContext(bp BundleDescriptor) (context.Context, context.CancelFunc) {
+	if end, ok := c.lifetimeEnd(bp); ok {
+		return context.WithDeadline(c.transfersCtx, end)
+	}
+	return context.WithCancel(c.transfersCtx)
+}
+
+// sendToPeer transmits a bundle to a peer, fragmented if necessary. The bundle is kept in the OutboundQueue, if set,
+// until its transmission has settled.
+func (c *Core) sendToPeer(ctx context.Context, node cla.ConvergenceSender, b bpv7.Bundle) error {
+	c.enqueueOutbound(node, b)
+	c.waitBandwidth(b)
+
+	err := sendFragmented(ctx, node, b, int(atomic.LoadInt32(&c.fragmentMinPayload)))
+	if outboundSettled(err) {
+		c.dequeueOutbound(node, b.ID())
+	}
+	return err
+}
+
+// retryOutbound re-attempts to send all queued bundles to their currently available peers.
+//
+// Bundles still pending in the store are left to the forward path, e.g., checkPendingBundles, which would otherwise
+// send them twice. Only queued bundles no longer pending, e.g., after having been forwarded to another peer, are
+// transmitted from here.
+func (c *Core) retryOutbound() {
+	outbound := c.getOutbound()
+	if outbound == nil || !c.beginTransfer() {
+		return
+	}
+	defer c.endTransfer()
+
+	for _, node := range c.claManager.Sender() {
+		bs, err := outbound.Pending(node.GetPeerEndpointID())
+		if err != nil {
+			log.WithField("cla", node).WithError(err).Warn("Failed to fetch outbound queue")
+			continue
+		}
+
+		for _, b := range bs {
+			logger := log.WithFields(log.Fields{
+				"bundle": b.ID(),
+				"cla":    node,
+			})
+
+			if b.IsLifetimeExceeded() {
+				logger.Info("Dropping expired bundle from outbound queue")
+				c.dequeueOutbound(node, b.ID())
+				continue
+			}
+
+			if bi, err := c.store.QueryId(b.ID()); err == nil && bi.Pending {
+				logger.Debug("Queued bundle is still pending, leaving it to the forward path")
+				continue
+			}
+
+			bp := NewBundleDescriptor(b.ID(), c.store)
+			bp.bndl = &b
+
+			ctx, cancel := c.transferContext(bp)
+			err := c.sendToPeer(ctx, node, b)
+			cancel()
+
+			if err == nil {
+				logger.Info("Sending queued bundle succeeded")
+			} else {
+				logger.WithError(err).Info("Sending queued bundle failed")
+			}
+		}
+	}
+}
@@ -6,7 +6,10 @@
 package routing
 
 import (
+	"context"
+	"errors"
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -16,11 +19,16 @@ import (
 
 // SendBundle transmits an outbounding bundle.
 func (c *Core) SendBundle(bndl *bpv7.Bundle) {
+	// The sequence number must be set before the bundle is signed and stored under its ID.
+	c.idKeeper.update(bndl)
+
 	if c.signPriv != nil && bndl.IsAdministrativeRecord() {
 		c.sendBundleAttachSignature(bndl)
 	}
+	c.prepareDeliveryGuarantee(bndl)
 	bp := NewBundleDescriptorFromBundle(*bndl, c.store)
 
+	c.seen.Add(bp.Id)
 	c.routing.NotifyNewBundle(bp)
 	c.transmit(bp)
 }
@@ -42,27 +50,27 @@ func (c *Core) sendBundleAttachSignature(bndl *bpv7.Bundle) {
 
 	bndl.AddExtensionBlock(cb)
 
-	log.WithField("bundle", bndl.ID()).Info("Attached signature to outgoing bundle")
+	log.WithField("bundle", bndl.ID()).Debug("Attached signature to outgoing bundle")
 }
 
 // transmit starts the transmission of an outbounding bundle pack. Therefore
-// the source's endpoint ID must be dtn:none or a member of this node.
+// the source's endpoint ID must be dtn:none or a member of this node, unless
+// the TransmitPolicy allows other sources.
 func (c *Core) transmit(bp BundleDescriptor) {
 	log.WithFields(log.Fields{
 		"bundle": bp.ID(),
-	}).Info("Transmission of bundle requested")
-
-	c.idKeeper.update(bp.MustBundle())
+	}).Debug("Transmission of bundle requested")
 
 	bp.AddConstraint(DispatchPending)
 	_ = bp.Sync()
 
 	src := bp.MustBundle().PrimaryBlock.SourceNode
-	if src != bpv7.DtnNone() && !c.HasEndpoint(src) {
+	if !c.checkTransmitSource(src) {
 		log.WithFields(log.Fields{
 			"bundle": bp.ID(),
 			"source": src,
-		}).Info("Bundle's source is neither dtn:none nor an endpoint of this node")
+			"policy": c.getTransmitPolicy(),
+		}).Warn("Bundle's source is neither dtn:none nor an endpoint of this node")
 
 		c.bundleDeletion(bp, bpv7.NoInformation)
 		return
@@ -71,36 +79,153 @@ func (c *Core) transmit(bp BundleDescriptor) {
 	c.dispatching(bp)
 }
 
+// receiveConvergence handles a bundle received from a CLA. The bundle is checked against the AcceptFilter, the
+// Throttle, and the FuturePolicy before being passed to receive, which stores it.
+func (c *Core) receiveConvergence(crb cla.ConvergenceReceivedBundle) {
+	c.settingsMutex.RLock()
+	filter, throttle := c.acceptFilter, c.throttle
+	c.settingsMutex.RUnlock()
+
+	if filter != nil && !filter(crb.Bundle.PrimaryBlock) {
+		log.WithFields(log.Fields{
+			"bundle": crb.Bundle.ID(),
+			"cla":    crb.Endpoint,
+		}).Warn("Received bundle was rejected by the accept filter")
+
+		c.refuseConvergence(crb, bpv7.NoInformation)
+		return
+	}
+
+	if throttle != nil && !throttle.Allow() {
+		log.WithFields(log.Fields{
+			"bundle": crb.Bundle.ID(),
+			"cla":    crb.Endpoint,
+		}).Warn("Received bundle was refused due to throttled intake")
+
+		c.refuseConvergence(crb, bpv7.DepletedStorage)
+		return
+	}
+
+	if !c.checkFuture(crb) {
+		return
+	}
+
+	if c.isDuplicate(*crb.Bundle) {
+		log.WithFields(log.Fields{
+			"bundle": crb.Bundle.ID(),
+			"cla":    crb.Endpoint,
+		}).Debug("Received bundle was already seen, dropping it")
+
+		bp := NewBundleDescriptor(crb.Bundle.ID(), c.store)
+		bp.bndl = crb.Bundle
+		c.notifyKnownBundle(bp)
+		return
+	}
+
+	// Don't use NewBundleDescriptorFromBundle, the Bundle is stored within receive after being checked.
+	bp := NewBundleDescriptor(crb.Bundle.ID(), c.store)
+	bp.bndl = crb.Bundle
+	bp.Receiver = crb.Endpoint
+
+	c.batchStatusReports(bp, func() { c.receive(bp) })
+}
+
+// refuseConvergence drops a received bundle without storing it, sending a deletion status report if requested.
+func (c *Core) refuseConvergence(crb cla.ConvergenceReceivedBundle, reason bpv7.StatusReportReason) {
+	if !statusReportRequested(*crb.Bundle, bpv7.StatusRequestDeletion) {
+		return
+	}
+
+	// Don't use NewBundleDescriptorFromBundle, which would store the Bundle.
+	bp := NewBundleDescriptor(crb.Bundle.ID(), c.store)
+	bp.bndl = crb.Bundle
+	bp.Receiver = crb.Endpoint
+
+	c.SendStatusReport(bp, bpv7.DeletedBundle, reason)
+}
+
+// statusReportRequested checks if a status report for the bundle is requested by this flag. Administrative records
+// never result in status reports, regardless of their flags, to prevent loops of status reports.
+func statusReportRequested(bndl bpv7.Bundle, flag bpv7.BundleControlFlags) bool {
+	return !bndl.IsAdministrativeRecord() && bndl.PrimaryBlock.BundleControlFlags.Has(flag)
+}
+
 // receive handles received/incoming bundles.
 func (c *Core) receive(bp BundleDescriptor) {
 	log.WithFields(log.Fields{
 		"bundle": bp.ID(),
 	}).Debug("Received new bundle")
 
-	if len(bp.Constraints) > 0 {
+	c.getMetrics().bundleReceived()
+
+	// A new fragment of an ongoing reassembly shares the already stored BundleItem, but must be checked like any
+	// other new bundle. It will be stored by the Reassembler on its local delivery.
+	fragment := c.reassembler.newFragment(*bp.MustBundle())
+
+	if len(bp.Constraints) > 0 && !fragment {
 		log.WithFields(log.Fields{
 			"bundle": bp.ID(),
 		}).Debug("Received bundle's ID is already known.")
 
+		c.notifyKnownBundle(bp)
+		c.reacknowledge(bp)
+
 		// bundleDeletion is _not_ called because this would delete the already
 		// stored BundleDescriptor.
 		return
 	}
 
+	if c.isLifetimeExceeded(bp) {
+		log.WithFields(log.Fields{
+			"bundle": bp.ID(),
+		}).Warn("Received bundle's lifetime is already exceeded")
+
+		c.bundleDeletion(bp, bpv7.LifetimeExpired)
+		return
+	}
+
 	log.WithFields(log.Fields{
 		"bundle": bp.ID(),
-	}).Info("Processing new received bundle")
+	}).Debug("Processing new received bundle")
 
 	bp.AddConstraint(DispatchPending)
-	_ = bp.Sync()
+	if !fragment {
+		_ = bp.Sync()
+	}
 
-	if bp.MustBundle().PrimaryBlock.BundleControlFlags.Has(bpv7.StatusRequestReception) {
+	if statusReportRequested(*bp.MustBundle(), bpv7.StatusRequestReception) {
 		c.SendStatusReport(bp, bpv7.ReceivedBundle, bpv7.NoInformation)
 	}
 
 	for i := len(bp.MustBundle().CanonicalBlocks) - 1; i >= 0; i-- {
 		var cb = &bp.MustBundle().CanonicalBlocks[i]
 
+		c.settingsMutex.RLock()
+		handler, ok := c.blockHandlers[cb.TypeCode()]
+		c.settingsMutex.RUnlock()
+
+		if ok {
+			action := handler.HandleBlock(cb, &bp)
+
+			log.WithFields(log.Fields{
+				"bundle": bp.ID(),
+				"number": i,
+				"type":   cb.TypeCode(),
+				"action": action,
+			}).Debug("Bundle's canonical block was processed by a BlockHandler")
+
+			switch action {
+			case BlockRemove:
+				bp.MustBundle().CanonicalBlocks = append(
+					bp.MustBundle().CanonicalBlocks[:i], bp.MustBundle().CanonicalBlocks[i+1:]...)
+
+			case BlockDeleteBundle:
+				c.bundleDeletion(bp, bpv7.NoInformation)
+				return
+			}
+			continue
+		}
+
 		if bpv7.GetExtensionBlockManager().IsKnown(cb.TypeCode()) {
 			continue
 		}
@@ -111,12 +236,12 @@ func (c *Core) receive(bp BundleDescriptor) {
 			"type":   cb.TypeCode(),
 		}).Warn("Bundle's canonical block is unknown")
 
-		if cb.BlockControlFlags.Has(bpv7.StatusReportBlock) {
+		if cb.BlockControlFlags.Has(bpv7.StatusReportBlock) && !bp.MustBundle().IsAdministrativeRecord() {
 			log.WithFields(log.Fields{
 				"bundle": bp.ID(),
 				"number": i,
 				"type":   cb.TypeCode(),
-			}).Info("Bundle's unknown canonical block requested reporting")
+			}).Debug("Bundle's unknown canonical block requested reporting")
 
 			c.SendStatusReport(bp, bpv7.ReceivedBundle, bpv7.BlockUnsupported)
 		}
@@ -126,7 +251,7 @@ func (c *Core) receive(bp BundleDescriptor) {
 				"bundle": bp.ID(),
 				"number": i,
 				"type":   cb.TypeCode(),
-			}).Info("Bundle's unknown canonical block requested bundle deletion")
+			}).Debug("Bundle's unknown canonical block requested bundle deletion")
 
 			c.bundleDeletion(bp, bpv7.BlockUnsupported)
 			return
@@ -137,29 +262,52 @@ func (c *Core) receive(bp BundleDescriptor) {
 				"bundle": bp.ID(),
 				"number": i,
 				"type":   cb.TypeCode(),
-			}).Info("Bundle's unknown canonical block requested to be removed")
+			}).Debug("Bundle's unknown canonical block requested to be removed")
 
 			bp.MustBundle().CanonicalBlocks = append(
 				bp.MustBundle().CanonicalBlocks[:i], bp.MustBundle().CanonicalBlocks[i+1:]...)
 		}
 	}
 
+	c.seen.Add(bp.Id)
 	c.routing.NotifyNewBundle(bp)
 
 	c.dispatching(bp)
 }
 
+// notifyKnownBundle informs the routing Algorithm about the previous node of a known bundle, received again. This
+// peer already possesses this bundle. The bundle will neither be stored nor delivered again.
+func (c *Core) notifyKnownBundle(bp BundleDescriptor) {
+	notifier, ok := c.routing.(KnownBundleNotifier)
+	if !ok {
+		return
+	}
+
+	pnBlock, err := bp.MustBundle().ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock)
+	if err != nil {
+		return
+	}
+
+	peer := pnBlock.Value.(*bpv7.PreviousNodeBlock).Endpoint()
+	log.WithFields(log.Fields{
+		"bundle": bp.ID(),
+		"peer":   peer,
+	}).Debug("Notifying routing about a known bundle from another peer")
+
+	notifier.NotifyKnownBundle(bp, peer)
+}
+
 // dispatching handles the dispatching of received bundles.
 func (c *Core) dispatching(bp BundleDescriptor) {
 	log.WithFields(log.Fields{
 		"bundle": bp.ID(),
-	}).Info("Dispatching bundle")
+	}).Debug("Dispatching bundle")
 
 	if !c.routing.DispatchingAllowed(bp) {
 		log.WithFields(log.Fields{
 			"bundle":  bp.ID(),
 			"routing": c.routing,
-		}).Info("Routing Algorithm has not allowed dispatching of the bundle")
+		}).Debug("Routing Algorithm has not allowed dispatching of the bundle")
 
 		return
 	}
@@ -173,6 +321,8 @@ func (c *Core) dispatching(bp BundleDescriptor) {
 		return
 	}
 
+	c.rewriteDestination(bndl)
+
 	if c.HasEndpoint(bndl.PrimaryBlock.Destination) {
 		c.localDelivery(bp)
 	} else {
@@ -180,11 +330,58 @@ func (c *Core) dispatching(bp BundleDescriptor) {
 	}
 }
 
+// forwardReaction describes the Core's reaction on a ConvergenceSender's result for a bundle transmission.
+type forwardReaction uint
+
+const (
+	// forwardSent treats the transmission as successful.
+	forwardSent forwardReaction = iota
+
+	// forwardRetry keeps the bundle for a later attempt without blaming the peer.
+	forwardRetry
+
+	// forwardFailed reports a failure to the routing Algorithm, which might select other senders.
+	forwardFailed
+)
+
+// sendReaction maps the error of a ConvergenceSender's Send method to a forwardReaction. A peer already having the
+// bundle is treated like a successful transmission to stop spreading it to this peer. A transmission aborted by
+// shutting down is retried after a restart.
+func sendReaction(err error) forwardReaction {
+	if err == nil {
+		return forwardSent
+	} else if errors.Is(err, context.Canceled) {
+		return forwardRetry
+	}
+
+	var refusal *cla.RefusalError
+	if !errors.As(err, &refusal) {
+		return forwardFailed
+	}
+
+	switch refusal.Kind {
+	case cla.RefusalDuplicate:
+		return forwardSent
+	case cla.RefusalTemporary:
+		return forwardRetry
+	default:
+		return forwardFailed
+	}
+}
+
 // forward forwards a bundle pack's bundle to another node.
 func (c *Core) forward(bp BundleDescriptor) {
+	if !c.beginTransfer() {
+		log.WithFields(log.Fields{
+			"bundle": bp.ID(),
+		}).Debug("Bundle will not be forwarded while shutting down")
+		return
+	}
+	defer c.endTransfer()
+
 	log.WithFields(log.Fields{
 		"bundle": bp.ID(),
-	}).Printf("Bundle will be forwarded")
+	}).Debug("Bundle will be forwarded")
 
 	bp.AddConstraint(ForwardPending)
 	bp.RemoveConstraint(DispatchPending)
@@ -194,6 +391,7 @@ func (c *Core) forward(bp BundleDescriptor) {
 		hc := hcBlock.Value.(*bpv7.HopCountBlock)
 		hc.Increment()
 		hcBlock.Value = hc
+		_ = hcBlock.UpdateCRC()
 
 		log.WithFields(log.Fields{
 			"bundle":    bp.ID(),
@@ -204,14 +402,14 @@ func (c *Core) forward(bp BundleDescriptor) {
 			log.WithFields(log.Fields{
 				"bundle":    bp.ID(),
 				"hop_count": hc,
-			}).Info("Bundle contains an exceeded hop count block")
+			}).Warn("Bundle contains an exceeded hop count block")
 
 			c.bundleDeletion(bp, bpv7.HopLimitExceeded)
 			return
 		}
 	}
 
-	if bp.MustBundle().IsLifetimeExceeded() {
+	if c.isLifetimeExceeded(bp) {
 		log.WithFields(log.Fields{
 			"bundle":        bp.ID(),
 			"primary_block": bp.MustBundle().PrimaryBlock,
@@ -221,6 +419,18 @@ func (c *Core) forward(bp BundleDescriptor) {
 		return
 	}
 
+	if c.isForwardingTimedOut(bp) {
+		log.WithFields(log.Fields{
+			"bundle":  bp.ID(),
+			"timeout": c.getForwardingTimeout(),
+		}).Warn("Bundle's forwarding timeout has passed")
+
+		c.bundleDeletion(bp, bpv7.NoNextNodeContact)
+		return
+	}
+
+	c.extendLifetime(bp)
+
 	if age, err := bp.UpdateBundleAge(); err == nil {
 		if age >= bp.MustBundle().PrimaryBlock.Lifetime {
 			log.WithFields(log.Fields{
@@ -232,10 +442,12 @@ func (c *Core) forward(bp BundleDescriptor) {
 		}
 	}
 
+	prevEid := bpv7.DtnNone()
 	if pnBlock, err := bp.MustBundle().ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock); err == nil {
 		// Replace the PreviousNodeBlock
-		prevEid := pnBlock.Value.(*bpv7.PreviousNodeBlock).Endpoint()
+		prevEid = pnBlock.Value.(*bpv7.PreviousNodeBlock).Endpoint()
 		pnBlock.Value = bpv7.NewPreviousNodeBlock(c.NodeId)
+		_ = pnBlock.UpdateCRC()
 
 		log.WithFields(log.Fields{
 			"bundle":  bp.ID(),
@@ -248,6 +460,10 @@ func (c *Core) forward(bp BundleDescriptor) {
 			0, 0, bpv7.NewPreviousNodeBlock(c.NodeId)))
 	}
 
+	if !c.checkCRC(bp) {
+		return
+	}
+
 	var nodes []cla.ConvergenceSender
 	var deleteAfterwards = true
 
@@ -256,48 +472,66 @@ func (c *Core) forward(bp BundleDescriptor) {
 	if nodes == nil {
 		nodes, deleteAfterwards = c.routing.SenderForBundle(bp)
 	}
+	nodes = filterForwardSenders(bp, nodes, prevEid)
+
+	ctx, cancel := c.transferContext(bp)
+	defer cancel()
 
 	var bundleSent = false
+	var forwardedPeers []bpv7.EndpointID
 
-	var wg sync.WaitGroup
 	var once sync.Once
+	var peersMutex sync.Mutex
 
-	wg.Add(len(nodes))
+	forwardStart := time.Now()
+	c.forwardPool(nodes, func(node cla.ConvergenceSender) {
+		log.WithFields(log.Fields{
+			"bundle": bp.ID(),
+			"cla":    node,
+		}).Debug("Sending bundle to a CLA (ConvergenceSender)")
 
-	for _, node := range nodes {
-		go func(node cla.ConvergenceSender) {
+		err := c.sendToPeer(ctx, node, *bp.MustBundle())
+		switch sendReaction(err) {
+		case forwardSent:
 			log.WithFields(log.Fields{
 				"bundle": bp.ID(),
 				"cla":    node,
-			}).Info("Sending bundle to a CLA (ConvergenceSender)")
+			}).Debug("Sending bundle succeeded")
 
-			if err := node.Send(*bp.MustBundle()); err != nil {
-				log.WithFields(log.Fields{
-					"bundle": bp.ID(),
-					"cla":    node,
-					"error":  err,
-				}).Warn("Sending bundle failed")
+			once.Do(func() { bundleSent = true })
 
-				c.routing.ReportFailure(bp, node)
-			} else {
-				log.WithFields(log.Fields{
-					"bundle": bp.ID(),
-					"cla":    node,
-				}).Printf("Sending bundle succeeded")
+			peersMutex.Lock()
+			forwardedPeers = append(forwardedPeers, node.GetPeerEndpointID())
+			peersMutex.Unlock()
 
-				once.Do(func() { bundleSent = true })
-			}
+		case forwardRetry:
+			log.WithFields(log.Fields{
+				"bundle": bp.ID(),
+				"cla":    node,
+				"error":  err,
+			}).Debug("Sending bundle was temporarily refused, retrying later")
 
-			wg.Done()
-		}(node)
-	}
+		default:
+			log.WithFields(log.Fields{
+				"bundle": bp.ID(),
+				"cla":    node,
+				"error":  err,
+			}).Warn("Sending bundle failed")
 
-	wg.Wait()
+			c.routing.ReportFailure(bp, node)
+		}
+	})
+
+	if len(nodes) > 0 {
+		c.getMetrics().forwardDuration(time.Since(forwardStart))
+	}
+	c.recordForwardEvents(bp, forwardedPeers)
 
 	if hcBlock, err := bp.MustBundle().ExtensionBlock(bpv7.ExtBlockTypeHopCountBlock); err == nil {
 		hc := hcBlock.Value.(*bpv7.HopCountBlock)
 		hc.Decrement()
 		hcBlock.Value = hc
+		_ = hcBlock.UpdateCRC()
 
 		log.WithFields(log.Fields{
 			"bundle":    bp.ID(),
@@ -306,11 +540,15 @@ func (c *Core) forward(bp BundleDescriptor) {
 	}
 
 	if bundleSent {
-		if bp.MustBundle().PrimaryBlock.BundleControlFlags.Has(bpv7.StatusRequestForward) {
+		c.getMetrics().bundleForwarded()
+
+		if statusReportRequested(*bp.MustBundle(), bpv7.StatusRequestForward) {
 			c.SendStatusReport(bp, bpv7.ForwardedBundle, bpv7.NoInformation)
 		}
 
-		if deleteAfterwards {
+		if c.awaitsAcknowledgement(bp) {
+			c.bundleAckPending(bp)
+		} else if deleteAfterwards || isBestEffort(bp) {
 			bp.PurgeConstraints()
 			_ = bp.Sync()
 		} else if c.InspectAllBundles && bp.MustBundle().IsAdministrativeRecord() {
@@ -322,8 +560,13 @@ func (c *Core) forward(bp BundleDescriptor) {
 	} else {
 		log.WithFields(log.Fields{
 			"bundle": bp.ID(),
-		}).Info("Failed to forward bundle to any CLA")
-		c.bundleContraindicated(bp)
+		}).Warn("Failed to forward bundle to any CLA")
+
+		if isBestEffort(bp) {
+			c.bundleDeletion(bp, bpv7.NoNextNodeContact)
+		} else {
+			c.bundleContraindicated(bp)
+		}
 	}
 }
 
@@ -361,7 +604,7 @@ func (c *Core) checkAdministrativeRecord(bp BundleDescriptor) bool {
 	log.WithFields(log.Fields{
 		"bundle":    bp.ID(),
 		"admin_rec": ar,
-	}).Info("Received bundle contains an administrative record")
+	}).Debug("Received bundle contains an administrative record")
 
 	// Currently there are only status reports. This must be changed if more
 	// types of administrative records are introduced.
@@ -380,6 +623,11 @@ func (c *Core) inspectStatusReport(bp BundleDescriptor, ar bpv7.AdministrativeRe
 		return
 	}
 
+	if !c.verifyAdministrativeRecord(bp) {
+		log.WithField("bundle", bp.ID()).Warn("Ignoring status report without a trusted signature")
+		return
+	}
+
 	var status = *ar.(*bpv7.StatusReport)
 	var sips = status.StatusInformations()
 
@@ -391,7 +639,7 @@ func (c *Core) inspectStatusReport(bp BundleDescriptor, ar bpv7.AdministrativeRe
 		return
 	}
 
-	var bpStore, err = c.store.QueryId(status.RefBundle)
+	var bpStore, err = c.store.QueryFromStatusReport(status)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"bundle":     bp.ID(),
@@ -412,7 +660,7 @@ func (c *Core) inspectStatusReport(bp BundleDescriptor, ar bpv7.AdministrativeRe
 			"status_rep":    status,
 			"status_bundle": bpStore.Id,
 			"information":   sip,
-		}).Info("Parsing status report")
+		}).Debug("Parsing status report")
 
 		switch sip {
 		case bpv7.ReceivedBundle, bpv7.ForwardedBundle, bpv7.DeletedBundle:
@@ -428,7 +676,7 @@ func (c *Core) inspectStatusReport(bp BundleDescriptor, ar bpv7.AdministrativeRe
 			if err := c.store.Delete(bpStore.BId); err != nil {
 				logger.WithError(err).Warn("Failed to delete delivered bundle")
 			} else {
-				logger.Info("Status report indicates delivered bundle, deleting bundle")
+				logger.Debug("Status report indicates delivered bundle, deleting bundle")
 			}
 
 		default:
@@ -443,52 +691,128 @@ func (c *Core) inspectStatusReport(bp BundleDescriptor, ar bpv7.AdministrativeRe
 }
 
 func (c *Core) localDelivery(bp BundleDescriptor) {
-	// TODO: check fragmentation
-
 	log.WithFields(log.Fields{
 		"bundle": bp.ID(),
-	}).Info("Received bundle for local delivery")
+	}).Debug("Received bundle for local delivery")
+
+	if bp.MustBundle().PrimaryBlock.BundleControlFlags.Has(bpv7.IsFragment) {
+		c.reassembler.add(bp)
+		return
+	}
+
+	// Bundles without a payload block, e.g., network probes, are forwarded, but cannot be delivered. An empty payload
+	// block is fine.
+	if _, err := bp.MustBundle().PayloadBlock(); err != nil {
+		log.WithField("bundle", bp.ID()).WithError(err).Warn("Bundle for local delivery has no payload block")
+		c.bundleDeletion(bp, bpv7.BlockUnintelligible)
+		return
+	}
 
 	if bp.MustBundle().IsAdministrativeRecord() {
-		if !c.checkAdministrativeRecord(bp) {
+		if !c.checkUnknownAdministrativeRecord(bp) {
+			return
+		} else if !c.checkAdministrativeRecord(bp) {
 			c.bundleDeletion(bp, bpv7.NoInformation)
 			return
 		}
 	}
 
+	if err := bp.MustBundle().VerifyPayloadIntegrity(); err != nil {
+		log.WithField("bundle", bp.ID()).WithError(err).Warn("Bundle failed payload verification")
+		c.bundleDeletion(bp, bpv7.BlockUnintelligible)
+		return
+	}
+
+	if c.agentManager.IsStale(*bp.MustBundle()) {
+		log.WithField("bundle", bp.ID()).Warn("Bundle is too old for all application agents, dropping it")
+		c.bundleDeletion(bp, bpv7.LifetimeExpired)
+		return
+	}
+
 	bp.AddConstraint(LocalEndpoint)
 	_ = bp.Sync()
 
+	c.settingsMutex.RLock()
+	order := c.deliveryOrder
+	c.settingsMutex.RUnlock()
+
+	if order != nil {
+		order.add(bp)
+	} else {
+		c.deliverLocal(bp)
+	}
+}
+
+// deliverLocal passes a bundle, prepared by localDelivery, to the application agents.
+func (c *Core) deliverLocal(bp BundleDescriptor) {
 	if err := c.agentManager.Deliver(bp); err != nil {
 		log.WithField("bundle", bp.ID()).WithError(err).Warn("Delivering local bundle errored")
+	} else {
+		c.getMetrics().bundleDelivered()
+
+		if latency, err := deliveryLatency(bp); err != nil {
+			log.WithField("bundle", bp.ID()).WithError(err).Debug("Failed to calculate delivery latency")
+		} else {
+			log.WithFields(log.Fields{
+				"bundle":  bp.ID(),
+				"latency": latency,
+			}).Debug("Delivered bundle locally")
+
+			c.latency.observe(latency)
+			c.getMetrics().bundleDeliveryLatency(latency)
+
+			c.settingsMutex.RLock()
+			callback := c.deliveryCallback
+			c.settingsMutex.RUnlock()
+
+			if callback != nil {
+				callback(bp.Id, latency)
+			}
+		}
 	}
 
-	if bp.MustBundle().PrimaryBlock.BundleControlFlags.Has(bpv7.StatusRequestDelivery) {
+	if statusReportRequested(*bp.MustBundle(), bpv7.StatusRequestDelivery) {
 		c.SendStatusReport(bp, bpv7.DeliveredBundle, bpv7.NoInformation)
 	}
 
+	c.settingsMutex.RLock()
+	retain := c.retainDelivered
+	c.settingsMutex.RUnlock()
+
 	bp.PurgeConstraints()
+	if guarantee, _ := bp.MustBundle().DeliveryGuarantee(); retain || guarantee == bpv7.ExactlyOnce {
+		bp.AddConstraint(Retained)
+	}
 	_ = bp.Sync()
 }
 
 func (c *Core) bundleContraindicated(bp BundleDescriptor) {
 	log.WithFields(log.Fields{
 		"bundle": bp.ID(),
-	}).Info("Bundle was marked for contraindication")
+	}).Debug("Bundle was marked for contraindication")
+
+	c.getMetrics().bundleContraindicated()
 
 	bp.AddConstraint(Contraindicated)
 	_ = bp.Sync()
 }
 
 func (c *Core) bundleDeletion(bp BundleDescriptor, reason bpv7.StatusReportReason) {
-	if bp.MustBundle().PrimaryBlock.BundleControlFlags.Has(bpv7.StatusRequestDeletion) {
+	c.getMetrics().bundleDeleted(reason)
+
+	if statusReportRequested(*bp.MustBundle(), bpv7.StatusRequestDeletion) {
 		c.SendStatusReport(bp, bpv7.DeletedBundle, reason)
 	}
 
+	// A Bundle which was never stored, e.g., refused on its reception, must not be inserted by Sync. Neither must a
+	// new fragment of an ongoing reassembly delete its already stored siblings.
 	bp.PurgeConstraints()
-	_ = bp.Sync()
+	if c.store.KnowsBundle(bp.Id.Scrub()) && !c.reassembler.newFragment(*bp.MustBundle()) {
+		_ = bp.Sync()
+	}
 
 	log.WithFields(log.Fields{
 		"bundle": bp.ID(),
-	}).Info("Bundle was marked for deletion")
+		"reason": reason,
+	}).Warn("Bundle was marked for deletion")
 }
@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// Reassembler collects the fragments of bundles addressed to this node before their local delivery. All fragments of
+// a bundle are kept in the Core's store, sharing the bundle's BundleItem, until their payload ranges cover the total
+// data length. Afterwards, a single non-fragmented bundle is reassembled and delivered.
+//
+// Fragments arriving for an ongoing reassembly are processed as new bundles until their local delivery, even though
+// their BundleItem is already stored. Duplicate fragments are treated as known bundles and overlapping fragments are
+// merged on reassembly.
+type Reassembler struct {
+	sync.Mutex
+
+	c       *Core
+	timeout time.Duration
+	timers  map[bpv7.BundleID]*time.Timer
+}
+
+// NewReassembler for a Core. Without a timeout, incomplete fragments are kept until their lifetime expires.
+func NewReassembler(c *Core) *Reassembler {
+	return &Reassembler{
+		c:      c,
+		timers: make(map[bpv7.BundleID]*time.Timer),
+	}
+}
+
+// SetReassemblyTimeout sets the duration after the first received fragment of a bundle until its incomplete
+// fragments are deleted. Zero disables this timeout, which is the default. Ongoing reassemblies keep their timeout.
+func (c *Core) SetReassemblyTimeout(timeout time.Duration) {
+	c.reassembler.Lock()
+	c.reassembler.timeout = timeout
+	c.reassembler.Unlock()
+}
+
+// newFragment checks if this bundle is a yet unknown fragment of a bundle whose reassembly is ongoing.
+func (r *Reassembler) newFragment(b bpv7.Bundle) bool {
+	if !b.PrimaryBlock.BundleControlFlags.Has(bpv7.IsFragment) {
+		return false
+	}
+
+	if !NewBundleDescriptor(b.ID(), r.c.store).HasConstraint(ReassemblyPending_) {
+		return false
+	}
+
+	bi, err := r.c.store.QueryId(b.ID().Scrub())
+	if err != nil {
+		return false
+	}
+
+	for _, part := range bi.Parts {
+		if part.FragmentOffset == b.PrimaryBlock.FragmentOffset &&
+			part.TotalDataLength == b.PrimaryBlock.TotalDataLength {
+			return false
+		}
+	}
+	return true
+}
+
+// add a fragment, addressed to this node, and deliver the reassembled bundle if all fragments are present.
+func (r *Reassembler) add(bp BundleDescriptor) {
+	r.Lock()
+	reassembled, ok := r.addFragment(bp)
+	r.Unlock()
+
+	if ok {
+		r.c.localDelivery(reassembled)
+	}
+}
+
+// addFragment to the store and try to reassemble its bundle. This method must be called while holding the lock.
+func (r *Reassembler) addFragment(bp BundleDescriptor) (reassembled BundleDescriptor, ok bool) {
+	bid := bp.Id.Scrub()
+
+	if err := r.c.store.Push(*bp.MustBundle()); err != nil {
+		log.WithField("bundle", bp.ID()).WithError(err).Warn("Failed to store fragment for reassembly")
+		return
+	}
+
+	bp.RemoveConstraint(DispatchPending)
+	bp.AddConstraint(ReassemblyPending_)
+	_ = bp.Sync()
+
+	if _, exists := r.timers[bid]; !exists && r.timeout > 0 {
+		r.timers[bid] = time.AfterFunc(r.timeout, func() { r.expire(bid) })
+	}
+
+	bi, err := r.c.store.QueryId(bid)
+	if err != nil {
+		log.WithField("bundle", bp.ID()).WithError(err).Warn("Failed to query fragments for reassembly")
+		return
+	} else if !bi.IsComplete() {
+		log.WithFields(log.Fields{
+			"bundle":    bp.ID(),
+			"fragments": len(bi.Parts),
+		}).Debug("Received fragment, reassembly is pending")
+		return
+	}
+
+	r.stopTimer(bid)
+
+	b, err := bi.Load()
+	if err != nil {
+		log.WithField("bundle", bp.ID()).WithError(err).Warn("Failed to reassemble fragments")
+		r.c.bundleDeletion(bp, bpv7.BlockUnintelligible)
+		return
+	}
+
+	if err := r.c.store.Delete(bid); err != nil {
+		log.WithField("bundle", bp.ID()).WithError(err).Warn("Failed to delete reassembled fragments")
+		return
+	}
+
+	reassembled = NewBundleDescriptorFromBundle(b, r.c.store)
+	reassembled.Receiver = bp.Receiver
+	reassembled.Timestamp = bp.Timestamp
+
+	log.WithFields(log.Fields{
+		"bundle":    reassembled.ID(),
+		"fragments": len(bi.Parts),
+	}).Info("Reassembled fragmented bundle")
+
+	ok = true
+	return
+}
+
+// expire an incomplete reassembly, deleting its fragments.
+func (r *Reassembler) expire(bid bpv7.BundleID) {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.timers, bid)
+
+	bp := NewBundleDescriptor(bid, r.c.store)
+	if !bp.HasConstraint(ReassemblyPending_) {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":  bp.ID(),
+		"timeout": r.timeout,
+	}).Info("Reassembly timed out, deleting fragments")
+
+	r.c.bundleDeletion(bp, bpv7.LifetimeExpired)
+}
+
+// stopTimer of a reassembly. This method must be called while holding the lock.
+func (r *Reassembler) stopTimer(bid bpv7.BundleID) {
+	if timer, exists := r.timers[bid]; exists {
+		timer.Stop()
+		delete(r.timers, bid)
+	}
+}
+
+// cancel the timeout of a reassembly, e.g., after its fragments were deleted.
+func (r *Reassembler) cancel(bid bpv7.BundleID) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.stopTimer(bid.Scrub())
+}
+
+// close stops all timeouts, e.g., before closing the store.
+func (r *Reassembler) close() {
+	r.Lock()
+	defer r.Unlock()
+
+	for bid := range r.timers {
+		r.stopTimer(bid)
+	}
+}
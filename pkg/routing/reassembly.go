@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// ReassemblyRange is a received range of a fragmented bundle's payload, starting at Offset.
+type ReassemblyRange struct {
+	Offset uint64
+	Length uint64
+}
+
+// ReassemblyInfo describes a fragmented bundle, whose fragments are not yet complete.
+type ReassemblyInfo struct {
+	Id              bpv7.BundleID
+	Source          bpv7.EndpointID
+	TotalDataLength uint64
+
+	// Received payload ranges, sorted by their offset. Overlapping or adjacent ranges are merged.
+	Received []ReassemblyRange
+
+	// Age since the first fragment was received.
+	Age time.Duration
+}
+
+// PendingReassemblies lists all stored fragmented bundles which cannot be reassembled yet, e.g., to debug a stuck
+// fragment delivery.
+func (c *Core) PendingReassemblies() (infos []ReassemblyInfo, err error) {
+	bis, err := c.store.QueryIncomplete()
+	if err != nil {
+		return
+	}
+
+	for _, bi := range bis {
+		info := ReassemblyInfo{
+			Id:     bi.BId,
+			Source: bi.BId.SourceNode,
+			Age:    time.Since(NewBundleDescriptor(bi.BId, c.store).Timestamp),
+		}
+
+		for _, part := range bi.Parts {
+			info.TotalDataLength = part.TotalDataLength
+
+			frag, fragErr := part.Load()
+			if fragErr != nil {
+				log.WithField("bundle", bi.Id).WithError(fragErr).Warn("Failed to load stored fragment")
+				continue
+			}
+
+			pb, pbErr := frag.PayloadBlock()
+			if pbErr != nil {
+				log.WithField("bundle", bi.Id).WithError(pbErr).Warn("Stored fragment misses its payload block")
+				continue
+			}
+
+			info.Received = append(info.Received, ReassemblyRange{
+				Offset: part.FragmentOffset,
+				Length: uint64(len(pb.Value.(*bpv7.PayloadBlock).Data())),
+			})
+		}
+
+		info.Received = mergeReassemblyRanges(info.Received)
+		infos = append(infos, info)
+	}
+	return
+}
+
+// mergeReassemblyRanges sorts the ranges and merges overlapping or adjacent ones.
+func mergeReassemblyRanges(ranges []ReassemblyRange) (merged []ReassemblyRange) {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Offset < ranges[j].Offset })
+
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && merged[n-1].Offset+merged[n-1].Length >= r.Offset {
+			if end := r.Offset + r.Length; end > merged[n-1].Offset+merged[n-1].Length {
+				merged[n-1].Length = end - merged[n-1].Offset
+			}
+		} else {
+			merged = append(merged, r)
+		}
+	}
+	return
+}
+
+// CancelReassembly deletes all stored fragments of a bundle, which cannot be reassembled yet, compare
+// PendingReassemblies.
+func (c *Core) CancelReassembly(bid bpv7.BundleID) error {
+	bi, err := c.store.QueryId(bid)
+	if err != nil {
+		return err
+	} else if !bi.Fragmented || bi.IsComplete() {
+		return fmt.Errorf("bundle %v has no pending reassembly", bid)
+	}
+
+	log.WithField("bundle", bi.Id).Info("Cancelling pending reassembly")
+	c.reassembler.cancel(bi.BId)
+	return c.store.Delete(bi.BId)
+}
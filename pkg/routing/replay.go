@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// SetRetainDelivered keeps delivered bundles stored until their lifetime has expired, allowing to Replay them.
+func (c *Core) SetRetainDelivered(retain bool) {
+	c.settingsMutex.Lock()
+	c.retainDelivered = retain
+	c.settingsMutex.Unlock()
+}
+
+// Replay re-injects a stored bundle into the dispatching, as if it was freshly received. This also works for
+// already delivered bundles, if retained, e.g., to re-deliver them after an agent has reconnected or to test routing
+// changes. Compare SetRetainDelivered.
+//
+// In contrast to a reception, a replayed bundle is not checked against the known bundles. Thus, it will be
+// delivered or forwarded again.
+func (c *Core) Replay(bid bpv7.BundleID) error {
+	if !c.store.KnowsBundle(bid) {
+		return fmt.Errorf("bundle %v is not stored", bid)
+	}
+
+	bp := NewBundleDescriptor(bid, c.store)
+	if _, err := bp.Bundle(); err != nil {
+		return err
+	}
+
+	log.WithField("bundle", bp.ID()).Info("Replaying stored bundle")
+
+	bp.AddConstraint(DispatchPending)
+	_ = bp.Sync()
+
+	c.dispatching(bp)
+	return nil
+}
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// SetTrustedKeys requires received administrative records, e.g., status reports, to be signed by their source node.
+// The map assigns each trusted node ID its ed25519 public key, matching the private key passed to its NewCore.
+// Administrative records without a valid SignatureBlock of their source node's key are ignored. A nil map disables
+// this verification, which is the default.
+func (c *Core) SetTrustedKeys(keys map[bpv7.EndpointID]ed25519.PublicKey) {
+	c.settingsMutex.Lock()
+	c.trustedKeys = keys
+	c.settingsMutex.Unlock()
+
+	if keys != nil && !bpv7.GetExtensionBlockManager().IsKnown(bpv7.ExtBlockTypeSignatureBlock) {
+		if err := bpv7.GetExtensionBlockManager().Register(&bpv7.SignatureBlock{}); err != nil {
+			log.WithError(err).Warn("SignatureBlock registration errored")
+		}
+	}
+}
+
+// trustedKey returns the trusted public key for a bundle's source node or nil, if this node is not trusted.
+func (c *Core) trustedKey(source bpv7.EndpointID) ed25519.PublicKey {
+	c.settingsMutex.RLock()
+	defer c.settingsMutex.RUnlock()
+
+	for node, key := range c.trustedKeys {
+		if source.SameNode(node) {
+			return key
+		}
+	}
+	return nil
+}
+
+// verifyAdministrativeRecord checks the SignatureBlock of a bundle with an administrative record, if trusted keys
+// are configured. If false is returned, the administrative record must be ignored.
+func (c *Core) verifyAdministrativeRecord(bp BundleDescriptor) bool {
+	c.settingsMutex.RLock()
+	keys := c.trustedKeys
+	c.settingsMutex.RUnlock()
+
+	if keys == nil {
+		return true
+	}
+
+	bndl := bp.MustBundle()
+	logger := log.WithFields(log.Fields{
+		"bundle": bp.ID(),
+		"source": bndl.PrimaryBlock.SourceNode,
+	})
+
+	key := c.trustedKey(bndl.PrimaryBlock.SourceNode)
+	if key == nil {
+		logger.Warn("Administrative record's source node is not trusted")
+		return false
+	}
+
+	cb, err := bndl.ExtensionBlock(bpv7.ExtBlockTypeSignatureBlock)
+	if err != nil {
+		logger.Warn("Administrative record is not signed")
+		return false
+	}
+
+	sb, ok := cb.Value.(*bpv7.SignatureBlock)
+	if !ok {
+		logger.WithField("block", cb.Value).Warn("Administrative record's signature block cannot be parsed")
+		return false
+	} else if !bytes.Equal(sb.PublicKey, key) {
+		logger.Warn("Administrative record is signed by another key than its source node's trusted key")
+		return false
+	} else if !sb.Verify(*bndl) {
+		logger.Warn("Administrative record's signature is invalid")
+		return false
+	}
+
+	logger.Debug("Administrative record's signature was verified")
+	return true
+}
@@ -0,0 +1,260 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/storage"
+)
+
+const (
+	// seenCacheCapacity is the default maximum amount of entries of the Core's SeenCache.
+	seenCacheCapacity = 10000
+
+	// seenCacheWindow is the default duration for which a bundle stays in the Core's SeenCache.
+	seenCacheWindow = 30 * time.Minute
+)
+
+// SeenCache is a bounded and time-windowed set of recently seen bundles. The Core manages one SeenCache, shared by
+// all routing Algorithms to have a consistent view without keeping their own state.
+//
+// For each bundle, the peers known to possess it are recorded as well, e.g., the previous node or the peers a bundle
+// was forwarded to. Routing Algorithms consult these peers to not offer a bundle to the same peer twice.
+//
+// If the capacity is exceeded, the oldest entries will be evicted first.
+type SeenCache struct {
+	mutex sync.Mutex
+
+	capacity int
+	window   time.Duration
+
+	entries map[string]time.Time
+	peers   map[string][]bpv7.EndpointID
+	order   []string
+
+	// store persists the entries, if not nil.
+	store *storage.Store
+}
+
+// NewSeenCache creates a new SeenCache for at most capacity entries, each kept for the window's duration.
+func NewSeenCache(capacity int, window time.Duration) *SeenCache {
+	return &SeenCache{
+		capacity: capacity,
+		window:   window,
+		entries:  make(map[string]time.Time),
+		peers:    make(map[string][]bpv7.EndpointID),
+	}
+}
+
+// NewPersistentSeenCache creates a new SeenCache like NewSeenCache, whose entries are persisted in the Store. Thus, a
+// restarted node still knows the bundles seen within the window. Previously persisted entries are loaded.
+func NewPersistentSeenCache(capacity int, window time.Duration, store *storage.Store) (*SeenCache, error) {
+	sc := NewSeenCache(capacity, window)
+
+	sis, err := store.QuerySeen(time.Now().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, si := range sis {
+		sc.entries[si.Id] = si.Seen
+		if len(si.Peers) > 0 {
+			sc.peers[si.Id] = si.Peers
+		}
+		sc.order = append(sc.order, si.Id)
+	}
+	sc.store = store
+
+	sc.mutex.Lock()
+	sc.evict()
+	sc.mutex.Unlock()
+
+	return sc, nil
+}
+
+// Add a BundleID to this SeenCache or refresh an existing entry.
+func (sc *SeenCache) Add(bid bpv7.BundleID) {
+	key := bid.Scrub().String()
+
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	sc.insert(key, time.Now())
+	sc.persist(key)
+	sc.evict()
+}
+
+// AddPeer records a peer possessing this bundle. An unknown BundleID is added as a new entry.
+func (sc *SeenCache) AddPeer(bid bpv7.BundleID, peer bpv7.EndpointID) {
+	key := bid.Scrub().String()
+
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	if _, ok := sc.entries[key]; !ok {
+		sc.insert(key, time.Now())
+	}
+
+	for _, eid := range sc.peers[key] {
+		if eid == peer {
+			return
+		}
+	}
+	sc.peers[key] = append(sc.peers[key], peer)
+
+	sc.persist(key)
+	sc.evict()
+}
+
+// RemovePeer no longer records a peer possessing this bundle, e.g., after a failed transmission.
+func (sc *SeenCache) RemovePeer(bid bpv7.BundleID, peer bpv7.EndpointID) {
+	key := bid.Scrub().String()
+
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	peers := sc.peers[key]
+	for i, eid := range peers {
+		if eid == peer {
+			sc.peers[key] = append(peers[:i:i], peers[i+1:]...)
+			sc.persist(key)
+			return
+		}
+	}
+}
+
+// Peers known to possess this bundle, in the order of their addition.
+func (sc *SeenCache) Peers(bid bpv7.BundleID) []bpv7.EndpointID {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	peers := sc.peers[bid.Scrub().String()]
+	return append(make([]bpv7.EndpointID, 0, len(peers)), peers...)
+}
+
+// HasPeer checks if a peer is known to possess this bundle.
+func (sc *SeenCache) HasPeer(bid bpv7.BundleID, peer bpv7.EndpointID) bool {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	for _, eid := range sc.peers[bid.Scrub().String()] {
+		if eid == peer {
+			return true
+		}
+	}
+	return false
+}
+
+// unseenSenders filters the ConvergenceSenders whose peers are not known to possess this bundle.
+func (sc *SeenCache) unseenSenders(bid bpv7.BundleID, clas []cla.ConvergenceSender) []cla.ConvergenceSender {
+	filtered := make([]cla.ConvergenceSender, 0, len(clas))
+	for _, cs := range clas {
+		if !sc.HasPeer(bid, cs.GetPeerEndpointID()) {
+			filtered = append(filtered, cs)
+		}
+	}
+	return filtered
+}
+
+// insert or refresh an entry. This method must be called while holding the mutex.
+func (sc *SeenCache) insert(key string, seen time.Time) {
+	if _, ok := sc.entries[key]; !ok {
+		sc.order = append(sc.order, key)
+	}
+	sc.entries[key] = seen
+}
+
+// persist an entry to the store, if set. This method must be called while holding the mutex.
+func (sc *SeenCache) persist(key string) {
+	if sc.store == nil {
+		return
+	}
+
+	if err := sc.store.PushSeen(key, sc.entries[key], sc.peers[key]); err != nil {
+		log.WithField("bundle", key).WithError(err).Warn("Failed to persist seen bundle")
+	}
+}
+
+// evict the oldest entries exceeding the capacity. This method must be called while holding the mutex.
+func (sc *SeenCache) evict() {
+	for len(sc.order) > sc.capacity {
+		key := sc.order[0]
+		delete(sc.entries, key)
+		delete(sc.peers, key)
+		sc.order = sc.order[1:]
+
+		if sc.store != nil {
+			if err := sc.store.DeleteSeen(key); err != nil {
+				log.WithField("bundle", key).WithError(err).Warn("Failed to delete persisted seen bundle")
+			}
+		}
+	}
+}
+
+// Contains checks if a BundleID was seen within the time window.
+func (sc *SeenCache) Contains(bid bpv7.BundleID) bool {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	seen, ok := sc.entries[bid.Scrub().String()]
+	return ok && time.Since(seen) < sc.window
+}
+
+// Len returns the amount of entries, including expired ones not yet being cleaned.
+func (sc *SeenCache) Len() int {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	return len(sc.entries)
+}
+
+// clean removes all entries outside the time window.
+func (sc *SeenCache) clean() {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	order := sc.order[:0]
+	for _, key := range sc.order {
+		if time.Since(sc.entries[key]) < sc.window {
+			order = append(order, key)
+		} else {
+			delete(sc.entries, key)
+			delete(sc.peers, key)
+		}
+	}
+	sc.order = order
+
+	if sc.store != nil {
+		if err := sc.store.DeleteSeenBefore(time.Now().Add(-sc.window)); err != nil {
+			log.WithError(err).Warn("Failed to delete persisted seen bundles outside the window")
+		}
+	}
+}
+
+// SeenBundles returns the Core's SeenCache, containing all recently received or created bundles.
+func (c *Core) SeenBundles() *SeenCache {
+	return c.seen
+}
+
+// isDuplicate checks if a received bundle was already seen, but is no longer stored, e.g., after its delivery or
+// before a restart. Stored bundles are detected as known bundles by their constraints. Fragments are excluded, as
+// all fragments of a bundle share the same ID. Bundles with an at-least-once or exactly-once guarantee are excluded
+// as well, as their retransmissions must pass until being acknowledged.
+func (c *Core) isDuplicate(b bpv7.Bundle) bool {
+	if b.PrimaryBlock.BundleControlFlags.Has(bpv7.IsFragment) {
+		return false
+	}
+	if guarantee, ok := b.DeliveryGuarantee(); ok && guarantee != bpv7.BestEffort {
+		return false
+	}
+
+	return c.seen.Contains(b.ID()) && !c.store.KnowsBundle(b.ID())
+}
@@ -0,0 +1,223 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/storage"
+)
+
+func testSeenCacheBundleID(i int) bpv7.BundleID {
+	return bpv7.BundleID{
+		SourceNode: bpv7.MustNewEndpointID(fmt.Sprintf("dtn://src-%d/", i)),
+		Timestamp:  bpv7.NewCreationTimestamp(bpv7.DtnTime(23), 0),
+	}
+}
+
+func TestSeenCacheCapacity(t *testing.T) {
+	sc := NewSeenCache(3, time.Minute)
+
+	for i := 0; i < 5; i++ {
+		sc.Add(testSeenCacheBundleID(i))
+	}
+
+	if l := sc.Len(); l != 3 {
+		t.Fatalf("SeenCache holds %d entries, expected 3", l)
+	}
+	for i := 0; i < 5; i++ {
+		if contains, expected := sc.Contains(testSeenCacheBundleID(i)), i >= 2; contains != expected {
+			t.Fatalf("SeenCache contains %d: %t, expected %t", i, contains, expected)
+		}
+	}
+}
+
+func TestSeenCacheWindow(t *testing.T) {
+	sc := NewSeenCache(10, 50*time.Millisecond)
+
+	bid := testSeenCacheBundleID(0)
+	sc.Add(bid)
+	if !sc.Contains(bid) {
+		t.Fatal("SeenCache does not contain a fresh entry")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if sc.Contains(bid) {
+		t.Fatal("SeenCache contains an expired entry")
+	}
+
+	sc.clean()
+	if l := sc.Len(); l != 0 {
+		t.Fatalf("SeenCache holds %d entries after cleaning, expected 0", l)
+	}
+}
+
+func TestSeenCacheShared(t *testing.T) {
+	// The Core's own SprayAndWait has no copies to spray and does not forward the bundle itself.
+	conf := RoutingConf{Algorithm: "spray", SprayConf: SprayConfig{Multiplicity: 1}}
+	testCoreRouting(t, conf, func(c *Core) {
+		er := NewEpidemicRouting(c, EpidemicConfig{})
+		sw := NewSprayAndWait(c, SprayConfig{Multiplicity: 5})
+
+		b := testCoreBundle(t, "dtn://core/", "dtn://dst/")
+		c.SendBundle(&b)
+
+		bp := NewBundleDescriptor(b.ID(), c.store)
+		sw.NotifyNewBundle(bp)
+
+		peerA := bpv7.MustNewEndpointID("dtn://a/")
+		peerB := bpv7.MustNewEndpointID("dtn://b/")
+		c.RegisterConvergable(newMockConvSender("mock://a", peerA))
+		c.RegisterConvergable(newMockConvSender("mock://b", peerB))
+
+		// A peer known to the EpidemicRouting is skipped by the SprayAndWait.
+		er.NotifyKnownBundle(bp, peerA)
+
+		if css, _ := sw.SenderForBundle(bp); len(css) != 1 || css[0].GetPeerEndpointID() != peerB {
+			t.Fatalf("SprayAndWait selected %v, expected only %v", css, peerB)
+		}
+
+		// The peer sprayed to by the SprayAndWait is skipped by the EpidemicRouting.
+		if css, _ := er.SenderForBundle(bp); len(css) != 0 {
+			t.Fatalf("EpidemicRouting selected %v, expected none", css)
+		}
+
+		// After a failed transmission, the peer becomes eligible again.
+		er.ReportFailure(bp, newMockConvSender("mock://b", peerB))
+		if css, _ := er.SenderForBundle(bp); len(css) != 1 || css[0].GetPeerEndpointID() != peerB {
+			t.Fatalf("EpidemicRouting selected %v, expected only %v", css, peerB)
+		}
+	})
+}
+
+func TestSeenCachePeers(t *testing.T) {
+	sc := NewSeenCache(1, time.Minute)
+
+	bid := testSeenCacheBundleID(0)
+	peer := bpv7.MustNewEndpointID("dtn://peer/")
+
+	sc.AddPeer(bid, peer)
+	sc.AddPeer(bid, peer)
+	if !sc.Contains(bid) {
+		t.Fatal("SeenCache does not contain a bundle with a peer")
+	} else if peers := sc.Peers(bid); len(peers) != 1 || peers[0] != peer {
+		t.Fatalf("SeenCache has peers %v, expected only %v", peers, peer)
+	}
+
+	sc.RemovePeer(bid, peer)
+	if sc.HasPeer(bid, peer) {
+		t.Fatal("SeenCache still has a removed peer")
+	}
+
+	// Evicted entries lose their peers.
+	sc.AddPeer(bid, peer)
+	sc.Add(testSeenCacheBundleID(1))
+	if sc.HasPeer(bid, peer) {
+		t.Fatal("SeenCache has a peer of an evicted entry")
+	}
+}
+
+func TestSeenCachePersistent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "seen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	store, err := storage.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := NewPersistentSeenCache(3, time.Minute, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		sc.Add(testSeenCacheBundleID(i))
+	}
+	sc.AddPeer(testSeenCacheBundleID(4), bpv7.MustNewEndpointID("dtn://peer/"))
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if store, err = storage.NewStore(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// A smaller capacity evicts the oldest persisted entries on loading.
+	sc, err = NewPersistentSeenCache(2, time.Minute, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if contains, expected := sc.Contains(testSeenCacheBundleID(i)), i >= 3; contains != expected {
+			t.Fatalf("restored SeenCache contains %d: %t, expected %t", i, contains, expected)
+		}
+	}
+	if peers := sc.Peers(testSeenCacheBundleID(4)); len(peers) != 1 {
+		t.Fatalf("restored SeenCache has peers %v, expected one", peers)
+	}
+
+	// Entries outside the window are neither loaded nor kept after cleaning.
+	time.Sleep(100 * time.Millisecond)
+	sc.window = 50 * time.Millisecond
+	sc.clean()
+
+	if sis, err := store.QuerySeen(time.Time{}); err != nil {
+		t.Fatal(err)
+	} else if len(sis) != 0 {
+		t.Fatalf("store still holds %d seen entries after cleaning", len(sis))
+	}
+}
+
+func TestCoreSeenAcrossRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	b := testCoreBundle(t, "dtn://src/", "dtn://core/app")
+
+	for i := 0; i < 2; i++ {
+		c, err := NewCore(dir, bpv7.MustNewEndpointID("dtn://core/"), false, RoutingConf{Algorithm: "epidemic"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		delivered := make(chan struct{}, 2)
+		ca := agent.NewCallback(bpv7.MustNewEndpointID("dtn://core/app"), func(bpv7.Bundle) {
+			delivered <- struct{}{}
+		})
+		if err := c.RegisterApplicationAgent(ca); err != nil {
+			t.Fatal(err)
+		}
+
+		c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+
+		select {
+		case <-delivered:
+			if i > 0 {
+				t.Fatal("bundle was delivered again after a restart")
+			}
+		case <-time.After(250 * time.Millisecond):
+			if i == 0 {
+				t.Fatal("bundle was not delivered")
+			}
+		}
+
+		ca.Close()
+		c.Close()
+	}
+}
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/internal/enum"
+)
+
+// defaultShutdownTimeout bounds how long a graceful shutdown waits for in-flight transfers.
+const defaultShutdownTimeout = 10 * time.Second
+
+// ShutdownMode describes how the Core terminates, e.g., for a planned maintenance or an emergency stop.
+type ShutdownMode int
+
+const (
+	// GracefulShutdown stops forwarding new bundles and waits for in-flight transfers to finish before the CLAs are
+	// closed. CLAs supporting it, e.g., TCPCLv4, terminate their sessions by a handshake with their peers, compare
+	// cla.GracefulCloser. This is the default.
+	GracefulShutdown ShutdownMode = iota
+
+	// ImmediateShutdown aborts in-flight transfers and closes all CLAs right away.
+	ImmediateShutdown
+)
+
+var shutdownModeNames = enum.NewNames("shutdown mode", "graceful", "immediate").Alias(0, "")
+
+func (mode ShutdownMode) String() string {
+	return shutdownModeNames.String(uint64(mode))
+}
+
+// ParseShutdownMode from its name, as returned by String. An empty name results in GracefulShutdown.
+func ParseShutdownMode(name string) (ShutdownMode, error) {
+	mode, err := shutdownModeNames.Parse(name)
+	return ShutdownMode(mode), err
+}
+
+// SetShutdown sets the ShutdownMode used by Close. For a GracefulShutdown, the timeout bounds both the waiting for
+// in-flight transfers and the graceful closing of the CLAs; a zero timeout results in the default of ten seconds.
+func (c *Core) SetShutdown(mode ShutdownMode, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	c.shutdownMode = mode
+	c.shutdownTimeout = timeout
+}
+
+// Shutdown the Core in the given ShutdownMode, regardless of the one set by SetShutdown.
+//
+// Bundles whose forwarding is refused while shutting down stay in the store and will be forwarded after a restart.
+// In-flight transfers, still running after a GracefulShutdown's timeout or on an ImmediateShutdown, are aborted and
+// awaited before the Core's handler is stopped, which might otherwise be blocked by such a transfer.
+func (c *Core) Shutdown(mode ShutdownMode) {
+	log.WithField("mode", mode).Info("Core is shutting down")
+
+	c.settingsMutex.RLock()
+	timeout := c.shutdownTimeout
+	c.settingsMutex.RUnlock()
+
+	c.transfersMutex.Lock()
+	c.draining = true
+	c.transfersMutex.Unlock()
+
+	if mode == GracefulShutdown {
+		c.awaitTransfers(timeout)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		c.claManager.CloseGracefully(ctx)
+		cancel()
+	}
+
+	c.abortTransfers()
+	c.awaitTransfers(timeout)
+
+	close(c.stopSyn)
+	<-c.stopAck
+}
+
+// beginTransfer registers an in-flight transfer, which must be finished by endTransfer. While shutting down, no new
+// transfers are allowed and false is returned.
+func (c *Core) beginTransfer() bool {
+	c.transfersMutex.RLock()
+	defer c.transfersMutex.RUnlock()
+
+	if c.draining {
+		return false
+	}
+
+	c.transfers.Add(1)
+	return true
+}
+
+// endTransfer marks an in-flight transfer, started by beginTransfer, as finished.
+func (c *Core) endTransfer() {
+	c.transfers.Done()
+}
+
+// awaitTransfers blocks until all in-flight transfers have finished or the timeout has passed.
+func (c *Core) awaitTransfers(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		c.transfers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Debug("All in-flight transfers have finished")
+
+	case <-time.After(timeout):
+		log.WithField("timeout", timeout).Warn("In-flight transfers have not finished before the shutdown timeout")
+	}
+}
@@ -0,0 +1,222 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// blockingConvSender is a mockConvSender whose Send blocks until release is closed.
+type blockingConvSender struct {
+	*mockConvSender
+	sending chan struct{}
+	release chan struct{}
+}
+
+func newBlockingConvSender(address string, eid bpv7.EndpointID) *blockingConvSender {
+	return &blockingConvSender{
+		mockConvSender: newMockConvSender(address, eid),
+		sending:        make(chan struct{}, 1),
+		release:        make(chan struct{}),
+	}
+}
+
+func (b *blockingConvSender) Send(bndl bpv7.Bundle) error {
+	b.sending <- struct{}{}
+	<-b.release
+	return b.mockConvSender.Send(bndl)
+}
+
+// gracefulConvSender is a mockConvSender implementing the cla.GracefulCloser.
+type gracefulConvSender struct {
+	*mockConvSender
+	closedGracefully chan struct{}
+}
+
+func (g *gracefulConvSender) CloseContext(ctx context.Context) error {
+	close(g.closedGracefully)
+	return nil
+}
+
+// testShutdownCore creates a Core with a pending transfer to a blocking peer. The bundle is either sent by an agent or,
+// if received is set, received from a CLA and forwarded by the Core's handler.
+func testShutdownCore(t *testing.T, received bool) (c *Core, peer *blockingConvSender, cleanup func()) {
+	dir, err := ioutil.TempDir("", "core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup = func() { _ = os.RemoveAll(dir) }
+
+	c, err = NewCore(dir, bpv7.MustNewEndpointID("dtn://core/"), false, RoutingConf{Algorithm: "epidemic"}, nil)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	peer = newBlockingConvSender("mock://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+	c.RegisterConvergable(peer)
+
+	if received {
+		bndl := testCoreBundle(t, "dtn://other/", "dtn://peer/")
+		c.claManager.Channel() <- cla.NewConvergenceReceivedBundle(peer, bpv7.MustNewEndpointID("dtn://other/"), &bndl)
+	} else {
+		bndl := testCoreBundle(t, "dtn://core/", "dtn://peer/")
+		go c.SendBundle(&bndl)
+	}
+
+	select {
+	case <-peer.sending:
+	case <-time.After(time.Second):
+		cleanup()
+		t.Fatal("bundle was not passed to the peer")
+	}
+
+	return
+}
+
+func TestShutdownModeParse(t *testing.T) {
+	for _, mode := range []ShutdownMode{GracefulShutdown, ImmediateShutdown} {
+		if parsed, err := ParseShutdownMode(mode.String()); err != nil {
+			t.Fatal(err)
+		} else if parsed != mode {
+			t.Fatalf("parsed %v, expected %v", parsed, mode)
+		}
+	}
+
+	if mode, err := ParseShutdownMode(""); err != nil || mode != GracefulShutdown {
+		t.Fatalf("empty name resulted in %v, %v", mode, err)
+	}
+	if _, err := ParseShutdownMode("eventually"); err == nil {
+		t.Fatal("unknown shutdown mode was parsed")
+	}
+}
+
+func TestCoreShutdownGraceful(t *testing.T) {
+	c, peer, cleanup := testShutdownCore(t, false)
+	defer cleanup()
+
+	const delay = 200 * time.Millisecond
+	go func() {
+		time.Sleep(delay)
+		close(peer.release)
+	}()
+
+	start := time.Now()
+	c.Shutdown(GracefulShutdown)
+
+	if d := time.Since(start); d < delay {
+		t.Fatalf("graceful shutdown returned after %v, before the in-flight transfer finished", d)
+	}
+	if n := len(peer.sent()); n != 1 {
+		t.Fatalf("peer received %d bundles, expected 1", n)
+	}
+	if c.beginTransfer() {
+		t.Fatal("new transfer was allowed after shutting down")
+	}
+}
+
+func TestCoreShutdownGracefulTimeout(t *testing.T) {
+	c, peer, cleanup := testShutdownCore(t, false)
+	defer cleanup()
+
+	c.SetShutdown(GracefulShutdown, 100*time.Millisecond)
+
+	start := time.Now()
+	c.Close()
+
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("graceful shutdown exceeded its timeout, took %v", d)
+	}
+	if n := len(peer.sent()); n != 0 {
+		t.Fatalf("peer received %d bundles, expected none", n)
+	}
+}
+
+func TestCoreShutdownImmediate(t *testing.T) {
+	c, peer, cleanup := testShutdownCore(t, false)
+	defer cleanup()
+
+	start := time.Now()
+	c.Shutdown(ImmediateShutdown)
+
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Fatalf("immediate shutdown took %v", d)
+	}
+	if n := len(peer.sent()); n != 0 {
+		t.Fatalf("peer received %d bundles, expected none", n)
+	}
+}
+
+func TestCoreShutdownBlockedHandler(t *testing.T) {
+	for _, mode := range []ShutdownMode{GracefulShutdown, ImmediateShutdown} {
+		t.Run(mode.String(), func(t *testing.T) {
+			c, peer, cleanup := testShutdownCore(t, true)
+			defer cleanup()
+			defer close(peer.release)
+
+			// The handler is blocked by forwarding the received bundle, which must be aborted.
+			c.SetShutdown(mode, 100*time.Millisecond)
+
+			start := time.Now()
+			c.Close()
+
+			if d := time.Since(start); d > time.Second {
+				t.Fatalf("shutdown took %v", d)
+			}
+		})
+	}
+}
+
+func TestCoreShutdownCloseGracefully(t *testing.T) {
+	tests := []struct {
+		mode     ShutdownMode
+		graceful bool
+	}{
+		{GracefulShutdown, true},
+		{ImmediateShutdown, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode.String(), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "core")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = os.RemoveAll(dir) }()
+
+			c, err := NewCore(dir, bpv7.MustNewEndpointID("dtn://core/"), false, RoutingConf{Algorithm: "epidemic"}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			peer := &gracefulConvSender{
+				mockConvSender:   newMockConvSender("mock://peer", bpv7.MustNewEndpointID("dtn://peer/")),
+				closedGracefully: make(chan struct{}),
+			}
+			c.RegisterConvergable(peer)
+			time.Sleep(100 * time.Millisecond)
+
+			c.Shutdown(test.mode)
+
+			select {
+			case <-peer.closedGracefully:
+				if !test.graceful {
+					t.Fatal("CLA was closed gracefully")
+				}
+			default:
+				if test.graceful {
+					t.Fatal("CLA was not closed gracefully")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// batchedStatus is a status, collected within a statusReportBatch, and the time of its assertion.
+type batchedStatus struct {
+	status bpv7.StatusInformationPos
+	time   bpv7.DtnTime
+}
+
+// statusReportBatch collects the status information for one bundle while it is being processed.
+type statusReportBatch struct {
+	descriptor BundleDescriptor
+	statuses   []batchedStatus
+	reason     bpv7.StatusReportReason
+}
+
+// add a status to this batch. The first reason other than NoInformation is kept for the whole status report.
+func (batch *statusReportBatch) add(status bpv7.StatusInformationPos, reason bpv7.StatusReportReason) {
+	known := false
+	for _, s := range batch.statuses {
+		known = known || s.status == status
+	}
+	if !known {
+		batch.statuses = append(batch.statuses, batchedStatus{status: status, time: bpv7.DtnTimeNow()})
+	}
+
+	if batch.reason == bpv7.NoInformation {
+		batch.reason = reason
+	}
+}
+
+// report creates a single status report, asserting all collected status information with their respective times.
+func (batch *statusReportBatch) report() *bpv7.StatusReport {
+	bndl := batch.descriptor.MustBundle()
+
+	first := batch.statuses[0]
+	sr := bpv7.NewStatusReport(*bndl, first.status, batch.reason, first.time)
+	for _, s := range batch.statuses[1:] {
+		if bndl.PrimaryBlock.BundleControlFlags.Has(bpv7.RequestStatusTime) {
+			sr.Assert(s.status, s.time)
+		} else {
+			sr.Assert(s.status, 0)
+		}
+	}
+	return sr
+}
+
+// batchStatusReports collects all status reports for a bundle, requested while f is executed, e.g., the reception of a
+// bundle which is directly delivered. Afterwards, they are sent as a single status report.
+func (c *Core) batchStatusReports(descriptor BundleDescriptor, f func()) {
+	id := descriptor.ID()
+
+	c.statusBatchesMutex.Lock()
+	if _, exists := c.statusBatches[id]; exists {
+		// The same bundle is already being processed, e.g., received twice at once.
+		c.statusBatchesMutex.Unlock()
+		f()
+		return
+	}
+	if c.statusBatches == nil {
+		c.statusBatches = make(map[string]*statusReportBatch)
+	}
+	c.statusBatches[id] = &statusReportBatch{descriptor: descriptor, reason: bpv7.NoInformation}
+	c.statusBatchesMutex.Unlock()
+
+	f()
+
+	c.statusBatchesMutex.Lock()
+	batch := c.statusBatches[id]
+	delete(c.statusBatches, id)
+	c.statusBatchesMutex.Unlock()
+
+	if len(batch.statuses) > 0 {
+		c.transmitStatusReport(batch.descriptor, batch.report())
+	}
+}
+
+// addToStatusReportBatch adds a status report to a currently collecting batch, compare batchStatusReports. If there is
+// no batch for this bundle, false is returned and the status report must be sent directly.
+func (c *Core) addToStatusReportBatch(descriptor BundleDescriptor, status bpv7.StatusInformationPos, reason bpv7.StatusReportReason) bool {
+	c.statusBatchesMutex.Lock()
+	defer c.statusBatchesMutex.Unlock()
+
+	batch, exists := c.statusBatches[descriptor.ID()]
+	if !exists {
+		return false
+	}
+
+	batch.add(status, reason)
+	return true
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+func TestCoreStatusReportBatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		reportTo    string
+		statuses    []bpv7.StatusInformationPos
+	}{
+		{"delivered", "dtn://core/", "dtn://relay/", []bpv7.StatusInformationPos{bpv7.ReceivedBundle, bpv7.DeliveredBundle}},
+		{"forwarded", "dtn://far/", "dtn://relay/", []bpv7.StatusInformationPos{bpv7.ReceivedBundle, bpv7.ForwardedBundle}},
+		{"none", "dtn://core/", "dtn:none", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testCore(t, func(c *Core) {
+				relay := newMockConvSender("mock://relay", bpv7.MustNewEndpointID("dtn://relay/"))
+				c.RegisterConvergable(relay)
+
+				b, err := bpv7.Builder().
+					BundleCtrlFlags(bpv7.StatusRequestReception | bpv7.StatusRequestForward |
+						bpv7.StatusRequestDelivery | bpv7.RequestStatusTime).
+					Source("dtn://src/").
+					Destination(test.destination).
+					ReportTo(test.reportTo).
+					CreationTimestampNow().
+					Lifetime("10m").
+					PayloadBlock([]byte("hello reports")).
+					Build()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				c.receiveConvergence(cla.ConvergenceReceivedBundle{Endpoint: c.NodeId, Bundle: &b})
+
+				var reports []*bpv7.StatusReport
+				for _, bSent := range relay.sent() {
+					if !bSent.IsAdministrativeRecord() {
+						continue
+					} else if ar, err := bSent.AdministrativeRecord(); err != nil {
+						t.Fatal(err)
+					} else {
+						reports = append(reports, ar.(*bpv7.StatusReport))
+					}
+				}
+
+				if test.statuses == nil {
+					if len(reports) != 0 {
+						t.Fatalf("%d status reports were sent", len(reports))
+					}
+					return
+				}
+
+				if len(reports) != 1 {
+					t.Fatalf("expected a single status report, got %d", len(reports))
+				} else if reports[0].RefBundle != b.ID() {
+					t.Fatalf("status report references %v, expected %v", reports[0].RefBundle, b.ID())
+				} else if sips := reports[0].StatusInformations(); !reflect.DeepEqual(sips, test.statuses) {
+					t.Fatalf("status report asserts %v, expected %v", sips, test.statuses)
+				}
+
+				for _, sip := range test.statuses {
+					if bsi := reports[0].StatusInformation[sip]; !bsi.StatusRequested || bsi.Time == bpv7.DtnTimeEpoch {
+						t.Fatalf("%v's status item has no time: %v", sip, bsi)
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// recoverStore prepares the persistent store after a restart. Expired bundles are deleted right away instead of by
+// the next janitor run. Bundles whose processing was interrupted, e.g., by a crash between their reception and
+// their forwarding, are marked as pending. Thus, checkPendingBundles will dispatch them again, as all other pending
+// bundles. Otherwise, such a bundle would stay in the store until its lifetime expires.
+//
+// Bundles stored by an older version might lack fields derived from their constraints, e.g., AwaitingAck. Those are
+// synchronized again to be found by the store's indexed queries.
+func (c *Core) recoverStore() {
+	c.store.DeleteExpired()
+
+	bis, err := c.store.QueryAll()
+	if err != nil {
+		log.WithError(err).Warn("Failed to fetch bundles for the store's recovery")
+		return
+	}
+
+	var recovered, reindexed int
+	for _, bi := range bis {
+		bp := NewBundleDescriptor(bi.BId, c.store)
+
+		updated := bi
+		bp.updateIndexedFields(&updated)
+		if bp.HasConstraints() && (updated.Pending != bi.Pending || updated.AwaitingAck != bi.AwaitingAck ||
+			updated.Protected != bi.Protected || updated.Constraints != bi.Constraints) {
+			if err := bp.Sync(); err != nil {
+				log.WithField("bundle", bi.BId).WithError(err).Warn("Failed to synchronize outdated bundle")
+			} else {
+				bi = updated
+				reindexed++
+			}
+		}
+
+		if bi.Pending {
+			continue
+		}
+
+		if bp.HasConstraint(ReassemblyPending_) || (!bp.HasConstraint(DispatchPending) && !bp.HasConstraint(ForwardPending)) {
+			continue
+		}
+
+		log.WithField("bundle", bi.BId).Info("Recovering bundle of an interrupted processing")
+
+		bp.RemoveConstraint(DispatchPending)
+		bp.AddConstraint(ForwardPending)
+		if err := bp.Sync(); err != nil {
+			log.WithField("bundle", bi.BId).WithError(err).Warn("Failed to recover bundle")
+			continue
+		}
+		recovered++
+	}
+
+	log.WithFields(log.Fields{
+		"bundles":   len(bis),
+		"recovered": recovered,
+		"reindexed": reindexed,
+	}).Debug("Recovered store")
+}
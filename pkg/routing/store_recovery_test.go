@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestCoreRecoverStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	nodeId := bpv7.MustNewEndpointID("dtn://core/")
+
+	c, err := NewCore(dir, nodeId, false, RoutingConf{Algorithm: "epidemic"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The processing of this bundle was interrupted right after its reception.
+	interrupted := NewBundleDescriptorFromBundle(testCoreBundle(t, "dtn://src/", "dtn://dst/"), c.store)
+	interrupted.AddConstraint(DispatchPending)
+	if err := interrupted.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	expiredBndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("500ms").
+		PayloadBlock([]byte("hello past")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired := NewBundleDescriptorFromBundle(expiredBndl, c.store)
+	expired.AddConstraint(ForwardPending)
+	if err := expired.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Close()
+	time.Sleep(600 * time.Millisecond)
+
+	if c, err = NewCore(dir, nodeId, false, RoutingConf{Algorithm: "epidemic"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if c.store.KnowsBundle(expired.Id) {
+		t.Fatal("expired bundle was not deleted on startup")
+	}
+
+	bi, err := c.store.QueryId(interrupted.Id)
+	if err != nil {
+		t.Fatal(err)
+	} else if !bi.Pending {
+		t.Fatal("interrupted bundle was not marked as pending")
+	}
+
+	recovered := NewBundleDescriptor(interrupted.Id, c.store)
+	if recovered.HasConstraint(DispatchPending) || !recovered.HasConstraint(ForwardPending) {
+		t.Fatalf("recovered bundle has the constraints %v", recovered.Constraints)
+	}
+}
+
+func TestCoreRecoverStoreReindex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	nodeId := bpv7.MustNewEndpointID("dtn://core/")
+
+	c, err := NewCore(dir, nodeId, false, RoutingConf{Algorithm: "epidemic"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	awaiting := NewBundleDescriptorFromBundle(testCoreBundle(t, "dtn://src/", "dtn://dst/"), c.store)
+	awaiting.AddConstraint(AckPending)
+	if err := awaiting.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Mimic a BundleItem stored by an older version, lacking the fields derived from its constraints.
+	bi, err := c.store.QueryId(awaiting.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bi.AwaitingAck = false
+	bi.Constraints = 0
+	if err := c.store.Update(bi); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Close()
+
+	if c, err = NewCore(dir, nodeId, false, RoutingConf{Algorithm: "epidemic"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if bis, err := c.store.QueryAwaitingAck(); err != nil {
+		t.Fatal(err)
+	} else if len(bis) != 1 || bis[0].BId != awaiting.Id {
+		t.Fatalf("QueryAwaitingAck returned %v after the recovery", bis)
+	}
+
+	if bis, err := c.store.QueryByConstraint(int(AckPending)); err != nil {
+		t.Fatal(err)
+	} else if len(bis) != 1 || bis[0].BId != awaiting.Id {
+		t.Fatalf("QueryByConstraint returned %v after the recovery", bis)
+	}
+}
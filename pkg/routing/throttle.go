@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/storage"
+)
+
+// ThrottleConfig describes the adaptive intake throttling of received bundles. The store's utilization is relative
+// to its capacity, compare Store.SetCapacity. All receptions will be refused for a full store.
+type ThrottleConfig struct {
+	// MaxRate is the maximum amount of accepted bundles per second for a mostly empty store. Zero disables throttling.
+	MaxRate float64 `toml:"max-rate"`
+
+	// MaxGoroutines refuses all receptions while more goroutines are running. Zero disables this limit.
+	MaxGoroutines int `toml:"max-goroutines"`
+}
+
+// throttleLowWater is the store utilization up to which the full MaxRate is allowed. Above, the rate decreases
+// linearly until it reaches zero at a full store.
+const throttleLowWater = 0.5
+
+// Throttle is a feedback controller for received bundles. Based on the store's utilization, an intake rate limit
+// is adjusted, enforced by a token bucket. This protects the Core against bundle storms.
+type Throttle struct {
+	sync.Mutex
+
+	config ThrottleConfig
+	store  *storage.Store
+
+	rate       float64
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewThrottle creates a new Throttle for the given configuration, based on the Store's utilization.
+func NewThrottle(config ThrottleConfig, store *storage.Store) *Throttle {
+	return &Throttle{
+		config:     config,
+		store:      store,
+		rate:       config.MaxRate,
+		tokens:     config.MaxRate,
+		lastRefill: time.Now(),
+	}
+}
+
+// Update the intake rate limit based on the current store utilization. A store without a capacity is never
+// considered to be utilized.
+func (t *Throttle) Update() {
+	var utilization float64
+	if capacity := t.store.Capacity(); capacity > 0 {
+		utilization = float64(t.store.Count()) / float64(capacity)
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	switch {
+	case utilization <= throttleLowWater:
+		t.rate = t.config.MaxRate
+	case utilization >= 1:
+		t.rate = 0
+	default:
+		t.rate = t.config.MaxRate * (1 - utilization) / (1 - throttleLowWater)
+	}
+
+	if t.tokens > t.rate {
+		t.tokens = t.rate
+	}
+
+	log.WithFields(log.Fields{
+		"utilization": utilization,
+		"rate":        t.rate,
+	}).Debug("Throttle updated intake rate")
+}
+
+// Allow checks if another bundle might be received right now. A true result consumes this allowance.
+func (t *Throttle) Allow() bool {
+	if t.config.MaxGoroutines > 0 && runtime.NumGoroutine() > t.config.MaxGoroutines {
+		return false
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	now := time.Now()
+	t.tokens += now.Sub(t.lastRefill).Seconds() * t.rate
+	if burst := math.Max(t.rate, 1); t.tokens > burst {
+		// Allow bursts of up to one second's worth of bundles.
+		t.tokens = burst
+	}
+	t.lastRefill = now
+
+	if t.tokens < 1 {
+		return false
+	}
+	t.tokens--
+	return true
+}
@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package routing
+
+import (
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/internal/enum"
+)
+
+// TransmitPolicy describes which sources are allowed for bundles passed to the Core's SendBundle.
+type TransmitPolicy int
+
+const (
+	// TransmitPolicyOriginate only allows bundles originated at this node, i.e., whose source is dtn:none or one of
+	// this node's endpoints. Other bundles are deleted. This is the default.
+	TransmitPolicyOriginate TransmitPolicy = iota
+
+	// TransmitPolicyRelay allows bundles of any source, e.g., for gateways or test harnesses re-injecting bundles
+	// originated elsewhere.
+	TransmitPolicyRelay
+)
+
+var transmitPolicyNames = enum.NewNames("transmit policy", "originate", "relay").Alias(0, "")
+
+func (policy TransmitPolicy) String() string {
+	return transmitPolicyNames.String(uint64(policy))
+}
+
+// ParseTransmitPolicy from its name, as returned by String. An empty name results in TransmitPolicyOriginate.
+func ParseTransmitPolicy(name string) (TransmitPolicy, error) {
+	policy, err := transmitPolicyNames.Parse(name)
+	return TransmitPolicy(policy), err
+}
+
+// SetTransmitPolicy sets the TransmitPolicy for outbounding bundles.
+func (c *Core) SetTransmitPolicy(policy TransmitPolicy) {
+	c.settingsMutex.Lock()
+	c.transmitPolicy = policy
+	c.settingsMutex.Unlock()
+}
+
+// getTransmitPolicy returns the TransmitPolicy set by SetTransmitPolicy.
+func (c *Core) getTransmitPolicy() TransmitPolicy {
+	c.settingsMutex.RLock()
+	defer c.settingsMutex.RUnlock()
+
+	return c.transmitPolicy
+}
+
+// checkTransmitSource checks if the TransmitPolicy allows transmitting a bundle of this source.
+func (c *Core) checkTransmitSource(src bpv7.EndpointID) bool {
+	return c.getTransmitPolicy() == TransmitPolicyRelay || src == bpv7.DtnNone() || c.HasEndpoint(src)
+}
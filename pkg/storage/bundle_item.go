@@ -23,12 +23,60 @@ type BundleItem struct {
 	Pending bool      `badgerholdIndex:"Pending"`
 	Expires time.Time `badgerholdIndex:"Expires"`
 
+	// AwaitingAck marks Bundles sent with a delivery guarantee, waiting for their acknowledgement.
+	AwaitingAck bool `badgerholdIndex:"AwaitingAck"`
+
+	// Source is the Bundle's source node as a string, compare Store.QueryBySource.
+	Source string `badgerholdIndex:"Source"`
+
+	// Constraints are the Bundle's retention constraints, compare Store.QueryByConstraint.
+	Constraints ConstraintSet `badgerholdIndex:"Constraints"`
+
+	// LastUsed is the time of the last insertion or update, used for an LRUEviction.
+	LastUsed time.Time
+
+	// Protected Bundles, e.g., awaiting their local delivery or reassembly, must not be evicted.
+	Protected bool
+
 	Fragmented bool
 	Parts      []BundlePart
 
 	Properties map[string]interface{}
 }
 
+// ConstraintSet is a bit set of retention constraints, identified by their numeric value. The Store itself does not
+// interpret constraints; they are defined by the routing package.
+type ConstraintSet uint64
+
+// NewConstraintSet of the given constraints' numeric values, each below 64.
+func NewConstraintSet(constraints ...int) (cs ConstraintSet) {
+	for _, constraint := range constraints {
+		cs |= 1 << uint(constraint)
+	}
+	return
+}
+
+// Has checks if the constraint is part of this ConstraintSet.
+func (cs ConstraintSet) Has(constraint int) bool {
+	return cs&NewConstraintSet(constraint) != 0
+}
+
+// Compare implements badgerhold's Comparer to query the indexed ConstraintSets by an equality check. Two ConstraintSets
+// are considered equal if they share a constraint. Otherwise, they are ordered by their numeric value.
+func (cs ConstraintSet) Compare(other interface{}) (int, error) {
+	o, ok := other.(ConstraintSet)
+	switch {
+	case !ok:
+		return 0, fmt.Errorf("cannot compare ConstraintSet to %T", other)
+	case cs&o != 0:
+		return 0, nil
+	case cs < o:
+		return -1, nil
+	default:
+		return 1, nil
+	}
+}
+
 // bundleParts is a slice of loaded bundleParts.
 func (bi BundleItem) bundleParts() (bundleParts []bpv7.Bundle, err error) {
 	bundleParts = make([]bpv7.Bundle, len(bi.Parts))
@@ -70,7 +118,7 @@ type BundlePart struct {
 
 // storeBundle serializes the Bundle of a BundleItem/BundlePart to the disk.
 func (bp BundlePart) storeBundle(b bpv7.Bundle) error {
-	if f, err := os.OpenFile(bp.Filename, os.O_WRONLY|os.O_CREATE, 0600); err != nil {
+	if f, err := os.OpenFile(bp.Filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600); err != nil {
 		return err
 	} else {
 		return b.WriteBundle(f)
@@ -116,6 +164,10 @@ func newBundleItem(b bpv7.Bundle, storagePath string) (bi BundleItem) {
 		Pending: false,
 		Expires: calcExpirationDate(b),
 
+		Source: b.PrimaryBlock.SourceNode.String(),
+
+		LastUsed: time.Now(),
+
 		Fragmented: b.PrimaryBlock.HasFragmentation(),
 
 		Properties: make(map[string]interface{}),
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package storage
+
+import (
+	"sort"
+
+	"github.com/dtn7/dtn7-go/pkg/internal/enum"
+)
+
+// EvictionStrategy selects stored BundleItems to be removed if the Store exceeds its capacity.
+type EvictionStrategy interface {
+	// Victims selects up to n BundleItems of the given slice to be evicted. The slice must not be altered.
+	//
+	// Only evictable BundleItems are passed, compare evictable.
+	Victims(bis []BundleItem, n int) []BundleItem
+}
+
+// evictable checks if a BundleItem might be selected as a victim. Protected Bundles and those awaiting an
+// acknowledgement are never evicted.
+func evictable(bi BundleItem) bool {
+	return !bi.Protected && !bi.AwaitingAck
+}
+
+// selectVictims returns the first n BundleItems of a sorted copy of bis.
+func selectVictims(bis []BundleItem, n int, less func(a, b BundleItem) bool) []BundleItem {
+	sorted := append([]BundleItem(nil), bis...)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+
+	if n > len(sorted) {
+		n = len(sorted)
+	}
+	return sorted[:n]
+}
+
+// ExpiryEviction evicts the BundleItems expiring first, based on their lifetime. This is the default.
+type ExpiryEviction struct{}
+
+// Victims selects the BundleItems with the earliest expiration date.
+func (_ ExpiryEviction) Victims(bis []BundleItem, n int) []BundleItem {
+	return selectVictims(bis, n, func(a, b BundleItem) bool { return a.Expires.Before(b.Expires) })
+}
+
+// LRUEviction evicts the least recently used BundleItems, i.e., those which were stored or updated the longest
+// time ago.
+type LRUEviction struct{}
+
+// Victims selects the BundleItems with the oldest usage.
+func (_ LRUEviction) Victims(bis []BundleItem, n int) []BundleItem {
+	return selectVictims(bis, n, func(a, b BundleItem) bool { return a.LastUsed.Before(b.LastUsed) })
+}
+
+// PriorityEviction evicts the BundleItems with the lowest priority, as rated by the Priority function.
+type PriorityEviction struct {
+	Priority func(BundleItem) int
+}
+
+// Victims selects the BundleItems with the lowest priority.
+func (pe PriorityEviction) Victims(bis []BundleItem, n int) []BundleItem {
+	return selectVictims(bis, n, func(a, b BundleItem) bool { return pe.Priority(a) < pe.Priority(b) })
+}
+
+var (
+	evictionStrategyNames = enum.NewNames("eviction strategy", "expiry", "lru").Alias(0, "")
+	evictionStrategies    = []EvictionStrategy{ExpiryEviction{}, LRUEviction{}}
+)
+
+// ParseEvictionStrategy returns an EvictionStrategy by its name, "expiry" or "lru". A PriorityEviction requires a
+// priority function and cannot be created by name.
+func ParseEvictionStrategy(name string) (EvictionStrategy, error) {
+	strategy, err := evictionStrategyNames.Parse(name)
+	if err != nil {
+		return nil, err
+	}
+	return evictionStrategies[strategy], nil
+}
@@ -0,0 +1,200 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package storage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestEvictionStrategies(t *testing.T) {
+	now := time.Now()
+	bis := []BundleItem{
+		{Id: "a", Expires: now.Add(3 * time.Hour), LastUsed: now.Add(-1 * time.Minute), Properties: map[string]interface{}{"prio": 2}},
+		{Id: "b", Expires: now.Add(1 * time.Hour), LastUsed: now.Add(-2 * time.Minute), Properties: map[string]interface{}{"prio": 3}},
+		{Id: "c", Expires: now.Add(2 * time.Hour), LastUsed: now.Add(-3 * time.Minute), Properties: map[string]interface{}{"prio": 1}},
+	}
+
+	tests := []struct {
+		name     string
+		strategy EvictionStrategy
+		victim   string
+	}{
+		{"expiry", ExpiryEviction{}, "b"},
+		{"lru", LRUEviction{}, "c"},
+		{"priority", PriorityEviction{Priority: func(bi BundleItem) int { return bi.Properties["prio"].(int) }}, "c"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			victims := test.strategy.Victims(bis, 1)
+			if len(victims) != 1 {
+				t.Fatalf("expected one victim, got %d", len(victims))
+			} else if victims[0].Id != test.victim {
+				t.Fatalf("expected victim %s, got %s", test.victim, victims[0].Id)
+			}
+
+			if all := test.strategy.Victims(bis, 5); len(all) != len(bis) {
+				t.Fatalf("expected %d victims, got %d", len(bis), len(all))
+			}
+			if bis[0].Id != "a" || bis[1].Id != "b" || bis[2].Id != "c" {
+				t.Fatal("strategy altered the passed slice")
+			}
+		})
+	}
+}
+
+func TestParseEvictionStrategy(t *testing.T) {
+	for _, name := range []string{"", "expiry", "lru"} {
+		if _, err := ParseEvictionStrategy(name); err != nil {
+			t.Fatalf("parsing %q errored: %v", name, err)
+		}
+	}
+
+	if _, err := ParseEvictionStrategy("random"); err == nil {
+		t.Fatal("parsing an unknown strategy did not error")
+	}
+}
+
+func TestStoreCapacity(t *testing.T) {
+	testStore(t, func(store *Store) {
+		const capacity = 3
+
+		store.SetCapacity(capacity, nil)
+
+		var bids []bpv7.BundleID
+		for i := 0; i < 2*capacity; i++ {
+			// Each new bundle expires later, so the oldest should be evicted first. Distinct sources prevent equal
+			// bundle IDs for bundles created within the same millisecond.
+			b, err := bpv7.Builder().
+				Source(fmt.Sprintf("dtn://src-%d/", i)).
+				Destination("dtn://dst/").
+				CreationTimestampNow().
+				Lifetime(fmt.Sprintf("%dm", 10+i)).
+				PayloadBlock([]byte("hello world")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			bids = append(bids, b.ID())
+
+			if err := store.Push(b); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if n := store.Count(); n != capacity {
+			t.Fatalf("store holds %d bundles, expected %d", n, capacity)
+		}
+
+		for i, bid := range bids {
+			if known, expected := store.KnowsBundle(bid), i >= capacity; known != expected {
+				t.Fatalf("bundle %d: known is %t, expected %t", i, known, expected)
+			}
+		}
+	})
+}
+
+func TestStoreCapacityKeepsIncoming(t *testing.T) {
+	testStore(t, func(store *Store) {
+		store.SetCapacity(1, nil)
+
+		var bids []bpv7.BundleID
+		for _, lifetime := range []string{"10m", "5m"} {
+			b, err := bpv7.Builder().
+				Source("dtn://src-" + lifetime + "/").
+				Destination("dtn://dst/").
+				CreationTimestampNow().
+				Lifetime(lifetime).
+				PayloadBlock([]byte("hello world")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			bids = append(bids, b.ID())
+
+			if err := store.Push(b); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// The incoming bundle expires first, but must not be evicted right away.
+		if n := store.Count(); n != 1 {
+			t.Fatalf("store holds %d bundles, expected 1", n)
+		} else if store.KnowsBundle(bids[0]) {
+			t.Fatal("older bundle was not evicted")
+		} else if !store.KnowsBundle(bids[1]) {
+			t.Fatal("incoming bundle was evicted")
+		}
+	})
+}
+
+func TestStoreCapacitySparesProtected(t *testing.T) {
+	testStore(t, func(store *Store) {
+		var bids []bpv7.BundleID
+		for i := 0; i < 3; i++ {
+			b, err := bpv7.Builder().
+				Source(fmt.Sprintf("dtn://src-%d/", i)).
+				Destination("dtn://dst/").
+				CreationTimestampNow().
+				Lifetime(fmt.Sprintf("%dm", 10+i)).
+				PayloadBlock([]byte("hello world")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			bids = append(bids, b.ID())
+
+			if err := store.Push(b); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// The two bundles expiring first must not be evicted.
+		for i, bid := range bids[:2] {
+			bi, err := store.QueryId(bid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bi.Protected = i == 0
+			bi.AwaitingAck = i == 1
+			if err := store.Update(bi); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var evicted []bpv7.BundleID
+		store.SetEvictionHandler(func(bi BundleItem) {
+			evicted = append(evicted, bi.BId)
+			if err := store.Delete(bi.BId); err != nil {
+				t.Fatal(err)
+			}
+		})
+		store.SetCapacity(3, nil)
+
+		b, err := bpv7.Builder().
+			Source("dtn://src-3/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("20m").
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Push(b); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(evicted) != 1 || evicted[0] != bids[2] {
+			t.Fatalf("evicted %v, expected %v", evicted, bids[2])
+		} else if n := store.Count(); n != 3 {
+			t.Fatalf("store holds %d bundles, expected 3", n)
+		}
+	})
+}
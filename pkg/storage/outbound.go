@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package storage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// dirOutbound is the OutboundQueue's directory within a Store's directory.
+const dirOutbound string = "outbound"
+
+// OutboundQueue persists Bundles which were accepted to be sent to a specific peer. Each peer has its own queue,
+// separated from the Store's general state. Thus, queued Bundles survive a restart and can be re-attempted.
+type OutboundQueue struct {
+	sync.Mutex
+
+	dir string
+}
+
+// NewOutboundQueue creates a new OutboundQueue or opens an existing one within a Store's directory.
+func NewOutboundQueue(dir string) (*OutboundQueue, error) {
+	queueDir := path.Join(dir, dirOutbound)
+	if err := os.MkdirAll(queueDir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &OutboundQueue{dir: queueDir}, nil
+}
+
+// hashName creates a file name for some identifier.
+func hashName(id string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(id)))
+}
+
+// peerDir is the directory of a peer's queue.
+func (q *OutboundQueue) peerDir(peer bpv7.EndpointID) string {
+	return path.Join(q.dir, hashName(peer.String()))
+}
+
+// Enqueue a Bundle for a peer. Enqueuing an already queued Bundle overwrites the previous one.
+func (q *OutboundQueue) Enqueue(peer bpv7.EndpointID, b bpv7.Bundle) error {
+	q.Lock()
+	defer q.Unlock()
+
+	dir := q.peerDir(peer)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first to not leave a partial Bundle behind on a crash.
+	filename := path.Join(dir, hashName(b.ID().String()))
+	f, err := os.OpenFile(filename+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err := b.WriteBundle(f); err != nil {
+		_ = f.Close()
+		return err
+	} else if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(filename+".tmp", filename)
+}
+
+// Remove a Bundle from a peer's queue, e.g., after a successful transmission. Removing an unknown Bundle is no error.
+func (q *OutboundQueue) Remove(peer bpv7.EndpointID, bid bpv7.BundleID) error {
+	q.Lock()
+	defer q.Unlock()
+
+	err := os.Remove(path.Join(q.peerDir(peer), hashName(bid.String())))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Pending returns all Bundles queued for a peer.
+func (q *OutboundQueue) Pending(peer bpv7.EndpointID) (bs []bpv7.Bundle, err error) {
+	q.Lock()
+	defer q.Unlock()
+
+	dir := q.peerDir(peer)
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, info := range infos {
+		if info.IsDir() || path.Ext(info.Name()) == ".tmp" {
+			continue
+		}
+
+		f, fErr := os.Open(path.Join(dir, info.Name()))
+		if fErr != nil {
+			return nil, fErr
+		}
+
+		b, bErr := bpv7.ParseBundle(f)
+		_ = f.Close()
+		if bErr != nil {
+			return nil, fmt.Errorf("parsing queued bundle %s failed: %w", info.Name(), bErr)
+		}
+
+		bs = append(bs, b)
+	}
+	return
+}
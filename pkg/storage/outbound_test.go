@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestOutboundQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbound")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	peer1 := bpv7.MustNewEndpointID("dtn://peer1/")
+	peer2 := bpv7.MustNewEndpointID("dtn://peer2/")
+
+	var bs []bpv7.Bundle
+	for i := 0; i < 3; i++ {
+		b, bErr := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampTime(time.Unix(1600000000+int64(i), 0)).
+			Lifetime("87600h").
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if bErr != nil {
+			t.Fatal(bErr)
+		}
+		bs = append(bs, b)
+	}
+
+	q, err := NewOutboundQueue(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, b := range bs {
+		if err := q.Enqueue(peer1, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Remove(peer1, bs[1].ID()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen the queue, as after a restart.
+	q, err = NewOutboundQueue(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pending, err := q.Pending(peer1); err != nil {
+		t.Fatal(err)
+	} else if len(pending) != 2 {
+		t.Fatalf("expected two pending bundles, got %d", len(pending))
+	} else {
+		for _, b := range pending {
+			if id := b.ID(); id != bs[0].ID() && id != bs[2].ID() {
+				t.Fatalf("unexpected pending bundle %v", id)
+			}
+		}
+	}
+
+	if pending, err := q.Pending(peer2); err != nil {
+		t.Fatal(err)
+	} else if len(pending) != 0 {
+		t.Fatalf("expected no pending bundles for another peer, got %d", len(pending))
+	}
+
+	if err := q.Remove(peer2, bs[0].ID()); err != nil {
+		t.Fatalf("removing an unknown bundle errored: %v", err)
+	}
+}
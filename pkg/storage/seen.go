@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2021 Alvar Penning
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package storage
+
+import (
+	"time"
+
+	"github.com/timshannon/badgerhold"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// SeenItem records when a Bundle, identified by its "scrubbed" BundleID's string, was seen last and which peers are
+// known to possess it. SeenItems are independent of stored BundleItems and persist the deduplication state across
+// restarts.
+type SeenItem struct {
+	Id    string    `badgerhold:"key"`
+	Seen  time.Time `badgerholdIndex:"Seen"`
+	Peers []bpv7.EndpointID
+}
+
+// PushSeen inserts or refreshes a SeenItem.
+func (s *Store) PushSeen(id string, seen time.Time, peers []bpv7.EndpointID) error {
+	return s.bh.Upsert(id, SeenItem{Id: id, Seen: seen, Peers: peers})
+}
+
+// QuerySeen fetches all SeenItems seen after the given time, ordered by their time.
+func (s *Store) QuerySeen(after time.Time) (sis []SeenItem, err error) {
+	err = s.bh.Find(&sis, badgerhold.Where("Seen").Gt(after).Index("Seen").SortBy("Seen"))
+	return
+}
+
+// DeleteSeen removes a SeenItem. Removing an unknown SeenItem is no error.
+func (s *Store) DeleteSeen(id string) error {
+	if err := s.bh.Delete(id, SeenItem{}); err != nil && err != badgerhold.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// DeleteSeenBefore removes all SeenItems seen before the given time.
+func (s *Store) DeleteSeenBefore(before time.Time) error {
+	return s.bh.DeleteMatching(SeenItem{}, badgerhold.Where("Seen").Lt(before).Index("Seen"))
+}
@@ -6,8 +6,11 @@
 package storage
 
 import (
+	"fmt"
 	"os"
 	"path"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -24,10 +27,22 @@ const (
 
 // Store implements a storage for Bundles together with meta data.
 type Store struct {
+	// count of stored BundleItems, maintained on insertion and deletion. It must be the first field to be 64-bit
+	// aligned for atomic operations on 32-bit platforms.
+	count int64
+
+	// evicting is non-zero while evict runs, preventing Bundles pushed by an eviction handler from evicting others.
+	evicting uint32
+
 	bh *badgerhold.Store
 
 	badgerDir string
 	bundleDir string
+
+	capacityMutex sync.Mutex
+	capacity      int
+	eviction      EvictionStrategy
+	evictHandler  func(BundleItem)
 }
 
 // NewStore creates a new Store or opens an existing Store from the given path.
@@ -50,16 +65,28 @@ func NewStore(dir string) (s *Store, err error) {
 		return
 	}
 
-	if bh, bhErr := badgerhold.Open(opts); bhErr != nil {
+	bh, bhErr := badgerhold.Open(opts)
+	if bhErr != nil {
 		err = bhErr
-	} else {
-		s = &Store{
-			bh: bh,
+		return
+	}
 
-			badgerDir: badgerDir,
-			bundleDir: bundleDir,
-		}
+	var bis []BundleItem
+	if findErr := bh.Find(&bis, nil); findErr != nil {
+		_ = bh.Close()
+		err = findErr
+		return
+	}
+
+	s = &Store{
+		count: int64(len(bis)),
+
+		bh: bh,
+
+		badgerDir: badgerDir,
+		bundleDir: bundleDir,
 	}
+	s.migrate(bis)
 	return
 }
 
@@ -68,6 +95,83 @@ func (s *Store) Close() error {
 	return s.bh.Close()
 }
 
+// SetCapacity limits the amount of stored Bundles. If a new Bundle exceeds the capacity, the EvictionStrategy selects
+// Bundles to be deleted. A nil strategy defaults to an ExpiryEviction and a zero capacity means no limit.
+func (s *Store) SetCapacity(capacity int, strategy EvictionStrategy) {
+	if strategy == nil {
+		strategy = ExpiryEviction{}
+	}
+
+	s.capacityMutex.Lock()
+	defer s.capacityMutex.Unlock()
+
+	s.capacity = capacity
+	s.eviction = strategy
+}
+
+// SetEvictionHandler sets a function to be called for each Bundle selected for an eviction. This handler is
+// responsible for deleting the Bundle, e.g., together with a status report. Without a handler, evicted Bundles are
+// deleted directly.
+func (s *Store) SetEvictionHandler(handler func(BundleItem)) {
+	s.capacityMutex.Lock()
+	defer s.capacityMutex.Unlock()
+
+	s.evictHandler = handler
+}
+
+// Capacity returns the maximum amount of stored Bundles, as set by SetCapacity. Zero means no limit.
+func (s *Store) Capacity() int {
+	s.capacityMutex.Lock()
+	defer s.capacityMutex.Unlock()
+
+	return s.capacity
+}
+
+// evict Bundles selected by the EvictionStrategy until the capacity is no longer exceeded. The incoming Bundle, which
+// was just pushed, is never selected.
+func (s *Store) evict(incoming bpv7.BundleID) {
+	s.capacityMutex.Lock()
+	capacity, eviction, handler := s.capacity, s.eviction, s.evictHandler
+	s.capacityMutex.Unlock()
+
+	excess := s.Count() - capacity
+	if capacity <= 0 || excess <= 0 {
+		return
+	}
+
+	// A deletion status report for an evicted Bundle is pushed while evicting. Evicting another Bundle for this report
+	// might cascade through the whole Store. Thus, the report exceeds the capacity until the next Bundle is pushed.
+	if !atomic.CompareAndSwapUint32(&s.evicting, 0, 1) {
+		return
+	}
+	defer atomic.StoreUint32(&s.evicting, 0)
+
+	var bis []BundleItem
+	if err := s.bh.Find(&bis, badgerhold.Where("Id").Ne(incoming.Scrub().String())); err != nil {
+		log.WithError(err).Warn("Failed to get Bundles for eviction")
+		return
+	}
+
+	candidates := bis[:0]
+	for _, bi := range bis {
+		if evictable(bi) {
+			candidates = append(candidates, bi)
+		}
+	}
+
+	for _, bi := range eviction.Victims(candidates, excess) {
+		logger := log.WithField("bundle", bi.Id)
+		if handler != nil {
+			logger.Info("Evicting Bundle due to exceeded capacity")
+			handler(bi)
+		} else if err := s.Delete(bi.BId); err != nil {
+			logger.WithError(err).Warn("Failed to evict Bundle")
+		} else {
+			logger.Info("Evicted Bundle due to exceeded capacity")
+		}
+	}
+}
+
 // Push a new/received Bundle to the Store.
 func (s *Store) Push(b bpv7.Bundle) error {
 	bi := newBundleItem(b, s.bundleDir)
@@ -75,13 +179,19 @@ func (s *Store) Push(b bpv7.Bundle) error {
 	if biStore, err := s.QueryId(b.ID()); err != nil {
 		log.WithFields(log.Fields{
 			"bundle": b.ID().String(),
-		}).Info("Bundle ID is unknown, inserting BundleItem")
+		}).Debug("Bundle ID is unknown, inserting BundleItem")
 
 		if err := bi.Parts[0].storeBundle(b); err != nil {
 			return err
 		}
 
-		return s.bh.Insert(bi.Id, bi)
+		if err := s.bh.Insert(bi.Id, bi); err != nil {
+			return err
+		}
+		atomic.AddInt64(&s.count, 1)
+
+		s.evict(b.ID())
+		return nil
 	} else if bi.Fragmented {
 		if !biStore.Fragmented {
 			log.WithFields(log.Fields{
@@ -108,7 +218,7 @@ func (s *Store) Push(b bpv7.Bundle) error {
 		} else {
 			log.WithFields(log.Fields{
 				"bundle": b.ID().String(),
-			}).Info("Received new bundle fragment, updating BundleItem")
+			}).Debug("Received new bundle fragment, updating BundleItem")
 
 			if err := compPart.storeBundle(b); err != nil {
 				return err
@@ -126,12 +236,26 @@ func (s *Store) Push(b bpv7.Bundle) error {
 	}
 }
 
+// UpdateBundle replaces the stored serialization of an already known, unfragmented Bundle, e.g., after altering its
+// primary block. The BundleItem's meta data remains unchanged.
+func (s *Store) UpdateBundle(b bpv7.Bundle) error {
+	bi, err := s.QueryId(b.ID())
+	if err != nil {
+		return err
+	} else if bi.Fragmented {
+		return fmt.Errorf("bundle %v is stored fragmented and cannot be updated", b.ID())
+	}
+
+	return bi.Parts[0].storeBundle(b)
+}
+
 // Update an existing BundleItem.
 func (s *Store) Update(bi BundleItem) error {
 	log.WithFields(log.Fields{
 		"bundle": bi.Id,
 	}).Debug("Store updates BundleItem")
 
+	bi.LastUsed = time.Now()
 	return s.bh.Update(bi.Id, bi)
 }
 
@@ -152,7 +276,10 @@ func (s *Store) Delete(bid bpv7.BundleID) error {
 			}
 		}
 
-		return s.bh.Delete(bi.Id, BundleItem{})
+		if err := s.bh.Delete(bi.Id, BundleItem{}); err != nil {
+			return err
+		}
+		atomic.AddInt64(&s.count, -1)
 	}
 
 	return nil
@@ -182,12 +309,98 @@ func (s *Store) QueryId(bid bpv7.BundleID) (bi BundleItem, err error) {
 	return
 }
 
+// QueryFromStatusReport fetches the BundleItem referenced by a StatusReport's subject. In contrast to QueryId, the
+// full BundleID is matched. A fragment's offset and total data length must match one of the stored parts, unless the
+// whole Bundle is stored.
+func (s *Store) QueryFromStatusReport(sr bpv7.StatusReport) (bi BundleItem, err error) {
+	bid := sr.RefBundle
+	if bi, err = s.QueryId(bid); err != nil || !bid.IsFragment || !bi.Fragmented {
+		return
+	}
+
+	for _, part := range bi.Parts {
+		if part.FragmentOffset == bid.FragmentOffset && part.TotalDataLength == bid.TotalDataLength {
+			return
+		}
+	}
+
+	err = fmt.Errorf("fragment %v is not stored", bid)
+	return
+}
+
+// QueryAll fetches all stored Bundles.
+func (s *Store) QueryAll() (bis []BundleItem, err error) {
+	err = s.bh.Find(&bis, nil)
+	return
+}
+
 // QueryPending fetches all pending Bundles.
 func (s *Store) QueryPending() (bis []BundleItem, err error) {
 	err = s.bh.Find(&bis, badgerhold.Where("Pending").Eq(true))
 	return
 }
 
+// QueryAwaitingAck fetches all Bundles waiting for an acknowledgement, compare BundleItem.AwaitingAck.
+func (s *Store) QueryAwaitingAck() (bis []BundleItem, err error) {
+	err = s.bh.Find(&bis, badgerhold.Where("AwaitingAck").Eq(true).Index("AwaitingAck"))
+	return
+}
+
+// QueryIncomplete fetches all fragmented Bundles whose fragments do not yet suffice for a reassembly.
+func (s *Store) QueryIncomplete() (bis []BundleItem, err error) {
+	var fragmented []BundleItem
+	if err = s.bh.Find(&fragmented, badgerhold.Where("Fragmented").Eq(true)); err != nil {
+		return
+	}
+
+	for _, bi := range fragmented {
+		if !bi.IsComplete() {
+			bis = append(bis, bi)
+		}
+	}
+	return
+}
+
+// migrate BundleItems stored by an older version, lacking the indexed Source field. Updating them also creates their
+// missing index entries.
+func (s *Store) migrate(bis []BundleItem) {
+	for _, bi := range bis {
+		if bi.Source != "" || len(bi.Parts) == 0 {
+			continue
+		}
+
+		b, err := bi.Parts[0].Load()
+		if err != nil {
+			log.WithField("bundle", bi.Id).WithError(err).Warn("Failed to load outdated bundle item for its migration")
+			continue
+		}
+
+		bi.Source = b.PrimaryBlock.SourceNode.String()
+		if err := s.bh.Update(bi.Id, bi); err != nil {
+			log.WithField("bundle", bi.Id).WithError(err).Warn("Failed to migrate outdated bundle item")
+		} else {
+			log.WithField("bundle", bi.Id).Debug("Migrated outdated bundle item")
+		}
+	}
+}
+
+// QueryBySource fetches all Bundles sent by this source endpoint, e.g., to inspect everything a sensor has sent.
+func (s *Store) QueryBySource(source bpv7.EndpointID) (bis []BundleItem, err error) {
+	err = s.bh.Find(&bis, badgerhold.Where("Source").Eq(source.String()).Index("Source"))
+	return
+}
+
+// QueryByConstraint fetches all Bundles having this retention constraint, compare BundleItem.Constraints.
+func (s *Store) QueryByConstraint(constraint int) (bis []BundleItem, err error) {
+	err = s.bh.Find(&bis, badgerhold.Where("Constraints").Eq(NewConstraintSet(constraint)).Index("Constraints"))
+	return
+}
+
+// Count returns the amount of stored Bundles. This counter is maintained by the Store and does not query the database.
+func (s *Store) Count() int {
+	return int(atomic.LoadInt64(&s.count))
+}
+
 // KnowsBundle checks if such a Bundle is known.
 func (s *Store) KnowsBundle(bid bpv7.BundleID) bool {
 	_, err := s.QueryId(bid)
@@ -6,6 +6,7 @@ package storage
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"os"
@@ -181,3 +182,204 @@ func TestStoreFragmented(t *testing.T) {
 		}
 	})
 }
+
+func TestStoreQueryFromStatusReport(t *testing.T) {
+	testStore(t, func(store *Store) {
+		b, err := bpv7.Builder().
+			Source("ipn:23.42").
+			Destination("ipn:64.1").
+			CreationTimestampNow().
+			Lifetime("10m").
+			PayloadBlock(make([]byte, 1024)).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		frags, err := b.Fragment(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := store.Push(frags[0]); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			bid   bpv7.BundleID
+			known bool
+		}{
+			{frags[0].ID(), true},
+			{frags[1].ID(), false},
+			{b.ID(), true},
+		}
+
+		for _, test := range tests {
+			sr := bpv7.NewStatusReportForID(test.bid, bpv7.DeliveredBundle, bpv7.NoInformation, bpv7.DtnTimeNow())
+
+			if bi, err := store.QueryFromStatusReport(*sr); test.known && err != nil {
+				t.Fatalf("status report for %v errored: %v", test.bid, err)
+			} else if test.known && bi.BId != b.ID() {
+				t.Fatalf("status report for %v matched %v", test.bid, bi.BId)
+			} else if !test.known && err == nil {
+				t.Fatalf("status report for %v matched unstored fragment", test.bid)
+			}
+		}
+	})
+}
+
+func TestStoreQueryByConstraint(t *testing.T) {
+	testStore(t, func(store *Store) {
+		sets := []ConstraintSet{
+			NewConstraintSet(), NewConstraintSet(1), NewConstraintSet(3), NewConstraintSet(1, 3), NewConstraintSet(4),
+		}
+		expected := make(map[int]map[bpv7.BundleID]bool)
+
+		for i, set := range sets {
+			b, err := bpv7.Builder().
+				Source(fmt.Sprintf("dtn://src-%d/", i)).
+				Destination("dtn://dest/").
+				CreationTimestampNow().
+				Lifetime("10m").
+				PayloadBlock([]byte("hello world")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.Push(b); err != nil {
+				t.Fatal(err)
+			}
+
+			bi, err := store.QueryId(b.ID())
+			if err != nil {
+				t.Fatal(err)
+			}
+			bi.Constraints = set
+			if err := store.Update(bi); err != nil {
+				t.Fatal(err)
+			}
+
+			for constraint := 0; constraint < 6; constraint++ {
+				if expected[constraint] == nil {
+					expected[constraint] = make(map[bpv7.BundleID]bool)
+				}
+				if set.Has(constraint) {
+					expected[constraint][b.ID()] = true
+				}
+			}
+		}
+
+		for constraint := 0; constraint < 6; constraint++ {
+			bis, err := store.QueryByConstraint(constraint)
+			if err != nil {
+				t.Fatal(err)
+			} else if l := len(bis); l != len(expected[constraint]) {
+				t.Fatalf("Found %d BundleItems for constraint %d, instead of %d", l, constraint, len(expected[constraint]))
+			}
+
+			for _, bi := range bis {
+				if !expected[constraint][bi.BId] {
+					t.Fatalf("BundleItem %v does not have constraint %d", bi.BId, constraint)
+				}
+			}
+		}
+	})
+}
+
+func TestStoreQueryBySource(t *testing.T) {
+	testStore(t, func(store *Store) {
+		sources := []string{"dtn://sensor-a/", "dtn://sensor-b/", "ipn:23.1"}
+		expected := make(map[string]map[bpv7.BundleID]bool)
+
+		for i := 0; i < 12; i++ {
+			source := sources[i%len(sources)]
+
+			b, err := bpv7.Builder().
+				Source(source).
+				Destination("dtn://dest/").
+				CreationTimestampTime(time.Now().Add(time.Duration(i) * time.Second)).
+				Lifetime("10m").
+				PayloadBlock([]byte("hello world")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.Push(b); err != nil {
+				t.Fatal(err)
+			}
+
+			if expected[source] == nil {
+				expected[source] = make(map[bpv7.BundleID]bool)
+			}
+			expected[source][b.ID()] = true
+		}
+
+		for _, source := range append(sources, "dtn://sensor-c/") {
+			bis, err := store.QueryBySource(bpv7.MustNewEndpointID(source))
+			if err != nil {
+				t.Fatal(err)
+			} else if l := len(bis); l != len(expected[source]) {
+				t.Fatalf("Found %d BundleItems for %s, instead of %d", l, source, len(expected[source]))
+			}
+
+			for _, bi := range bis {
+				if !expected[source][bi.BId] {
+					t.Fatalf("BundleItem %v was not sent by %s", bi.BId, source)
+				}
+			}
+		}
+	})
+}
+
+func TestStoreMigrateSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := bpv7.Builder().
+		Source("dtn://sensor/").
+		Destination("dtn://dest/").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Push(b); err != nil {
+		t.Fatal(err)
+	}
+
+	// Mimic a BundleItem stored by an older version, lacking its Source.
+	bi, err := store.QueryId(b.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bi.Source = ""
+	if err := store.bh.Update(bi.Id, bi); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if store, err = NewStore(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if bis, err := store.QueryBySource(bpv7.MustNewEndpointID("dtn://sensor/")); err != nil {
+		t.Fatal(err)
+	} else if len(bis) != 1 || bis[0].BId != b.ID() {
+		t.Fatalf("QueryBySource returned %v after the migration", bis)
+	}
+}